@@ -2,20 +2,70 @@ package app
 
 import (
 	"context"
+	"expvar"
 	"flag"
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
+	"strings"
+	"sync"
 
+	"link-shortener-service/internal/codegen"
 	"link-shortener-service/internal/config"
+	"link-shortener-service/internal/geoip"
+	"link-shortener-service/internal/handler/add_workspace_domain"
+	"link-shortener-service/internal/handler/broken_links"
+	"link-shortener-service/internal/handler/create_api_key"
+	"link-shortener-service/internal/handler/create_workspace"
+	"link-shortener-service/internal/handler/delete_url"
+	"link-shortener-service/internal/handler/disable_url"
 	"link-shortener-service/internal/handler/expander_url"
+	"link-shortener-service/internal/handler/explain_rules"
+	"link-shortener-service/internal/handler/link_details"
+	"link-shortener-service/internal/handler/link_qr"
+	"link-shortener-service/internal/handler/link_stats"
+	"link-shortener-service/internal/handler/redirect_url"
+	"link-shortener-service/internal/handler/resolve_urls"
+	"link-shortener-service/internal/handler/revoke_api_key"
 	"link-shortener-service/internal/handler/shorter_url"
+	"link-shortener-service/internal/handler/update_url"
 	"link-shortener-service/internal/infastracture/repository/inmemory"
 	"link-shortener-service/internal/infastracture/repository/postgres"
+	"link-shortener-service/internal/linkcheck"
 	"link-shortener-service/internal/middleware"
+	"link-shortener-service/internal/model"
+	"link-shortener-service/internal/preview"
+	"link-shortener-service/internal/safehttp"
+	"link-shortener-service/internal/screening"
+	usecase_add_workspace_domain "link-shortener-service/internal/usecase/add_workspace_domain"
+	usecase_authenticate_key "link-shortener-service/internal/usecase/authenticate_key"
+	usecase_broken_links "link-shortener-service/internal/usecase/broken_links"
+	usecase_check_links "link-shortener-service/internal/usecase/check_links"
 	"link-shortener-service/internal/usecase/contract/repository"
+	"link-shortener-service/internal/usecase/contract/service"
+	usecase_create_api_key "link-shortener-service/internal/usecase/create_api_key"
+	usecase_create_workspace "link-shortener-service/internal/usecase/create_workspace"
+	usecase_delete_url "link-shortener-service/internal/usecase/delete_url"
+	usecase_destination_preview "link-shortener-service/internal/usecase/destination_preview"
+	usecase_disable_url "link-shortener-service/internal/usecase/disable_url"
 	usecase_expander_url "link-shortener-service/internal/usecase/expander_url"
+	usecase_explain_rules "link-shortener-service/internal/usecase/explain_rules"
+	usecase_fetch_metadata "link-shortener-service/internal/usecase/fetch_metadata"
+	usecase_limit_rate "link-shortener-service/internal/usecase/limit_rate"
+	usecase_link_details "link-shortener-service/internal/usecase/link_details"
+	usecase_link_qr "link-shortener-service/internal/usecase/link_qr"
+	usecase_link_stats "link-shortener-service/internal/usecase/link_stats"
+	usecase_resolve_urls "link-shortener-service/internal/usecase/resolve_urls"
+	usecase_revoke_api_key "link-shortener-service/internal/usecase/revoke_api_key"
+	usecase_rollup_clicks "link-shortener-service/internal/usecase/rollup_clicks"
+	usecase_route_domain "link-shortener-service/internal/usecase/route_domain"
 	usecase_shorter_url "link-shortener-service/internal/usecase/shorter_url"
+	usecase_sweep_expired "link-shortener-service/internal/usecase/sweep_expired"
+	usecase_sweep_rate_limits "link-shortener-service/internal/usecase/sweep_rate_limits"
+	usecase_track_click "link-shortener-service/internal/usecase/track_click"
+	usecase_update_url "link-shortener-service/internal/usecase/update_url"
+	"link-shortener-service/internal/worker"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gorilla/mux"
@@ -24,25 +74,66 @@ import (
 	"github.com/pressly/goose/v3"
 )
 
+// userAgent identifies the requests of the service to the destinations of links
+const userAgent = "link-shortener-service"
+
 type App struct {
-	server http.Server
-	config config.Config
-	pool   *pgxpool.Pool
+	server     http.Server
+	config     config.Config
+	pool       *pgxpool.Pool
+	repo       repository.URLRepository
+	clicks     repository.ClickRepository
+	stats      repository.StatsRepository
+	seq        repository.SequenceRepository
+	keys       repository.APIKeyRepository
+	workspaces repository.WorkspaceRepository
+	buckets    repository.RateLimitRepository
+	health     repository.LinkHealthRepository
+	previews   repository.LinkPreviewRepository
+	screener   *screening.Screener
+	locator    *geoip.Locator
+	workers    []worker.Worker
+
+	clickTracker interface {
+		worker.Worker
+		usecase_expander_url.ClickTracker
+	}
+	// prefetcher is nil when fetching the metadata of the destinations is disabled
+	prefetcher interface {
+		worker.Worker
+		usecase_shorter_url.Prefetcher
+		Refresh(ctx context.Context) error
+	}
+
+	workersCtx  context.Context
+	stopWorkers context.CancelFunc
+	workersWG   sync.WaitGroup
 }
 
 func (a *App) Run() error {
+	for _, w := range a.workers {
+		a.workersWG.Add(1)
+		go func() {
+			defer a.workersWG.Done()
+			w.Run(a.workersCtx)
+		}()
+	}
 	return a.server.ListenAndServe()
 }
 
 func (a *App) Stop() error {
 	log.Println("Gracefully shutdown...")
-	return a.server.Shutdown(context.Background())
+	err := a.server.Shutdown(context.Background())
+	a.stopWorkers()
+	a.workersWG.Wait()
+	return err
 }
 
 func NewApp(ctx context.Context, cfg config.Config) (*App, error) {
 	a := &App{
 		config: cfg,
 	}
+	a.workersCtx, a.stopWorkers = context.WithCancel(context.Background())
 
 	err := a.setup(ctx)
 	if err != nil {
@@ -55,7 +146,13 @@ func NewApp(ctx context.Context, cfg config.Config) (*App, error) {
 func (a *App) setup(ctx context.Context) error {
 	funcs := []func(context.Context) error{
 		a.newPool,
+		a.setupRepository,
+		a.setupClickTracker,
+		a.setupScreener,
+		a.setupLocator,
+		a.setupPrefetcher,
 		a.setupHttpServer,
+		a.setupWorkers,
 		a.runMigrationsDB,
 	}
 
@@ -68,31 +165,269 @@ func (a *App) setup(ctx context.Context) error {
 	return nil
 }
 
-func (a *App) setupHttpServer(_ context.Context) error {
-	var rep repository.URLRepository
+func (a *App) setupRepository(_ context.Context) error {
 	switch a.config.AppSettings.Storage {
 	case "db":
-		rep = postgres.NewDBRepository(a.pool)
+		repo := postgres.NewDBRepository(a.pool)
+		a.repo, a.clicks, a.stats, a.seq, a.keys, a.workspaces, a.health, a.previews = repo, repo, repo, repo, repo, repo, repo, repo
 	case "map":
-		rep = inmemory.NewMapRepository()
+		repo := inmemory.NewMapRepository()
+		a.repo, a.clicks, a.stats, a.seq, a.keys, a.workspaces, a.health, a.previews = repo, repo, repo, repo, repo, repo, repo, repo
 	default:
 		return fmt.Errorf("got unknown storage type from config: %s", a.config.AppSettings.Storage)
 	}
+
+	switch a.config.AppSettings.RateLimitStore {
+	case "db":
+		a.buckets = postgres.NewDBRepository(a.pool)
+	case "map":
+		a.buckets = inmemory.NewMapRepository()
+	default:
+		return fmt.Errorf("got unknown rate limit store from config: %s", a.config.AppSettings.RateLimitStore)
+	}
+
+	return nil
+}
+
+func (a *App) setupClickTracker(_ context.Context) error {
+	settings := a.config.AppSettings
+	if settings.ClickQueueSize <= 0 || settings.ClickBatchSize <= 0 || settings.ClickFlushInterval <= 0 {
+		return fmt.Errorf("got non-positive click tracking settings from config: queue %d, batch %d, flush interval %s",
+			settings.ClickQueueSize, settings.ClickBatchSize, settings.ClickFlushInterval)
+	}
+	a.clickTracker = usecase_track_click.NewUsecase(a.clicks, usecase_track_click.Policy{
+		QueueSize:     settings.ClickQueueSize,
+		BatchSize:     settings.ClickBatchSize,
+		FlushInterval: settings.ClickFlushInterval,
+	})
+
+	return nil
+}
+
+func (a *App) setupScreener(_ context.Context) error {
+	screener, err := screening.New(screening.Sources{
+		DomainFiles:     a.config.AppSettings.BlocklistDomainFiles,
+		PatternFiles:    a.config.AppSettings.BlocklistPatternFiles,
+		HashPrefixFiles: a.config.AppSettings.BlocklistHashPrefixFiles,
+	})
+	if err != nil {
+		return fmt.Errorf("got invalid blocklists from config: %w", err)
+	}
+	a.screener = screener
+
+	return nil
+}
+
+func (a *App) setupLocator(_ context.Context) error {
+	file := a.config.AppSettings.GeoIPDatabaseFile
+	if file == "" {
+		return nil
+	}
+	locator, err := geoip.Open(file)
+	if err != nil {
+		return fmt.Errorf("got invalid GeoIP database from config: %w", err)
+	}
+	a.locator = locator
+
+	return nil
+}
+
+func (a *App) setupPrefetcher(_ context.Context) error {
+	settings := a.config.AppSettings
+	if settings.PreviewFetchInterval <= 0 {
+		return nil
+	}
+	if settings.PreviewQueueSize <= 0 || settings.PreviewBatchSize <= 0 || settings.PreviewRefreshInterval <= 0 ||
+		settings.PreviewRetry <= 0 || settings.PreviewMaxBytes <= 0 {
+		return fmt.Errorf("got non-positive link preview settings from config: queue %d, batch %d, refresh %s, retry %s, max bytes %d",
+			settings.PreviewQueueSize, settings.PreviewBatchSize, settings.PreviewRefreshInterval, settings.PreviewRetry, settings.PreviewMaxBytes)
+	}
+
+	fetcher, err := preview.New(safehttp.NewClient(settings.PreviewTimeout), preview.Options{
+		MaxBytes:  settings.PreviewMaxBytes,
+		UserAgent: userAgent,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create link preview fetcher: %w", err)
+	}
+	a.prefetcher = usecase_fetch_metadata.NewUsecase(a.previews, fetcher, usecase_fetch_metadata.Policy{
+		QueueSize:   settings.PreviewQueueSize,
+		BatchSize:   settings.PreviewBatchSize,
+		Concurrency: settings.PreviewConcurrency,
+		Refresh:     settings.PreviewRefreshInterval,
+		Retry:       settings.PreviewRetry,
+	})
+
+	return nil
+}
+
+func (a *App) setupHttpServer(_ context.Context) error {
+	if !redirect_url.IsRedirectStatus(a.config.AppSettings.RedirectStatus) {
+		return fmt.Errorf("got unsupported redirect status from config: %d", a.config.AppSettings.RedirectStatus)
+	}
+	if a.config.AppSettings.InterstitialCountdown < 0 {
+		return fmt.Errorf("got negative interstitial countdown from config: %s", a.config.AppSettings.InterstitialCountdown)
+	}
+	if a.config.AppSettings.PasswordMaxAttempts <= 0 || a.config.AppSettings.PasswordLockout <= 0 {
+		return fmt.Errorf("got non-positive password lockout settings from config: max attempts %d, lockout %s",
+			a.config.AppSettings.PasswordMaxAttempts, a.config.AppSettings.PasswordLockout)
+	}
 	valid := validator.New(validator.WithRequiredStructEnabled())
 
+	if a.config.AppSettings.CodeMaxAttempts <= 0 {
+		return fmt.Errorf("got non-positive code max attempts from config: %d", a.config.AppSettings.CodeMaxAttempts)
+	}
+	alphabet, err := codegen.Alphabet(a.config.AppSettings.CodeAlphabet)
+	if err != nil {
+		return fmt.Errorf("got invalid code alphabet from config: %w", err)
+	}
+	caseInsensitive := a.config.AppSettings.CaseInsensitiveCodes
+	if caseInsensitive && strings.ToLower(alphabet) != alphabet {
+		return fmt.Errorf("got case insensitive codes with a mixed case alphabet from config: %s", alphabet)
+	}
+	blocklist := codegen.NewBlocklist(a.config.AppSettings.BlockedWords)
+
+	fragments := a.config.AppSettings.FragmentsPolicy
+	if fragments != usecase_shorter_url.FragmentsKeep && fragments != usecase_shorter_url.FragmentsStrip {
+		return fmt.Errorf("got unknown fragments policy from config: %s", fragments)
+	}
+
+	codes, err := codegen.NewAdaptive(
+		codegen.Options{
+			Kind:     a.config.AppSettings.CodeGenerator,
+			Alphabet: alphabet,
+			Length:   a.config.AppSettings.URLLength,
+			Key:      a.config.AppSettings.CodeGeneratorKey,
+			// generated codes must not shadow the routes an alias can't take either
+			Reserved: a.config.AppSettings.ReservedAliases,
+		},
+		a.seq,
+		blocklist,
+		codegen.GrowthPolicy{
+			Threshold: a.config.AppSettings.CodeCollisionThreshold,
+			Window:    a.config.AppSettings.CodeCollisionWindow,
+			MaxLength: a.config.AppSettings.CodeMaxLength,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("got invalid code generator settings from config: %w", err)
+	}
+
+	base, err := url.Parse(a.config.AppSettings.FirstURLPart)
+	if err != nil || base.Host == "" {
+		return fmt.Errorf("got invalid first URL part from config: %s", a.config.AppSettings.FirstURLPart)
+	}
+	defaultHost := model.NormalizeHost(base.Host)
+	if a.config.AppSettings.DomainCacheTTL < 0 {
+		return fmt.Errorf("got negative domain cache TTL from config: %s", a.config.AppSettings.DomainCacheTTL)
+	}
+	domains := usecase_route_domain.NewUsecase(a.workspaces, defaultHost, a.config.AppSettings.DomainCacheTTL)
+
+	var prefetcher usecase_shorter_url.Prefetcher
+	if a.prefetcher != nil {
+		prefetcher = a.prefetcher
+	}
 	shorterUseCase := usecase_shorter_url.NewUsecase(
-		rep,
+		a.repo,
+		a.workspaces,
+		codes,
+		a.screener,
+		prefetcher,
 		a.config.AppSettings.FirstURLPart,
-		a.config.AppSettings.URLLength,
+		a.config.AppSettings.CodeMaxAttempts,
+		usecase_shorter_url.AliasPolicy{
+			MinLength:       a.config.AppSettings.AliasMinLength,
+			MaxLength:       a.config.AppSettings.AliasMaxLength,
+			Reserved:        a.config.AppSettings.ReservedAliases,
+			CaseInsensitive: caseInsensitive,
+			Blocklist:       blocklist,
+		},
+		usecase_shorter_url.CanonicalPolicy{
+			StripTracking: a.config.AppSettings.StripTrackingParams,
+			Fragments:     fragments,
+		},
 	)
-	shorter := shorter_url.New(shorterUseCase, valid)
+	shorter := shorter_url.New(shorterUseCase, valid, a.config.AppSettings.BatchMaxSize)
+	// links stored by older versions get the dedup keys of the current canonical policy
+	a.workers = append(a.workers, worker.NewOnce("dedup keys backfill", shorterUseCase.BackfillDedupKeys))
+
+	proxies, err := middleware.ParseTrustedProxies(a.config.AppSettings.TrustedProxies)
+	if err != nil {
+		return fmt.Errorf("got invalid trusted proxies from config: %w", err)
+	}
+
+	var locator service.Locator
+	if a.locator != nil {
+		locator = a.locator
+	}
+	expanderUseCase := usecase_expander_url.NewUsecase(a.repo, domains, a.clickTracker, a.screener, locator, usecase_expander_url.PasswordPolicy{
+		MaxAttempts: a.config.AppSettings.PasswordMaxAttempts,
+		Lockout:     a.config.AppSettings.PasswordLockout,
+	}, caseInsensitive)
+	expander := expander_url.New(expanderUseCase, valid, proxies)
+	redirect := redirect_url.New(expanderUseCase, usecase_destination_preview.NewUsecase(a.previews),
+		a.config.AppSettings.RedirectStatus, a.config.AppSettings.InterstitialCountdown, proxies)
+
+	deleter := delete_url.New(usecase_delete_url.NewUsecase(a.repo), caseInsensitive)
+	disabler := disable_url.New(usecase_disable_url.NewUsecase(a.repo), caseInsensitive)
+	stats := link_stats.New(usecase_link_stats.NewUsecase(a.repo, a.stats), caseInsensitive)
+	resolver := resolve_urls.New(usecase_resolve_urls.NewUsecase(a.repo, domains, a.screener, caseInsensitive), valid, a.config.AppSettings.BatchMaxSize)
+	updater := update_url.New(usecase_update_url.NewUsecase(a.repo, a.screener, a.config.AppSettings.FirstURLPart), valid, caseInsensitive)
+	keyCreator := create_api_key.New(usecase_create_api_key.NewUsecase(a.keys), valid)
+	keyRevoker := revoke_api_key.New(usecase_revoke_api_key.NewUsecase(a.keys))
+	workspaceCreator := create_workspace.New(usecase_create_workspace.NewUsecase(a.workspaces, defaultHost), valid)
+	domainAdder := add_workspace_domain.New(usecase_add_workspace_domain.NewUsecase(a.workspaces, defaultHost), valid)
+	details := link_details.New(usecase_link_details.NewUsecase(a.repo, a.previews, a.config.AppSettings.FirstURLPart), caseInsensitive)
+	qr := link_qr.New(usecase_link_qr.NewUsecase(a.repo, a.config.AppSettings.FirstURLPart), caseInsensitive)
+	rules := explain_rules.New(usecase_explain_rules.NewUsecase(a.repo, locator), caseInsensitive, proxies)
+	brokenLinks := broken_links.New(usecase_broken_links.NewUsecase(a.health, a.config.AppSettings.FirstURLPart))
+
+	limiter := middleware.NewRateLimiter(usecase_limit_rate.NewUsecase(a.buckets), proxies)
+	// the limits are checked after authentication, so API keys get their own buckets.
+	// A batch counts as one creation, its size is limited by BatchMaxSize instead
+	create := limiter.Limit("create", a.createLimit())
+	resolve := limiter.Limit("resolve", a.resolveLimit())
 
-	expanderUseCase := usecase_expander_url.NewUsecase(rep)
-	expander := expander_url.New(expanderUseCase, valid)
+	// the client IP is limited before the API key is checked, wrong keys use up the limit as well
+	auth := middleware.NewAuth(usecase_authenticate_key.NewUsecase(a.keys, a.config.AppSettings.AdminAPIKey))
+	guard := limiter.LimitIP("auth", a.authLimit())
+	require := func(scope model.Scope) func(http.Handler) http.Handler {
+		requireScope := auth.Require(scope)
+		return func(next http.Handler) http.Handler {
+			return guard(requireScope(next))
+		}
+	}
+	read, write, admin := require(model.ScopeRead), require(model.ScopeWrite), require(model.ScopeAdmin)
 
+	// redirects stay public, everything else needs an API key
 	r := mux.NewRouter()
-	r.HandleFunc("/", expander.ExpanderURL).Methods("GET")
-	r.HandleFunc("/", shorter.ShorterURL).Methods("POST")
+	r.Handle("/debug/vars", admin(expvar.Handler())).Methods("GET")
+	r.Handle("/", resolve(http.HandlerFunc(expander.ExpanderURL))).Methods("GET")
+	r.Handle("/", write(create(http.HandlerFunc(shorter.ShorterURL)))).Methods("POST")
+	r.Handle("/{code}", resolve(http.HandlerFunc(redirect.Redirect))).Methods("GET", "HEAD", "POST")
+
+	keys := r.PathPrefix("/api/keys").Subrouter()
+	keys.Use(admin)
+	keys.HandleFunc("", keyCreator.CreateAPIKey).Methods("POST")
+	keys.HandleFunc("/{id}", keyRevoker.RevokeAPIKey).Methods("DELETE")
+
+	workspaces := r.PathPrefix("/api/workspaces").Subrouter()
+	workspaces.Use(admin)
+	workspaces.HandleFunc("", workspaceCreator.CreateWorkspace).Methods("POST")
+	workspaces.HandleFunc("/{id}/domains", domainAdder.AddDomain).Methods("POST")
+
+	api := r.PathPrefix("/api/links").Subrouter()
+	api.Handle("/batch", write(create(http.HandlerFunc(shorter.BatchShorterURL)))).Methods("POST")
+	api.Handle("/resolve", read(resolve(http.HandlerFunc(resolver.ResolveURLs)))).Methods("POST")
+	api.Handle("/broken", read(http.HandlerFunc(brokenLinks.BrokenLinks))).Methods("GET")
+	api.Handle("/{code}", read(http.HandlerFunc(details.Details))).Methods("GET")
+	api.Handle("/{code}", write(http.HandlerFunc(deleter.DeleteURL))).Methods("DELETE")
+	api.Handle("/{code}", write(http.HandlerFunc(updater.UpdateURL))).Methods("PATCH")
+	api.Handle("/{code}/disable", write(http.HandlerFunc(disabler.DisableURL))).Methods("POST")
+	api.Handle("/{code}/enable", write(http.HandlerFunc(disabler.EnableURL))).Methods("POST")
+	api.Handle("/{code}/stats", read(http.HandlerFunc(stats.Stats))).Methods("GET")
+	api.Handle("/{code}/qr", read(http.HandlerFunc(qr.QR))).Methods("GET")
+	api.Handle("/{code}/rules/explain", read(http.HandlerFunc(rules.Explain))).Methods("GET")
 
 	h := middleware.LoggerMiddleware(r)
 	h = middleware.PanicMiddleware(h)
@@ -106,6 +441,77 @@ func (a *App) setupHttpServer(_ context.Context) error {
 	return nil
 }
 
+func (a *App) setupWorkers(_ context.Context) error {
+	settings := a.config.AppSettings
+	if settings.SweepInterval <= 0 || settings.StatsRollupInterval <= 0 || settings.BlocklistReloadInterval <= 0 {
+		return fmt.Errorf("got non-positive worker intervals from config: sweep %s, stats rollup %s, blocklist reload %s",
+			settings.SweepInterval, settings.StatsRollupInterval, settings.BlocklistReloadInterval)
+	}
+	if a.locator != nil && settings.GeoIPReloadInterval <= 0 {
+		return fmt.Errorf("got non-positive GeoIP reload interval from config: %s", settings.GeoIPReloadInterval)
+	}
+
+	sweeper := usecase_sweep_expired.NewUsecase(a.repo)
+	rollup := usecase_rollup_clicks.NewUsecase(a.stats)
+	buckets := usecase_sweep_rate_limits.NewUsecase(a.buckets, max(a.createLimit().FullAfter(), a.resolveLimit().FullAfter(), a.authLimit().FullAfter()))
+	a.workers = append(a.workers,
+		worker.NewPeriodic("expired links sweeper", a.config.AppSettings.SweepInterval, sweeper.Run),
+		a.clickTracker,
+		worker.NewPeriodic("clicks rollup", a.config.AppSettings.StatsRollupInterval, rollup.Run),
+		worker.NewPeriodic("rate limit buckets sweeper", a.config.AppSettings.SweepInterval, buckets.Run),
+		worker.NewPeriodic("blocklists reload", a.config.AppSettings.BlocklistReloadInterval, a.screener.Reload),
+	)
+
+	if settings.LinkCheckInterval > 0 {
+		if settings.LinkCheckBatchSize <= 0 || settings.LinkRecheckInterval <= 0 || settings.LinkCheckRetry <= 0 {
+			return fmt.Errorf("got non-positive link check settings from config: batch %d, recheck %s, retry %s",
+				settings.LinkCheckBatchSize, settings.LinkRecheckInterval, settings.LinkCheckRetry)
+		}
+		// the checker limits the time of every request itself
+		checker, err := linkcheck.New(safehttp.NewClient(0), linkcheck.Options{
+			Concurrency: settings.LinkCheckConcurrency,
+			HostDelay:   settings.LinkCheckHostDelay,
+			Timeout:     settings.LinkCheckTimeout,
+			UserAgent:   userAgent,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create link checker: %w", err)
+		}
+		checkLinks := usecase_check_links.NewUsecase(a.health, checker, usecase_check_links.Policy{
+			BatchSize: settings.LinkCheckBatchSize,
+			Recheck:   settings.LinkRecheckInterval,
+			Retry:     settings.LinkCheckRetry,
+		})
+		a.workers = append(a.workers, worker.NewPeriodic("dead link checker", settings.LinkCheckInterval, checkLinks.Run))
+	}
+
+	if a.locator != nil {
+		a.workers = append(a.workers,
+			worker.NewPeriodic("GeoIP database reload", settings.GeoIPReloadInterval, a.locator.Reload))
+	}
+
+	if a.prefetcher != nil {
+		a.workers = append(a.workers,
+			a.prefetcher,
+			worker.NewPeriodic("link metadata fetcher", settings.PreviewFetchInterval, a.prefetcher.Refresh),
+		)
+	}
+
+	return nil
+}
+
+func (a *App) createLimit() model.RateLimit {
+	return model.RateLimit{Rate: a.config.AppSettings.CreateRate, Burst: a.config.AppSettings.CreateBurst}
+}
+
+func (a *App) resolveLimit() model.RateLimit {
+	return model.RateLimit{Rate: a.config.AppSettings.ResolveRate, Burst: a.config.AppSettings.ResolveBurst}
+}
+
+func (a *App) authLimit() model.RateLimit {
+	return model.RateLimit{Rate: a.config.AppSettings.AuthRate, Burst: a.config.AppSettings.AuthBurst}
+}
+
 func (a *App) newPool(ctx context.Context) error {
 	pool, err := pgxpool.New(ctx, a.config.DB.Conn)
 	if err != nil {
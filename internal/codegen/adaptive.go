@@ -0,0 +1,158 @@
+package codegen
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+)
+
+var (
+	codesGenerated = expvar.NewInt("codes_generated")
+	codeCollisions = expvar.NewInt("code_collisions")
+	codeLength     = expvar.NewInt("code_length")
+	codesBlocked   = expvar.NewInt("codes_blocked")
+)
+
+// maxBlockedRetries bounds how many times codes containing blocked words or reserved codes are regenerated
+const maxBlockedRetries = 10
+
+// GrowthPolicy makes codes one symbol longer when more than Threshold of the last Window
+// generated codes were taken, up to MaxLength
+type GrowthPolicy struct {
+	Threshold float64
+	Window    int
+	MaxLength int
+}
+
+// Adaptive generates codes of a length that grows with the observed collision rate
+type Adaptive struct {
+	mu        sync.RWMutex
+	newGen    func(length int) (Generator, error)
+	generator Generator
+	blocklist *Blocklist
+	reserved  map[string]struct{}
+	length    int
+	policy    GrowthPolicy
+
+	generated int
+	collided  int
+}
+
+// NewAdaptive starts with codes of opts.Length symbols, codes containing a word of the blocklist
+// and the reserved codes of opts are never returned
+func NewAdaptive(opts Options, seq Sequence, blocklist *Blocklist, policy GrowthPolicy) (*Adaptive, error) {
+	if policy.MaxLength < opts.Length {
+		return nil, fmt.Errorf("%w: max length %d is less than %d", ErrInvalidLength, policy.MaxLength, opts.Length)
+	}
+	reserved := make(map[string]struct{}, len(opts.Reserved))
+	for _, code := range opts.Reserved {
+		reserved[strings.ToLower(strings.TrimSpace(code))] = struct{}{}
+	}
+
+	newGen := func(length int) (Generator, error) {
+		opts.Length = length
+		return New(opts, seq)
+	}
+	generator, err := newGen(opts.Length)
+	if err != nil {
+		return nil, err
+	}
+	codeLength.Set(int64(opts.Length))
+
+	return &Adaptive{
+		newGen:    newGen,
+		generator: generator,
+		blocklist: blocklist,
+		reserved:  reserved,
+		length:    opts.Length,
+		policy:    policy,
+	}, nil
+}
+
+func (a *Adaptive) Generate(ctx context.Context, originals []string, attempt int) ([]string, error) {
+	a.mu.RLock()
+	generator := a.generator
+	a.mu.RUnlock()
+
+	codes, err := generator.Generate(ctx, originals, attempt)
+	if err != nil {
+		return nil, err
+	}
+
+	// blocked and reserved codes are replaced by codes of the next attempts, which differ even for the hash generator
+	for try := 1; ; try++ {
+		var blocked []int
+		for i, code := range codes {
+			if a.blocked(code) {
+				blocked = append(blocked, i)
+			}
+		}
+		if len(blocked) == 0 {
+			return codes, nil
+		}
+		if try > maxBlockedRetries {
+			return nil, fmt.Errorf("%w: %d codes", ErrBlockedCodes, len(blocked))
+		}
+		codesBlocked.Add(int64(len(blocked)))
+
+		retry := make([]string, len(blocked))
+		for j, i := range blocked {
+			retry[j] = originals[i]
+		}
+		replacements, err := generator.Generate(ctx, retry, attempt+try)
+		if err != nil {
+			return nil, err
+		}
+		for j, i := range blocked {
+			codes[i] = replacements[j]
+		}
+	}
+}
+
+func (a *Adaptive) blocked(code string) bool {
+	if _, ok := a.reserved[strings.ToLower(code)]; ok {
+		return true
+	}
+	return a.blocklist.Contains(code)
+}
+
+// Observe reports how many generated codes were tried and how many of them were already taken
+func (a *Adaptive) Observe(generated, collided int) {
+	codesGenerated.Add(int64(generated))
+	codeCollisions.Add(int64(collided))
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.generated += generated
+	a.collided += collided
+	if a.generated < a.policy.Window {
+		return
+	}
+
+	rate := float64(a.collided) / float64(a.generated)
+	a.generated, a.collided = 0, 0
+	if rate <= a.policy.Threshold || a.length >= a.policy.MaxLength {
+		return
+	}
+
+	generator, err := a.newGen(a.length + 1)
+	if err != nil {
+		log.Printf("failed to grow short codes to %d symbols: %v", a.length+1, err)
+		return
+	}
+	a.generator = generator
+	a.length++
+	codeLength.Set(int64(a.length))
+	log.Printf("collision rate %.2f is over %.2f, short codes grow to %d symbols", rate, a.policy.Threshold, a.length)
+}
+
+func (a *Adaptive) Length() int {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return a.length
+}
@@ -0,0 +1,55 @@
+package codegen
+
+import (
+	"strings"
+)
+
+// leetReplacer undoes the usual digit substitutions, so "h3ll0" is matched by "hello"
+var leetReplacer = strings.NewReplacer(
+	"0", "o",
+	"1", "i",
+	"3", "e",
+	"4", "a",
+	"5", "s",
+	"7", "t",
+	"-", "",
+	"_", "",
+)
+
+// Blocklist matches codes containing any of its words regardless of case, separators and digit substitutions.
+// A nil Blocklist matches nothing
+type Blocklist struct {
+	words []string
+}
+
+func NewBlocklist(words []string) *Blocklist {
+	b := &Blocklist{}
+	for _, word := range words {
+		word = normalizeWord(strings.TrimSpace(word))
+		if word != "" {
+			b.words = append(b.words, word)
+		}
+	}
+	return b
+}
+
+func (b *Blocklist) Contains(code string) bool {
+	if b == nil {
+		return false
+	}
+
+	normalized := normalizeWord(code)
+	// "1" may stand for "l" as well as for "i"
+	withL := strings.ReplaceAll(strings.ToLower(code), "1", "l")
+	withL = normalizeWord(withL)
+	for _, word := range b.words {
+		if strings.Contains(normalized, word) || strings.Contains(withL, word) {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeWord(word string) string {
+	return leetReplacer.Replace(strings.ToLower(word))
+}
@@ -0,0 +1,137 @@
+// Package codegen generates short codes for new links
+package codegen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"unicode"
+)
+
+const (
+	KindRandom   = "random"
+	KindSequence = "sequence"
+	KindHash     = "hash"
+	KindFeistel  = "feistel"
+
+	Base62 = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	// Base58 drops 0, O, I and l from Base62
+	Base58 = "123456789abcdefghijkmnopqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ"
+	// Crockford is the Crockford base32 alphabet without I, L, O and U, written in lowercase
+	Crockford = "0123456789abcdefghjkmnpqrstvwxyz"
+	Lowercase = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+	// MinKeyLength is the shortest key of the feistel permutation, shorter keys make the codes guessable
+	MinKeyLength = 16
+)
+
+var alphabets = map[string]string{
+	"base62":    Base62,
+	"base58":    Base58,
+	"crockford": Crockford,
+	"lowercase": Lowercase,
+}
+
+var (
+	ErrUnknownKind       = errors.New("unknown code generator")
+	ErrInvalidLength     = errors.New("invalid code length")
+	ErrInvalidAlphabet   = errors.New("invalid alphabet")
+	ErrInvalidKey        = errors.New("invalid code generator key")
+	ErrSequence          = errors.New("failed to get sequence values")
+	ErrBlockedCodes      = errors.New("failed to generate codes without blocked words and reserved codes")
+	ErrSequenceExhausted = errors.New("sequence does not fit into the code length")
+)
+
+// Generator returns one code per original URL, attempt is the number of earlier
+// attempts for the same URLs that ended with a taken code
+type Generator interface {
+	Generate(ctx context.Context, originals []string, attempt int) ([]string, error)
+}
+
+// Sequence hands out increasing values that are never reused
+type Sequence interface {
+	NextCodeSequences(ctx context.Context, n int) ([]int64, error)
+}
+
+type Options struct {
+	Kind string
+	// Alphabet is the symbols of the codes, Base62 when empty
+	Alphabet string
+	// Length is the length of the codes, the sequence codes are as short as possible and ignore it
+	Length int
+	// Key keys the feistel permutation, it needs at least MinKeyLength bytes
+	Key string
+	// Reserved is the codes that are never returned regardless of case, like the reserved aliases.
+	// Only Adaptive filters them
+	Reserved []string
+}
+
+func New(opts Options, seq Sequence) (Generator, error) {
+	if opts.Length <= 0 {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidLength, opts.Length)
+	}
+	alphabet := opts.Alphabet
+	if alphabet == "" {
+		alphabet = Base62
+	}
+
+	switch opts.Kind {
+	case KindRandom:
+		return newRandom(alphabet, opts.Length), nil
+	case KindSequence:
+		return newSequence(alphabet, seq), nil
+	case KindHash:
+		return newHash(alphabet, opts.Length), nil
+	case KindFeistel:
+		if len(opts.Key) < MinKeyLength {
+			return nil, fmt.Errorf("%w: feistel needs at least %d bytes", ErrInvalidKey, MinKeyLength)
+		}
+		return newFeistel(alphabet, opts.Length, opts.Key, seq), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKind, opts.Kind)
+	}
+}
+
+// Alphabet returns the alphabet by its name (base62, base58, crockford or lowercase),
+// any other value is used as the alphabet itself
+func Alphabet(name string) (string, error) {
+	if alphabet, ok := alphabets[name]; ok {
+		return alphabet, nil
+	}
+	if len(name) < 2 {
+		return "", fmt.Errorf("%w: at least 2 symbols are required", ErrInvalidAlphabet)
+	}
+
+	seen := make(map[rune]struct{}, len(name))
+	for _, r := range name {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_') {
+			return "", fmt.Errorf("%w: %q can't be used in short URLs", ErrInvalidAlphabet, r)
+		}
+		if _, ok := seen[r]; ok {
+			return "", fmt.Errorf("%w: %q is repeated", ErrInvalidAlphabet, r)
+		}
+		seen[r] = struct{}{}
+	}
+	return name, nil
+}
+
+// encode writes n in the base of the alphabet, left padded with the zero digit up to width
+func encode(n uint64, alphabet string, width int) string {
+	base := uint64(len(alphabet))
+	var b []byte
+	for n > 0 || len(b) < width {
+		b = append(b, alphabet[n%base])
+		n /= base
+	}
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+	return string(b)
+}
+
+// capacityBits is the number of bits whose every value fits into width digits of the alphabet
+func capacityBits(alphabet string, width int) int {
+	bits := int(math.Floor(float64(width) * math.Log2(float64(len(alphabet)))))
+	return min(bits, 64)
+}
@@ -0,0 +1,247 @@
+package codegen
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type counter struct {
+	last int64
+	err  error
+}
+
+func (c *counter) NextCodeSequences(_ context.Context, n int) ([]int64, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	values := make([]int64, n)
+	for i := range values {
+		c.last++
+		values[i] = c.last
+	}
+	return values, nil
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(Options{Kind: "uuid", Length: 10}, nil)
+	assert.ErrorIs(t, err, ErrUnknownKind)
+
+	_, err = New(Options{Kind: KindRandom, Length: 0}, nil)
+	assert.ErrorIs(t, err, ErrInvalidLength)
+}
+
+func TestRandom(t *testing.T) {
+	g, err := New(Options{Kind: KindRandom, Length: 10}, nil)
+	require.NoError(t, err)
+
+	codes, err := g.Generate(context.Background(), []string{"https://some.com/", "https://some.com/"}, 0)
+	require.NoError(t, err)
+	require.Len(t, codes, 2)
+	for _, code := range codes {
+		assert.Len(t, code, 10)
+		assert.Regexp(t, `^[0-9a-zA-Z]+$`, code)
+	}
+	assert.NotEqual(t, codes[0], codes[1])
+}
+
+func TestSequence(t *testing.T) {
+	seq := &counter{last: 59}
+	g, err := New(Options{Kind: KindSequence, Length: 10}, seq)
+	require.NoError(t, err)
+
+	codes, err := g.Generate(context.Background(), []string{"a", "b", "c"}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Y", "Z", "10"}, codes)
+
+	seq.err = errors.New("db is down")
+	_, err = g.Generate(context.Background(), []string{"a"}, 0)
+	assert.ErrorIs(t, err, ErrSequence)
+}
+
+func TestHash(t *testing.T) {
+	g, err := New(Options{Kind: KindHash, Length: 8}, nil)
+	require.NoError(t, err)
+
+	first, err := g.Generate(context.Background(), []string{"https://some.com/", "https://other.com/"}, 0)
+	require.NoError(t, err)
+	again, err := g.Generate(context.Background(), []string{"https://some.com/"}, 0)
+	require.NoError(t, err)
+	retry, err := g.Generate(context.Background(), []string{"https://some.com/"}, 1)
+	require.NoError(t, err)
+
+	assert.Len(t, first[0], 8)
+	assert.Equal(t, first[0], again[0])
+	assert.NotEqual(t, first[0], first[1])
+	assert.NotEqual(t, first[0], retry[0])
+
+	long, err := New(Options{Kind: KindHash, Length: 100}, nil)
+	require.NoError(t, err)
+	codes, err := long.Generate(context.Background(), []string{"https://some.com/"}, 0)
+	require.NoError(t, err)
+	assert.Len(t, codes[0], 100)
+}
+
+func TestFeistel(t *testing.T) {
+	g, err := New(Options{Kind: KindFeistel, Length: 3, Key: "0123456789abcdef"}, &counter{})
+	require.NoError(t, err)
+
+	// every value of the block maps to its own code of the full length
+	f := g.(*feistel)
+	block := 1 << (2 * f.halfBits)
+	originals := make([]string, block-1)
+	codes, err := g.Generate(context.Background(), originals, 0)
+	require.NoError(t, err)
+
+	seen := make(map[string]struct{}, len(codes))
+	for _, code := range codes {
+		assert.Len(t, code, 3)
+		seen[code] = struct{}{}
+	}
+	assert.Len(t, seen, len(codes))
+	assert.NotEqual(t, encode(1, Base62, 3), codes[0])
+
+	_, err = g.Generate(context.Background(), []string{"a", "b"}, 0)
+	assert.ErrorIs(t, err, ErrSequenceExhausted)
+
+	other, err := New(Options{Kind: KindFeistel, Length: 3, Key: "fedcba9876543210"}, &counter{})
+	require.NoError(t, err)
+	otherCodes, err := other.Generate(context.Background(), []string{"a"}, 0)
+	require.NoError(t, err)
+	assert.NotEqual(t, codes[0], otherCodes[0])
+}
+
+func TestFeistelKey(t *testing.T) {
+	for _, key := range []string{"", "secret", "0123456789abcde"} {
+		_, err := New(Options{Kind: KindFeistel, Length: 3, Key: key}, &counter{})
+		assert.ErrorIs(t, err, ErrInvalidKey, key)
+	}
+
+	// the key matters for the feistel generator only
+	_, err := New(Options{Kind: KindRandom, Length: 3}, nil)
+	assert.NoError(t, err)
+}
+
+func TestEncode(t *testing.T) {
+	assert.Equal(t, "0", encode(0, Base62, 1))
+	assert.Equal(t, "00z", encode(35, Base62, 3))
+	assert.Equal(t, "10", encode(62, Base62, 1))
+}
+
+func TestAdaptive(t *testing.T) {
+	policy := GrowthPolicy{Threshold: 0.5, Window: 4, MaxLength: 4}
+
+	_, err := NewAdaptive(Options{Kind: KindRandom, Length: 5}, nil, nil, policy)
+	assert.ErrorIs(t, err, ErrInvalidLength)
+
+	g, err := NewAdaptive(Options{Kind: KindRandom, Length: 3}, nil, nil, policy)
+	require.NoError(t, err)
+
+	// the window isn't full yet
+	g.Observe(3, 2)
+	assert.Equal(t, 3, g.Length())
+
+	// two collisions out of four are not over the threshold
+	g.Observe(1, 0)
+	assert.Equal(t, 3, g.Length())
+
+	g.Observe(4, 3)
+	assert.Equal(t, 4, g.Length())
+	codes, err := g.Generate(context.Background(), []string{"a"}, 0)
+	require.NoError(t, err)
+	assert.Len(t, codes[0], 4)
+
+	// the length never grows over the max length
+	g.Observe(4, 4)
+	assert.Equal(t, 4, g.Length())
+}
+
+func TestAlphabet(t *testing.T) {
+	alphabet, err := Alphabet("crockford")
+	require.NoError(t, err)
+	assert.Equal(t, Crockford, alphabet)
+
+	alphabet, err = Alphabet("abc123")
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", alphabet)
+
+	for _, invalid := range []string{"a", "abca", "ab/c", "abcя"} {
+		_, err = Alphabet(invalid)
+		assert.ErrorIs(t, err, ErrInvalidAlphabet, invalid)
+	}
+
+	g, err := New(Options{Kind: KindRandom, Alphabet: Crockford, Length: 50}, nil)
+	require.NoError(t, err)
+	codes, err := g.Generate(context.Background(), []string{"a"}, 0)
+	require.NoError(t, err)
+	assert.Regexp(t, `^[0-9a-hjkmnp-tv-z]{50}$`, codes[0])
+}
+
+func TestBlocklist(t *testing.T) {
+	b := NewBlocklist([]string{"Bad", " evil ", ""})
+
+	assert.True(t, b.Contains("xxBADxx"))
+	assert.True(t, b.Contains("b4d"))
+	assert.True(t, b.Contains("3v1l"))
+	assert.True(t, b.Contains("b-a_d"))
+	assert.False(t, b.Contains("bead"))
+
+	var none *Blocklist
+	assert.False(t, none.Contains("bad"))
+}
+
+func TestAdaptiveBlocklist(t *testing.T) {
+	policy := GrowthPolicy{Threshold: 0.5, Window: 100, MaxLength: 10}
+	originals := []string{"https://some.com/1", "https://some.com/2"}
+
+	plain, err := New(Options{Kind: KindHash, Length: 6}, nil)
+	require.NoError(t, err)
+	codes, err := plain.Generate(context.Background(), originals, 0)
+	require.NoError(t, err)
+
+	// the first code is blocked, so it is replaced while the second one is kept
+	g, err := NewAdaptive(Options{Kind: KindHash, Length: 6}, nil, NewBlocklist([]string{codes[0]}), policy)
+	require.NoError(t, err)
+	filtered, err := g.Generate(context.Background(), originals, 0)
+	require.NoError(t, err)
+	assert.NotEqual(t, codes[0], filtered[0])
+	assert.Equal(t, codes[1], filtered[1])
+
+	// every symbol of the alphabet is blocked
+	g, err = NewAdaptive(Options{Kind: KindRandom, Alphabet: "ab", Length: 1}, nil, NewBlocklist([]string{"a", "b"}), policy)
+	require.NoError(t, err)
+	_, err = g.Generate(context.Background(), originals, 0)
+	assert.ErrorIs(t, err, ErrBlockedCodes)
+}
+
+func TestAdaptiveReserved(t *testing.T) {
+	policy := GrowthPolicy{Threshold: 0.5, Window: 100, MaxLength: 10}
+	originals := []string{"https://some.com/1", "https://some.com/2"}
+
+	for _, kind := range []string{KindSequence, KindHash, KindFeistel} {
+		opts := Options{Kind: kind, Alphabet: Lowercase, Length: 6, Key: "0123456789abcdef"}
+		plain, err := New(opts, &counter{})
+		require.NoError(t, err)
+		codes, err := plain.Generate(context.Background(), originals, 0)
+		require.NoError(t, err)
+
+		// the first code is reserved regardless of case, so it is replaced while the second one is kept
+		opts.Reserved = []string{" " + strings.ToUpper(codes[0]) + " "}
+		g, err := NewAdaptive(opts, &counter{}, nil, policy)
+		require.NoError(t, err)
+		filtered, err := g.Generate(context.Background(), originals, 0)
+		require.NoError(t, err, kind)
+		assert.NotEqual(t, codes[0], filtered[0], kind)
+		assert.NotContains(t, filtered, codes[0], kind)
+	}
+
+	// every code of the alphabet is reserved
+	g, err := NewAdaptive(Options{Kind: KindRandom, Alphabet: "ab", Length: 1, Reserved: []string{"A", "b"}}, nil, nil, policy)
+	require.NoError(t, err)
+	_, err = g.Generate(context.Background(), originals, 0)
+	assert.ErrorIs(t, err, ErrBlockedCodes)
+}
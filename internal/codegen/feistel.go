@@ -0,0 +1,69 @@
+package codegen
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+)
+
+const feistelRounds = 4
+
+// feistel shuffles the next sequence values with a keyed Feistel network. The network is a permutation,
+// so codes never collide with each other while consecutive values give codes that look random
+type feistel struct {
+	alphabet string
+	length   int
+	key      []byte
+	seq      Sequence
+	halfBits int
+	mask     uint64
+}
+
+func newFeistel(alphabet string, length int, key string, seq Sequence) *feistel {
+	// the network works on two halves of equal size, the whole block must fit into the code length
+	halfBits := capacityBits(alphabet, length) / 2
+	return &feistel{
+		alphabet: alphabet,
+		length:   length,
+		key:      []byte(key),
+		seq:      seq,
+		halfBits: halfBits,
+		mask:     1<<halfBits - 1,
+	}
+}
+
+func (g *feistel) Generate(ctx context.Context, originals []string, _ int) ([]string, error) {
+	values, err := g.seq.NextCodeSequences(ctx, len(originals))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSequence, err)
+	}
+
+	codes := make([]string, len(values))
+	for i, value := range values {
+		if value < 0 || uint64(value)>>(2*g.halfBits) != 0 {
+			return nil, fmt.Errorf("%w: %d", ErrSequenceExhausted, value)
+		}
+		codes[i] = encode(g.permute(uint64(value)), g.alphabet, g.length)
+	}
+	return codes, nil
+}
+
+func (g *feistel) permute(n uint64) uint64 {
+	left, right := n>>g.halfBits, n&g.mask
+	for round := 0; round < feistelRounds; round++ {
+		left, right = right, left^g.round(round, right)
+	}
+	return left<<g.halfBits | right
+}
+
+func (g *feistel) round(round int, half uint64) uint64 {
+	var input [9]byte
+	input[0] = byte(round)
+	binary.BigEndian.PutUint64(input[1:], half)
+
+	mac := hmac.New(sha256.New, g.key)
+	mac.Write(input[:])
+	return binary.BigEndian.Uint64(mac.Sum(nil)) & g.mask
+}
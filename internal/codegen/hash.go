@@ -0,0 +1,46 @@
+package codegen
+
+import (
+	"context"
+	"crypto/sha256"
+	"math/big"
+	"strconv"
+)
+
+// hash derives the code from the original URL, the same URL gets the same code
+// and a collision is resolved by hashing the URL together with the attempt number
+type hash struct {
+	alphabet string
+	length   int
+}
+
+func newHash(alphabet string, length int) *hash {
+	return &hash{alphabet: alphabet, length: length}
+}
+
+func (g *hash) Generate(_ context.Context, originals []string, attempt int) ([]string, error) {
+	base := big.NewInt(int64(len(g.alphabet)))
+	codes := make([]string, len(originals))
+	for i, original := range originals {
+		input := original
+		if attempt > 0 {
+			input += "#" + strconv.Itoa(attempt)
+		}
+		sum := sha256.Sum256([]byte(input))
+
+		// the digest holds enough digits for any sane length, it is stretched by rehashing otherwise
+		n := new(big.Int).SetBytes(sum[:])
+		digit := new(big.Int)
+		b := make([]byte, g.length)
+		for j := range b {
+			if n.Sign() == 0 {
+				sum = sha256.Sum256(sum[:])
+				n.SetBytes(sum[:])
+			}
+			n.DivMod(n, base, digit)
+			b[j] = g.alphabet[digit.Int64()]
+		}
+		codes[i] = string(b)
+	}
+	return codes, nil
+}
@@ -0,0 +1,34 @@
+package codegen
+
+import (
+	"context"
+	"crypto/rand"
+	"math/big"
+)
+
+// random draws every symbol from crypto/rand, codes are unpredictable but may collide
+type random struct {
+	alphabet string
+	length   int
+}
+
+func newRandom(alphabet string, length int) *random {
+	return &random{alphabet: alphabet, length: length}
+}
+
+func (g *random) Generate(_ context.Context, originals []string, _ int) ([]string, error) {
+	base := big.NewInt(int64(len(g.alphabet)))
+	codes := make([]string, len(originals))
+	for i := range codes {
+		b := make([]byte, g.length)
+		for j := range b {
+			n, err := rand.Int(rand.Reader, base)
+			if err != nil {
+				return nil, err
+			}
+			b[j] = g.alphabet[n.Int64()]
+		}
+		codes[i] = string(b)
+	}
+	return codes, nil
+}
@@ -0,0 +1,30 @@
+package codegen
+
+import (
+	"context"
+	"fmt"
+)
+
+// sequence encodes the next sequence values, codes are as short as possible and never collide
+// with each other, only with aliases
+type sequence struct {
+	alphabet string
+	seq      Sequence
+}
+
+func newSequence(alphabet string, seq Sequence) *sequence {
+	return &sequence{alphabet: alphabet, seq: seq}
+}
+
+func (g *sequence) Generate(ctx context.Context, originals []string, _ int) ([]string, error) {
+	values, err := g.seq.NextCodeSequences(ctx, len(originals))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSequence, err)
+	}
+
+	codes := make([]string, len(values))
+	for i, value := range values {
+		codes[i] = encode(uint64(value), g.alphabet, 1)
+	}
+	return codes, nil
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"log"
 	"os"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
@@ -14,9 +15,116 @@ type Config struct {
 }
 
 type AppSettings struct {
+	// URLLength is the length of generated codes, sequence codes are as short as possible and ignore it
 	URLLength    int    `yaml:"url_length" env:"URL_LENGTH" env-default:"10"`
 	Storage      string `yaml:"storage" env:"STORAGE_TYPE" env-default:"map"`
 	FirstURLPart string `yaml:"first_url_part" env:"FIRST_URL_PART" env-default:"https://somedomain.su/"`
+	// CodeGenerator is random, sequence, hash or feistel, CodeGeneratorKey keys the feistel permutation
+	// and is required for it, at least 16 bytes
+	CodeGenerator    string `yaml:"code_generator" env:"CODE_GENERATOR" env-default:"random"`
+	CodeGeneratorKey string `yaml:"code_generator_key" env:"CODE_GENERATOR_KEY" env-default:""`
+	// CodeAlphabet is base62, base58, crockford, lowercase or the symbols themselves. CaseInsensitiveCodes
+	// stores aliases and resolves codes in lowercase, it needs a lowercase alphabet
+	CodeAlphabet         string `yaml:"code_alphabet" env:"CODE_ALPHABET" env-default:"base62"`
+	CaseInsensitiveCodes bool   `yaml:"case_insensitive_codes" env:"CASE_INSENSITIVE_CODES" env-default:"false"`
+	// BlockedWords are never handed out as a part of generated codes or accepted in aliases
+	BlockedWords []string `yaml:"blocked_words" env:"BLOCKED_WORDS" env-separator:"," env-default:"fuck,shit,cunt,dick,cock,piss,slut,whore,bitch,nazi"`
+	// CodeMaxAttempts limits how many codes are tried for a link before giving up, codes grow one symbol
+	// up to CodeMaxLength when more than CodeCollisionThreshold of the last CodeCollisionWindow codes were taken
+	CodeMaxAttempts        int     `yaml:"code_max_attempts" env:"CODE_MAX_ATTEMPTS" env-default:"10"`
+	CodeCollisionThreshold float64 `yaml:"code_collision_threshold" env:"CODE_COLLISION_THRESHOLD" env-default:"0.1"`
+	CodeCollisionWindow    int     `yaml:"code_collision_window" env:"CODE_COLLISION_WINDOW" env-default:"1000"`
+	CodeMaxLength          int     `yaml:"code_max_length" env:"CODE_MAX_LENGTH" env-default:"16"`
+	// RedirectStatus is used for links created without their own redirect status
+	RedirectStatus int `yaml:"redirect_status" env:"REDIRECT_STATUS" env-default:"302"`
+	// InterstitialCountdown is how long the interstitial page of links with an interstitial waits before continuing
+	InterstitialCountdown time.Duration `yaml:"interstitial_countdown" env:"INTERSTITIAL_COUNTDOWN" env-default:"5s"`
+	// DomainCacheTTL is how long the workspace domain a request host belongs to is remembered,
+	// zero disables the cache. A newly added domain may serve the default domain for that long
+	DomainCacheTTL time.Duration `yaml:"domain_cache_ttl" env:"DOMAIN_CACHE_TTL" env-default:"30s"`
+
+	AliasMinLength  int      `yaml:"alias_min_length" env:"ALIAS_MIN_LENGTH" env-default:"3"`
+	AliasMaxLength  int      `yaml:"alias_max_length" env:"ALIAS_MAX_LENGTH" env-default:"64"`
+	ReservedAliases []string `yaml:"reserved_aliases" env:"RESERVED_ALIASES" env-separator:"," env-default:"api,debug,health,metrics,static,admin"`
+
+	// StripTrackingParams and FragmentsPolicy (keep or strip) tune the canonical form of original URLs,
+	// plain links to URLs with the same canonical form share the short URL
+	StripTrackingParams bool   `yaml:"strip_tracking_params" env:"STRIP_TRACKING_PARAMS" env-default:"false"`
+	FragmentsPolicy     string `yaml:"fragments_policy" env:"FRAGMENTS_POLICY" env-default:"keep"`
+
+	// BatchMaxSize limits the number of URLs shortened by a single batch request
+	BatchMaxSize int `yaml:"batch_max_size" env:"BATCH_MAX_SIZE" env-default:"10000"`
+
+	// SweepInterval is how often expired links are purged from the storage
+	SweepInterval time.Duration `yaml:"sweep_interval" env:"SWEEP_INTERVAL" env-default:"1m"`
+
+	// PasswordMaxAttempts failed attempts within PasswordLockout lock the short code for PasswordLockout
+	PasswordMaxAttempts int           `yaml:"password_max_attempts" env:"PASSWORD_MAX_ATTEMPTS" env-default:"5"`
+	PasswordLockout     time.Duration `yaml:"password_lockout" env:"PASSWORD_LOCKOUT" env-default:"15m"`
+
+	// ClickQueueSize clicks wait to be written in batches of ClickBatchSize, clicks over the queue size are dropped
+	ClickQueueSize     int           `yaml:"click_queue_size" env:"CLICK_QUEUE_SIZE" env-default:"10000"`
+	ClickBatchSize     int           `yaml:"click_batch_size" env:"CLICK_BATCH_SIZE" env-default:"500"`
+	ClickFlushInterval time.Duration `yaml:"click_flush_interval" env:"CLICK_FLUSH_INTERVAL" env-default:"1s"`
+
+	// StatsRollupInterval is how often recorded clicks are added to the stats rollups
+	StatsRollupInterval time.Duration `yaml:"stats_rollup_interval" env:"STATS_ROLLUP_INTERVAL" env-default:"1m"`
+
+	// Blocklist files screen the destinations of new links and redirects: domains, regular expressions
+	// and hex SHA-256 hash prefixes of URL expressions. Changed files are reloaded every BlocklistReloadInterval
+	BlocklistDomainFiles     []string      `yaml:"blocklist_domain_files" env:"BLOCKLIST_DOMAIN_FILES" env-separator:"," env-default:""`
+	BlocklistPatternFiles    []string      `yaml:"blocklist_pattern_files" env:"BLOCKLIST_PATTERN_FILES" env-separator:"," env-default:""`
+	BlocklistHashPrefixFiles []string      `yaml:"blocklist_hash_prefix_files" env:"BLOCKLIST_HASH_PREFIX_FILES" env-separator:"," env-default:""`
+	BlocklistReloadInterval  time.Duration `yaml:"blocklist_reload_interval" env:"BLOCKLIST_RELOAD_INTERVAL" env-default:"1m"`
+
+	// GeoIPDatabaseFile is a GeoIP2 or GeoLite2 Country or City database locating the visitors without
+	// a country header for redirect rules and stats, empty disables it. It is reloaded every GeoIPReloadInterval when changed
+	GeoIPDatabaseFile   string        `yaml:"geoip_database_file" env:"GEOIP_DATABASE_FILE" env-default:""`
+	GeoIPReloadInterval time.Duration `yaml:"geoip_reload_interval" env:"GEOIP_RELOAD_INTERVAL" env-default:"1h"`
+
+	// LinkCheckInterval is how often up to LinkCheckBatchSize destinations due for a check are requested,
+	// a zero interval disables the checks. Healthy links are rechecked every LinkRecheckInterval, broken ones
+	// after LinkCheckRetry doubling with each failure. LinkCheckConcurrency hosts are checked at once,
+	// with LinkCheckHostDelay between the requests to the same host
+	LinkCheckInterval    time.Duration `yaml:"link_check_interval" env:"LINK_CHECK_INTERVAL" env-default:"5m"`
+	LinkCheckBatchSize   int           `yaml:"link_check_batch_size" env:"LINK_CHECK_BATCH_SIZE" env-default:"500"`
+	LinkRecheckInterval  time.Duration `yaml:"link_recheck_interval" env:"LINK_RECHECK_INTERVAL" env-default:"24h"`
+	LinkCheckRetry       time.Duration `yaml:"link_check_retry" env:"LINK_CHECK_RETRY" env-default:"1h"`
+	LinkCheckConcurrency int           `yaml:"link_check_concurrency" env:"LINK_CHECK_CONCURRENCY" env-default:"10"`
+	LinkCheckHostDelay   time.Duration `yaml:"link_check_host_delay" env:"LINK_CHECK_HOST_DELAY" env-default:"1s"`
+	LinkCheckTimeout     time.Duration `yaml:"link_check_timeout" env:"LINK_CHECK_TIMEOUT" env-default:"10s"`
+
+	// PreviewFetchInterval is how often up to PreviewBatchSize links due for a metadata fetch are fetched,
+	// new links are fetched right away unless over PreviewQueueSize. A zero interval disables the fetches.
+	// Metadata is refreshed every PreviewRefreshInterval, failed fetches are retried after PreviewRetry
+	// doubling with each failure. Pages are read up to PreviewMaxBytes within PreviewTimeout
+	PreviewFetchInterval   time.Duration `yaml:"preview_fetch_interval" env:"PREVIEW_FETCH_INTERVAL" env-default:"1m"`
+	PreviewQueueSize       int           `yaml:"preview_queue_size" env:"PREVIEW_QUEUE_SIZE" env-default:"1000"`
+	PreviewBatchSize       int           `yaml:"preview_batch_size" env:"PREVIEW_BATCH_SIZE" env-default:"100"`
+	PreviewConcurrency     int           `yaml:"preview_concurrency" env:"PREVIEW_CONCURRENCY" env-default:"4"`
+	PreviewRefreshInterval time.Duration `yaml:"preview_refresh_interval" env:"PREVIEW_REFRESH_INTERVAL" env-default:"168h"`
+	PreviewRetry           time.Duration `yaml:"preview_retry" env:"PREVIEW_RETRY" env-default:"1h"`
+	PreviewMaxBytes        int64         `yaml:"preview_max_bytes" env:"PREVIEW_MAX_BYTES" env-default:"524288"`
+	PreviewTimeout         time.Duration `yaml:"preview_timeout" env:"PREVIEW_TIMEOUT" env-default:"10s"`
+
+	// CreateRate and ResolveRate requests per second refill the buckets of CreateBurst and ResolveBurst requests
+	// of every API key or client IP, a zero rate disables the limit. RateLimitStore is map or db,
+	// the db store is shared by the service instances
+	RateLimitStore string  `yaml:"rate_limit_store" env:"RATE_LIMIT_STORE" env-default:"map"`
+	CreateRate     float64 `yaml:"create_rate" env:"CREATE_RATE" env-default:"1"`
+	CreateBurst    int     `yaml:"create_burst" env:"CREATE_BURST" env-default:"20"`
+	ResolveRate    float64 `yaml:"resolve_rate" env:"RESOLVE_RATE" env-default:"50"`
+	ResolveBurst   int     `yaml:"resolve_burst" env:"RESOLVE_BURST" env-default:"200"`
+	// AuthRate and AuthBurst limit the requests needing an API key per client IP before the key is checked,
+	// so keys can't be guessed at full speed
+	AuthRate  float64 `yaml:"auth_rate" env:"AUTH_RATE" env-default:"20"`
+	AuthBurst int     `yaml:"auth_burst" env:"AUTH_BURST" env-default:"100"`
+	// TrustedProxies are addresses or CIDRs of the proxies allowed to pass the client IP in X-Forwarded-For,
+	// the IP is used by the rate limits, clicks and GeoIP lookups
+	TrustedProxies []string `yaml:"trusted_proxies" env:"TRUSTED_PROXIES" env-separator:"," env-default:""`
+
+	// AdminAPIKey is a bootstrap key with the admin scope for creating the first API keys, empty disables it
+	AdminAPIKey string `yaml:"admin_api_key" env:"ADMIN_API_KEY" env-default:""`
 }
 
 type ServerConfig struct {
@@ -0,0 +1,116 @@
+package geoip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+var ErrInvalidDatabase = errors.New("invalid MaxMind database")
+
+// countryRecord is the part of the Country and City records the lookups need
+type countryRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	RegisteredCountry struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"registered_country"`
+}
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// Locator finds the country of IP addresses in a GeoIP2 or GeoLite2 Country or City database,
+// Reload picks up a replaced file without blocking the lookups
+type Locator struct {
+	file string
+	db   atomic.Pointer[maxminddb.Reader]
+
+	mu    sync.Mutex
+	stamp fileStamp
+}
+
+func Open(file string) (*Locator, error) {
+	l := &Locator{file: file}
+	if err := l.load(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Country returns the ISO 3166-1 alpha-2 code of the country of the address, the registered country
+// when the country is not known, and empty when neither is
+func (l *Locator) Country(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ""
+	}
+	// IPv6 addresses are not found in IPv4 databases, the lookup fails for them
+	var record countryRecord
+	if err := l.db.Load().Lookup(net.IP(addr.Unmap().AsSlice()), &record); err != nil {
+		return ""
+	}
+
+	if record.Country.ISOCode != "" {
+		return strings.ToUpper(record.Country.ISOCode)
+	}
+	return strings.ToUpper(record.RegisteredCountry.ISOCode)
+}
+
+// Reload loads the database again when the file changed, the old database stays in use when the new one is broken
+func (l *Locator) Reload(_ context.Context) error {
+	l.mu.Lock()
+	stamp, err := stampOf(l.file)
+	changed := err != nil || stamp != l.stamp
+	l.mu.Unlock()
+
+	if !changed {
+		return nil
+	}
+	return l.load()
+}
+
+func (l *Locator) load() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	stamp, err := stampOf(l.file)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidDatabase, err)
+	}
+	file, err := os.ReadFile(l.file)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidDatabase, err)
+	}
+	db, err := maxminddb.FromBytes(file)
+	if err != nil {
+		return fmt.Errorf("%s: %w: %v", l.file, ErrInvalidDatabase, err)
+	}
+
+	l.db.Store(db)
+	l.stamp = stamp
+	return nil
+}
+
+func stampOf(file string) (fileStamp, error) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return fileStamp{}, err
+	}
+	if !info.Mode().IsRegular() {
+		return fileStamp{}, errors.New("not a regular file")
+	}
+	return fileStamp{modTime: info.ModTime(), size: info.Size()}, nil
+}
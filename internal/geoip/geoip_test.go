@@ -0,0 +1,317 @@
+package geoip
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// the values of the test databases are written in the MaxMind DB data format,
+// https://maxmind.github.io/MaxMind-DB/
+
+// metadataMarker starts the metadata at the end of a MaxMind DB file
+var metadataMarker = []byte("\xab\xcd\xefMaxMind.com")
+
+// the data types of the MaxMind DB format, the types after typeMap take an extra byte
+const (
+	typePointer = 1
+	typeString  = 2
+	typeUint16  = 5
+	typeUint32  = 6
+	typeMap     = 7
+	typeArray   = 11
+	typeBoolean = 14
+)
+
+type pair struct {
+	key   string
+	value any
+}
+
+// pointer is a pointer to the offset in the data section
+type pointer int
+
+func encode(value any) []byte {
+	header := func(typ, size int) []byte {
+		var ctrl []byte
+		if typ > 7 {
+			ctrl = []byte{0, byte(typ - 7)}
+		} else {
+			ctrl = []byte{byte(typ << 5)}
+		}
+		switch {
+		case size < 29:
+			ctrl[0] |= byte(size)
+		case size < 285:
+			ctrl[0] |= 29
+			ctrl = append(ctrl, byte(size-29))
+		default:
+			ctrl[0] |= 30
+			ctrl = append(ctrl, byte((size-285)>>8), byte(size-285))
+		}
+		return ctrl
+	}
+
+	switch v := value.(type) {
+	case string:
+		return append(header(typeString, len(v)), v...)
+	case uint32:
+		return append(header(typeUint32, 4), byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+	case uint16:
+		return append(header(typeUint16, 2), byte(v>>8), byte(v))
+	case bool:
+		size := 0
+		if v {
+			size = 1
+		}
+		return header(typeBoolean, size)
+	case []pair:
+		result := header(typeMap, len(v))
+		for _, p := range v {
+			result = append(result, encode(p.key)...)
+			result = append(result, encode(p.value)...)
+		}
+		return result
+	case []any:
+		result := header(typeArray, len(v))
+		for _, item := range v {
+			result = append(result, encode(item)...)
+		}
+		return result
+	case pointer:
+		if v < 2048 {
+			return []byte{byte(typePointer<<5) | byte(v>>8), byte(v)}
+		}
+		v -= 2048
+		return []byte{byte(typePointer<<5) | 1<<3 | byte(v>>16), byte(v >> 8), byte(v)}
+	}
+	panic(fmt.Sprintf("unsupported value %T", value))
+}
+
+type network struct {
+	prefix string
+	// record is the offset of the record in the data section
+	record int
+}
+
+// build writes a database with the networks, IPv4 networks of IPv6 databases go under ::/96
+func build(t testing.TB, ipVersion, recordSize int, data []byte, networks []network) []byte {
+	t.Helper()
+
+	type node struct{ children [2]any }
+	nodes := []*node{{}}
+	for _, n := range networks {
+		prefix := netip.MustParsePrefix(n.prefix)
+		bits := prefix.Addr().AsSlice()
+		length := prefix.Bits()
+		if ipVersion == 6 && prefix.Addr().Is4() {
+			bits = append(make([]byte, 12), bits...)
+			length += 96
+		}
+
+		current := nodes[0]
+		for i := range length {
+			bit := bits[i/8] >> (7 - i%8) & 1
+			if i == length-1 {
+				current.children[bit] = n.record
+				break
+			}
+			if current.children[bit] == nil {
+				nodes = append(nodes, &node{})
+				current.children[bit] = -len(nodes)
+			}
+			next := current.children[bit].(int)
+			require.Negative(t, next, "networks overlap")
+			current = nodes[-next-1]
+		}
+	}
+
+	nodeCount := len(nodes)
+	value := func(child any) uint {
+		switch c := child.(type) {
+		case nil:
+			return uint(nodeCount)
+		case int:
+			if c < 0 {
+				return uint(-c - 1)
+			}
+			return uint(nodeCount + 16 + c)
+		}
+		panic("unexpected child")
+	}
+
+	var file []byte
+	for _, n := range nodes {
+		left, right := value(n.children[0]), value(n.children[1])
+		switch recordSize {
+		case 24:
+			file = append(file, byte(left>>16), byte(left>>8), byte(left), byte(right>>16), byte(right>>8), byte(right))
+		case 28:
+			file = append(file, byte(left>>16), byte(left>>8), byte(left),
+				byte(left>>24)<<4|byte(right>>24), byte(right>>16), byte(right>>8), byte(right))
+		case 32:
+			file = append(file, byte(left>>24), byte(left>>16), byte(left>>8), byte(left),
+				byte(right>>24), byte(right>>16), byte(right>>8), byte(right))
+		}
+	}
+	file = append(file, make([]byte, 16)...)
+	file = append(file, data...)
+	file = append(file, metadataMarker...)
+	file = append(file, encode([]pair{
+		{"binary_format_major_version", uint16(2)},
+		{"database_type", "GeoLite2-Country"},
+		{"ip_version", uint16(ipVersion)},
+		{"node_count", uint32(nodeCount)},
+		{"record_size", uint16(recordSize)},
+	})...)
+	return file
+}
+
+// testData has a Country record with a shared country map behind a pointer, and a record with the
+// registered country only
+func testData() ([]byte, []int) {
+	var data []byte
+	gb := len(data)
+	data = append(data, encode([]pair{{"geoname_id", uint32(2635167)}, {"iso_code", "GB"}})...)
+
+	first := len(data)
+	data = append(data, encode([]pair{
+		{"continent", []pair{{"code", "EU"}, {"names", []pair{{"en", "Europe"}, {"de", "Europa"}}}}},
+		{"country", pointer(gb)},
+		{"is_anycast", true},
+		{"subdivisions", []any{[]pair{{"iso_code", "ENG"}}}},
+	})...)
+
+	second := len(data)
+	data = append(data, encode([]pair{
+		{"registered_country", []pair{{"iso_code", "de"}}},
+	})...)
+
+	return data, []int{first, second}
+}
+
+func writeFile(t *testing.T, file string, content []byte) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(file, content, 0o644))
+}
+
+func TestCountry(t *testing.T) {
+	data, records := testData()
+
+	for _, recordSize := range []int{24, 28, 32} {
+		t.Run(fmt.Sprintf("IPv6 database with %d bit records", recordSize), func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "country.mmdb")
+			writeFile(t, file, build(t, 6, recordSize, data, []network{
+				{"81.2.69.0/24", records[0]},
+				{"2001:db8::/32", records[1]},
+			}))
+
+			locator, err := Open(file)
+			require.NoError(t, err)
+
+			assert.Equal(t, "GB", locator.Country("81.2.69.160"))
+			assert.Equal(t, "GB", locator.Country("::ffff:81.2.69.160"))
+			assert.Equal(t, "DE", locator.Country("2001:db8::1"))
+			assert.Equal(t, "", locator.Country("81.2.70.1"))
+			assert.Equal(t, "", locator.Country("2001:db9::1"))
+			assert.Equal(t, "", locator.Country("not an IP"))
+		})
+	}
+
+	t.Run("IPv4 database", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "country.mmdb")
+		writeFile(t, file, build(t, 4, 24, data, []network{{"81.2.69.0/24", records[0]}}))
+
+		locator, err := Open(file)
+		require.NoError(t, err)
+
+		assert.Equal(t, "GB", locator.Country("81.2.69.160"))
+		assert.Equal(t, "", locator.Country("2001:db8::1"))
+	})
+}
+
+func TestOpen(t *testing.T) {
+	data, records := testData()
+	valid := build(t, 6, 24, data, []network{{"81.2.69.0/24", records[0]}})
+
+	tests := []struct {
+		name    string
+		content []byte
+	}{
+		{"no metadata", []byte("not a database")},
+		{"unknown record size", build(t, 6, 20, data, nil)},
+		{"truncated tree", valid[len(valid)-120:]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "country.mmdb")
+			writeFile(t, file, tt.content)
+
+			_, err := Open(file)
+
+			assert.ErrorIs(t, err, ErrInvalidDatabase)
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := Open(filepath.Join(t.TempDir(), "missing.mmdb"))
+
+		assert.ErrorIs(t, err, ErrInvalidDatabase)
+	})
+}
+
+func TestReload(t *testing.T) {
+	data, records := testData()
+	file := filepath.Join(t.TempDir(), "country.mmdb")
+	writeFile(t, file, build(t, 6, 24, data, []network{{"81.2.69.0/24", records[0]}}))
+
+	locator, err := Open(file)
+	require.NoError(t, err)
+	require.NoError(t, locator.Reload(context.Background()), "unchanged file")
+	assert.Equal(t, "GB", locator.Country("81.2.69.160"))
+
+	writeFile(t, file, build(t, 6, 28, data, []network{{"81.2.69.0/24", records[1]}}))
+	require.NoError(t, locator.Reload(context.Background()))
+	assert.Equal(t, "DE", locator.Country("81.2.69.160"))
+
+	writeFile(t, file, []byte("broken"))
+	assert.ErrorIs(t, locator.Reload(context.Background()), ErrInvalidDatabase)
+	assert.Equal(t, "DE", locator.Country("81.2.69.160"), "the old database stays")
+}
+
+// a broken database must neither panic the loading nor the lookups of the redirects
+func FuzzCountry(f *testing.F) {
+	data, records := testData()
+	for _, recordSize := range []int{24, 28, 32} {
+		valid := build(f, 6, recordSize, data, []network{
+			{"81.2.69.0/24", records[0]},
+			{"2001:db8::/32", records[1]},
+		})
+		f.Add(valid)
+		f.Add(valid[:len(valid)/2])
+		f.Add(valid[len(valid)-120:])
+	}
+	f.Add(build(f, 4, 24, data, []network{{"81.2.69.0/24", records[0]}}))
+	f.Add([]byte("not a database"))
+
+	f.Fuzz(func(t *testing.T, content []byte) {
+		file := filepath.Join(t.TempDir(), "country.mmdb")
+		writeFile(t, file, content)
+
+		locator, err := Open(file)
+		if err != nil {
+			assert.ErrorIs(t, err, ErrInvalidDatabase)
+			return
+		}
+		for _, ip := range []string{"81.2.69.160", "::ffff:81.2.69.160", "2001:db8::1", "0.0.0.0", "ffff::"} {
+			locator.Country(ip)
+		}
+	})
+}
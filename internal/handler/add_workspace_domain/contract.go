@@ -0,0 +1,20 @@
+package add_workspace_domain
+
+import (
+	"context"
+
+	"link-shortener-service/internal/usecase/add_workspace_domain"
+)
+
+//go:generate mockgen -source=contract.go -destination=mocks/contract_mock.go -package=add_workspace_domain usecase
+type usecase interface {
+	Run(ctx context.Context, req add_workspace_domain.In) (string, error)
+}
+
+type AddDomain struct {
+	Domain string `json:"domain" validate:"required,fqdn"`
+}
+
+type AddedDomain struct {
+	Domain string `json:"domain"`
+}
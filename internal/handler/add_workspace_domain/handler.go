@@ -0,0 +1,76 @@
+package add_workspace_domain
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"link-shortener-service/internal/handler"
+	usecase_add_workspace_domain "link-shortener-service/internal/usecase/add_workspace_domain"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+)
+
+type domainHandler struct {
+	usecase   usecase
+	validator *validator.Validate
+}
+
+func New(usecase usecase, validator *validator.Validate) *domainHandler {
+	return &domainHandler{
+		usecase:   usecase,
+		validator: validator,
+	}
+}
+
+func (h *domainHandler) AddDomain(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var domain AddDomain
+	if err := json.NewDecoder(r.Body).Decode(&domain); err != nil {
+		handler.RespondWithError(w, http.StatusBadRequest, "failed to decode request", err)
+		return
+	}
+
+	if err := h.validator.Struct(domain); err != nil {
+		handler.RespondWithError(w, http.StatusBadRequest, "validation failed", err)
+		return
+	}
+
+	added, err := h.usecase.Run(r.Context(), usecase_add_workspace_domain.In{
+		WorkspaceID: mux.Vars(r)["id"],
+		Domain:      domain.Domain,
+	})
+	if err != nil {
+		handleUseCaseError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err = json.NewEncoder(w).Encode(AddedDomain{Domain: added}); err != nil {
+		handler.RespondWithError(w, http.StatusInternalServerError, "failed to encode response", err)
+		return
+	}
+}
+
+func handleUseCaseError(w http.ResponseWriter, err error) {
+	statusCode := http.StatusInternalServerError
+	errorMsg := "internal server error"
+
+	switch {
+	case errors.Is(err, usecase_add_workspace_domain.ErrInvalidDomain):
+		statusCode = http.StatusBadRequest
+		errorMsg = "invalid short domain"
+	case errors.Is(err, usecase_add_workspace_domain.ErrWorkspaceNotFound):
+		statusCode = http.StatusNotFound
+		errorMsg = "workspace does not exist"
+	case errors.Is(err, usecase_add_workspace_domain.ErrDomainTaken):
+		statusCode = http.StatusConflict
+		errorMsg = "short domain belongs to another workspace"
+	case errors.Is(err, usecase_add_workspace_domain.ErrDomainAdd):
+		errorMsg = "failed to add short domain"
+	}
+
+	handler.RespondWithError(w, statusCode, errorMsg, err)
+}
@@ -0,0 +1,135 @@
+package add_workspace_domain
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	add_workspace_domain "link-shortener-service/internal/handler/add_workspace_domain/mocks"
+	usecase_add_workspace_domain "link-shortener-service/internal/usecase/add_workspace_domain"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddDomain(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	valid := validator.New(validator.WithRequiredStructEnabled())
+
+	usecaseIn := usecase_add_workspace_domain.In{
+		WorkspaceID: "acme",
+		Domain:      "Go.Acme.io",
+	}
+	reqBody := `{"domain":"Go.Acme.io"}`
+
+	tests := []struct {
+		name          string
+		setupMock     func(*add_workspace_domain.Mockusecase)
+		reqBody       string
+		expectedCode  int
+		expected      *AddedDomain
+		expectedError string
+	}{
+		{
+			name: "successful add",
+			setupMock: func(mockUsecase *add_workspace_domain.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecaseIn).
+					Return("go.acme.io", nil)
+			},
+			reqBody:      reqBody,
+			expectedCode: http.StatusCreated,
+			expected:     &AddedDomain{Domain: "go.acme.io"},
+		},
+		{
+			name:          "missing domain",
+			setupMock:     func(mockUsecase *add_workspace_domain.Mockusecase) {},
+			reqBody:       `{}`,
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "validation failed",
+		},
+		{
+			name: "usecase.Run error - invalid domain",
+			setupMock: func(mockUsecase *add_workspace_domain.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecaseIn).
+					Return("", usecase_add_workspace_domain.ErrInvalidDomain)
+			},
+			reqBody:       reqBody,
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "invalid short domain",
+		},
+		{
+			name: "usecase.Run error - unknown workspace",
+			setupMock: func(mockUsecase *add_workspace_domain.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecaseIn).
+					Return("", usecase_add_workspace_domain.ErrWorkspaceNotFound)
+			},
+			reqBody:       reqBody,
+			expectedCode:  http.StatusNotFound,
+			expectedError: "workspace does not exist",
+		},
+		{
+			name: "usecase.Run error - domain is taken",
+			setupMock: func(mockUsecase *add_workspace_domain.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecaseIn).
+					Return("", usecase_add_workspace_domain.ErrDomainTaken)
+			},
+			reqBody:       reqBody,
+			expectedCode:  http.StatusConflict,
+			expectedError: "short domain belongs to another workspace",
+		},
+		{
+			name: "usecase.Run error - error from storage",
+			setupMock: func(mockUsecase *add_workspace_domain.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecaseIn).
+					Return("", usecase_add_workspace_domain.ErrDomainAdd)
+			},
+			reqBody:       reqBody,
+			expectedCode:  http.StatusInternalServerError,
+			expectedError: "failed to add short domain",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := add_workspace_domain.NewMockusecase(ctrl)
+			handler := New(mockUsecase, valid)
+
+			tt.setupMock(mockUsecase)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/api/workspaces/acme/domains", strings.NewReader(tt.reqBody))
+			req.Header.Set("Content-Type", "application/json")
+			req = mux.SetURLVars(req, map[string]string{"id": "acme"})
+
+			handler.AddDomain(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+
+			if tt.expected != nil {
+				var response AddedDomain
+				err := json.NewDecoder(w.Body).Decode(&response)
+				require.NoError(t, err)
+				assert.Equal(t, *tt.expected, response)
+			}
+
+			if tt.expectedError != "" {
+				var errorResponse map[string]interface{}
+				err := json.NewDecoder(w.Body).Decode(&errorResponse)
+				require.NoError(t, err)
+				assert.Contains(t, errorResponse["error"], tt.expectedError)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package broken_links
+
+import (
+	"context"
+	"time"
+
+	"link-shortener-service/internal/model"
+	"link-shortener-service/internal/usecase/broken_links"
+)
+
+//go:generate mockgen -source=contract.go -destination=mocks/contract_mock.go -package=broken_links usecase
+type usecase interface {
+	Run(ctx context.Context, req broken_links.In) ([]model.LinkHealth, error)
+}
+
+type Report struct {
+	Links []BrokenLink `json:"links"`
+}
+
+type BrokenLink struct {
+	ShortURL    string `json:"short_url"`
+	OriginalURL string `json:"original_url"`
+	// Status is 0 when the destination did not respond, Error tells why
+	Status         int       `json:"status"`
+	Error          string    `json:"error,omitempty"`
+	ResponseTimeMs int64     `json:"response_time_ms"`
+	Redirects      []string  `json:"redirects"`
+	CheckedAt      time.Time `json:"checked_at"`
+	Failures       int       `json:"failures"`
+	NextCheckAt    time.Time `json:"next_check_at"`
+}
@@ -0,0 +1,86 @@
+package broken_links
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"link-shortener-service/internal/handler"
+	"link-shortener-service/internal/middleware"
+	"link-shortener-service/internal/model"
+	usecase_broken_links "link-shortener-service/internal/usecase/broken_links"
+)
+
+type brokenLinksHandler struct {
+	usecase usecase
+}
+
+func New(usecase usecase) *brokenLinksHandler {
+	return &brokenLinksHandler{
+		usecase: usecase,
+	}
+}
+
+func (h *brokenLinksHandler) BrokenLinks(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	principal, _ := middleware.PrincipalFromContext(r.Context())
+	in := usecase_broken_links.In{Principal: principal}
+	if limit := r.URL.Query().Get("limit"); limit != "" {
+		var err error
+		if in.Limit, err = strconv.Atoi(limit); err != nil {
+			handler.RespondWithError(w, http.StatusBadRequest, "invalid query", fmt.Errorf("limit: %w", err))
+			return
+		}
+	}
+
+	broken, err := h.usecase.Run(r.Context(), in)
+	if err != nil {
+		handleUseCaseError(w, err)
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(toReport(broken)); err != nil {
+		handler.RespondWithError(w, http.StatusInternalServerError, "failed to encode response", err)
+		return
+	}
+}
+
+func toReport(broken []model.LinkHealth) Report {
+	report := Report{Links: make([]BrokenLink, 0, len(broken))}
+	for _, link := range broken {
+		redirects := link.Redirects
+		if redirects == nil {
+			redirects = []string{}
+		}
+		report.Links = append(report.Links, BrokenLink{
+			ShortURL:       link.Shorted,
+			OriginalURL:    link.Original,
+			Status:         link.Status,
+			Error:          link.Error,
+			ResponseTimeMs: link.ResponseTime.Milliseconds(),
+			Redirects:      redirects,
+			CheckedAt:      link.CheckedAt,
+			Failures:       link.Failures,
+			NextCheckAt:    link.NextCheckAt,
+		})
+	}
+	return report
+}
+
+func handleUseCaseError(w http.ResponseWriter, err error) {
+	statusCode := http.StatusInternalServerError
+	errorMsg := "internal server error"
+
+	switch {
+	case errors.Is(err, usecase_broken_links.ErrInvalidLimit):
+		statusCode = http.StatusBadRequest
+		errorMsg = "invalid query"
+	case errors.Is(err, usecase_broken_links.ErrBrokenRetrieval):
+		errorMsg = "failed to get broken links"
+	}
+
+	handler.RespondWithError(w, statusCode, errorMsg, err)
+}
@@ -0,0 +1,151 @@
+package broken_links
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	broken_links "link-shortener-service/internal/handler/broken_links/mocks"
+	"link-shortener-service/internal/model"
+	usecase_broken_links "link-shortener-service/internal/usecase/broken_links"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBrokenLinks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	checkedAt := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		query         string
+		setupMock     func(*broken_links.Mockusecase)
+		expectedCode  int
+		expected      string
+		expectedError string
+	}{
+		{
+			name:  "successful report",
+			query: "?limit=2",
+			setupMock: func(mockUsecase *broken_links.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecase_broken_links.In{Limit: 2}).
+					Return([]model.LinkHealth{
+						{
+							Shorted:  "http://localhost:8080/abc",
+							Original: "http://some.com/old",
+							LinkCheck: model.LinkCheck{
+								Status:       404,
+								ResponseTime: 250 * time.Millisecond,
+								Redirects:    []string{"https://some.com/old"},
+							},
+							CheckedAt:   checkedAt,
+							Failures:    1,
+							NextCheckAt: checkedAt.Add(time.Hour),
+						},
+						{
+							Shorted:     "http://localhost:8080/xyz",
+							Original:    "https://down.com",
+							LinkCheck:   model.LinkCheck{Error: "connection refused"},
+							CheckedAt:   checkedAt,
+							Failures:    3,
+							NextCheckAt: checkedAt.Add(4 * time.Hour),
+						},
+					}, nil)
+			},
+			expectedCode: http.StatusOK,
+			expected: `{"links": [
+				{
+					"short_url": "http://localhost:8080/abc",
+					"original_url": "http://some.com/old",
+					"status": 404,
+					"response_time_ms": 250,
+					"redirects": ["https://some.com/old"],
+					"checked_at": "2025-07-01T12:00:00Z",
+					"failures": 1,
+					"next_check_at": "2025-07-01T13:00:00Z"
+				},
+				{
+					"short_url": "http://localhost:8080/xyz",
+					"original_url": "https://down.com",
+					"status": 0,
+					"error": "connection refused",
+					"response_time_ms": 0,
+					"redirects": [],
+					"checked_at": "2025-07-01T12:00:00Z",
+					"failures": 3,
+					"next_check_at": "2025-07-01T16:00:00Z"
+				}
+			]}`,
+		},
+		{
+			name: "no broken links",
+			setupMock: func(mockUsecase *broken_links.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), gomock.Any()).
+					Return([]model.LinkHealth{}, nil)
+			},
+			expectedCode: http.StatusOK,
+			expected:     `{"links": []}`,
+		},
+		{
+			name:          "invalid limit",
+			query:         "?limit=all",
+			setupMock:     func(*broken_links.Mockusecase) {},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "invalid query",
+		},
+		{
+			name:  "usecase.Run error - limit out of range",
+			query: "?limit=-1",
+			setupMock: func(mockUsecase *broken_links.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), gomock.Any()).
+					Return(nil, usecase_broken_links.ErrInvalidLimit)
+			},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "invalid query",
+		},
+		{
+			name: "usecase.Run error - error from storage",
+			setupMock: func(mockUsecase *broken_links.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), gomock.Any()).
+					Return(nil, usecase_broken_links.ErrBrokenRetrieval)
+			},
+			expectedCode:  http.StatusInternalServerError,
+			expectedError: "failed to get broken links",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := broken_links.NewMockusecase(ctrl)
+			handler := New(mockUsecase)
+
+			tt.setupMock(mockUsecase)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/api/links/broken"+tt.query, nil)
+
+			handler.BrokenLinks(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+
+			if tt.expectedError != "" {
+				var errorResponse map[string]interface{}
+				err := json.NewDecoder(w.Body).Decode(&errorResponse)
+				require.NoError(t, err)
+				assert.Contains(t, errorResponse["error"], tt.expectedError)
+				return
+			}
+			assert.JSONEq(t, tt.expected, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package create_api_key
+
+import (
+	"context"
+	"time"
+
+	"link-shortener-service/internal/usecase/create_api_key"
+)
+
+//go:generate mockgen -source=contract.go -destination=mocks/contract_mock.go -package=create_api_key usecase
+type usecase interface {
+	Run(ctx context.Context, req create_api_key.In) (*create_api_key.Out, error)
+}
+
+type CreateAPIKey struct {
+	OwnerID string   `json:"owner_id" validate:"required,max=64"`
+	Name    string   `json:"name" validate:"max=128"`
+	Scopes  []string `json:"scopes" validate:"required,min=1,dive,oneof=read write admin"`
+}
+
+type CreatedAPIKey struct {
+	ID      string   `json:"id"`
+	OwnerID string   `json:"owner_id"`
+	Name    string   `json:"name,omitempty"`
+	Scopes  []string `json:"scopes"`
+	// Key is returned only once, the service keeps just its hash
+	Key       string    `json:"key"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,82 @@
+package create_api_key
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"link-shortener-service/internal/handler"
+	"link-shortener-service/internal/model"
+	usecase_create_api_key "link-shortener-service/internal/usecase/create_api_key"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type keyHandler struct {
+	usecase   usecase
+	validator *validator.Validate
+}
+
+func New(usecase usecase, validator *validator.Validate) *keyHandler {
+	return &keyHandler{
+		usecase:   usecase,
+		validator: validator,
+	}
+}
+
+func (h *keyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var key CreateAPIKey
+	if err := json.NewDecoder(r.Body).Decode(&key); err != nil {
+		handler.RespondWithError(w, http.StatusBadRequest, "failed to decode request", err)
+		return
+	}
+
+	if err := h.validator.Struct(key); err != nil {
+		handler.RespondWithError(w, http.StatusBadRequest, "validation failed", err)
+		return
+	}
+
+	scopes := make([]model.Scope, len(key.Scopes))
+	for i, scope := range key.Scopes {
+		scopes[i] = model.Scope(scope)
+	}
+	result, err := h.usecase.Run(r.Context(), usecase_create_api_key.In{
+		OwnerID: key.OwnerID,
+		Name:    key.Name,
+		Scopes:  scopes,
+	})
+	if err != nil {
+		handleUseCaseError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err = json.NewEncoder(w).Encode(CreatedAPIKey{
+		ID:        result.APIKey.ID,
+		OwnerID:   result.APIKey.OwnerID,
+		Name:      result.APIKey.Name,
+		Scopes:    key.Scopes,
+		Key:       result.Key,
+		CreatedAt: result.APIKey.CreatedAt,
+	}); err != nil {
+		handler.RespondWithError(w, http.StatusInternalServerError, "failed to encode response", err)
+		return
+	}
+}
+
+func handleUseCaseError(w http.ResponseWriter, err error) {
+	statusCode := http.StatusInternalServerError
+	errorMsg := "internal server error"
+
+	switch {
+	case errors.Is(err, usecase_create_api_key.ErrInvalidScope):
+		statusCode = http.StatusBadRequest
+		errorMsg = "invalid scope"
+	case errors.Is(err, usecase_create_api_key.ErrKeyCreate):
+		errorMsg = "failed to create API key"
+	}
+
+	handler.RespondWithError(w, statusCode, errorMsg, err)
+}
@@ -0,0 +1,121 @@
+package create_api_key
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	create_api_key "link-shortener-service/internal/handler/create_api_key/mocks"
+	"link-shortener-service/internal/model"
+	usecase_create_api_key "link-shortener-service/internal/usecase/create_api_key"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	valid := validator.New(validator.WithRequiredStructEnabled())
+
+	usecaseIn := usecase_create_api_key.In{
+		OwnerID: "team-a",
+		Name:    "ci",
+		Scopes:  []model.Scope{model.ScopeRead, model.ScopeWrite},
+	}
+	createdAt := time.Date(2025, 6, 10, 9, 0, 0, 0, time.UTC)
+	usecaseOut := usecase_create_api_key.Out{
+		APIKey: model.APIKey{ID: "k1", OwnerID: "team-a", Name: "ci", Scopes: usecaseIn.Scopes, CreatedAt: createdAt},
+		Key:    "lsk_k1_secret",
+	}
+
+	tests := []struct {
+		name          string
+		setupMock     func(*create_api_key.Mockusecase)
+		reqBody       string
+		expectedCode  int
+		expected      *CreatedAPIKey
+		expectedError string
+	}{
+		{
+			name: "successful create",
+			setupMock: func(mockUsecase *create_api_key.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecaseIn).
+					Return(&usecaseOut, nil)
+			},
+			reqBody:      `{"owner_id":"team-a","name":"ci","scopes":["read","write"]}`,
+			expectedCode: http.StatusCreated,
+			expected: &CreatedAPIKey{
+				ID:        "k1",
+				OwnerID:   "team-a",
+				Name:      "ci",
+				Scopes:    []string{"read", "write"},
+				Key:       "lsk_k1_secret",
+				CreatedAt: createdAt,
+			},
+		},
+		{
+			name:          "unknown scope",
+			setupMock:     func(mockUsecase *create_api_key.Mockusecase) {},
+			reqBody:       `{"owner_id":"team-a","scopes":["root"]}`,
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "validation failed",
+		},
+		{
+			name:          "missing owner",
+			setupMock:     func(mockUsecase *create_api_key.Mockusecase) {},
+			reqBody:       `{"scopes":["read"]}`,
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "validation failed",
+		},
+		{
+			name: "usecase.Run error - error from storage",
+			setupMock: func(mockUsecase *create_api_key.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecaseIn).
+					Return(nil, usecase_create_api_key.ErrKeyCreate)
+			},
+			reqBody:       `{"owner_id":"team-a","name":"ci","scopes":["read","write"]}`,
+			expectedCode:  http.StatusInternalServerError,
+			expectedError: "failed to create API key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := create_api_key.NewMockusecase(ctrl)
+			handler := New(mockUsecase, valid)
+
+			tt.setupMock(mockUsecase)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/api/keys", strings.NewReader(tt.reqBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			handler.CreateAPIKey(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+
+			if tt.expected != nil {
+				var response CreatedAPIKey
+				err := json.NewDecoder(w.Body).Decode(&response)
+				require.NoError(t, err)
+				assert.Equal(t, *tt.expected, response)
+			}
+
+			if tt.expectedError != "" {
+				var errorResponse map[string]interface{}
+				err := json.NewDecoder(w.Body).Decode(&errorResponse)
+				require.NoError(t, err)
+				assert.Contains(t, errorResponse["error"], tt.expectedError)
+			}
+		})
+	}
+}
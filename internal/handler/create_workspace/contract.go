@@ -0,0 +1,27 @@
+package create_workspace
+
+import (
+	"context"
+	"time"
+
+	"link-shortener-service/internal/model"
+	"link-shortener-service/internal/usecase/create_workspace"
+)
+
+//go:generate mockgen -source=contract.go -destination=mocks/contract_mock.go -package=create_workspace usecase
+type usecase interface {
+	Run(ctx context.Context, req create_workspace.In) (*model.Workspace, error)
+}
+
+type CreateWorkspace struct {
+	ID      string   `json:"id" validate:"required,max=64"`
+	Name    string   `json:"name" validate:"max=128"`
+	Domains []string `json:"domains" validate:"dive,fqdn"`
+}
+
+type CreatedWorkspace struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name,omitempty"`
+	Domains   []string  `json:"domains"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,85 @@
+package create_workspace
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"link-shortener-service/internal/handler"
+	usecase_create_workspace "link-shortener-service/internal/usecase/create_workspace"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type workspaceHandler struct {
+	usecase   usecase
+	validator *validator.Validate
+}
+
+func New(usecase usecase, validator *validator.Validate) *workspaceHandler {
+	return &workspaceHandler{
+		usecase:   usecase,
+		validator: validator,
+	}
+}
+
+func (h *workspaceHandler) CreateWorkspace(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var workspace CreateWorkspace
+	if err := json.NewDecoder(r.Body).Decode(&workspace); err != nil {
+		handler.RespondWithError(w, http.StatusBadRequest, "failed to decode request", err)
+		return
+	}
+
+	if err := h.validator.Struct(workspace); err != nil {
+		handler.RespondWithError(w, http.StatusBadRequest, "validation failed", err)
+		return
+	}
+
+	result, err := h.usecase.Run(r.Context(), usecase_create_workspace.In{
+		ID:      workspace.ID,
+		Name:    workspace.Name,
+		Domains: workspace.Domains,
+	})
+	if err != nil {
+		handleUseCaseError(w, err)
+		return
+	}
+
+	domains := result.Domains
+	if domains == nil {
+		domains = []string{}
+	}
+	w.WriteHeader(http.StatusCreated)
+	if err = json.NewEncoder(w).Encode(CreatedWorkspace{
+		ID:        result.ID,
+		Name:      result.Name,
+		Domains:   domains,
+		CreatedAt: result.CreatedAt,
+	}); err != nil {
+		handler.RespondWithError(w, http.StatusInternalServerError, "failed to encode response", err)
+		return
+	}
+}
+
+func handleUseCaseError(w http.ResponseWriter, err error) {
+	statusCode := http.StatusInternalServerError
+	errorMsg := "internal server error"
+
+	switch {
+	case errors.Is(err, usecase_create_workspace.ErrInvalidDomain):
+		statusCode = http.StatusBadRequest
+		errorMsg = "invalid short domain"
+	case errors.Is(err, usecase_create_workspace.ErrWorkspaceExist):
+		statusCode = http.StatusConflict
+		errorMsg = "workspace already exists"
+	case errors.Is(err, usecase_create_workspace.ErrDomainTaken):
+		statusCode = http.StatusConflict
+		errorMsg = "short domain belongs to another workspace"
+	case errors.Is(err, usecase_create_workspace.ErrWorkspaceCreate):
+		errorMsg = "failed to create workspace"
+	}
+
+	handler.RespondWithError(w, statusCode, errorMsg, err)
+}
@@ -0,0 +1,149 @@
+package create_workspace
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	create_workspace "link-shortener-service/internal/handler/create_workspace/mocks"
+	"link-shortener-service/internal/model"
+	usecase_create_workspace "link-shortener-service/internal/usecase/create_workspace"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateWorkspace(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	valid := validator.New(validator.WithRequiredStructEnabled())
+
+	usecaseIn := usecase_create_workspace.In{
+		ID:      "acme",
+		Name:    "Acme",
+		Domains: []string{"go.acme.io"},
+	}
+	createdAt := time.Date(2025, 6, 17, 10, 0, 0, 0, time.UTC)
+	reqBody := `{"id":"acme","name":"Acme","domains":["go.acme.io"]}`
+
+	tests := []struct {
+		name          string
+		setupMock     func(*create_workspace.Mockusecase)
+		reqBody       string
+		expectedCode  int
+		expected      *CreatedWorkspace
+		expectedError string
+	}{
+		{
+			name: "successful create",
+			setupMock: func(mockUsecase *create_workspace.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecaseIn).
+					Return(&model.Workspace{ID: "acme", Name: "Acme", Domains: []string{"go.acme.io"}, CreatedAt: createdAt}, nil)
+			},
+			reqBody:      reqBody,
+			expectedCode: http.StatusCreated,
+			expected: &CreatedWorkspace{
+				ID:        "acme",
+				Name:      "Acme",
+				Domains:   []string{"go.acme.io"},
+				CreatedAt: createdAt,
+			},
+		},
+		{
+			name:          "missing id",
+			setupMock:     func(mockUsecase *create_workspace.Mockusecase) {},
+			reqBody:       `{"name":"Acme"}`,
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "validation failed",
+		},
+		{
+			name:          "domain is not a host name",
+			setupMock:     func(mockUsecase *create_workspace.Mockusecase) {},
+			reqBody:       `{"id":"acme","domains":["https://go.acme.io/"]}`,
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "validation failed",
+		},
+		{
+			name: "usecase.Run error - invalid domain",
+			setupMock: func(mockUsecase *create_workspace.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecaseIn).
+					Return(nil, usecase_create_workspace.ErrInvalidDomain)
+			},
+			reqBody:       reqBody,
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "invalid short domain",
+		},
+		{
+			name: "usecase.Run error - workspace exists",
+			setupMock: func(mockUsecase *create_workspace.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecaseIn).
+					Return(nil, usecase_create_workspace.ErrWorkspaceExist)
+			},
+			reqBody:       reqBody,
+			expectedCode:  http.StatusConflict,
+			expectedError: "workspace already exists",
+		},
+		{
+			name: "usecase.Run error - domain is taken",
+			setupMock: func(mockUsecase *create_workspace.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecaseIn).
+					Return(nil, usecase_create_workspace.ErrDomainTaken)
+			},
+			reqBody:       reqBody,
+			expectedCode:  http.StatusConflict,
+			expectedError: "short domain belongs to another workspace",
+		},
+		{
+			name: "usecase.Run error - error from storage",
+			setupMock: func(mockUsecase *create_workspace.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecaseIn).
+					Return(nil, usecase_create_workspace.ErrWorkspaceCreate)
+			},
+			reqBody:       reqBody,
+			expectedCode:  http.StatusInternalServerError,
+			expectedError: "failed to create workspace",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := create_workspace.NewMockusecase(ctrl)
+			handler := New(mockUsecase, valid)
+
+			tt.setupMock(mockUsecase)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/api/workspaces", strings.NewReader(tt.reqBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			handler.CreateWorkspace(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+
+			if tt.expected != nil {
+				var response CreatedWorkspace
+				err := json.NewDecoder(w.Body).Decode(&response)
+				require.NoError(t, err)
+				assert.Equal(t, *tt.expected, response)
+			}
+
+			if tt.expectedError != "" {
+				var errorResponse map[string]interface{}
+				err := json.NewDecoder(w.Body).Decode(&errorResponse)
+				require.NoError(t, err)
+				assert.Contains(t, errorResponse["error"], tt.expectedError)
+			}
+		})
+	}
+}
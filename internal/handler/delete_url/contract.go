@@ -0,0 +1,12 @@
+package delete_url
+
+import (
+	"context"
+
+	"link-shortener-service/internal/usecase/delete_url"
+)
+
+//go:generate mockgen -source=contract.go -destination=mocks/contract_mock.go -package=delete_url usecase
+type usecase interface {
+	Run(ctx context.Context, req delete_url.In) error
+}
@@ -0,0 +1,58 @@
+package delete_url
+
+import (
+	"errors"
+	"net/http"
+
+	"link-shortener-service/internal/handler"
+	"link-shortener-service/internal/middleware"
+	usecase_delete_url "link-shortener-service/internal/usecase/delete_url"
+)
+
+type urlHandler struct {
+	usecase         usecase
+	caseInsensitive bool
+}
+
+func New(usecase usecase, caseInsensitive bool) *urlHandler {
+	return &urlHandler{
+		usecase:         usecase,
+		caseInsensitive: caseInsensitive,
+	}
+}
+
+func (h *urlHandler) DeleteURL(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	principal, _ := middleware.PrincipalFromContext(r.Context())
+	err := h.usecase.Run(r.Context(), usecase_delete_url.In{
+		Principal:  principal,
+		ShortedURL: handler.LinkKey(r, h.caseInsensitive),
+	})
+	if err != nil {
+		handleUseCaseError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func handleUseCaseError(w http.ResponseWriter, err error) {
+	statusCode := http.StatusInternalServerError
+	errorMsg := "internal server error"
+
+	switch {
+	case errors.Is(err, usecase_delete_url.ErrURLNotFound):
+		statusCode = http.StatusNotFound
+		errorMsg = "short URL does not exist"
+	case errors.Is(err, usecase_delete_url.ErrForbidden):
+		statusCode = http.StatusForbidden
+		errorMsg = "short URL belongs to another owner"
+	case errors.Is(err, usecase_delete_url.ErrURLRetrieval):
+		errorMsg = "failed to get short URL"
+	case errors.Is(err, usecase_delete_url.ErrURLDelete):
+		errorMsg = "failed to delete short URL"
+	}
+
+	handler.RespondWithError(w, statusCode, errorMsg, err)
+}
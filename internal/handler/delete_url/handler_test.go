@@ -0,0 +1,135 @@
+package delete_url
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	delete_url "link-shortener-service/internal/handler/delete_url/mocks"
+	usecase_delete_url "link-shortener-service/internal/usecase/delete_url"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteURL(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	usecaseIn := usecase_delete_url.In{
+		ShortedURL: "xHsvC_0NTU",
+	}
+
+	tests := []struct {
+		name          string
+		setupMock     func(*delete_url.Mockusecase)
+		expectedCode  int
+		expectedError string
+	}{
+		{
+			name: "successful delete",
+			setupMock: func(mockUsecase *delete_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecaseIn).
+					Return(nil)
+			},
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name: "usecase.Run error - unknown short URL",
+			setupMock: func(mockUsecase *delete_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecaseIn).
+					Return(usecase_delete_url.ErrURLNotFound)
+			},
+			expectedCode:  http.StatusNotFound,
+			expectedError: "short URL does not exist",
+		},
+		{
+			name: "usecase.Run error - link of another owner",
+			setupMock: func(mockUsecase *delete_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecaseIn).
+					Return(usecase_delete_url.ErrForbidden)
+			},
+			expectedCode:  http.StatusForbidden,
+			expectedError: "short URL belongs to another owner",
+		},
+		{
+			name: "usecase.Run error - error from storage",
+			setupMock: func(mockUsecase *delete_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecaseIn).
+					Return(usecase_delete_url.ErrURLDelete)
+			},
+			expectedCode:  http.StatusInternalServerError,
+			expectedError: "failed to delete short URL",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := delete_url.NewMockusecase(ctrl)
+			handler := New(mockUsecase, false)
+
+			tt.setupMock(mockUsecase)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("DELETE", "/api/links/xHsvC_0NTU", nil)
+			req = mux.SetURLVars(req, map[string]string{"code": "xHsvC_0NTU"})
+
+			handler.DeleteURL(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+
+			if tt.expectedError != "" {
+				var errorResponse map[string]interface{}
+				err := json.NewDecoder(w.Body).Decode(&errorResponse)
+				require.NoError(t, err)
+				assert.Contains(t, errorResponse["error"], tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestDeleteURLCaseInsensitive(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name            string
+		caseInsensitive bool
+		expected        string
+	}{
+		{
+			name:            "case insensitive codes are looked up in lowercase",
+			caseInsensitive: true,
+			expected:        "example.com/spring-sale",
+		},
+		{
+			name:     "case sensitive codes are kept",
+			expected: "example.com/Spring-Sale",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := delete_url.NewMockusecase(ctrl)
+			mockUsecase.EXPECT().
+				Run(gomock.Any(), usecase_delete_url.In{ShortedURL: tt.expected}).
+				Return(nil)
+			handler := New(mockUsecase, tt.caseInsensitive)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("DELETE", "/api/links/Spring-Sale?domain=Example.com", nil)
+			req = mux.SetURLVars(req, map[string]string{"code": "Spring-Sale"})
+
+			handler.DeleteURL(w, req)
+
+			assert.Equal(t, http.StatusNoContent, w.Code)
+		})
+	}
+}
@@ -0,0 +1,12 @@
+package disable_url
+
+import (
+	"context"
+
+	"link-shortener-service/internal/usecase/disable_url"
+)
+
+//go:generate mockgen -source=contract.go -destination=mocks/contract_mock.go -package=disable_url usecase
+type usecase interface {
+	Run(ctx context.Context, req disable_url.In) error
+}
@@ -0,0 +1,111 @@
+package disable_url
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	disable_url "link-shortener-service/internal/handler/disable_url/mocks"
+	usecase_disable_url "link-shortener-service/internal/usecase/disable_url"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetDisabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name          string
+		disable       bool
+		setupMock     func(*disable_url.Mockusecase)
+		expectedCode  int
+		expectedError string
+	}{
+		{
+			name:    "successful disable",
+			disable: true,
+			setupMock: func(mockUsecase *disable_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecase_disable_url.In{ShortedURL: "xHsvC_0NTU", Disabled: true}).
+					Return(nil)
+			},
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:    "successful enable",
+			disable: false,
+			setupMock: func(mockUsecase *disable_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecase_disable_url.In{ShortedURL: "xHsvC_0NTU", Disabled: false}).
+					Return(nil)
+			},
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:    "usecase.Run error - unknown short URL",
+			disable: true,
+			setupMock: func(mockUsecase *disable_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), gomock.Any()).
+					Return(usecase_disable_url.ErrURLNotFound)
+			},
+			expectedCode:  http.StatusNotFound,
+			expectedError: "short URL does not exist",
+		},
+		{
+			name:    "usecase.Run error - link of another owner",
+			disable: true,
+			setupMock: func(mockUsecase *disable_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), gomock.Any()).
+					Return(usecase_disable_url.ErrForbidden)
+			},
+			expectedCode:  http.StatusForbidden,
+			expectedError: "short URL belongs to another owner",
+		},
+		{
+			name:    "usecase.Run error - error from storage",
+			disable: false,
+			setupMock: func(mockUsecase *disable_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), gomock.Any()).
+					Return(usecase_disable_url.ErrURLUpdate)
+			},
+			expectedCode:  http.StatusInternalServerError,
+			expectedError: "failed to update short URL",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := disable_url.NewMockusecase(ctrl)
+			handler := New(mockUsecase, false)
+
+			tt.setupMock(mockUsecase)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/api/links/xHsvC_0NTU/disable", nil)
+			req = mux.SetURLVars(req, map[string]string{"code": "xHsvC_0NTU"})
+
+			if tt.disable {
+				handler.DisableURL(w, req)
+			} else {
+				handler.EnableURL(w, req)
+			}
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+
+			if tt.expectedError != "" {
+				var errorResponse map[string]interface{}
+				err := json.NewDecoder(w.Body).Decode(&errorResponse)
+				require.NoError(t, err)
+				assert.Contains(t, errorResponse["error"], tt.expectedError)
+			}
+		})
+	}
+}
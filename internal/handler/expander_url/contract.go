@@ -14,4 +14,5 @@ type usecase interface {
 
 type ExpandToOriginalURL struct {
 	ShortedURL string `json:"shorted_url" validate:"required,url"`
+	Password   string `json:"password"`
 }
@@ -1,12 +1,12 @@
 package expander_url
 
 import (
-	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
 
 	"link-shortener-service/internal/handler"
+	"link-shortener-service/internal/middleware"
 	usecase_expander_url "link-shortener-service/internal/usecase/expander_url"
 
 	"github.com/go-playground/validator/v10"
@@ -15,12 +15,14 @@ import (
 type urlHandler struct {
 	usecase   usecase
 	validator *validator.Validate
+	proxies   middleware.TrustedProxies
 }
 
-func New(usecase usecase, validator *validator.Validate) *urlHandler {
+func New(usecase usecase, validator *validator.Validate, proxies middleware.TrustedProxies) *urlHandler {
 	return &urlHandler{
 		usecase:   usecase,
 		validator: validator,
+		proxies:   proxies,
 	}
 }
 
@@ -38,9 +40,13 @@ func (h *urlHandler) ExpanderURL(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx := context.TODO()
-	result, err := h.usecase.Run(ctx, usecase_expander_url.In{
+	result, err := h.usecase.Run(r.Context(), usecase_expander_url.In{
 		ShortedURL: url.ShortedURL,
+		Host:       r.Host,
+		// API clients get the destination without the interstitial page
+		Confirmed: true,
+		Password:  url.Password,
+		Visitor:   handler.VisitorFromRequest(r, h.proxies.ClientIP(r), h.proxies.Proxied(r)),
 	})
 	if err != nil {
 		handleUseCaseError(w, err)
@@ -63,6 +69,27 @@ func handleUseCaseError(w http.ResponseWriter, err error) {
 	case errors.Is(err, usecase_expander_url.ErrURLNotFound):
 		statusCode = http.StatusNotFound
 		errorMsg = "original URL does not exist"
+	case errors.Is(err, usecase_expander_url.ErrURLExpired):
+		statusCode = http.StatusGone
+		errorMsg = "short URL has expired"
+	case errors.Is(err, usecase_expander_url.ErrURLExhausted):
+		statusCode = http.StatusGone
+		errorMsg = "short URL has no clicks left"
+	case errors.Is(err, usecase_expander_url.ErrURLDisabled):
+		statusCode = http.StatusGone
+		errorMsg = "short URL is disabled"
+	case errors.Is(err, usecase_expander_url.ErrURLBlocked):
+		statusCode = http.StatusGone
+		errorMsg = "short URL leads to a blocklisted destination"
+	case errors.Is(err, usecase_expander_url.ErrPasswordRequired):
+		statusCode = http.StatusUnauthorized
+		errorMsg = "password required"
+	case errors.Is(err, usecase_expander_url.ErrWrongPassword):
+		statusCode = http.StatusForbidden
+		errorMsg = "wrong password"
+	case errors.Is(err, usecase_expander_url.ErrTooManyAttempts):
+		statusCode = http.StatusTooManyRequests
+		errorMsg = "too many failed password attempts"
 	case errors.Is(err, usecase_expander_url.ErrURLRetrieval):
 		errorMsg = "failed to get original URL"
 	}
@@ -28,8 +28,13 @@ func TestShorterURL(t *testing.T) {
 	reqDTO := ExpandToOriginalURL{
 		ShortedURL: "https://some.com/xHsvC_0NTU",
 	}
+	// httptest requests come from this address
+	visitor := model.Visitor{IP: "192.0.2.1"}
 	usecaseIn := usecase_expander_url.In{
 		ShortedURL: "https://some.com/xHsvC_0NTU",
+		Host:       "example.com",
+		Confirmed:  true,
+		Visitor:    visitor,
 	}
 	usecaseOut := model.URLPair{
 		Original: "https://some.com/asdasd",
@@ -48,7 +53,7 @@ func TestShorterURL(t *testing.T) {
 			name: "successful expand",
 			setupMock: func(mockUsecase *expander_url.Mockusecase) {
 				mockUsecase.EXPECT().
-					Run(context.TODO(), usecaseIn).
+					Run(gomock.Any(), usecaseIn).
 					Return(&usecaseOut, nil)
 			},
 			reqBody:      fmt.Sprintf(`{"shorted_url":"%s"}`, reqDTO.ShortedURL),
@@ -73,18 +78,95 @@ func TestShorterURL(t *testing.T) {
 			name: "usecase.Run error - not found existing short URL associating",
 			setupMock: func(mockUsecase *expander_url.Mockusecase) {
 				mockUsecase.EXPECT().
-					Run(context.TODO(), usecaseIn).
+					Run(gomock.Any(), usecaseIn).
 					Return(nil, usecase_expander_url.ErrURLNotFound)
 			},
 			reqBody:       fmt.Sprintf(`{"shorted_url":"%s"}`, reqDTO.ShortedURL),
 			expectedCode:  http.StatusNotFound,
 			expectedError: "original URL does not exist",
 		},
+		{
+			name: "usecase.Run error - expired short URL",
+			setupMock: func(mockUsecase *expander_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecaseIn).
+					Return(nil, usecase_expander_url.ErrURLExpired)
+			},
+			reqBody:       fmt.Sprintf(`{"shorted_url":"%s"}`, reqDTO.ShortedURL),
+			expectedCode:  http.StatusGone,
+			expectedError: "short URL has expired",
+		},
+		{
+			name: "usecase.Run error - no clicks left",
+			setupMock: func(mockUsecase *expander_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecaseIn).
+					Return(nil, usecase_expander_url.ErrURLExhausted)
+			},
+			reqBody:       fmt.Sprintf(`{"shorted_url":"%s"}`, reqDTO.ShortedURL),
+			expectedCode:  http.StatusGone,
+			expectedError: "short URL has no clicks left",
+		},
+		{
+			name: "usecase.Run error - disabled short URL",
+			setupMock: func(mockUsecase *expander_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecaseIn).
+					Return(nil, usecase_expander_url.ErrURLDisabled)
+			},
+			reqBody:       fmt.Sprintf(`{"shorted_url":"%s"}`, reqDTO.ShortedURL),
+			expectedCode:  http.StatusGone,
+			expectedError: "short URL is disabled",
+		},
+		{
+			name: "usecase.Run error - blocklisted destination",
+			setupMock: func(mockUsecase *expander_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecaseIn).
+					Return(nil, usecase_expander_url.ErrURLBlocked)
+			},
+			reqBody:       fmt.Sprintf(`{"shorted_url":"%s"}`, reqDTO.ShortedURL),
+			expectedCode:  http.StatusGone,
+			expectedError: "short URL leads to a blocklisted destination",
+		},
+		{
+			name: "usecase.Run error - password required",
+			setupMock: func(mockUsecase *expander_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecaseIn).
+					Return(nil, usecase_expander_url.ErrPasswordRequired)
+			},
+			reqBody:       fmt.Sprintf(`{"shorted_url":"%s"}`, reqDTO.ShortedURL),
+			expectedCode:  http.StatusUnauthorized,
+			expectedError: "password required",
+		},
+		{
+			name: "usecase.Run error - wrong password",
+			setupMock: func(mockUsecase *expander_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecase_expander_url.In{ShortedURL: reqDTO.ShortedURL, Host: "example.com", Confirmed: true, Password: "wrong", Visitor: visitor}).
+					Return(nil, usecase_expander_url.ErrWrongPassword)
+			},
+			reqBody:       fmt.Sprintf(`{"shorted_url":"%s","password":"wrong"}`, reqDTO.ShortedURL),
+			expectedCode:  http.StatusForbidden,
+			expectedError: "wrong password",
+		},
+		{
+			name: "usecase.Run error - too many attempts",
+			setupMock: func(mockUsecase *expander_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecase_expander_url.In{ShortedURL: reqDTO.ShortedURL, Host: "example.com", Confirmed: true, Password: "wrong", Visitor: visitor}).
+					Return(nil, usecase_expander_url.ErrTooManyAttempts)
+			},
+			reqBody:       fmt.Sprintf(`{"shorted_url":"%s","password":"wrong"}`, reqDTO.ShortedURL),
+			expectedCode:  http.StatusTooManyRequests,
+			expectedError: "too many failed password attempts",
+		},
 		{
 			name: "usecase.Run error - error from storage",
 			setupMock: func(mockUsecase *expander_url.Mockusecase) {
 				mockUsecase.EXPECT().
-					Run(context.TODO(), usecaseIn).
+					Run(gomock.Any(), usecaseIn).
 					Return(nil, usecase_expander_url.ErrURLRetrieval)
 			},
 			reqBody:       fmt.Sprintf(`{"shorted_url":"%s"}`, reqDTO.ShortedURL),
@@ -96,7 +178,7 @@ func TestShorterURL(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockUsecase := expander_url.NewMockusecase(ctrl)
-			handler := New(mockUsecase, valid)
+			handler := New(mockUsecase, valid, nil)
 
 			tt.setupMock(mockUsecase)
 
@@ -128,3 +210,23 @@ func TestShorterURL(t *testing.T) {
 		})
 	}
 }
+
+func TestExpanderURLPassesRequestContext(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := expander_url.NewMockusecase(ctrl)
+	mockUsecase.EXPECT().
+		Run(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, in usecase_expander_url.In) (*model.URLPair, error) {
+			assert.ErrorIs(t, ctx.Err(), context.Canceled)
+			return &model.URLPair{Original: "https://some.com/asdasd", Shorted: in.ShortedURL}, nil
+		})
+	handler := New(mockUsecase, validator.New(validator.WithRequiredStructEnabled()), nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequestWithContext(ctx, "GET", "/", strings.NewReader(`{"shorted_url":"https://some.com/xHsvC_0NTU"}`))
+	req.Header.Set("Content-Type", "application/json")
+	handler.ExpanderURL(httptest.NewRecorder(), req)
+}
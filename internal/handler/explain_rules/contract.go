@@ -0,0 +1,36 @@
+package explain_rules
+
+import (
+	"context"
+	"time"
+
+	"link-shortener-service/internal/handler"
+	"link-shortener-service/internal/usecase/explain_rules"
+)
+
+//go:generate mockgen -source=contract.go -destination=mocks/contract_mock.go -package=explain_rules usecase
+type usecase interface {
+	Run(ctx context.Context, req explain_rules.In) (*explain_rules.Out, error)
+}
+
+type Explanation struct {
+	Visitor Visitor `json:"visitor"`
+	// MatchedRule is the index of the matching rule, null when the visitor goes to the original URL
+	MatchedRule *int         `json:"matched_rule"`
+	Destination string       `json:"destination"`
+	Rules       []RuleResult `json:"rules"`
+}
+
+// Visitor is what the rules know about the visitor
+type Visitor struct {
+	Device   string    `json:"device"`
+	Language string    `json:"language"`
+	Country  string    `json:"country"`
+	At       time.Time `json:"at"`
+}
+
+type RuleResult struct {
+	handler.RedirectRule
+	Matches    bool     `json:"matches"`
+	Mismatches []string `json:"mismatches,omitempty"`
+}
@@ -0,0 +1,126 @@
+package explain_rules
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"link-shortener-service/internal/handler"
+	"link-shortener-service/internal/middleware"
+	usecase_explain_rules "link-shortener-service/internal/usecase/explain_rules"
+)
+
+type explainHandler struct {
+	usecase         usecase
+	caseInsensitive bool
+	proxies         middleware.TrustedProxies
+}
+
+func New(usecase usecase, caseInsensitive bool, proxies middleware.TrustedProxies) *explainHandler {
+	return &explainHandler{
+		usecase:         usecase,
+		caseInsensitive: caseInsensitive,
+		proxies:         proxies,
+	}
+}
+
+// Explain checks the redirect rules of the link for the visitor making the request, the query parameters
+// user_agent, accept_language, ip, country and at (RFC 3339) stand in for the request itself
+func (h *explainHandler) Explain(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	in, err := parseQuery(r, h.proxies)
+	if err != nil {
+		handler.RespondWithError(w, http.StatusBadRequest, "invalid query", err)
+		return
+	}
+	in.ShortedURL = handler.LinkKey(r, h.caseInsensitive)
+
+	result, err := h.usecase.Run(r.Context(), in)
+	if err != nil {
+		handleUseCaseError(w, err)
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(toExplanation(result)); err != nil {
+		handler.RespondWithError(w, http.StatusInternalServerError, "failed to encode response", err)
+		return
+	}
+}
+
+func parseQuery(r *http.Request, proxies middleware.TrustedProxies) (usecase_explain_rules.In, error) {
+	query := r.URL.Query()
+	principal, _ := middleware.PrincipalFromContext(r.Context())
+	in := usecase_explain_rules.In{
+		Principal: principal,
+		Visitor:   handler.VisitorFromRequest(r, proxies.ClientIP(r), proxies.Proxied(r)),
+	}
+
+	if query.Has("user_agent") {
+		in.Visitor.UserAgent = query.Get("user_agent")
+	}
+	if query.Has("accept_language") {
+		in.Visitor.AcceptLanguage = query.Get("accept_language")
+	}
+	// a different address is looked up again unless the country is given as well
+	if query.Has("ip") {
+		in.Visitor.IP = query.Get("ip")
+		in.Visitor.Country = ""
+	}
+	if query.Has("country") {
+		in.Visitor.Country = strings.ToUpper(query.Get("country"))
+	}
+	if raw := query.Get("at"); raw != "" {
+		at, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return usecase_explain_rules.In{}, fmt.Errorf("at must be an RFC 3339 time: %w", err)
+		}
+		in.At = at
+	}
+	return in, nil
+}
+
+func toExplanation(result *usecase_explain_rules.Out) Explanation {
+	explanation := Explanation{
+		Visitor: Visitor{
+			Device:   result.Audience.Device,
+			Language: result.Audience.Language,
+			Country:  result.Audience.Country,
+			At:       result.Audience.At,
+		},
+		Destination: result.Destination,
+		Rules:       make([]RuleResult, 0, len(result.Rules)),
+	}
+	if result.Matched >= 0 {
+		explanation.MatchedRule = &result.Matched
+	}
+	for _, rule := range result.Rules {
+		explanation.Rules = append(explanation.Rules, RuleResult{
+			RedirectRule: handler.RuleFromModel(rule.Rule),
+			Matches:      len(rule.Mismatches) == 0,
+			Mismatches:   rule.Mismatches,
+		})
+	}
+	return explanation
+}
+
+func handleUseCaseError(w http.ResponseWriter, err error) {
+	statusCode := http.StatusInternalServerError
+	errorMsg := "internal server error"
+
+	switch {
+	case errors.Is(err, usecase_explain_rules.ErrURLNotFound):
+		statusCode = http.StatusNotFound
+		errorMsg = "short URL does not exist"
+	case errors.Is(err, usecase_explain_rules.ErrForbidden):
+		statusCode = http.StatusForbidden
+		errorMsg = "short URL belongs to another owner"
+	case errors.Is(err, usecase_explain_rules.ErrURLRetrieval):
+		errorMsg = "failed to get short URL"
+	}
+
+	handler.RespondWithError(w, statusCode, errorMsg, err)
+}
@@ -0,0 +1,156 @@
+package explain_rules
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	explain_rules "link-shortener-service/internal/handler/explain_rules/mocks"
+	"link-shortener-service/internal/model"
+	usecase_explain_rules "link-shortener-service/internal/usecase/explain_rules"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplain(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	at := time.Date(2025, 7, 15, 12, 0, 0, 0, time.UTC)
+	rule := model.RedirectRule{Target: "https://apps.apple.com/app/some", Devices: []string{"ios"}}
+
+	tests := []struct {
+		name          string
+		query         string
+		setupMock     func(*explain_rules.Mockusecase)
+		expectedCode  int
+		expected      string
+		expectedError string
+	}{
+		{
+			name:  "visitor from the query",
+			query: "?user_agent=iPhone&accept_language=pt-BR&ip=81.2.69.160&at=2025-07-15T12:00:00Z",
+			setupMock: func(mockUsecase *explain_rules.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecase_explain_rules.In{
+						ShortedURL: "app",
+						Visitor: model.Visitor{
+							UserAgent:      "iPhone",
+							AcceptLanguage: "pt-BR",
+							IP:             "81.2.69.160",
+						},
+						At: at,
+					}).
+					Return(&usecase_explain_rules.Out{
+						Audience:    model.Audience{Device: "ios", Language: "pt-br", Country: "BR", At: at},
+						Rules:       []usecase_explain_rules.RuleResult{{Rule: rule}},
+						Matched:     0,
+						Destination: "https://apps.apple.com/app/some",
+					}, nil)
+			},
+			expectedCode: http.StatusOK,
+			expected: `{
+				"visitor": {"device": "ios", "language": "pt-br", "country": "BR", "at": "2025-07-15T12:00:00Z"},
+				"matched_rule": 0,
+				"destination": "https://apps.apple.com/app/some",
+				"rules": [{"target": "https://apps.apple.com/app/some", "devices": ["ios"], "matches": true}]
+			}`,
+		},
+		{
+			name: "no rule matches",
+			setupMock: func(mockUsecase *explain_rules.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), gomock.Any()).
+					Return(&usecase_explain_rules.Out{
+						Audience: model.Audience{Device: "desktop", At: at},
+						Rules: []usecase_explain_rules.RuleResult{
+							{Rule: rule, Mismatches: []string{"device desktop is not one of ios"}},
+						},
+						Matched:     -1,
+						Destination: "https://some.com",
+					}, nil)
+			},
+			expectedCode: http.StatusOK,
+			expected: `{
+				"visitor": {"device": "desktop", "language": "", "country": "", "at": "2025-07-15T12:00:00Z"},
+				"matched_rule": null,
+				"destination": "https://some.com",
+				"rules": [{
+					"target": "https://apps.apple.com/app/some",
+					"devices": ["ios"],
+					"matches": false,
+					"mismatches": ["device desktop is not one of ios"]
+				}]
+			}`,
+		},
+		{
+			name:          "invalid time",
+			query:         "?at=yesterday",
+			setupMock:     func(mockUsecase *explain_rules.Mockusecase) {},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "invalid query",
+		},
+		{
+			name: "usecase.Run error - unknown short URL",
+			setupMock: func(mockUsecase *explain_rules.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), gomock.Any()).
+					Return(nil, usecase_explain_rules.ErrURLNotFound)
+			},
+			expectedCode:  http.StatusNotFound,
+			expectedError: "short URL does not exist",
+		},
+		{
+			name: "usecase.Run error - link of another owner",
+			setupMock: func(mockUsecase *explain_rules.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), gomock.Any()).
+					Return(nil, usecase_explain_rules.ErrForbidden)
+			},
+			expectedCode:  http.StatusForbidden,
+			expectedError: "short URL belongs to another owner",
+		},
+		{
+			name: "usecase.Run error - error from storage",
+			setupMock: func(mockUsecase *explain_rules.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), gomock.Any()).
+					Return(nil, usecase_explain_rules.ErrURLRetrieval)
+			},
+			expectedCode:  http.StatusInternalServerError,
+			expectedError: "failed to get short URL",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := explain_rules.NewMockusecase(ctrl)
+			handler := New(mockUsecase, false, nil)
+
+			tt.setupMock(mockUsecase)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/api/links/app/rules/explain"+tt.query, nil)
+			req.Header.Del("User-Agent")
+			req = mux.SetURLVars(req, map[string]string{"code": "app"})
+
+			handler.Explain(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+
+			if tt.expectedError != "" {
+				var errorResponse map[string]interface{}
+				err := json.NewDecoder(w.Body).Decode(&errorResponse)
+				require.NoError(t, err)
+				assert.Contains(t, errorResponse["error"], tt.expectedError)
+				return
+			}
+			assert.JSONEq(t, tt.expected, w.Body.String())
+		})
+	}
+}
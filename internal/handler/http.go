@@ -3,6 +3,11 @@ package handler
 import (
 	"encoding/json"
 	"net/http"
+	"strings"
+
+	"link-shortener-service/internal/model"
+
+	"github.com/gorilla/mux"
 )
 
 func RespondWithError(w http.ResponseWriter, status int, errorMsg string, err error) {
@@ -15,3 +20,32 @@ func RespondWithError(w http.ResponseWriter, status int, errorMsg string, err er
 		return
 	}
 }
+
+// countryHeader is set by CDNs in front of the service to the country of the client
+const countryHeader = "CF-IPCountry"
+
+// VisitorFromRequest describes the client making the request, ip is its address resolved behind
+// the trusted proxies. The country header is taken only from the proxies, the clients could pick
+// the country of the redirect rules with it otherwise
+func VisitorFromRequest(r *http.Request, ip string, proxied bool) model.Visitor {
+	visitor := model.Visitor{
+		Referrer:       r.Referer(),
+		UserAgent:      r.UserAgent(),
+		IP:             ip,
+		AcceptLanguage: r.Header.Get("Accept-Language"),
+	}
+	if proxied {
+		visitor.Country = strings.ToUpper(r.Header.Get(countryHeader))
+	}
+	return visitor
+}
+
+// LinkKey is the key of the link addressed by the code path variable, links on custom short domains
+// are addressed with the domain query parameter. Codes are stored in lowercase when they are case insensitive
+func LinkKey(r *http.Request, caseInsensitive bool) string {
+	code := mux.Vars(r)["code"]
+	if caseInsensitive {
+		code = strings.ToLower(code)
+	}
+	return model.LinkKey(model.NormalizeHost(r.URL.Query().Get("domain")), code)
+}
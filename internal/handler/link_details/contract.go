@@ -0,0 +1,40 @@
+package link_details
+
+import (
+	"context"
+	"time"
+
+	"link-shortener-service/internal/handler"
+	"link-shortener-service/internal/usecase/link_details"
+)
+
+//go:generate mockgen -source=contract.go -destination=mocks/contract_mock.go -package=link_details usecase
+type usecase interface {
+	Run(ctx context.Context, req link_details.In) (*link_details.Out, error)
+}
+
+type Details struct {
+	ShortURL       string     `json:"short_url"`
+	OriginalURL    string     `json:"original_url"`
+	RedirectStatus int        `json:"redirect_status,omitempty"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+	MaxClicks      int        `json:"max_clicks,omitempty"`
+	ClicksUsed     int        `json:"clicks_used"`
+	Protected      bool       `json:"password_protected"`
+	Disabled       bool       `json:"disabled"`
+	Interstitial   bool       `json:"interstitial"`
+	// Rules are the redirect rules in the order they are checked
+	Rules []handler.RedirectRule `json:"rules,omitempty"`
+	// Targets are the split targets of the visitors no rule matched
+	Targets []handler.SplitTarget `json:"targets,omitempty"`
+	// Preview is null until the metadata of the destination is fetched
+	Preview *Preview `json:"preview"`
+}
+
+type Preview struct {
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Favicon     string    `json:"favicon"`
+	Image       string    `json:"image"`
+	FetchedAt   time.Time `json:"fetched_at"`
+}
@@ -0,0 +1,95 @@
+package link_details
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"link-shortener-service/internal/handler"
+	"link-shortener-service/internal/middleware"
+	usecase_link_details "link-shortener-service/internal/usecase/link_details"
+)
+
+type detailsHandler struct {
+	usecase         usecase
+	caseInsensitive bool
+}
+
+func New(usecase usecase, caseInsensitive bool) *detailsHandler {
+	return &detailsHandler{
+		usecase:         usecase,
+		caseInsensitive: caseInsensitive,
+	}
+}
+
+func (h *detailsHandler) Details(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	principal, _ := middleware.PrincipalFromContext(r.Context())
+	result, err := h.usecase.Run(r.Context(), usecase_link_details.In{
+		Principal:  principal,
+		ShortedURL: handler.LinkKey(r, h.caseInsensitive),
+	})
+	if err != nil {
+		handleUseCaseError(w, err)
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(toDetails(result)); err != nil {
+		handler.RespondWithError(w, http.StatusInternalServerError, "failed to encode response", err)
+		return
+	}
+}
+
+func toDetails(result *usecase_link_details.Out) Details {
+	pair := result.URLPair
+	details := Details{
+		ShortURL:       pair.Shorted,
+		OriginalURL:    pair.Original,
+		RedirectStatus: pair.RedirectStatus,
+		MaxClicks:      pair.MaxClicks,
+		ClicksUsed:     pair.ClicksUsed,
+		Protected:      pair.IsProtected(),
+		Disabled:       pair.Disabled,
+		Interstitial:   pair.Interstitial,
+	}
+	if !pair.ExpiresAt.IsZero() {
+		details.ExpiresAt = &pair.ExpiresAt
+	}
+	for _, rule := range pair.Rules {
+		details.Rules = append(details.Rules, handler.RuleFromModel(rule))
+	}
+	for _, target := range pair.Targets {
+		details.Targets = append(details.Targets, handler.TargetFromModel(target))
+	}
+	if p := result.Preview; p != nil {
+		details.Preview = &Preview{
+			Title:       p.Title,
+			Description: p.Description,
+			Favicon:     p.Favicon,
+			Image:       p.Image,
+			FetchedAt:   p.FetchedAt,
+		}
+	}
+	return details
+}
+
+func handleUseCaseError(w http.ResponseWriter, err error) {
+	statusCode := http.StatusInternalServerError
+	errorMsg := "internal server error"
+
+	switch {
+	case errors.Is(err, usecase_link_details.ErrURLNotFound):
+		statusCode = http.StatusNotFound
+		errorMsg = "short URL does not exist"
+	case errors.Is(err, usecase_link_details.ErrForbidden):
+		statusCode = http.StatusForbidden
+		errorMsg = "short URL belongs to another owner"
+	case errors.Is(err, usecase_link_details.ErrURLRetrieval):
+		errorMsg = "failed to get short URL"
+	case errors.Is(err, usecase_link_details.ErrPreviewRetrieval):
+		errorMsg = "failed to get link preview"
+	}
+
+	handler.RespondWithError(w, statusCode, errorMsg, err)
+}
@@ -0,0 +1,196 @@
+package link_details
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	link_details "link-shortener-service/internal/handler/link_details/mocks"
+	"link-shortener-service/internal/model"
+	usecase_link_details "link-shortener-service/internal/usecase/link_details"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	expiresAt := time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)
+	fetchedAt := time.Date(2025, 7, 8, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		setupMock     func(*link_details.Mockusecase)
+		expectedCode  int
+		expected      string
+		expectedError string
+	}{
+		{
+			name: "link with preview",
+			setupMock: func(mockUsecase *link_details.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecase_link_details.In{ShortedURL: "xHsvC_0NTU"}).
+					Return(&usecase_link_details.Out{
+						URLPair: model.URLPair{
+							Original:     "https://some.com",
+							Shorted:      "http://localhost:8080/xHsvC_0NTU",
+							ExpiresAt:    expiresAt,
+							MaxClicks:    10,
+							ClicksUsed:   3,
+							PasswordHash: "$2a$10$hash",
+							Interstitial: true,
+						},
+						Preview: &model.LinkPreview{
+							LinkMetadata: model.LinkMetadata{
+								Title:       "Some",
+								Description: "Some page",
+								Favicon:     "https://some.com/favicon.ico",
+								Image:       "https://some.com/og.png",
+							},
+							FetchedAt: fetchedAt,
+						},
+					}, nil)
+			},
+			expectedCode: http.StatusOK,
+			expected: `{
+				"short_url": "http://localhost:8080/xHsvC_0NTU",
+				"original_url": "https://some.com",
+				"expires_at": "2025-12-31T00:00:00Z",
+				"max_clicks": 10,
+				"clicks_used": 3,
+				"password_protected": true,
+				"disabled": false,
+				"interstitial": true,
+				"preview": {
+					"title": "Some",
+					"description": "Some page",
+					"favicon": "https://some.com/favicon.ico",
+					"image": "https://some.com/og.png",
+					"fetched_at": "2025-07-08T12:00:00Z"
+				}
+			}`,
+		},
+		{
+			name: "metadata not fetched yet",
+			setupMock: func(mockUsecase *link_details.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), gomock.Any()).
+					Return(&usecase_link_details.Out{
+						URLPair: model.URLPair{Original: "https://some.com", Shorted: "http://localhost:8080/xHsvC_0NTU", RedirectStatus: 301},
+					}, nil)
+			},
+			expectedCode: http.StatusOK,
+			expected: `{
+				"short_url": "http://localhost:8080/xHsvC_0NTU",
+				"original_url": "https://some.com",
+				"redirect_status": 301,
+				"clicks_used": 0,
+				"password_protected": false,
+				"disabled": false,
+				"interstitial": false,
+				"preview": null
+			}`,
+		},
+		{
+			name: "link with redirect rules and split targets",
+			setupMock: func(mockUsecase *link_details.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), gomock.Any()).
+					Return(&usecase_link_details.Out{
+						URLPair: model.URLPair{
+							Original: "https://some.com",
+							Shorted:  "http://localhost:8080/xHsvC_0NTU",
+							Rules: []model.RedirectRule{
+								{Target: "https://apps.apple.com/app/some", Devices: []string{"ios"}},
+								{Target: "https://some.com/sale", Countries: []string{"DE"}, Until: expiresAt},
+							},
+							Targets: []model.SplitTarget{
+								{Variant: "a", URL: "https://some.com/a", Weight: 70},
+								{Variant: "b", URL: "https://some.com/b", Weight: 30},
+							},
+						},
+					}, nil)
+			},
+			expectedCode: http.StatusOK,
+			expected: `{
+				"short_url": "http://localhost:8080/xHsvC_0NTU",
+				"original_url": "https://some.com",
+				"clicks_used": 0,
+				"password_protected": false,
+				"disabled": false,
+				"interstitial": false,
+				"rules": [
+					{"target": "https://apps.apple.com/app/some", "devices": ["ios"]},
+					{"target": "https://some.com/sale", "countries": ["DE"], "until": "2025-12-31T00:00:00Z"}
+				],
+				"targets": [
+					{"variant": "a", "url": "https://some.com/a", "weight": 70},
+					{"variant": "b", "url": "https://some.com/b", "weight": 30}
+				],
+				"preview": null
+			}`,
+		},
+		{
+			name: "usecase.Run error - unknown short URL",
+			setupMock: func(mockUsecase *link_details.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), gomock.Any()).
+					Return(nil, usecase_link_details.ErrURLNotFound)
+			},
+			expectedCode:  http.StatusNotFound,
+			expectedError: "short URL does not exist",
+		},
+		{
+			name: "usecase.Run error - link of another owner",
+			setupMock: func(mockUsecase *link_details.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), gomock.Any()).
+					Return(nil, usecase_link_details.ErrForbidden)
+			},
+			expectedCode:  http.StatusForbidden,
+			expectedError: "short URL belongs to another owner",
+		},
+		{
+			name: "usecase.Run error - error from storage",
+			setupMock: func(mockUsecase *link_details.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), gomock.Any()).
+					Return(nil, usecase_link_details.ErrPreviewRetrieval)
+			},
+			expectedCode:  http.StatusInternalServerError,
+			expectedError: "failed to get link preview",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := link_details.NewMockusecase(ctrl)
+			handler := New(mockUsecase, false)
+
+			tt.setupMock(mockUsecase)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/api/links/xHsvC_0NTU", nil)
+			req = mux.SetURLVars(req, map[string]string{"code": "xHsvC_0NTU"})
+
+			handler.Details(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+
+			if tt.expectedError != "" {
+				var errorResponse map[string]interface{}
+				err := json.NewDecoder(w.Body).Decode(&errorResponse)
+				require.NoError(t, err)
+				assert.Contains(t, errorResponse["error"], tt.expectedError)
+				return
+			}
+			assert.JSONEq(t, tt.expected, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,12 @@
+package link_qr
+
+import (
+	"context"
+
+	"link-shortener-service/internal/usecase/link_qr"
+)
+
+//go:generate mockgen -source=contract.go -destination=mocks/contract_mock.go -package=link_qr usecase
+type usecase interface {
+	Run(ctx context.Context, req link_qr.In) (*link_qr.Out, error)
+}
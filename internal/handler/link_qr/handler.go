@@ -0,0 +1,145 @@
+package link_qr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"link-shortener-service/internal/handler"
+	"link-shortener-service/internal/middleware"
+	usecase_link_qr "link-shortener-service/internal/usecase/link_qr"
+)
+
+// cacheControl lets clients keep the image for a day, it is revalidated with the ETag afterwards
+const cacheControl = "private, max-age=86400"
+
+type qrHandler struct {
+	usecase         usecase
+	caseInsensitive bool
+}
+
+func New(usecase usecase, caseInsensitive bool) *qrHandler {
+	return &qrHandler{
+		usecase:         usecase,
+		caseInsensitive: caseInsensitive,
+	}
+}
+
+func (h *qrHandler) QR(w http.ResponseWriter, r *http.Request) {
+	in, err := parseQuery(r)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		handler.RespondWithError(w, http.StatusBadRequest, "invalid query", err)
+		return
+	}
+	in.ShortedURL = handler.LinkKey(r, h.caseInsensitive)
+
+	result, err := h.usecase.Run(r.Context(), in)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		handleUseCaseError(w, err)
+		return
+	}
+
+	w.Header().Set("ETag", result.ETag)
+	w.Header().Set("Cache-Control", cacheControl)
+	if matches(r.Header.Get("If-None-Match"), result.ETag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	// the image is drawn before the headers are sent to answer rendering errors with JSON
+	var buf bytes.Buffer
+	contentType := "image/png"
+	if result.Format == usecase_link_qr.FormatSVG {
+		contentType = "image/svg+xml"
+		err = result.Code.SVG(&buf, result.Style)
+	} else {
+		err = result.Code.PNG(&buf, result.Style)
+	}
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		handler.RespondWithError(w, http.StatusInternalServerError, "failed to render QR code", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	_, _ = buf.WriteTo(w)
+}
+
+func parseQuery(r *http.Request) (usecase_link_qr.In, error) {
+	query := r.URL.Query()
+	principal, _ := middleware.PrincipalFromContext(r.Context())
+	in := usecase_link_qr.In{
+		Principal:  principal,
+		Format:     usecase_link_qr.FormatPNG,
+		Size:       usecase_link_qr.DefaultSize,
+		Margin:     usecase_link_qr.DefaultMargin,
+		Level:      usecase_link_qr.DefaultLevel,
+		Foreground: usecase_link_qr.DefaultForeground,
+		Background: usecase_link_qr.DefaultBackground,
+	}
+
+	var err error
+	if format := query.Get("format"); format != "" {
+		in.Format = strings.ToLower(format)
+	}
+	if size := query.Get("size"); size != "" {
+		if in.Size, err = strconv.Atoi(size); err != nil {
+			return in, fmt.Errorf("size: %w", err)
+		}
+	}
+	if margin := query.Get("margin"); margin != "" {
+		if in.Margin, err = strconv.Atoi(margin); err != nil {
+			return in, fmt.Errorf("margin: %w", err)
+		}
+	}
+	if level := query.Get("ec"); level != "" {
+		in.Level = level
+	}
+	if fg := query.Get("fg"); fg != "" {
+		in.Foreground = fg
+	}
+	if bg := query.Get("bg"); bg != "" {
+		in.Background = bg
+	}
+	return in, nil
+}
+
+// matches is the weak comparison of If-None-Match from RFC 9110 section 13.1.2
+func matches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+func handleUseCaseError(w http.ResponseWriter, err error) {
+	statusCode := http.StatusInternalServerError
+	errorMsg := "internal server error"
+
+	switch {
+	case errors.Is(err, usecase_link_qr.ErrInvalidOptions):
+		statusCode = http.StatusBadRequest
+		errorMsg = "invalid query"
+	case errors.Is(err, usecase_link_qr.ErrURLNotFound):
+		statusCode = http.StatusNotFound
+		errorMsg = "short URL does not exist"
+	case errors.Is(err, usecase_link_qr.ErrForbidden):
+		statusCode = http.StatusForbidden
+		errorMsg = "short URL belongs to another owner"
+	case errors.Is(err, usecase_link_qr.ErrURLRetrieval):
+		errorMsg = "failed to get short URL"
+	case errors.Is(err, usecase_link_qr.ErrEncode):
+		errorMsg = "failed to encode QR code"
+	}
+
+	handler.RespondWithError(w, statusCode, errorMsg, err)
+}
@@ -0,0 +1,215 @@
+package link_qr
+
+import (
+	"encoding/json"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	link_qr "link-shortener-service/internal/handler/link_qr/mocks"
+	"link-shortener-service/internal/qrcode"
+	usecase_link_qr "link-shortener-service/internal/usecase/link_qr"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const etag = `"5d41402abc4b2a76b9719d911017c592"`
+
+func out(t *testing.T, format string) *usecase_link_qr.Out {
+	code, err := qrcode.Encode([]byte("http://localhost:8080/xHsvC_0NTU"), qrcode.LevelM)
+	require.NoError(t, err)
+	return &usecase_link_qr.Out{
+		ShortURL: "http://localhost:8080/xHsvC_0NTU",
+		Code:     code,
+		Format:   format,
+		Style: qrcode.Style{
+			Size:       256,
+			Margin:     4,
+			Foreground: color.NRGBA{A: 0xff},
+			Background: color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+		},
+		ETag: etag,
+	}
+}
+
+func TestQR(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	defaults := usecase_link_qr.In{
+		ShortedURL: "xHsvC_0NTU",
+		Format:     "png",
+		Size:       256,
+		Margin:     4,
+		Level:      "M",
+		Foreground: "000000",
+		Background: "ffffff",
+	}
+
+	tests := []struct {
+		name                string
+		query               string
+		ifNoneMatch         string
+		setupMock           func(*link_qr.Mockusecase)
+		expectedCode        int
+		expectedContentType string
+		expectedError       string
+	}{
+		{
+			name: "png with defaults",
+			setupMock: func(mockUsecase *link_qr.Mockusecase) {
+				mockUsecase.EXPECT().Run(gomock.Any(), defaults).Return(out(t, "png"), nil)
+			},
+			expectedCode:        http.StatusOK,
+			expectedContentType: "image/png",
+		},
+		{
+			name:  "svg with options",
+			query: "?format=SVG&size=512&margin=0&ec=h&fg=112233&bg=ffffff00",
+			setupMock: func(mockUsecase *link_qr.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecase_link_qr.In{
+						ShortedURL: "xHsvC_0NTU",
+						Format:     "svg",
+						Size:       512,
+						Margin:     0,
+						Level:      "h",
+						Foreground: "112233",
+						Background: "ffffff00",
+					}).
+					Return(out(t, "svg"), nil)
+			},
+			expectedCode:        http.StatusOK,
+			expectedContentType: "image/svg+xml",
+		},
+		{
+			name:        "not modified",
+			ifNoneMatch: `"other", ` + etag,
+			setupMock: func(mockUsecase *link_qr.Mockusecase) {
+				mockUsecase.EXPECT().Run(gomock.Any(), defaults).Return(out(t, "png"), nil)
+			},
+			expectedCode: http.StatusNotModified,
+		},
+		{
+			name:        "weak validator matches",
+			ifNoneMatch: "W/" + etag,
+			setupMock: func(mockUsecase *link_qr.Mockusecase) {
+				mockUsecase.EXPECT().Run(gomock.Any(), defaults).Return(out(t, "png"), nil)
+			},
+			expectedCode: http.StatusNotModified,
+		},
+		{
+			name:        "changed options",
+			ifNoneMatch: `"other"`,
+			setupMock: func(mockUsecase *link_qr.Mockusecase) {
+				mockUsecase.EXPECT().Run(gomock.Any(), defaults).Return(out(t, "png"), nil)
+			},
+			expectedCode:        http.StatusOK,
+			expectedContentType: "image/png",
+		},
+		{
+			name:          "size is not a number",
+			query:         "?size=big",
+			setupMock:     func(mockUsecase *link_qr.Mockusecase) {},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "invalid query",
+		},
+		{
+			name:          "margin is not a number",
+			query:         "?margin=wide",
+			setupMock:     func(mockUsecase *link_qr.Mockusecase) {},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "invalid query",
+		},
+		{
+			name:  "usecase.Run error - invalid options",
+			query: "?size=10",
+			setupMock: func(mockUsecase *link_qr.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), gomock.Any()).
+					Return(nil, usecase_link_qr.ErrInvalidOptions)
+			},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "invalid query",
+		},
+		{
+			name: "usecase.Run error - URL not found",
+			setupMock: func(mockUsecase *link_qr.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), gomock.Any()).
+					Return(nil, usecase_link_qr.ErrURLNotFound)
+			},
+			expectedCode:  http.StatusNotFound,
+			expectedError: "short URL does not exist",
+		},
+		{
+			name: "usecase.Run error - link of another owner",
+			setupMock: func(mockUsecase *link_qr.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), gomock.Any()).
+					Return(nil, usecase_link_qr.ErrForbidden)
+			},
+			expectedCode:  http.StatusForbidden,
+			expectedError: "short URL belongs to another owner",
+		},
+		{
+			name: "usecase.Run error - error from storage",
+			setupMock: func(mockUsecase *link_qr.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), gomock.Any()).
+					Return(nil, usecase_link_qr.ErrURLRetrieval)
+			},
+			expectedCode:  http.StatusInternalServerError,
+			expectedError: "failed to get short URL",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := link_qr.NewMockusecase(ctrl)
+			handler := New(mockUsecase, false)
+
+			tt.setupMock(mockUsecase)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/api/links/xHsvC_0NTU/qr"+tt.query, nil)
+			req = mux.SetURLVars(req, map[string]string{"code": "xHsvC_0NTU"})
+			if tt.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+
+			handler.QR(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+
+			if tt.expectedError != "" {
+				var errorResponse map[string]interface{}
+				err := json.NewDecoder(w.Body).Decode(&errorResponse)
+				require.NoError(t, err)
+				assert.Contains(t, errorResponse["error"], tt.expectedError)
+				return
+			}
+			assert.Equal(t, etag, w.Header().Get("ETag"))
+			assert.Equal(t, "private, max-age=86400", w.Header().Get("Cache-Control"))
+			if tt.expectedCode == http.StatusNotModified {
+				assert.Empty(t, w.Body.Bytes())
+				return
+			}
+			assert.Equal(t, tt.expectedContentType, w.Header().Get("Content-Type"))
+			switch tt.expectedContentType {
+			case "image/png":
+				img, err := png.Decode(w.Body)
+				require.NoError(t, err)
+				assert.Equal(t, 256, img.Bounds().Dx())
+			case "image/svg+xml":
+				assert.True(t, strings.HasPrefix(w.Body.String(), "<svg "))
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package link_stats
+
+import (
+	"context"
+	"time"
+
+	"link-shortener-service/internal/model"
+	"link-shortener-service/internal/usecase/link_stats"
+)
+
+//go:generate mockgen -source=contract.go -destination=mocks/contract_mock.go -package=link_stats usecase
+type usecase interface {
+	Run(ctx context.Context, req link_stats.In) (*model.ClickStats, error)
+}
+
+type Stats struct {
+	Code           string    `json:"code"`
+	From           time.Time `json:"from"`
+	To             time.Time `json:"to"`
+	Timezone       string    `json:"timezone"`
+	Granularity    string    `json:"granularity"`
+	TotalClicks    int64     `json:"total_clicks"`
+	UniqueVisitors int64     `json:"unique_visitors"`
+	Buckets        []Bucket  `json:"buckets"`
+	TopReferrers   []Counter `json:"top_referrers"`
+	TopCountries   []Counter `json:"top_countries"`
+	TopUserAgents  []Counter `json:"top_user_agents"`
+	// Variants are the clicks of every split target of the link
+	Variants []Counter `json:"variants"`
+}
+
+type Bucket struct {
+	Start  time.Time `json:"start"`
+	Clicks int64     `json:"clicks"`
+}
+
+type Counter struct {
+	Value  string `json:"value"`
+	Clicks int64  `json:"clicks"`
+}
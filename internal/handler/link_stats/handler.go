@@ -0,0 +1,141 @@
+package link_stats
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+	// timezones are resolved even on hosts without the tz database
+	_ "time/tzdata"
+
+	"link-shortener-service/internal/handler"
+	"link-shortener-service/internal/middleware"
+	"link-shortener-service/internal/model"
+	usecase_link_stats "link-shortener-service/internal/usecase/link_stats"
+)
+
+type statsHandler struct {
+	usecase         usecase
+	caseInsensitive bool
+}
+
+func New(usecase usecase, caseInsensitive bool) *statsHandler {
+	return &statsHandler{
+		usecase:         usecase,
+		caseInsensitive: caseInsensitive,
+	}
+}
+
+func (h *statsHandler) Stats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	in, err := parseQuery(r)
+	if err != nil {
+		handler.RespondWithError(w, http.StatusBadRequest, "invalid query", err)
+		return
+	}
+	in.ShortedURL = handler.LinkKey(r, h.caseInsensitive)
+
+	result, err := h.usecase.Run(r.Context(), in)
+	if err != nil {
+		handleUseCaseError(w, err)
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(toStats(in, result)); err != nil {
+		handler.RespondWithError(w, http.StatusInternalServerError, "failed to encode response", err)
+		return
+	}
+}
+
+func parseQuery(r *http.Request) (usecase_link_stats.In, error) {
+	query := r.URL.Query()
+	principal, _ := middleware.PrincipalFromContext(r.Context())
+	in := usecase_link_stats.In{
+		Principal:   principal,
+		Location:    time.UTC,
+		Granularity: query.Get("granularity"),
+	}
+
+	var err error
+	if tz := query.Get("tz"); tz != "" {
+		if in.Location, err = time.LoadLocation(tz); err != nil {
+			return in, fmt.Errorf("tz: %w", err)
+		}
+	}
+	if from := query.Get("from"); from != "" {
+		if in.From, err = time.Parse(time.RFC3339, from); err != nil {
+			return in, fmt.Errorf("from: %w", err)
+		}
+	}
+	if to := query.Get("to"); to != "" {
+		if in.To, err = time.Parse(time.RFC3339, to); err != nil {
+			return in, fmt.Errorf("to: %w", err)
+		}
+	}
+	if top := query.Get("top"); top != "" {
+		if in.Top, err = strconv.Atoi(top); err != nil {
+			return in, fmt.Errorf("top: %w", err)
+		}
+	}
+	return in, nil
+}
+
+func toStats(in usecase_link_stats.In, result *model.ClickStats) Stats {
+	stats := Stats{
+		Code:           in.ShortedURL,
+		From:           result.From.In(in.Location),
+		To:             result.To.In(in.Location),
+		Timezone:       in.Location.String(),
+		Granularity:    in.Granularity,
+		TotalClicks:    result.Total,
+		UniqueVisitors: result.UniqueVisitors,
+		Buckets:        make([]Bucket, 0, len(result.Buckets)),
+		TopReferrers:   toCounters(result.Referrers),
+		TopCountries:   toCounters(result.Countries),
+		TopUserAgents:  toCounters(result.UserAgents),
+		Variants:       toCounters(result.Variants),
+	}
+	if stats.Granularity == "" {
+		stats.Granularity = usecase_link_stats.GranularityDay
+	}
+	for _, bucket := range result.Buckets {
+		stats.Buckets = append(stats.Buckets, Bucket{Start: bucket.Start.In(in.Location), Clicks: bucket.Clicks})
+	}
+	return stats
+}
+
+func toCounters(counters []model.Counter) []Counter {
+	result := make([]Counter, 0, len(counters))
+	for _, c := range counters {
+		result = append(result, Counter{Value: c.Value, Clicks: c.Clicks})
+	}
+	return result
+}
+
+func handleUseCaseError(w http.ResponseWriter, err error) {
+	statusCode := http.StatusInternalServerError
+	errorMsg := "internal server error"
+
+	switch {
+	case errors.Is(err, usecase_link_stats.ErrURLNotFound):
+		statusCode = http.StatusNotFound
+		errorMsg = "short URL does not exist"
+	case errors.Is(err, usecase_link_stats.ErrForbidden):
+		statusCode = http.StatusForbidden
+		errorMsg = "short URL belongs to another owner"
+	case errors.Is(err, usecase_link_stats.ErrInvalidRange),
+		errors.Is(err, usecase_link_stats.ErrInvalidGranularity),
+		errors.Is(err, usecase_link_stats.ErrInvalidTop):
+		statusCode = http.StatusBadRequest
+		errorMsg = "invalid query"
+	case errors.Is(err, usecase_link_stats.ErrURLRetrieval):
+		errorMsg = "failed to get short URL"
+	case errors.Is(err, usecase_link_stats.ErrStatsRetrieval):
+		errorMsg = "failed to get stats"
+	}
+
+	handler.RespondWithError(w, statusCode, errorMsg, err)
+}
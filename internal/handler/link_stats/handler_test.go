@@ -0,0 +1,165 @@
+package link_stats
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	link_stats "link-shortener-service/internal/handler/link_stats/mocks"
+	"link-shortener-service/internal/model"
+	usecase_link_stats "link-shortener-service/internal/usecase/link_stats"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	require.NoError(t, err)
+	from := time.Date(2025, 5, 7, 0, 0, 0, 0, moscow)
+	to := time.Date(2025, 5, 9, 0, 0, 0, 0, moscow)
+
+	tests := []struct {
+		name          string
+		query         string
+		setupMock     func(*link_stats.Mockusecase)
+		expectedCode  int
+		expected      string
+		expectedError string
+	}{
+		{
+			name:  "successful stats",
+			query: "?from=2025-05-07T00:00:00%2B03:00&to=2025-05-09T00:00:00%2B03:00&tz=Europe/Moscow&top=3",
+			setupMock: func(mockUsecase *link_stats.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ any, in usecase_link_stats.In) (*model.ClickStats, error) {
+						assert.Equal(t, "xHsvC_0NTU", in.ShortedURL)
+						assert.True(t, from.Equal(in.From))
+						assert.True(t, to.Equal(in.To))
+						assert.Equal(t, moscow, in.Location)
+						assert.Equal(t, 3, in.Top)
+						return &model.ClickStats{
+							From:           from,
+							To:             to,
+							Total:          9,
+							UniqueVisitors: 4,
+							Buckets: []model.ClickBucket{
+								{Start: from, Clicks: 0},
+								{Start: from.AddDate(0, 0, 1), Clicks: 9},
+							},
+							Referrers:  []model.Counter{{Value: "ref.com", Clicks: 5}},
+							UserAgents: []model.Counter{{Value: "Chrome", Clicks: 9}},
+							Variants:   []model.Counter{{Value: "a", Clicks: 6}, {Value: "b", Clicks: 3}},
+						}, nil
+					})
+			},
+			expectedCode: http.StatusOK,
+			expected: `{
+				"code": "xHsvC_0NTU",
+				"from": "2025-05-07T00:00:00+03:00",
+				"to": "2025-05-09T00:00:00+03:00",
+				"timezone": "Europe/Moscow",
+				"granularity": "day",
+				"total_clicks": 9,
+				"unique_visitors": 4,
+				"buckets": [
+					{"start": "2025-05-07T00:00:00+03:00", "clicks": 0},
+					{"start": "2025-05-08T00:00:00+03:00", "clicks": 9}
+				],
+				"top_referrers": [{"value": "ref.com", "clicks": 5}],
+				"top_countries": [],
+				"top_user_agents": [{"value": "Chrome", "clicks": 9}],
+				"variants": [{"value": "a", "clicks": 6}, {"value": "b", "clicks": 3}]
+			}`,
+		},
+		{
+			name:          "invalid timezone",
+			query:         "?tz=Mars/Olympus",
+			setupMock:     func(*link_stats.Mockusecase) {},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "invalid query",
+		},
+		{
+			name:          "invalid date",
+			query:         "?from=yesterday",
+			setupMock:     func(*link_stats.Mockusecase) {},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "invalid query",
+		},
+		{
+			name:  "usecase.Run error - invalid range",
+			query: "?granularity=hour",
+			setupMock: func(mockUsecase *link_stats.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), gomock.Any()).
+					Return(nil, usecase_link_stats.ErrInvalidRange)
+			},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "invalid query",
+		},
+		{
+			name: "usecase.Run error - unknown short URL",
+			setupMock: func(mockUsecase *link_stats.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), gomock.Any()).
+					Return(nil, usecase_link_stats.ErrURLNotFound)
+			},
+			expectedCode:  http.StatusNotFound,
+			expectedError: "short URL does not exist",
+		},
+		{
+			name: "usecase.Run error - link of another owner",
+			setupMock: func(mockUsecase *link_stats.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), gomock.Any()).
+					Return(nil, usecase_link_stats.ErrForbidden)
+			},
+			expectedCode:  http.StatusForbidden,
+			expectedError: "short URL belongs to another owner",
+		},
+		{
+			name: "usecase.Run error - error from storage",
+			setupMock: func(mockUsecase *link_stats.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), gomock.Any()).
+					Return(nil, usecase_link_stats.ErrStatsRetrieval)
+			},
+			expectedCode:  http.StatusInternalServerError,
+			expectedError: "failed to get stats",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := link_stats.NewMockusecase(ctrl)
+			handler := New(mockUsecase, false)
+
+			tt.setupMock(mockUsecase)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/api/links/xHsvC_0NTU/stats"+tt.query, nil)
+			req = mux.SetURLVars(req, map[string]string{"code": "xHsvC_0NTU"})
+
+			handler.Stats(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+
+			if tt.expectedError != "" {
+				var errorResponse map[string]interface{}
+				err := json.NewDecoder(w.Body).Decode(&errorResponse)
+				require.NoError(t, err)
+				assert.Contains(t, errorResponse["error"], tt.expectedError)
+				return
+			}
+			assert.JSONEq(t, tt.expected, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package redirect_url
+
+import (
+	"context"
+
+	"link-shortener-service/internal/model"
+	"link-shortener-service/internal/usecase/destination_preview"
+	"link-shortener-service/internal/usecase/expander_url"
+)
+
+//go:generate mockgen -source=contract.go -destination=mocks/contract_mock.go -package=redirect_url usecase,previewer
+type usecase interface {
+	Run(ctx context.Context, req expander_url.In) (*model.URLPair, error)
+}
+
+type previewer interface {
+	Run(ctx context.Context, req destination_preview.In) (*model.LinkPreview, error)
+}
+
+type errorPage struct {
+	Status  int
+	Title   string
+	Message string
+}
+
+type passwordPage struct {
+	Code  string
+	Error string
+}
+
+type interstitialPage struct {
+	Host        string
+	Destination string
+	// Title and Description are empty until the metadata of the destination is fetched
+	Title       string
+	Description string
+	ContinueURL string
+	// Countdown is the number of seconds before continuing on its own, 0 waits for the visitor
+	Countdown int
+}
@@ -0,0 +1,174 @@
+package redirect_url
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"link-shortener-service/internal/handler"
+	"link-shortener-service/internal/middleware"
+	"link-shortener-service/internal/model"
+	usecase_destination_preview "link-shortener-service/internal/usecase/destination_preview"
+	usecase_expander_url "link-shortener-service/internal/usecase/expander_url"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// variantCookieName is scoped to the path of the link, so every split link keeps its own variant
+	variantCookieName   = "variant"
+	variantCookieMaxAge = 30 * 24 * time.Hour
+)
+
+type redirectHandler struct {
+	usecase       usecase
+	previews      previewer
+	defaultStatus int
+	// countdown is how long the interstitial page of links with an interstitial waits before continuing
+	countdown time.Duration
+	proxies   middleware.TrustedProxies
+}
+
+func New(usecase usecase, previews previewer, defaultStatus int, countdown time.Duration,
+	proxies middleware.TrustedProxies,
+) *redirectHandler {
+	return &redirectHandler{
+		usecase:       usecase,
+		previews:      previews,
+		defaultStatus: defaultStatus,
+		countdown:     countdown,
+		proxies:       proxies,
+	}
+}
+
+func (h *redirectHandler) Redirect(w http.ResponseWriter, r *http.Request) {
+	// codes never contain "+", a "+" after the code or ?preview=1 shows where the link leads instead of redirecting
+	code, preview := strings.CutSuffix(mux.Vars(r)["code"], "+")
+	query := r.URL.Query()
+	preview = preview || query.Get("preview") == "1"
+
+	in := usecase_expander_url.In{
+		ShortedURL: code,
+		Host:       r.Host,
+		// link checkers use HEAD, they must not use up single-use links
+		Peek: r.Method == http.MethodHead || preview,
+		// the password form stands in for the interstitial page
+		Confirmed: r.Method == http.MethodPost || query.Get("continue") == "1",
+		Visitor:   handler.VisitorFromRequest(r, h.proxies.ClientIP(r), h.proxies.Proxied(r)),
+	}
+	if r.Method == http.MethodPost {
+		in.Password = r.PostFormValue("password")
+	}
+	if cookie, err := r.Cookie(variantCookieName); err == nil {
+		in.Variant = cookie.Value
+	}
+
+	result, err := h.usecase.Run(r.Context(), in)
+	if err != nil {
+		formCode := code
+		if preview {
+			formCode += "+"
+		}
+		handleUseCaseError(w, formCode, err)
+		return
+	}
+	// returning visitors of split links are sent to the same variant, continuing from a preview as well
+	if result.Variant != "" && result.Variant != in.Variant {
+		http.SetCookie(w, &http.Cookie{
+			Name:     variantCookieName,
+			Value:    result.Variant,
+			Path:     "/" + code,
+			MaxAge:   int(variantCookieMaxAge.Seconds()),
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+	}
+
+	switch {
+	case preview:
+		h.renderInterstitial(w, r, code, result, 0)
+		return
+	case result.Interstitial && !in.Confirmed && r.Method == http.MethodHead:
+		// HEAD gets the status of the page without its body, so the preview is not fetched for it
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusOK)
+		return
+	case result.Interstitial && !in.Confirmed:
+		h.renderInterstitial(w, r, code, result, h.countdown)
+		return
+	}
+
+	status := result.RedirectStatus
+	if status == 0 {
+		status = h.defaultStatus
+	}
+	// 307 and 308 would make the browser repeat the password form POST to the destination
+	if r.Method == http.MethodPost {
+		status = http.StatusSeeOther
+	}
+	http.Redirect(w, r, result.Original, status)
+}
+
+func (h *redirectHandler) renderInterstitial(w http.ResponseWriter, r *http.Request, code string, pair *model.URLPair,
+	countdown time.Duration,
+) {
+	page := interstitialPage{
+		Host:        pair.Original,
+		Destination: pair.Original,
+		ContinueURL: "/" + code + "?continue=1",
+		Countdown:   int(countdown.Seconds()),
+	}
+	if parsed, err := url.Parse(pair.Original); err == nil && parsed.Host != "" {
+		page.Host = parsed.Hostname()
+	}
+
+	preview, err := h.previews.Run(r.Context(), usecase_destination_preview.In{
+		ShortedURL:  pair.Shorted,
+		Destination: pair.Original,
+	})
+	if err != nil {
+		// the page does without the title
+		log.Printf("failed to get the preview of %s: %v", code, err)
+	} else if preview != nil {
+		page.Title, page.Description = preview.Title, preview.Description
+	}
+
+	// the page spends no click, it must not be served from a cache instead of the redirect
+	w.Header().Set("Cache-Control", "no-store")
+	renderPage(w, http.StatusOK, "interstitial.html", page)
+}
+
+func IsRedirectStatus(status int) bool {
+	switch status {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+func handleUseCaseError(w http.ResponseWriter, code string, err error) {
+	switch {
+	case errors.Is(err, usecase_expander_url.ErrURLNotFound):
+		renderError(w, http.StatusNotFound, "This short link does not exist.")
+	case errors.Is(err, usecase_expander_url.ErrURLExpired):
+		renderError(w, http.StatusGone, "This short link has expired.")
+	case errors.Is(err, usecase_expander_url.ErrURLExhausted):
+		renderError(w, http.StatusGone, "This short link has already been used.")
+	case errors.Is(err, usecase_expander_url.ErrURLDisabled):
+		renderError(w, http.StatusGone, "This short link has been disabled.")
+	case errors.Is(err, usecase_expander_url.ErrURLBlocked):
+		renderError(w, http.StatusGone, "This short link leads to a page reported as unsafe.")
+	case errors.Is(err, usecase_expander_url.ErrPasswordRequired):
+		renderPage(w, http.StatusUnauthorized, "password.html", passwordPage{Code: code})
+	case errors.Is(err, usecase_expander_url.ErrWrongPassword):
+		renderPage(w, http.StatusForbidden, "password.html", passwordPage{Code: code, Error: "Wrong password, try again."})
+	case errors.Is(err, usecase_expander_url.ErrTooManyAttempts):
+		renderError(w, http.StatusTooManyRequests, "Too many wrong passwords, try again later.")
+	default:
+		renderError(w, http.StatusInternalServerError, "Something went wrong while resolving this link.")
+	}
+}
@@ -0,0 +1,431 @@
+package redirect_url
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	redirect_url "link-shortener-service/internal/handler/redirect_url/mocks"
+	"link-shortener-service/internal/middleware"
+	"link-shortener-service/internal/model"
+	usecase_destination_preview "link-shortener-service/internal/usecase/destination_preview"
+	usecase_expander_url "link-shortener-service/internal/usecase/expander_url"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedirect(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	code := "xHsvC_0NTU"
+	// httptest requests come from this address
+	visitor := model.Visitor{IP: "192.0.2.1"}
+	usecaseIn := usecase_expander_url.In{
+		ShortedURL: code,
+		Host:       "example.com",
+		Visitor:    visitor,
+	}
+
+	tests := []struct {
+		name             string
+		method           string
+		form             url.Values
+		setupMock        func(*redirect_url.Mockusecase)
+		expectedCode     int
+		expectedLocation string
+		expectedBody     string
+	}{
+		{
+			name:   "redirect with default status",
+			method: http.MethodGet,
+			setupMock: func(mockUsecase *redirect_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecaseIn).
+					Return(&model.URLPair{Original: "https://some.com/asdasd", Shorted: code}, nil)
+			},
+			expectedCode:     http.StatusFound,
+			expectedLocation: "https://some.com/asdasd",
+		},
+		{
+			name:   "redirect with per link status",
+			method: http.MethodGet,
+			setupMock: func(mockUsecase *redirect_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecaseIn).
+					Return(&model.URLPair{
+						Original:       "https://some.com/asdasd",
+						Shorted:        code,
+						RedirectStatus: http.StatusMovedPermanently,
+					}, nil)
+			},
+			expectedCode:     http.StatusMovedPermanently,
+			expectedLocation: "https://some.com/asdasd",
+		},
+		{
+			name:   "HEAD request does not spend clicks",
+			method: http.MethodHead,
+			setupMock: func(mockUsecase *redirect_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecase_expander_url.In{ShortedURL: code, Host: "example.com", Peek: true, Visitor: visitor}).
+					Return(&model.URLPair{Original: "https://some.com/asdasd", Shorted: code}, nil)
+			},
+			expectedCode:     http.StatusFound,
+			expectedLocation: "https://some.com/asdasd",
+		},
+		{
+			name:   "unknown code",
+			method: http.MethodGet,
+			setupMock: func(mockUsecase *redirect_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecaseIn).
+					Return(nil, usecase_expander_url.ErrURLNotFound)
+			},
+			expectedCode: http.StatusNotFound,
+			expectedBody: "This short link does not exist.",
+		},
+		{
+			name:   "expired code",
+			method: http.MethodGet,
+			setupMock: func(mockUsecase *redirect_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecaseIn).
+					Return(nil, usecase_expander_url.ErrURLExpired)
+			},
+			expectedCode: http.StatusGone,
+			expectedBody: "This short link has expired.",
+		},
+		{
+			name:   "no clicks left",
+			method: http.MethodGet,
+			setupMock: func(mockUsecase *redirect_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecaseIn).
+					Return(nil, usecase_expander_url.ErrURLExhausted)
+			},
+			expectedCode: http.StatusGone,
+			expectedBody: "This short link has already been used.",
+		},
+		{
+			name:   "disabled code",
+			method: http.MethodGet,
+			setupMock: func(mockUsecase *redirect_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecaseIn).
+					Return(nil, usecase_expander_url.ErrURLDisabled)
+			},
+			expectedCode: http.StatusGone,
+			expectedBody: "This short link has been disabled.",
+		},
+		{
+			name:   "blocklisted destination",
+			method: http.MethodGet,
+			setupMock: func(mockUsecase *redirect_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecaseIn).
+					Return(nil, usecase_expander_url.ErrURLBlocked)
+			},
+			expectedCode: http.StatusGone,
+			expectedBody: "This short link leads to a page reported as unsafe.",
+		},
+		{
+			name:   "password form",
+			method: http.MethodGet,
+			setupMock: func(mockUsecase *redirect_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecaseIn).
+					Return(nil, usecase_expander_url.ErrPasswordRequired)
+			},
+			expectedCode: http.StatusUnauthorized,
+			expectedBody: `action="/xHsvC_0NTU"`,
+		},
+		{
+			name:   "password submitted",
+			method: http.MethodPost,
+			form:   url.Values{"password": {"secret"}},
+			setupMock: func(mockUsecase *redirect_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecase_expander_url.In{ShortedURL: code, Host: "example.com", Confirmed: true, Password: "secret", Visitor: visitor}).
+					Return(&model.URLPair{
+						Original:       "https://some.com/asdasd",
+						Shorted:        code,
+						RedirectStatus: http.StatusPermanentRedirect,
+					}, nil)
+			},
+			expectedCode:     http.StatusSeeOther,
+			expectedLocation: "https://some.com/asdasd",
+		},
+		{
+			name:   "wrong password",
+			method: http.MethodPost,
+			form:   url.Values{"password": {"wrong"}},
+			setupMock: func(mockUsecase *redirect_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecase_expander_url.In{ShortedURL: code, Host: "example.com", Confirmed: true, Password: "wrong", Visitor: visitor}).
+					Return(nil, usecase_expander_url.ErrWrongPassword)
+			},
+			expectedCode: http.StatusForbidden,
+			expectedBody: "Wrong password, try again.",
+		},
+		{
+			name:   "too many attempts",
+			method: http.MethodPost,
+			form:   url.Values{"password": {"wrong"}},
+			setupMock: func(mockUsecase *redirect_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecase_expander_url.In{ShortedURL: code, Host: "example.com", Confirmed: true, Password: "wrong", Visitor: visitor}).
+					Return(nil, usecase_expander_url.ErrTooManyAttempts)
+			},
+			expectedCode: http.StatusTooManyRequests,
+			expectedBody: "Too many wrong passwords",
+		},
+		{
+			name:   "error from usecase",
+			method: http.MethodGet,
+			setupMock: func(mockUsecase *redirect_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecaseIn).
+					Return(nil, usecase_expander_url.ErrURLRetrieval)
+			},
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: "Something went wrong",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := redirect_url.NewMockusecase(ctrl)
+			handler := New(mockUsecase, nil, http.StatusFound, 5*time.Second, nil)
+
+			tt.setupMock(mockUsecase)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, "/"+code, strings.NewReader(tt.form.Encode()))
+			if tt.form != nil {
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			}
+			req = mux.SetURLVars(req, map[string]string{"code": code})
+
+			handler.Redirect(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			assert.Equal(t, tt.expectedLocation, w.Header().Get("Location"))
+			if tt.expectedBody != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedBody)
+			}
+		})
+	}
+}
+
+func TestVariantCookie(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	code := "xHsvC_0NTU"
+	visitor := model.Visitor{IP: "192.0.2.1"}
+	mockUsecase := redirect_url.NewMockusecase(ctrl)
+	handler := New(mockUsecase, nil, http.StatusFound, 5*time.Second, nil)
+
+	// a new visitor is assigned a variant
+	mockUsecase.EXPECT().
+		Run(gomock.Any(), usecase_expander_url.In{ShortedURL: code, Host: "example.com", Visitor: visitor}).
+		Return(&model.URLPair{Original: "https://some.com/b", Shorted: code, Variant: "b"}, nil)
+
+	w := httptest.NewRecorder()
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/"+code, nil), map[string]string{"code": code})
+	handler.Redirect(w, req)
+
+	assert.Equal(t, "https://some.com/b", w.Header().Get("Location"))
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "variant", cookies[0].Name)
+	assert.Equal(t, "b", cookies[0].Value)
+	assert.Equal(t, "/"+code, cookies[0].Path)
+
+	// a returning visitor keeps the variant and the cookie is left as it is
+	mockUsecase.EXPECT().
+		Run(gomock.Any(), usecase_expander_url.In{ShortedURL: code, Host: "example.com", Visitor: visitor, Variant: "b"}).
+		Return(&model.URLPair{Original: "https://some.com/b", Shorted: code, Variant: "b"}, nil)
+
+	w = httptest.NewRecorder()
+	req = mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/"+code, nil), map[string]string{"code": code})
+	req.AddCookie(cookies[0])
+	handler.Redirect(w, req)
+
+	assert.Equal(t, "https://some.com/b", w.Header().Get("Location"))
+	assert.Empty(t, w.Result().Cookies())
+}
+
+func TestVisitorBehindProxy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	code := "xHsvC_0NTU"
+	proxies, err := middleware.ParseTrustedProxies([]string{"192.0.2.0/24"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		expected   model.Visitor
+	}{
+		{
+			name:       "trusted proxy tells the client address and country",
+			remoteAddr: "192.0.2.1:1234",
+			expected:   model.Visitor{IP: "203.0.113.7", Country: "DE"},
+		},
+		{
+			name:       "other clients are taken at their address and located by it",
+			remoteAddr: "198.51.100.1:1234",
+			expected:   model.Visitor{IP: "198.51.100.1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := redirect_url.NewMockusecase(ctrl)
+			mockUsecase.EXPECT().
+				Run(gomock.Any(), usecase_expander_url.In{ShortedURL: code, Host: "example.com", Visitor: tt.expected}).
+				Return(&model.URLPair{Original: "https://some.com/asdasd", Shorted: code}, nil)
+			handler := New(mockUsecase, nil, http.StatusFound, 5*time.Second, proxies)
+
+			w := httptest.NewRecorder()
+			req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/"+code, nil), map[string]string{"code": code})
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("X-Forwarded-For", "203.0.113.7")
+			req.Header.Set("CF-IPCountry", "de")
+			handler.Redirect(w, req)
+
+			assert.Equal(t, http.StatusFound, w.Code)
+		})
+	}
+}
+
+func TestInterstitial(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	code := "xHsvC_0NTU"
+	visitor := model.Visitor{IP: "192.0.2.1"}
+	pair := &model.URLPair{Original: "https://some.com/asdasd", Shorted: code}
+	withInterstitial := &model.URLPair{Original: "https://some.com/asdasd", Shorted: code, Interstitial: true}
+
+	tests := []struct {
+		name             string
+		method           string
+		target           string
+		setupMock        func(*redirect_url.Mockusecase, *redirect_url.Mockpreviewer)
+		expectedCode     int
+		expectedLocation string
+		expectedBody     []string
+	}{
+		{
+			name:   "preview with plus",
+			method: http.MethodGet,
+			target: "/" + code + "+",
+			setupMock: func(mockUsecase *redirect_url.Mockusecase, mockPreviewer *redirect_url.Mockpreviewer) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecase_expander_url.In{ShortedURL: code, Host: "example.com", Peek: true, Visitor: visitor}).
+					Return(pair, nil)
+				mockPreviewer.EXPECT().
+					Run(gomock.Any(), usecase_destination_preview.In{ShortedURL: code, Destination: "https://some.com/asdasd"}).
+					Return(&model.LinkPreview{LinkMetadata: model.LinkMetadata{Title: "Some page"}}, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: []string{"This link leads to some.com", "Some page", "https://some.com/asdasd", `href="/xHsvC_0NTU?continue=1"`},
+		},
+		{
+			name:   "preview with query",
+			method: http.MethodGet,
+			target: "/" + code + "?preview=1",
+			setupMock: func(mockUsecase *redirect_url.Mockusecase, mockPreviewer *redirect_url.Mockpreviewer) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecase_expander_url.In{ShortedURL: code, Host: "example.com", Peek: true, Visitor: visitor}).
+					Return(pair, nil)
+				mockPreviewer.EXPECT().Run(gomock.Any(), gomock.Any()).Return(nil, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: []string{"This link leads to some.com"},
+		},
+		{
+			name:   "preview of a protected link",
+			method: http.MethodGet,
+			target: "/" + code + "+",
+			setupMock: func(mockUsecase *redirect_url.Mockusecase, _ *redirect_url.Mockpreviewer) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecase_expander_url.In{ShortedURL: code, Host: "example.com", Peek: true, Visitor: visitor}).
+					Return(nil, usecase_expander_url.ErrPasswordRequired)
+			},
+			expectedCode: http.StatusUnauthorized,
+			expectedBody: []string{`action="/xHsvC_0NTU&#43;"`},
+		},
+		{
+			name:   "mandatory interstitial",
+			method: http.MethodGet,
+			target: "/" + code,
+			setupMock: func(mockUsecase *redirect_url.Mockusecase, mockPreviewer *redirect_url.Mockpreviewer) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecase_expander_url.In{ShortedURL: code, Host: "example.com", Visitor: visitor}).
+					Return(withInterstitial, nil)
+				mockPreviewer.EXPECT().Run(gomock.Any(), gomock.Any()).Return(nil, errors.New("db is down"))
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: []string{`content="5;url=/xHsvC_0NTU?continue=1"`, `<span id="countdown">5</span>`},
+		},
+		{
+			name:   "continue from the interstitial",
+			method: http.MethodGet,
+			target: "/" + code + "?continue=1",
+			setupMock: func(mockUsecase *redirect_url.Mockusecase, _ *redirect_url.Mockpreviewer) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecase_expander_url.In{ShortedURL: code, Host: "example.com", Confirmed: true, Visitor: visitor}).
+					Return(withInterstitial, nil)
+			},
+			expectedCode:     http.StatusFound,
+			expectedLocation: "https://some.com/asdasd",
+		},
+		{
+			name:   "HEAD request gets the interstitial status",
+			method: http.MethodHead,
+			target: "/" + code,
+			setupMock: func(mockUsecase *redirect_url.Mockusecase, _ *redirect_url.Mockpreviewer) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecase_expander_url.In{ShortedURL: code, Host: "example.com", Peek: true, Visitor: visitor}).
+					Return(withInterstitial, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := redirect_url.NewMockusecase(ctrl)
+			mockPreviewer := redirect_url.NewMockpreviewer(ctrl)
+			handler := New(mockUsecase, mockPreviewer, http.StatusFound, 5*time.Second, nil)
+
+			tt.setupMock(mockUsecase, mockPreviewer)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			req = mux.SetURLVars(req, map[string]string{"code": strings.TrimPrefix(req.URL.Path, "/")})
+
+			handler.Redirect(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			assert.Equal(t, tt.expectedLocation, w.Header().Get("Location"))
+			for _, expected := range tt.expectedBody {
+				assert.Contains(t, w.Body.String(), expected)
+			}
+			if tt.method == http.MethodHead {
+				assert.Empty(t, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package redirect_url
+
+import (
+	"embed"
+	"html/template"
+	"log"
+	"net/http"
+)
+
+//go:embed templates/*.html
+var templatesFS embed.FS
+
+var pages = template.Must(template.ParseFS(templatesFS, "templates/*.html"))
+
+func renderPage(w http.ResponseWriter, status int, name string, data any) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	if err := pages.ExecuteTemplate(w, name, data); err != nil {
+		log.Printf("failed to render %s: %v", name, err)
+	}
+}
+
+func renderError(w http.ResponseWriter, status int, message string) {
+	renderPage(w, status, "error.html", errorPage{
+		Status:  status,
+		Title:   http.StatusText(status),
+		Message: message,
+	})
+}
@@ -0,0 +1,24 @@
+package resolve_urls
+
+import (
+	"context"
+
+	"link-shortener-service/internal/usecase/resolve_urls"
+)
+
+//go:generate mockgen -source=contract.go -destination=mocks/contract_mock.go -package=resolve_urls usecase
+type usecase interface {
+	Run(ctx context.Context, req resolve_urls.In) ([]resolve_urls.Result, error)
+}
+
+type ResolveShortURLs struct {
+	ShortURLs []string `json:"short_urls" validate:"required,min=1,dive,required"`
+}
+
+type ResolveResult struct {
+	ShortURL    string `json:"short_url"`
+	Code        string `json:"code"`
+	Found       bool   `json:"found"`
+	Status      string `json:"status"`
+	OriginalURL string `json:"original_url,omitempty"`
+}
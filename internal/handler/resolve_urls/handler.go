@@ -0,0 +1,169 @@
+package resolve_urls
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"link-shortener-service/internal/handler"
+	usecase_resolve_urls "link-shortener-service/internal/usecase/resolve_urls"
+
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	statusActive            = "active"
+	statusNotFound          = "not_found"
+	statusDisabled          = "disabled"
+	statusExpired           = "expired"
+	statusExhausted         = "exhausted"
+	statusPasswordProtected = "password_protected"
+	statusBlocked           = "blocked"
+)
+
+// maxResolveItemBytes is the body size allowed per short URL, far more than any short URL needs
+const maxResolveItemBytes = 2 << 10
+
+var errTooManyItems = errors.New("too many items")
+
+type urlHandler struct {
+	usecase   usecase
+	validator *validator.Validate
+	maxSize   int
+}
+
+func New(usecase usecase, validator *validator.Validate, maxSize int) *urlHandler {
+	return &urlHandler{
+		usecase:   usecase,
+		validator: validator,
+		maxSize:   maxSize,
+	}
+}
+
+// ResolveURLs looks up many short URLs or codes at once without counting clicks,
+// the results are in the order of the request
+func (h *urlHandler) ResolveURLs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	r.Body = http.MaxBytesReader(w, r.Body, int64(h.maxSize)*maxResolveItemBytes)
+	req, err := h.readRequest(r.Body)
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.Is(err, errTooManyItems):
+		handler.RespondWithError(w, http.StatusRequestEntityTooLarge, "too many items", err)
+		return
+	case errors.As(err, &tooLarge):
+		handler.RespondWithError(w, http.StatusRequestEntityTooLarge, "request body is too large", err)
+		return
+	case err != nil:
+		handler.RespondWithError(w, http.StatusBadRequest, "failed to decode request", err)
+		return
+	}
+	if err = h.validator.Struct(req); err != nil {
+		handler.RespondWithError(w, http.StatusBadRequest, "validation failed", err)
+		return
+	}
+
+	results, err := h.usecase.Run(r.Context(), usecase_resolve_urls.In{ShortedURLs: req.ShortURLs, Host: r.Host})
+	if err != nil {
+		handler.RespondWithError(w, http.StatusInternalServerError, "failed to resolve short URLs", err)
+		return
+	}
+
+	response := make([]ResolveResult, len(results))
+	for i, result := range results {
+		response[i] = ResolveResult{
+			ShortURL: result.ShortedURL,
+			Code:     result.Code,
+			Found:    !errors.Is(result.Err, usecase_resolve_urls.ErrURLNotFound),
+			Status:   status(result.Err),
+		}
+		if result.URLPair != nil {
+			response[i].OriginalURL = result.URLPair.Original
+		}
+	}
+
+	if err = json.NewEncoder(w).Encode(map[string][]ResolveResult{
+		"results": response,
+	}); err != nil {
+		handler.RespondWithError(w, http.StatusInternalServerError, "failed to encode response", err)
+		return
+	}
+}
+
+// readRequest decodes the request counting the short URLs while reading, a request over the limit
+// is refused before the rest is read
+func (h *urlHandler) readRequest(body io.Reader) (ResolveShortURLs, error) {
+	var req ResolveShortURLs
+	decoder := json.NewDecoder(body)
+	if err := expectDelim(decoder, '{'); err != nil {
+		return req, err
+	}
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return req, err
+		}
+		if key, _ := token.(string); !strings.EqualFold(key, "short_urls") {
+			// the other fields are not used, they are read to get to the next one
+			var skipped json.RawMessage
+			if err = decoder.Decode(&skipped); err != nil {
+				return req, err
+			}
+			continue
+		}
+
+		if err = expectDelim(decoder, '['); err != nil {
+			return req, err
+		}
+		req.ShortURLs = []string{}
+		for decoder.More() {
+			if len(req.ShortURLs) == h.maxSize {
+				return req, fmt.Errorf("%w: more than %d short URLs", errTooManyItems, h.maxSize)
+			}
+			var shortURL string
+			if err = decoder.Decode(&shortURL); err != nil {
+				return req, err
+			}
+			req.ShortURLs = append(req.ShortURLs, shortURL)
+		}
+		if err = expectDelim(decoder, ']'); err != nil {
+			return req, err
+		}
+	}
+	return req, expectDelim(decoder, '}')
+}
+
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("expected %v, got %v", delim, token)
+	}
+	return nil
+}
+
+func status(err error) string {
+	switch {
+	case err == nil:
+		return statusActive
+	case errors.Is(err, usecase_resolve_urls.ErrURLDisabled):
+		return statusDisabled
+	case errors.Is(err, usecase_resolve_urls.ErrURLExpired):
+		return statusExpired
+	case errors.Is(err, usecase_resolve_urls.ErrURLExhausted):
+		return statusExhausted
+	case errors.Is(err, usecase_resolve_urls.ErrPasswordRequired):
+		return statusPasswordProtected
+	case errors.Is(err, usecase_resolve_urls.ErrURLBlocked):
+		return statusBlocked
+	default:
+		return statusNotFound
+	}
+}
@@ -0,0 +1,149 @@
+package resolve_urls
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	resolve_urls "link-shortener-service/internal/handler/resolve_urls/mocks"
+	"link-shortener-service/internal/model"
+	usecase_resolve_urls "link-shortener-service/internal/usecase/resolve_urls"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveURLs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	valid := validator.New(validator.WithRequiredStructEnabled())
+
+	usecaseIn := usecase_resolve_urls.In{ShortedURLs: []string{"https://some.com/active", "unknown", "locked"}, Host: "example.com"}
+	usecaseOut := []usecase_resolve_urls.Result{
+		{
+			ShortedURL: "https://some.com/active",
+			Code:       "active",
+			URLPair:    &model.URLPair{Original: "https://some.com/1", Shorted: "active"},
+		},
+		{ShortedURL: "unknown", Code: "unknown", Err: usecase_resolve_urls.ErrURLNotFound},
+		{ShortedURL: "locked", Code: "locked", Err: usecase_resolve_urls.ErrPasswordRequired},
+	}
+
+	tests := []struct {
+		name          string
+		body          string
+		setupMock     func(*resolve_urls.Mockusecase)
+		expectedCode  int
+		expected      []ResolveResult
+		expectedError string
+	}{
+		{
+			name: "successful resolve",
+			body: `{"short_urls": ["https://some.com/active", "unknown", "locked"]}`,
+			setupMock: func(mockUsecase *resolve_urls.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecaseIn).
+					Return(usecaseOut, nil)
+			},
+			expectedCode: http.StatusOK,
+			expected: []ResolveResult{
+				{ShortURL: "https://some.com/active", Code: "active", Found: true, Status: "active", OriginalURL: "https://some.com/1"},
+				{ShortURL: "unknown", Code: "unknown", Status: "not_found"},
+				{ShortURL: "locked", Code: "locked", Found: true, Status: "password_protected"},
+			},
+		},
+		{
+			name:          "invalid JSON",
+			body:          `{"short_urls": `,
+			setupMock:     func(mockUsecase *resolve_urls.Mockusecase) {},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "failed to decode request",
+		},
+		{
+			name:          "empty list",
+			body:          `{"short_urls": []}`,
+			setupMock:     func(mockUsecase *resolve_urls.Mockusecase) {},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "validation failed",
+		},
+		{
+			name:          "too many short URLs",
+			body:          `{"short_urls": ["a", "b", "c", "d"]}`,
+			setupMock:     func(mockUsecase *resolve_urls.Mockusecase) {},
+			expectedCode:  http.StatusRequestEntityTooLarge,
+			expectedError: "too many items",
+		},
+		{
+			name: "other fields are skipped",
+			body: `{"options": {"peek": [1, 2]}, "short_urls": ["https://some.com/active", "unknown", "locked"]}`,
+			setupMock: func(mockUsecase *resolve_urls.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecaseIn).
+					Return(usecaseOut, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:          "not an object",
+			body:          `["a"]`,
+			setupMock:     func(mockUsecase *resolve_urls.Mockusecase) {},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "failed to decode request",
+		},
+		{
+			name:          "body too large",
+			body:          `{"short_urls": ["` + strings.Repeat("a", 3*maxResolveItemBytes) + `"]}`,
+			setupMock:     func(mockUsecase *resolve_urls.Mockusecase) {},
+			expectedCode:  http.StatusRequestEntityTooLarge,
+			expectedError: "request body is too large",
+		},
+		{
+			name: "error from storage",
+			body: `{"short_urls": ["https://some.com/active", "unknown", "locked"]}`,
+			setupMock: func(mockUsecase *resolve_urls.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecaseIn).
+					Return(nil, errors.New("db is down"))
+			},
+			expectedCode:  http.StatusInternalServerError,
+			expectedError: "failed to resolve short URLs",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := resolve_urls.NewMockusecase(ctrl)
+			handler := New(mockUsecase, valid, 3)
+
+			tt.setupMock(mockUsecase)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/api/links/resolve", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			handler.ResolveURLs(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+
+			if tt.expected != nil {
+				var response map[string][]ResolveResult
+				err := json.NewDecoder(w.Body).Decode(&response)
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, response["results"])
+			}
+
+			if tt.expectedError != "" {
+				var errorResponse map[string]interface{}
+				err := json.NewDecoder(w.Body).Decode(&errorResponse)
+				require.NoError(t, err)
+				assert.Contains(t, errorResponse["error"], tt.expectedError)
+			}
+		})
+	}
+}
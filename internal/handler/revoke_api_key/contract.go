@@ -0,0 +1,12 @@
+package revoke_api_key
+
+import (
+	"context"
+
+	"link-shortener-service/internal/usecase/revoke_api_key"
+)
+
+//go:generate mockgen -source=contract.go -destination=mocks/contract_mock.go -package=revoke_api_key usecase
+type usecase interface {
+	Run(ctx context.Context, req revoke_api_key.In) error
+}
@@ -0,0 +1,50 @@
+package revoke_api_key
+
+import (
+	"errors"
+	"net/http"
+
+	"link-shortener-service/internal/handler"
+	usecase_revoke_api_key "link-shortener-service/internal/usecase/revoke_api_key"
+
+	"github.com/gorilla/mux"
+)
+
+type keyHandler struct {
+	usecase usecase
+}
+
+func New(usecase usecase) *keyHandler {
+	return &keyHandler{
+		usecase: usecase,
+	}
+}
+
+func (h *keyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	err := h.usecase.Run(r.Context(), usecase_revoke_api_key.In{
+		ID: mux.Vars(r)["id"],
+	})
+	if err != nil {
+		handleUseCaseError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func handleUseCaseError(w http.ResponseWriter, err error) {
+	statusCode := http.StatusInternalServerError
+	errorMsg := "internal server error"
+
+	switch {
+	case errors.Is(err, usecase_revoke_api_key.ErrKeyNotFound):
+		statusCode = http.StatusNotFound
+		errorMsg = "API key does not exist"
+	case errors.Is(err, usecase_revoke_api_key.ErrKeyRevoke):
+		errorMsg = "failed to revoke API key"
+	}
+
+	handler.RespondWithError(w, statusCode, errorMsg, err)
+}
@@ -0,0 +1,86 @@
+package revoke_api_key
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	revoke_api_key "link-shortener-service/internal/handler/revoke_api_key/mocks"
+	usecase_revoke_api_key "link-shortener-service/internal/usecase/revoke_api_key"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevokeAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	usecaseIn := usecase_revoke_api_key.In{
+		ID: "k1",
+	}
+
+	tests := []struct {
+		name          string
+		setupMock     func(*revoke_api_key.Mockusecase)
+		expectedCode  int
+		expectedError string
+	}{
+		{
+			name: "successful revoke",
+			setupMock: func(mockUsecase *revoke_api_key.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecaseIn).
+					Return(nil)
+			},
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name: "usecase.Run error - unknown key",
+			setupMock: func(mockUsecase *revoke_api_key.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecaseIn).
+					Return(usecase_revoke_api_key.ErrKeyNotFound)
+			},
+			expectedCode:  http.StatusNotFound,
+			expectedError: "API key does not exist",
+		},
+		{
+			name: "usecase.Run error - error from storage",
+			setupMock: func(mockUsecase *revoke_api_key.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecaseIn).
+					Return(usecase_revoke_api_key.ErrKeyRevoke)
+			},
+			expectedCode:  http.StatusInternalServerError,
+			expectedError: "failed to revoke API key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := revoke_api_key.NewMockusecase(ctrl)
+			handler := New(mockUsecase)
+
+			tt.setupMock(mockUsecase)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("DELETE", "/api/keys/k1", nil)
+			req = mux.SetURLVars(req, map[string]string{"id": "k1"})
+
+			handler.RevokeAPIKey(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+
+			if tt.expectedError != "" {
+				var errorResponse map[string]interface{}
+				err := json.NewDecoder(w.Body).Decode(&errorResponse)
+				require.NoError(t, err)
+				assert.Contains(t, errorResponse["error"], tt.expectedError)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"time"
+
+	"link-shortener-service/internal/model"
+)
+
+// RedirectRule is a conditional redirect rule of a link in requests and responses
+type RedirectRule struct {
+	Target    string     `json:"target" validate:"required,url"`
+	Devices   []string   `json:"devices,omitempty" validate:"omitempty,dive,oneof=ios android mobile desktop bot"`
+	Languages []string   `json:"languages,omitempty" validate:"omitempty,dive,bcp47_language_tag"`
+	Countries []string   `json:"countries,omitempty" validate:"omitempty,dive,len=2,alpha"`
+	From      *time.Time `json:"from,omitempty"`
+	Until     *time.Time `json:"until,omitempty"`
+}
+
+func RulesToModel(rules []RedirectRule) []model.RedirectRule {
+	if len(rules) == 0 {
+		return nil
+	}
+	result := make([]model.RedirectRule, 0, len(rules))
+	for _, rule := range rules {
+		r := model.RedirectRule{
+			Target:    rule.Target,
+			Devices:   rule.Devices,
+			Languages: rule.Languages,
+			Countries: rule.Countries,
+		}
+		if rule.From != nil {
+			r.From = *rule.From
+		}
+		if rule.Until != nil {
+			r.Until = *rule.Until
+		}
+		result = append(result, r)
+	}
+	return result
+}
+
+func RuleFromModel(rule model.RedirectRule) RedirectRule {
+	r := RedirectRule{
+		Target:    rule.Target,
+		Devices:   rule.Devices,
+		Languages: rule.Languages,
+		Countries: rule.Countries,
+	}
+	if !rule.From.IsZero() {
+		r.From = &rule.From
+	}
+	if !rule.Until.IsZero() {
+		r.Until = &rule.Until
+	}
+	return r
+}
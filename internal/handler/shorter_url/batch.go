@@ -0,0 +1,155 @@
+package shorter_url
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"link-shortener-service/internal/handler"
+	"link-shortener-service/internal/middleware"
+	usecase_shorter_url "link-shortener-service/internal/usecase/shorter_url"
+)
+
+// maxBatchItemBytes is the body size allowed per item of a batch, rules and targets included
+const maxBatchItemBytes = 64 << 10
+
+var errBatchTooLarge = errors.New("batch is too large")
+
+// BatchShorterURL shortens a JSON array or NDJSON stream of URLs, every item is validated
+// on its own and gets its own result, the results are in the order of the items
+func (h *urlHandler) BatchShorterURL(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	r.Body = http.MaxBytesReader(w, r.Body, int64(h.maxBatchSize)*maxBatchItemBytes)
+	items, err := h.readBatch(r)
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.Is(err, errBatchTooLarge):
+		handler.RespondWithError(w, http.StatusRequestEntityTooLarge, "too many items", err)
+		return
+	case errors.As(err, &tooLarge):
+		handler.RespondWithError(w, http.StatusRequestEntityTooLarge, "request body is too large", err)
+		return
+	case err != nil:
+		handler.RespondWithError(w, http.StatusBadRequest, "failed to decode request", err)
+		return
+	case len(items) == 0:
+		handler.RespondWithError(w, http.StatusBadRequest, "validation failed", errors.New("batch is empty"))
+		return
+	}
+
+	principal, _ := middleware.PrincipalFromContext(r.Context())
+	results := make([]BatchItemResult, len(items))
+	var (
+		ins     []usecase_shorter_url.In
+		indexes []int
+	)
+	for i, item := range items {
+		results[i].Index = i
+
+		var url ShortFromOriginalURL
+		if err = json.Unmarshal(item, &url); err != nil {
+			results[i].fail(http.StatusBadRequest, "failed to decode item", err)
+			continue
+		}
+		if err = h.validator.Struct(url); err != nil {
+			results[i].fail(http.StatusBadRequest, "validation failed", err)
+			continue
+		}
+		ins = append(ins, toIn(url, principal.OwnerID))
+		indexes = append(indexes, i)
+	}
+
+	if len(ins) > 0 {
+		batchResults, err := h.usecase.RunBatch(r.Context(), ins)
+		if err != nil {
+			handleUseCaseError(w, err)
+			return
+		}
+		for j, result := range batchResults {
+			i := indexes[j]
+			if result.Err != nil {
+				statusCode, errorMsg := useCaseError(result.Err)
+				results[i].fail(statusCode, errorMsg, result.Err)
+				continue
+			}
+			results[i].Status = http.StatusOK
+			results[i].ShortURL = result.URLPair.Shorted
+			results[i].OriginalURL = result.URLPair.Original
+		}
+	}
+
+	if err = json.NewEncoder(w).Encode(map[string][]BatchItemResult{
+		"results": results,
+	}); err != nil {
+		handler.RespondWithError(w, http.StatusInternalServerError, "failed to encode response", err)
+		return
+	}
+}
+
+func (r *BatchItemResult) fail(status int, errorMsg string, err error) {
+	r.Status = status
+	r.Error = errorMsg
+	r.Details = err.Error()
+}
+
+// readBatch splits the body into raw items without decoding them, so a broken item doesn't fail the others.
+// The items are counted while reading, a batch over the limit is refused before the rest is read
+func (h *urlHandler) readBatch(r *http.Request) ([]json.RawMessage, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/x-ndjson", "application/ndjson", "application/jsonl":
+		return h.readNDJSON(r.Body)
+	}
+	return h.readJSONArray(r.Body)
+}
+
+func (h *urlHandler) readJSONArray(body io.Reader) ([]json.RawMessage, error) {
+	decoder := json.NewDecoder(body)
+	if token, err := decoder.Token(); err != nil {
+		return nil, err
+	} else if token != json.Delim('[') {
+		return nil, fmt.Errorf("expected an array, got %v", token)
+	}
+
+	var items []json.RawMessage
+	for decoder.More() {
+		if len(items) == h.maxBatchSize {
+			return nil, fmt.Errorf("%w: more than %d items", errBatchTooLarge, h.maxBatchSize)
+		}
+		var item json.RawMessage
+		if err := decoder.Decode(&item); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (h *urlHandler) readNDJSON(body io.Reader) ([]json.RawMessage, error) {
+	var items []json.RawMessage
+	reader := bufio.NewReader(body)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			if len(items) == h.maxBatchSize {
+				return nil, fmt.Errorf("%w: more than %d items", errBatchTooLarge, h.maxBatchSize)
+			}
+			items = append(items, line)
+		}
+		if errors.Is(err, io.EOF) {
+			return items, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package shorter_url
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	shorter_url "link-shortener-service/internal/handler/shorter_url/mocks"
+	"link-shortener-service/internal/model"
+	usecase_shorter_url "link-shortener-service/internal/usecase/shorter_url"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchShorterURL(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	valid := validator.New(validator.WithRequiredStructEnabled())
+	okResults := func(ins []usecase_shorter_url.In) []usecase_shorter_url.BatchResult {
+		results := make([]usecase_shorter_url.BatchResult, len(ins))
+		for i, in := range ins {
+			results[i].URLPair = &model.URLPair{Original: in.OriginalURL, Shorted: "https://some.com/" + in.Alias}
+		}
+		return results
+	}
+
+	tests := []struct {
+		name          string
+		contentType   string
+		body          string
+		setupMock     func(*shorter_url.Mockusecase)
+		expectedCode  int
+		expected      []BatchItemResult
+		expectedError string
+	}{
+		{
+			name:        "JSON array with broken items",
+			contentType: "application/json",
+			body: `[
+				{"original_url": "https://some.com/1", "alias": "first"},
+				{"original_url": 5},
+				{"original_url": "not a url"},
+				{"original_url": "https://some.com/4", "alias": "taken"}
+			]`,
+			setupMock: func(mockUsecase *shorter_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					RunBatch(gomock.Any(), []usecase_shorter_url.In{
+						{OriginalURL: "https://some.com/1", Alias: "first"},
+						{OriginalURL: "https://some.com/4", Alias: "taken"},
+					}).
+					DoAndReturn(func(_ any, ins []usecase_shorter_url.In) ([]usecase_shorter_url.BatchResult, error) {
+						results := okResults(ins)
+						results[1] = usecase_shorter_url.BatchResult{Err: usecase_shorter_url.ErrAliasTaken}
+						return results, nil
+					})
+			},
+			expectedCode: http.StatusOK,
+			expected: []BatchItemResult{
+				{Index: 0, Status: http.StatusOK, ShortURL: "https://some.com/first", OriginalURL: "https://some.com/1"},
+				{Index: 1, Status: http.StatusBadRequest, Error: "failed to decode item"},
+				{Index: 2, Status: http.StatusBadRequest, Error: "validation failed"},
+				{Index: 3, Status: http.StatusConflict, Error: "alias is already taken"},
+			},
+		},
+		{
+			name:        "NDJSON stream",
+			contentType: "application/x-ndjson",
+			body:        "{\"original_url\": \"https://some.com/1\", \"alias\": \"first\"}\n\n{broken\n{\"original_url\": \"https://some.com/3\", \"alias\": \"third\"}",
+			setupMock: func(mockUsecase *shorter_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					RunBatch(gomock.Any(), gomock.Len(2)).
+					DoAndReturn(func(_ any, ins []usecase_shorter_url.In) ([]usecase_shorter_url.BatchResult, error) {
+						return okResults(ins), nil
+					})
+			},
+			expectedCode: http.StatusOK,
+			expected: []BatchItemResult{
+				{Index: 0, Status: http.StatusOK, ShortURL: "https://some.com/first", OriginalURL: "https://some.com/1"},
+				{Index: 1, Status: http.StatusBadRequest, Error: "failed to decode item"},
+				{Index: 2, Status: http.StatusOK, ShortURL: "https://some.com/third", OriginalURL: "https://some.com/3"},
+			},
+		},
+		{
+			name:          "too many items",
+			contentType:   "application/json",
+			body:          `[{}, {}, {}, {}, {}]`,
+			setupMock:     func(*shorter_url.Mockusecase) {},
+			expectedCode:  http.StatusRequestEntityTooLarge,
+			expectedError: "too many items",
+		},
+		{
+			name:          "too many NDJSON items",
+			contentType:   "application/x-ndjson",
+			body:          "{}\n{}\n{}\n{}\n{}\n",
+			setupMock:     func(*shorter_url.Mockusecase) {},
+			expectedCode:  http.StatusRequestEntityTooLarge,
+			expectedError: "too many items",
+		},
+		{
+			name:          "too many items are refused before the rest is read",
+			contentType:   "application/json",
+			body:          `[{}, {}, {}, {}, {}, not even JSON`,
+			setupMock:     func(*shorter_url.Mockusecase) {},
+			expectedCode:  http.StatusRequestEntityTooLarge,
+			expectedError: "too many items",
+		},
+		{
+			name:          "body over the size limit",
+			contentType:   "application/json",
+			body:          `[{"original_url": "https://some.com/` + strings.Repeat("a", 4*maxBatchItemBytes) + `"}]`,
+			setupMock:     func(*shorter_url.Mockusecase) {},
+			expectedCode:  http.StatusRequestEntityTooLarge,
+			expectedError: "request body is too large",
+		},
+		{
+			name:          "NDJSON body over the size limit",
+			contentType:   "application/x-ndjson",
+			body:          `{"original_url": "https://some.com/` + strings.Repeat("a", 4*maxBatchItemBytes) + `"}`,
+			setupMock:     func(*shorter_url.Mockusecase) {},
+			expectedCode:  http.StatusRequestEntityTooLarge,
+			expectedError: "request body is too large",
+		},
+		{
+			name:          "empty batch",
+			contentType:   "application/json",
+			body:          `[]`,
+			setupMock:     func(*shorter_url.Mockusecase) {},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "validation failed",
+		},
+		{
+			name:          "not an array",
+			contentType:   "application/json",
+			body:          `{"original_url": "https://some.com/1"}`,
+			setupMock:     func(*shorter_url.Mockusecase) {},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "failed to decode request",
+		},
+		{
+			name:        "usecase.RunBatch error - error from storage",
+			contentType: "application/json",
+			body:        `[{"original_url": "https://some.com/1"}]`,
+			setupMock: func(mockUsecase *shorter_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					RunBatch(gomock.Any(), gomock.Any()).
+					Return(nil, usecase_shorter_url.ErrCheckExistingURL)
+			},
+			expectedCode:  http.StatusInternalServerError,
+			expectedError: "failed getting short URL",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := shorter_url.NewMockusecase(ctrl)
+			handler := New(mockUsecase, valid, 4)
+
+			tt.setupMock(mockUsecase)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/api/links/batch", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+
+			handler.BatchShorterURL(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+
+			if tt.expectedError != "" {
+				var errorResponse map[string]interface{}
+				err := json.NewDecoder(w.Body).Decode(&errorResponse)
+				require.NoError(t, err)
+				assert.Contains(t, errorResponse["error"], tt.expectedError)
+				return
+			}
+
+			var response map[string][]BatchItemResult
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+			results := response["results"]
+			for i := range results {
+				// details are driver specific, only their presence is checked
+				assert.Equal(t, results[i].Error != "", results[i].Details != "")
+				results[i].Details = ""
+			}
+			assert.Equal(t, tt.expected, results)
+		})
+	}
+}
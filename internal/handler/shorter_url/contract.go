@@ -2,7 +2,9 @@ package shorter_url
 
 import (
 	"context"
+	"time"
 
+	"link-shortener-service/internal/handler"
 	"link-shortener-service/internal/model"
 	"link-shortener-service/internal/usecase/shorter_url"
 )
@@ -10,8 +12,32 @@ import (
 //go:generate mockgen -source=contract.go -destination=mocks/contract_mock.go -package=shorter_url usecase
 type usecase interface {
 	Run(ctx context.Context, req shorter_url.In) (*model.URLPair, error)
+	RunBatch(ctx context.Context, reqs []shorter_url.In) ([]shorter_url.BatchResult, error)
 }
 
 type ShortFromOriginalURL struct {
-	OriginalURL string `json:"original_url" validate:"required,url"`
+	OriginalURL    string     `json:"original_url" validate:"required,url"`
+	RedirectStatus int        `json:"redirect_status" validate:"omitempty,oneof=301 302 307 308"`
+	Alias          string     `json:"alias"`
+	ExpiresAt      *time.Time `json:"expires_at" validate:"excluded_with=TTLSeconds"`
+	TTLSeconds     int64      `json:"ttl_seconds" validate:"omitempty,gt=0"`
+	MaxClicks      int        `json:"max_clicks" validate:"omitempty,gt=0"`
+	Password       string     `json:"password" validate:"omitempty,min=4,max=72"`
+	// Domain is a short domain of the workspace, the default domain is used when empty
+	Domain string `json:"domain" validate:"omitempty,fqdn"`
+	// Rules are checked in order on redirect, visitors matching none go to OriginalURL
+	Rules []handler.RedirectRule `json:"rules" validate:"omitempty,max=50,dive"`
+	// Targets split the visitors no rule matched between weighted destinations, sticky per visitor
+	Targets []handler.SplitTarget `json:"targets" validate:"omitempty,max=20,dive"`
+	// Interstitial shows the destination with a countdown before redirecting
+	Interstitial bool `json:"interstitial"`
+}
+
+type BatchItemResult struct {
+	Index       int    `json:"index"`
+	ShortURL    string `json:"short_url,omitempty"`
+	OriginalURL string `json:"original_url,omitempty"`
+	Status      int    `json:"status"`
+	Error       string `json:"error,omitempty"`
+	Details     string `json:"details,omitempty"`
 }
@@ -1,26 +1,29 @@
 package shorter_url
 
 import (
-	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
+	"time"
 
 	"link-shortener-service/internal/handler"
+	"link-shortener-service/internal/middleware"
 	usecase_shorter_url "link-shortener-service/internal/usecase/shorter_url"
 
 	"github.com/go-playground/validator/v10"
 )
 
 type urlHandler struct {
-	usecase   usecase
-	validator *validator.Validate
+	usecase      usecase
+	validator    *validator.Validate
+	maxBatchSize int
 }
 
-func New(IShortURLUseCase usecase, validator *validator.Validate) *urlHandler {
+func New(IShortURLUseCase usecase, validator *validator.Validate, maxBatchSize int) *urlHandler {
 	return &urlHandler{
-		usecase:   IShortURLUseCase,
-		validator: validator,
+		usecase:      IShortURLUseCase,
+		validator:    validator,
+		maxBatchSize: maxBatchSize,
 	}
 }
 
@@ -38,10 +41,8 @@ func (h *urlHandler) ShorterURL(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx := context.TODO()
-	result, err := h.usecase.Run(ctx, usecase_shorter_url.In{
-		OriginalURL: url.OriginalURL,
-	})
+	principal, _ := middleware.PrincipalFromContext(r.Context())
+	result, err := h.usecase.Run(r.Context(), toIn(url, principal.OwnerID))
 	if err != nil {
 		handleUseCaseError(w, err)
 		return
@@ -55,14 +56,79 @@ func (h *urlHandler) ShorterURL(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func toIn(url ShortFromOriginalURL, ownerID string) usecase_shorter_url.In {
+	in := usecase_shorter_url.In{
+		OwnerID:        ownerID,
+		OriginalURL:    url.OriginalURL,
+		RedirectStatus: url.RedirectStatus,
+		Alias:          url.Alias,
+		TTL:            time.Duration(url.TTLSeconds) * time.Second,
+		MaxClicks:      url.MaxClicks,
+		Password:       url.Password,
+		Domain:         url.Domain,
+		Rules:          handler.RulesToModel(url.Rules),
+		Targets:        handler.TargetsToModel(url.Targets),
+		Interstitial:   url.Interstitial,
+	}
+	if url.ExpiresAt != nil {
+		in.ExpiresAt = *url.ExpiresAt
+	}
+	return in
+}
+
 func handleUseCaseError(w http.ResponseWriter, err error) {
+	statusCode, errorMsg := useCaseError(err)
+	handler.RespondWithError(w, statusCode, errorMsg, err)
+}
+
+func useCaseError(err error) (int, string) {
 	statusCode := http.StatusInternalServerError
 	errorMsg := "internal server error"
 
 	switch {
 	case errors.Is(err, usecase_shorter_url.ErrCheckExistingURL):
 		errorMsg = "failed getting short URL"
+	case errors.Is(err, usecase_shorter_url.ErrHashPassword):
+		errorMsg = "failed to hash password"
+	case errors.Is(err, usecase_shorter_url.ErrInvalidAlias):
+		statusCode = http.StatusBadRequest
+		errorMsg = "invalid alias"
+	case errors.Is(err, usecase_shorter_url.ErrReservedAlias):
+		statusCode = http.StatusBadRequest
+		errorMsg = "alias is reserved"
+	case errors.Is(err, usecase_shorter_url.ErrBlockedAlias):
+		statusCode = http.StatusBadRequest
+		errorMsg = "alias contains a blocked word"
+	case errors.Is(err, usecase_shorter_url.ErrAliasTaken):
+		statusCode = http.StatusConflict
+		errorMsg = "alias is already taken"
+	case errors.Is(err, usecase_shorter_url.ErrInvalidExpiry):
+		statusCode = http.StatusBadRequest
+		errorMsg = "invalid expiration"
+	case errors.Is(err, usecase_shorter_url.ErrInvalidRules):
+		statusCode = http.StatusBadRequest
+		errorMsg = "invalid redirect rules"
+	case errors.Is(err, usecase_shorter_url.ErrInvalidTargets):
+		statusCode = http.StatusBadRequest
+		errorMsg = "invalid split targets"
+	case errors.Is(err, usecase_shorter_url.ErrOriginalURLDisabled):
+		statusCode = http.StatusConflict
+		errorMsg = "original URL belongs to a disabled short URL"
+	case errors.Is(err, usecase_shorter_url.ErrBlockedURL):
+		statusCode = http.StatusUnprocessableEntity
+		errorMsg = "original URL is blocklisted"
+	case errors.Is(err, usecase_shorter_url.ErrUnknownDomain):
+		statusCode = http.StatusBadRequest
+		errorMsg = "unknown short domain"
+	case errors.Is(err, usecase_shorter_url.ErrForeignDomain):
+		statusCode = http.StatusForbidden
+		errorMsg = "short domain belongs to another workspace"
+	case errors.Is(err, usecase_shorter_url.ErrCheckDomain):
+		errorMsg = "failed to check short domain"
+	case errors.Is(err, usecase_shorter_url.ErrShortURLGeneration):
+		statusCode = http.StatusServiceUnavailable
+		errorMsg = "failed to generate short URL"
 	}
 
-	handler.RespondWithError(w, statusCode, errorMsg, err)
+	return statusCode, errorMsg
 }
@@ -8,8 +8,10 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	shorter_url "link-shortener-service/internal/handler/shorter_url/mocks"
+	"link-shortener-service/internal/middleware"
 	"link-shortener-service/internal/model"
 	usecase_shorter_url "link-shortener-service/internal/usecase/shorter_url"
 
@@ -39,6 +41,7 @@ func TestShorterURL(t *testing.T) {
 	tests := []struct {
 		name          string
 		setupMock     func(*shorter_url.Mockusecase)
+		ownerID       string
 		reqBody       string
 		expectedCode  int
 		expected      string
@@ -48,13 +51,25 @@ func TestShorterURL(t *testing.T) {
 			name: "successful shorten",
 			setupMock: func(mockUsecase *shorter_url.Mockusecase) {
 				mockUsecase.EXPECT().
-					Run(context.TODO(), usecaseIn).
+					Run(gomock.Any(), usecaseIn).
 					Return(&usecaseOut, nil)
 			},
 			reqBody:      fmt.Sprintf(`{"original_url":"%s"}`, reqDTO.OriginalURL),
 			expectedCode: http.StatusOK,
 			expected:     usecaseOut.Shorted,
 		},
+		{
+			name: "successful shorten by key owner",
+			setupMock: func(mockUsecase *shorter_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecase_shorter_url.In{OriginalURL: reqDTO.OriginalURL, OwnerID: "team-a"}).
+					Return(&usecaseOut, nil)
+			},
+			ownerID:      "team-a",
+			reqBody:      fmt.Sprintf(`{"original_url":"%s"}`, reqDTO.OriginalURL),
+			expectedCode: http.StatusOK,
+			expected:     usecaseOut.Shorted,
+		},
 		{
 			name:          "empty body",
 			setupMock:     func(mockUsecase *shorter_url.Mockusecase) {},
@@ -69,23 +84,228 @@ func TestShorterURL(t *testing.T) {
 			expectedCode:  http.StatusBadRequest,
 			expectedError: "validation failed",
 		},
+		{
+			name: "successful shorten with redirect status",
+			setupMock: func(mockUsecase *shorter_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecase_shorter_url.In{
+						OriginalURL:    reqDTO.OriginalURL,
+						RedirectStatus: http.StatusPermanentRedirect,
+					}).
+					Return(&usecaseOut, nil)
+			},
+			reqBody:      fmt.Sprintf(`{"original_url":"%s","redirect_status":308}`, reqDTO.OriginalURL),
+			expectedCode: http.StatusOK,
+			expected:     usecaseOut.Shorted,
+		},
+		{
+			name:          "validator error - unsupported redirect status",
+			setupMock:     func(mockUsecase *shorter_url.Mockusecase) {},
+			reqBody:       fmt.Sprintf(`{"original_url":"%s","redirect_status":303}`, reqDTO.OriginalURL),
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "validation failed",
+		},
 		{
 			name: "usecase.Run error",
 			setupMock: func(mockUsecase *shorter_url.Mockusecase) {
 				mockUsecase.EXPECT().
-					Run(context.TODO(), usecaseIn).
+					Run(gomock.Any(), usecaseIn).
 					Return(nil, usecase_shorter_url.ErrCheckExistingURL)
 			},
 			reqBody:       fmt.Sprintf(`{"original_url":"%s"}`, reqDTO.OriginalURL),
 			expectedCode:  http.StatusInternalServerError,
 			expectedError: "failed getting short URL",
 		},
+		{
+			name: "successful shorten with redirect rules",
+			setupMock: func(mockUsecase *shorter_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecase_shorter_url.In{
+						OriginalURL: reqDTO.OriginalURL,
+						Rules: []model.RedirectRule{
+							{Target: "https://apps.apple.com/app/some", Devices: []string{"ios"}},
+							{Target: "https://some.de", Countries: []string{"DE"}, Languages: []string{"de-AT"}},
+						},
+					}).
+					Return(&usecaseOut, nil)
+			},
+			reqBody: fmt.Sprintf(`{"original_url":"%s","rules":[
+				{"target":"https://apps.apple.com/app/some","devices":["ios"]},
+				{"target":"https://some.de","countries":["DE"],"languages":["de-AT"]}]}`, reqDTO.OriginalURL),
+			expectedCode: http.StatusOK,
+			expected:     usecaseOut.Shorted,
+		},
+		{
+			name:          "validator error - unknown rule device",
+			setupMock:     func(mockUsecase *shorter_url.Mockusecase) {},
+			reqBody:       fmt.Sprintf(`{"original_url":"%s","rules":[{"target":"https://some.com/tv","devices":["tv"]}]}`, reqDTO.OriginalURL),
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "validation failed",
+		},
+		{
+			name: "usecase.Run error - invalid rules",
+			setupMock: func(mockUsecase *shorter_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), gomock.Any()).
+					Return(nil, usecase_shorter_url.ErrInvalidRules)
+			},
+			reqBody:       fmt.Sprintf(`{"original_url":"%s","rules":[{"target":"https://some.com/a"}]}`, reqDTO.OriginalURL),
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "invalid redirect rules",
+		},
+		{
+			name: "successful shorten with split targets",
+			setupMock: func(mockUsecase *shorter_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecase_shorter_url.In{
+						OriginalURL: reqDTO.OriginalURL,
+						Targets: []model.SplitTarget{
+							{URL: "https://some.com/a", Weight: 70},
+							{Variant: "new", URL: "https://some.com/new", Weight: 30},
+						},
+					}).
+					Return(&usecaseOut, nil)
+			},
+			reqBody: fmt.Sprintf(`{"original_url":"%s","targets":[
+				{"url":"https://some.com/a","weight":70},
+				{"variant":"new","url":"https://some.com/new","weight":30}]}`, reqDTO.OriginalURL),
+			expectedCode: http.StatusOK,
+			expected:     usecaseOut.Shorted,
+		},
+		{
+			name: "usecase.Run error - invalid split targets",
+			setupMock: func(mockUsecase *shorter_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), gomock.Any()).
+					Return(nil, usecase_shorter_url.ErrInvalidTargets)
+			},
+			reqBody:       fmt.Sprintf(`{"original_url":"%s","targets":[{"url":"https://some.com/a","weight":0}]}`, reqDTO.OriginalURL),
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "invalid split targets",
+		},
+		{
+			name: "successful shorten with interstitial",
+			setupMock: func(mockUsecase *shorter_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecase_shorter_url.In{OriginalURL: reqDTO.OriginalURL, Interstitial: true}).
+					Return(&usecaseOut, nil)
+			},
+			reqBody:      fmt.Sprintf(`{"original_url":"%s","interstitial":true}`, reqDTO.OriginalURL),
+			expectedCode: http.StatusOK,
+			expected:     usecaseOut.Shorted,
+		},
+		{
+			name: "successful shorten with TTL",
+			setupMock: func(mockUsecase *shorter_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecase_shorter_url.In{
+						OriginalURL: reqDTO.OriginalURL,
+						TTL:         time.Hour,
+					}).
+					Return(&usecaseOut, nil)
+			},
+			reqBody:      fmt.Sprintf(`{"original_url":"%s","ttl_seconds":3600}`, reqDTO.OriginalURL),
+			expectedCode: http.StatusOK,
+			expected:     usecaseOut.Shorted,
+		},
+		{
+			name:          "validator error - both TTL and expiration date",
+			setupMock:     func(mockUsecase *shorter_url.Mockusecase) {},
+			reqBody:       fmt.Sprintf(`{"original_url":"%s","ttl_seconds":60,"expires_at":"2030-01-01T00:00:00Z"}`, reqDTO.OriginalURL),
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "validation failed",
+		},
+		{
+			name: "usecase.Run error - expiration in the past",
+			setupMock: func(mockUsecase *shorter_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecase_shorter_url.In{
+						OriginalURL: reqDTO.OriginalURL,
+						ExpiresAt:   time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+					}).
+					Return(nil, usecase_shorter_url.ErrInvalidExpiry)
+			},
+			reqBody:       fmt.Sprintf(`{"original_url":"%s","expires_at":"2020-01-01T00:00:00Z"}`, reqDTO.OriginalURL),
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "invalid expiration",
+		},
+		{
+			name:          "validator error - too short password",
+			setupMock:     func(mockUsecase *shorter_url.Mockusecase) {},
+			reqBody:       fmt.Sprintf(`{"original_url":"%s","password":"abc"}`, reqDTO.OriginalURL),
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "validation failed",
+		},
+		{
+			name: "usecase.Run error - alias is taken",
+			setupMock: func(mockUsecase *shorter_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecase_shorter_url.In{
+						OriginalURL: reqDTO.OriginalURL,
+						Alias:       "spring-sale",
+					}).
+					Return(nil, usecase_shorter_url.ErrAliasTaken)
+			},
+			reqBody:       fmt.Sprintf(`{"original_url":"%s","alias":"spring-sale"}`, reqDTO.OriginalURL),
+			expectedCode:  http.StatusConflict,
+			expectedError: "alias is already taken",
+		},
+		{
+			name: "usecase.Run error - blocklisted destination",
+			setupMock: func(mockUsecase *shorter_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecase_shorter_url.In{OriginalURL: reqDTO.OriginalURL}).
+					Return(nil, usecase_shorter_url.ErrBlockedURL)
+			},
+			reqBody:       fmt.Sprintf(`{"original_url":"%s"}`, reqDTO.OriginalURL),
+			expectedCode:  http.StatusUnprocessableEntity,
+			expectedError: "original URL is blocklisted",
+		},
+		{
+			name: "usecase.Run error - no free short URL",
+			setupMock: func(mockUsecase *shorter_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecase_shorter_url.In{OriginalURL: reqDTO.OriginalURL}).
+					Return(nil, usecase_shorter_url.ErrShortURLGeneration)
+			},
+			reqBody:       fmt.Sprintf(`{"original_url":"%s"}`, reqDTO.OriginalURL),
+			expectedCode:  http.StatusServiceUnavailable,
+			expectedError: "failed to generate short URL",
+		},
+		{
+			name: "usecase.Run error - blocked alias",
+			setupMock: func(mockUsecase *shorter_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecase_shorter_url.In{
+						OriginalURL: reqDTO.OriginalURL,
+						Alias:       "so-bad",
+					}).
+					Return(nil, usecase_shorter_url.ErrBlockedAlias)
+			},
+			reqBody:       fmt.Sprintf(`{"original_url":"%s","alias":"so-bad"}`, reqDTO.OriginalURL),
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "alias contains a blocked word",
+		},
+		{
+			name: "usecase.Run error - reserved alias",
+			setupMock: func(mockUsecase *shorter_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecase_shorter_url.In{
+						OriginalURL: reqDTO.OriginalURL,
+						Alias:       "api",
+					}).
+					Return(nil, usecase_shorter_url.ErrReservedAlias)
+			},
+			reqBody:       fmt.Sprintf(`{"original_url":"%s","alias":"api"}`, reqDTO.OriginalURL),
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "alias is reserved",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockUsecase := shorter_url.NewMockusecase(ctrl)
-			handler := New(mockUsecase, valid)
+			handler := New(mockUsecase, valid, 10)
 
 			tt.setupMock(mockUsecase)
 
@@ -96,6 +316,9 @@ func TestShorterURL(t *testing.T) {
 				strings.NewReader(tt.reqBody),
 			)
 			req.Header.Set("Content-Type", "application/json")
+			if tt.ownerID != "" {
+				req = req.WithContext(middleware.ContextWithPrincipal(req.Context(), model.Principal{OwnerID: tt.ownerID}))
+			}
 
 			handler.ShorterURL(w, req)
 
@@ -117,3 +340,24 @@ func TestShorterURL(t *testing.T) {
 		})
 	}
 }
+
+func TestShorterURLPassesRequestContext(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := shorter_url.NewMockusecase(ctrl)
+	mockUsecase.EXPECT().
+		Run(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, in usecase_shorter_url.In) (*model.URLPair, error) {
+			// the usecase stops generating codes once the client is gone
+			assert.ErrorIs(t, ctx.Err(), context.Canceled)
+			return &model.URLPair{Original: in.OriginalURL, Shorted: "xHsvC_0NTU"}, nil
+		})
+	handler := New(mockUsecase, validator.New(validator.WithRequiredStructEnabled()), 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequestWithContext(ctx, "POST", "/", strings.NewReader(`{"original_url":"https://some.com/asdasd"}`))
+	req.Header.Set("Content-Type", "application/json")
+	handler.ShorterURL(httptest.NewRecorder(), req)
+}
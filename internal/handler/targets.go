@@ -0,0 +1,25 @@
+package handler
+
+import "link-shortener-service/internal/model"
+
+// SplitTarget is a weighted destination of an A/B split link in requests and responses
+type SplitTarget struct {
+	Variant string `json:"variant,omitempty" validate:"omitempty,max=32"`
+	URL     string `json:"url" validate:"required,url"`
+	Weight  int    `json:"weight" validate:"gte=0"`
+}
+
+func TargetsToModel(targets []SplitTarget) []model.SplitTarget {
+	if len(targets) == 0 {
+		return nil
+	}
+	result := make([]model.SplitTarget, 0, len(targets))
+	for _, target := range targets {
+		result = append(result, model.SplitTarget{Variant: target.Variant, URL: target.URL, Weight: target.Weight})
+	}
+	return result
+}
+
+func TargetFromModel(target model.SplitTarget) SplitTarget {
+	return SplitTarget{Variant: target.Variant, URL: target.URL, Weight: target.Weight}
+}
@@ -0,0 +1,17 @@
+package update_url
+
+import (
+	"context"
+
+	"link-shortener-service/internal/model"
+	"link-shortener-service/internal/usecase/update_url"
+)
+
+//go:generate mockgen -source=contract.go -destination=mocks/contract_mock.go -package=update_url usecase
+type usecase interface {
+	Run(ctx context.Context, req update_url.In) (*model.URLPair, error)
+}
+
+type UpdateOriginalURL struct {
+	OriginalURL string `json:"original_url" validate:"required,url"`
+}
@@ -0,0 +1,84 @@
+package update_url
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"link-shortener-service/internal/handler"
+	"link-shortener-service/internal/middleware"
+	usecase_update_url "link-shortener-service/internal/usecase/update_url"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type urlHandler struct {
+	usecase         usecase
+	validator       *validator.Validate
+	caseInsensitive bool
+}
+
+func New(usecase usecase, validator *validator.Validate, caseInsensitive bool) *urlHandler {
+	return &urlHandler{
+		usecase:         usecase,
+		validator:       validator,
+		caseInsensitive: caseInsensitive,
+	}
+}
+
+func (h *urlHandler) UpdateURL(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var url UpdateOriginalURL
+	if err := json.NewDecoder(r.Body).Decode(&url); err != nil {
+		handler.RespondWithError(w, http.StatusBadRequest, "failed to decode request", err)
+		return
+	}
+
+	if err := h.validator.Struct(url); err != nil {
+		handler.RespondWithError(w, http.StatusBadRequest, "validation failed", err)
+		return
+	}
+
+	principal, _ := middleware.PrincipalFromContext(r.Context())
+	result, err := h.usecase.Run(r.Context(), usecase_update_url.In{
+		Principal:   principal,
+		ShortedURL:  handler.LinkKey(r, h.caseInsensitive),
+		OriginalURL: url.OriginalURL,
+	})
+	if err != nil {
+		handleUseCaseError(w, err)
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(map[string]string{
+		"short_url":    result.Shorted,
+		"original_url": result.Original,
+	}); err != nil {
+		handler.RespondWithError(w, http.StatusInternalServerError, "failed to encode response", err)
+		return
+	}
+}
+
+func handleUseCaseError(w http.ResponseWriter, err error) {
+	statusCode := http.StatusInternalServerError
+	errorMsg := "internal server error"
+
+	switch {
+	case errors.Is(err, usecase_update_url.ErrURLNotFound):
+		statusCode = http.StatusNotFound
+		errorMsg = "short URL does not exist"
+	case errors.Is(err, usecase_update_url.ErrForbidden):
+		statusCode = http.StatusForbidden
+		errorMsg = "short URL belongs to another owner"
+	case errors.Is(err, usecase_update_url.ErrBlockedURL):
+		statusCode = http.StatusUnprocessableEntity
+		errorMsg = "original URL is blocklisted"
+	case errors.Is(err, usecase_update_url.ErrURLRetrieval):
+		errorMsg = "failed to get short URL"
+	case errors.Is(err, usecase_update_url.ErrURLUpdate):
+		errorMsg = "failed to update short URL"
+	}
+
+	handler.RespondWithError(w, statusCode, errorMsg, err)
+}
@@ -0,0 +1,140 @@
+package update_url
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	update_url "link-shortener-service/internal/handler/update_url/mocks"
+	"link-shortener-service/internal/model"
+	usecase_update_url "link-shortener-service/internal/usecase/update_url"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateURL(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	usecaseIn := usecase_update_url.In{
+		ShortedURL:  "xHsvC_0NTU",
+		OriginalURL: "https://other.com/",
+	}
+
+	tests := []struct {
+		name             string
+		body             string
+		setupMock        func(*update_url.Mockusecase)
+		expectedCode     int
+		expectedResponse map[string]string
+		expectedError    string
+	}{
+		{
+			name: "successful update",
+			body: `{"original_url": "https://other.com/"}`,
+			setupMock: func(mockUsecase *update_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecaseIn).
+					Return(&model.URLPair{Original: "https://other.com/", Shorted: "https://some.com/xHsvC_0NTU"}, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedResponse: map[string]string{
+				"short_url":    "https://some.com/xHsvC_0NTU",
+				"original_url": "https://other.com/",
+			},
+		},
+		{
+			name:          "invalid JSON",
+			body:          `{"original_url": `,
+			setupMock:     func(mockUsecase *update_url.Mockusecase) {},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "failed to decode request",
+		},
+		{
+			name:          "invalid URL",
+			body:          `{"original_url": "not a url"}`,
+			setupMock:     func(mockUsecase *update_url.Mockusecase) {},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "validation failed",
+		},
+		{
+			name: "usecase.Run error - unknown short URL",
+			body: `{"original_url": "https://other.com/"}`,
+			setupMock: func(mockUsecase *update_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecaseIn).
+					Return(nil, usecase_update_url.ErrURLNotFound)
+			},
+			expectedCode:  http.StatusNotFound,
+			expectedError: "short URL does not exist",
+		},
+		{
+			name: "usecase.Run error - link of another owner",
+			body: `{"original_url": "https://other.com/"}`,
+			setupMock: func(mockUsecase *update_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecaseIn).
+					Return(nil, usecase_update_url.ErrForbidden)
+			},
+			expectedCode:  http.StatusForbidden,
+			expectedError: "short URL belongs to another owner",
+		},
+		{
+			name: "usecase.Run error - blocklisted destination",
+			body: `{"original_url": "https://other.com/"}`,
+			setupMock: func(mockUsecase *update_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecaseIn).
+					Return(nil, usecase_update_url.ErrBlockedURL)
+			},
+			expectedCode:  http.StatusUnprocessableEntity,
+			expectedError: "original URL is blocklisted",
+		},
+		{
+			name: "usecase.Run error - error from storage",
+			body: `{"original_url": "https://other.com/"}`,
+			setupMock: func(mockUsecase *update_url.Mockusecase) {
+				mockUsecase.EXPECT().
+					Run(gomock.Any(), usecaseIn).
+					Return(nil, usecase_update_url.ErrURLUpdate)
+			},
+			expectedCode:  http.StatusInternalServerError,
+			expectedError: "failed to update short URL",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := update_url.NewMockusecase(ctrl)
+			handler := New(mockUsecase, validator.New(), false)
+
+			tt.setupMock(mockUsecase)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("PATCH", "/api/links/xHsvC_0NTU", strings.NewReader(tt.body))
+			req = mux.SetURLVars(req, map[string]string{"code": "xHsvC_0NTU"})
+
+			handler.UpdateURL(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+
+			if tt.expectedError != "" {
+				var errorResponse map[string]interface{}
+				err := json.NewDecoder(w.Body).Decode(&errorResponse)
+				require.NoError(t, err)
+				assert.Contains(t, errorResponse["error"], tt.expectedError)
+				return
+			}
+
+			var response map[string]string
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+			assert.Equal(t, tt.expectedResponse, response)
+		})
+	}
+}
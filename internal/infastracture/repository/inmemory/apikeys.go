@@ -0,0 +1,45 @@
+package inmemory
+
+import (
+	"context"
+	"time"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	"link-shortener-service/internal/model"
+)
+
+func (r *repository) PutAPIKey(_ context.Context, key model.APIKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.apiKeys[key.ID] = key
+
+	return nil
+}
+
+func (r *repository) GetAPIKey(_ context.Context, id string) (*model.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	key, exists := r.apiKeys[id]
+	if !exists {
+		return nil, rep.ErrNotFound
+	}
+	return &key, nil
+}
+
+func (r *repository) RevokeAPIKey(_ context.Context, id string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key, exists := r.apiKeys[id]
+	if !exists {
+		return rep.ErrNotFound
+	}
+	if !key.IsRevoked() {
+		key.RevokedAt = at
+		r.apiKeys[id] = key
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package inmemory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	"link-shortener-service/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKeys(t *testing.T) {
+	repo := NewMapRepository()
+	key := model.APIKey{ID: "k1", OwnerID: "team-a", Hash: "hash", Scopes: []model.Scope{model.ScopeRead}}
+
+	assert.NoError(t, repo.PutAPIKey(context.Background(), key))
+
+	result, err := repo.GetAPIKey(context.Background(), "k1")
+	assert.NoError(t, err)
+	assert.Equal(t, &key, result)
+
+	revokedAt := time.Now()
+	assert.NoError(t, repo.RevokeAPIKey(context.Background(), "k1", revokedAt))
+	assert.NoError(t, repo.RevokeAPIKey(context.Background(), "k1", revokedAt.Add(time.Hour)))
+	result, err = repo.GetAPIKey(context.Background(), "k1")
+	assert.NoError(t, err)
+	assert.Equal(t, revokedAt, result.RevokedAt)
+
+	_, err = repo.GetAPIKey(context.Background(), "unknown")
+	assert.Equal(t, rep.ErrNotFound, err)
+	assert.Equal(t, rep.ErrNotFound, repo.RevokeAPIKey(context.Background(), "unknown", revokedAt))
+}
@@ -0,0 +1,23 @@
+package inmemory
+
+import (
+	"context"
+
+	"link-shortener-service/internal/model"
+)
+
+// maxPendingClicks caps the clicks waiting for the rollup, the oldest ones are dropped
+// when the rollup falls behind
+const maxPendingClicks = 100_000
+
+func (r *repository) SaveClicks(_ context.Context, clicks []model.Click) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.clicks = append(r.clicks, clicks...)
+	if excess := len(r.clicks) - maxPendingClicks; excess > 0 {
+		r.clicks = append(r.clicks[:0], r.clicks[excess:]...)
+	}
+
+	return nil
+}
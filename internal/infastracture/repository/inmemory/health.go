@@ -0,0 +1,71 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"link-shortener-service/internal/model"
+)
+
+func (r *repository) GetLinksToCheck(_ context.Context, now time.Time, limit int) ([]model.LinkHealth, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var due []model.LinkHealth
+	for shorted, pair := range r.shortOrig {
+		if pair.Disabled || pair.IsExpired(now) {
+			continue
+		}
+		health, checked := r.health[shorted]
+		if checked && health.NextCheckAt.After(now) {
+			continue
+		}
+		health.Shorted, health.Original, health.OwnerID = shorted, pair.Original, pair.OwnerID
+		due = append(due, health)
+	}
+
+	// links never checked have no next check time and go first
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].NextCheckAt.Before(due[j].NextCheckAt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	return due, nil
+}
+
+func (r *repository) SaveLinkHealth(_ context.Context, health []model.LinkHealth) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, h := range health {
+		h.Redirects = append([]string(nil), h.Redirects...)
+		r.health[h.Shorted] = h
+	}
+
+	return nil
+}
+
+func (r *repository) GetBrokenLinks(_ context.Context, ownerID string, limit int) ([]model.LinkHealth, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var broken []model.LinkHealth
+	for shorted, health := range r.health {
+		pair, exists := r.shortOrig[shorted]
+		if !exists || pair.Disabled || !health.IsBroken() || (ownerID != "" && pair.OwnerID != ownerID) {
+			continue
+		}
+		health.Original, health.OwnerID = pair.Original, pair.OwnerID
+		broken = append(broken, health)
+	}
+
+	sort.Slice(broken, func(i, j int) bool {
+		return broken[i].CheckedAt.After(broken[j].CheckedAt)
+	})
+	if len(broken) > limit {
+		broken = broken[:limit]
+	}
+	return broken, nil
+}
@@ -0,0 +1,69 @@
+package inmemory
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"link-shortener-service/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinkHealth(t *testing.T) {
+	repo := NewMapRepository()
+	ctx := context.Background()
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, pair := range []model.URLPair{
+		{Original: "https://some.com/1", Shorted: "fresh", OwnerID: "team-a"},
+		{Original: "https://some.com/2", Shorted: "checked", OwnerID: "team-a"},
+		{Original: "https://some.com/3", Shorted: "due", OwnerID: "team-b"},
+		{Original: "https://some.com/4", Shorted: "disabled", Disabled: true},
+	} {
+		_, err := repo.PutURLPair(ctx, pair)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, repo.SaveLinkHealth(ctx, []model.LinkHealth{
+		{
+			Shorted:     "checked",
+			LinkCheck:   model.LinkCheck{Status: http.StatusNotFound},
+			CheckedAt:   now.Add(-time.Hour),
+			Failures:    1,
+			NextCheckAt: now.Add(time.Hour),
+		},
+		{
+			Shorted:     "due",
+			LinkCheck:   model.LinkCheck{Error: "connection refused"},
+			CheckedAt:   now.Add(-2 * time.Hour),
+			Failures:    2,
+			NextCheckAt: now.Add(-time.Minute),
+		},
+	}))
+
+	due, err := repo.GetLinksToCheck(ctx, now, 10)
+	require.NoError(t, err)
+	require.Len(t, due, 2)
+	assert.Equal(t, model.LinkHealth{Shorted: "fresh", Original: "https://some.com/1", OwnerID: "team-a"}, due[0])
+	assert.Equal(t, "due", due[1].Shorted)
+	assert.Equal(t, 2, due[1].Failures)
+
+	due, err = repo.GetLinksToCheck(ctx, now, 1)
+	require.NoError(t, err)
+	assert.Len(t, due, 1)
+
+	broken, err := repo.GetBrokenLinks(ctx, "", 10)
+	require.NoError(t, err)
+	require.Len(t, broken, 2)
+	assert.Equal(t, "checked", broken[0].Shorted)
+	assert.Equal(t, "https://some.com/2", broken[0].Original)
+	assert.Equal(t, "due", broken[1].Shorted)
+
+	broken, err = repo.GetBrokenLinks(ctx, "team-b", 10)
+	require.NoError(t, err)
+	require.Len(t, broken, 1)
+	assert.Equal(t, "due", broken[0].Shorted)
+}
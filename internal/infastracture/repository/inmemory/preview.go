@@ -0,0 +1,62 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	"link-shortener-service/internal/model"
+)
+
+func (r *repository) GetLinksToFetch(_ context.Context, now time.Time, limit int) ([]model.LinkPreview, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var due []model.LinkPreview
+	for shorted, pair := range r.shortOrig {
+		if pair.Disabled || pair.IsExpired(now) {
+			continue
+		}
+		preview, fetched := r.previews[shorted]
+		if fetched && preview.Original != pair.Original {
+			// the metadata belongs to the previous destination
+			preview = model.LinkPreview{}
+		} else if fetched && preview.NextFetchAt.After(now) {
+			continue
+		}
+		preview.Shorted, preview.Original = shorted, pair.Original
+		due = append(due, preview)
+	}
+
+	// links never fetched have no next fetch time and go first
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].NextFetchAt.Before(due[j].NextFetchAt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	return due, nil
+}
+
+func (r *repository) SaveLinkPreviews(_ context.Context, previews []model.LinkPreview) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, preview := range previews {
+		r.previews[preview.Shorted] = preview
+	}
+
+	return nil
+}
+
+func (r *repository) GetLinkPreview(_ context.Context, shorted string) (*model.LinkPreview, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	preview, exists := r.previews[shorted]
+	if !exists {
+		return nil, rep.ErrNotFound
+	}
+	return &preview, nil
+}
@@ -0,0 +1,75 @@
+package inmemory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	"link-shortener-service/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinkPreviews(t *testing.T) {
+	repo := NewMapRepository()
+	ctx := context.Background()
+	now := time.Date(2025, 7, 8, 12, 0, 0, 0, time.UTC)
+
+	for _, pair := range []model.URLPair{
+		{Original: "https://some.com/1", Shorted: "fresh"},
+		{Original: "https://some.com/2", Shorted: "fetched"},
+		{Original: "https://some.com/3", Shorted: "due"},
+		{Original: "https://some.com/4", Shorted: "changed"},
+		{Original: "https://some.com/5", Shorted: "disabled", Disabled: true},
+	} {
+		_, err := repo.PutURLPair(ctx, pair)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, repo.SaveLinkPreviews(ctx, []model.LinkPreview{
+		{
+			Shorted:      "fetched",
+			Original:     "https://some.com/2",
+			LinkMetadata: model.LinkMetadata{Title: "Two"},
+			FetchedAt:    now.Add(-time.Hour),
+			NextFetchAt:  now.Add(time.Hour),
+		},
+		{
+			Shorted:     "due",
+			Original:    "https://some.com/3",
+			Error:       "timeout",
+			Failures:    1,
+			NextFetchAt: now.Add(-time.Minute),
+		},
+		{
+			Shorted:      "changed",
+			Original:     "https://some.com/old",
+			LinkMetadata: model.LinkMetadata{Title: "Old"},
+			FetchedAt:    now.Add(-time.Hour),
+			NextFetchAt:  now.Add(time.Hour),
+		},
+	}))
+
+	due, err := repo.GetLinksToFetch(ctx, now, 10)
+	require.NoError(t, err)
+	require.Len(t, due, 3)
+	assert.ElementsMatch(t, []model.LinkPreview{
+		{Shorted: "fresh", Original: "https://some.com/1"},
+		{Shorted: "changed", Original: "https://some.com/4"},
+	}, due[:2])
+	assert.Equal(t, "due", due[2].Shorted)
+	assert.Equal(t, 1, due[2].Failures)
+
+	due, err = repo.GetLinksToFetch(ctx, now, 1)
+	require.NoError(t, err)
+	assert.Len(t, due, 1)
+
+	preview, err := repo.GetLinkPreview(ctx, "fetched")
+	require.NoError(t, err)
+	assert.Equal(t, "Two", preview.Title)
+
+	_, err = repo.GetLinkPreview(ctx, "fresh")
+	assert.ErrorIs(t, err, rep.ErrNotFound)
+}
@@ -0,0 +1,33 @@
+package inmemory
+
+import (
+	"context"
+	"time"
+
+	"link-shortener-service/internal/model"
+)
+
+func (r *repository) TakeToken(_ context.Context, key string, limit model.RateLimit, now time.Time) (float64, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	bucket, taken := r.buckets[key].Take(limit, now)
+	r.buckets[key] = bucket
+
+	return bucket.Tokens, taken, nil
+}
+
+func (r *repository) DeleteIdleBuckets(_ context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	for key, bucket := range r.buckets {
+		if bucket.UpdatedAt.Before(before) {
+			delete(r.buckets, key)
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
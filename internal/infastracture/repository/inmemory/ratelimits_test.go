@@ -0,0 +1,60 @@
+package inmemory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"link-shortener-service/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTakeToken(t *testing.T) {
+	repo := NewMapRepository()
+	limit := model.RateLimit{Rate: 2, Burst: 3}
+	now := time.Date(2025, 6, 24, 12, 0, 0, 0, time.UTC)
+
+	// a new client gets the whole burst
+	for want := 2.0; want >= 0; want-- {
+		tokens, taken, err := repo.TakeToken(context.Background(), "ip:10.0.0.1", limit, now)
+		require.NoError(t, err)
+		assert.True(t, taken)
+		assert.Equal(t, want, tokens)
+	}
+	tokens, taken, err := repo.TakeToken(context.Background(), "ip:10.0.0.1", limit, now)
+	require.NoError(t, err)
+	assert.False(t, taken)
+	assert.Equal(t, 0.0, tokens)
+
+	// other keys have their own buckets
+	_, taken, _ = repo.TakeToken(context.Background(), "ip:10.0.0.2", limit, now)
+	assert.True(t, taken)
+
+	// the bucket refills at the rate and never over the burst
+	tokens, taken, _ = repo.TakeToken(context.Background(), "ip:10.0.0.1", limit, now.Add(250*time.Millisecond))
+	assert.False(t, taken)
+	assert.Equal(t, 0.5, tokens)
+	tokens, taken, _ = repo.TakeToken(context.Background(), "ip:10.0.0.1", limit, now.Add(500*time.Millisecond))
+	assert.True(t, taken)
+	assert.Equal(t, 0.0, tokens)
+	tokens, taken, _ = repo.TakeToken(context.Background(), "ip:10.0.0.1", limit, now.Add(time.Hour))
+	assert.True(t, taken)
+	assert.Equal(t, 2.0, tokens)
+}
+
+func TestDeleteIdleBuckets(t *testing.T) {
+	repo := NewMapRepository()
+	limit := model.RateLimit{Rate: 1, Burst: 1}
+	now := time.Date(2025, 6, 24, 12, 0, 0, 0, time.UTC)
+
+	_, _, _ = repo.TakeToken(context.Background(), "idle", limit, now.Add(-time.Hour))
+	_, _, _ = repo.TakeToken(context.Background(), "active", limit, now)
+
+	deleted, err := repo.DeleteIdleBuckets(context.Background(), now.Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+	assert.Contains(t, repo.buckets, "active")
+	assert.NotContains(t, repo.buckets, "idle")
+}
@@ -2,7 +2,11 @@ package inmemory
 
 import (
 	"context"
+	"errors"
+	"slices"
+	"strings"
 	"sync"
+	"time"
 
 	rep "link-shortener-service/internal/infastracture/repository"
 
@@ -11,14 +15,40 @@ import (
 
 type repository struct {
 	mu        sync.RWMutex
-	shortOrig map[string]string
+	shortOrig map[string]model.URLPair
 	origShort map[string]string
+	// tombstones keeps short codes of deleted and expired pairs so they are never issued again
+	tombstones map[string]struct{}
+	// clicks are kept only until they are added to rollups
+	clicks  []model.Click
+	rollups map[string]*linkRollup
+	// sequence is the last value handed out by NextCodeSequences
+	sequence int64
+	apiKeys  map[string]model.APIKey
+	// workspaces by ID and their IDs by domain
+	workspaces       map[string]model.Workspace
+	domainWorkspaces map[string]string
+	// buckets of the rate limited clients by key
+	buckets map[string]model.TokenBucket
+	// health of the link destinations by short URL
+	health map[string]model.LinkHealth
+	// previews of the link destinations by short URL
+	previews map[string]model.LinkPreview
 }
 
 func NewMapRepository() *repository {
 	return &repository{
-		shortOrig: make(map[string]string),
-		origShort: make(map[string]string),
+		shortOrig:  make(map[string]model.URLPair),
+		origShort:  make(map[string]string),
+		tombstones: make(map[string]struct{}),
+		rollups:    make(map[string]*linkRollup),
+		apiKeys:    make(map[string]model.APIKey),
+
+		workspaces:       make(map[string]model.Workspace),
+		domainWorkspaces: make(map[string]string),
+		buckets:          make(map[string]model.TokenBucket),
+		health:           make(map[string]model.LinkHealth),
+		previews:         make(map[string]model.LinkPreview),
 	}
 }
 
@@ -26,19 +56,40 @@ func (r *repository) PutURLPair(_ context.Context, urlPair model.URLPair) (*mode
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if existingShortened, exists := r.origShort[urlPair.Original]; exists {
-		return &model.URLPair{
-			Original: urlPair.Original,
-			Shorted:  existingShortened,
-		}, rep.ErrOriginalURLExist
+	return r.putURLPair(urlPair)
+}
+
+func (r *repository) PutURLPairs(_ context.Context, urlPairs []model.URLPair) ([]*model.URLPair, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := make([]*model.URLPair, len(urlPairs))
+	for i, urlPair := range urlPairs {
+		stored, err := r.putURLPair(urlPair)
+		if err == nil || errors.Is(err, rep.ErrOriginalURLExist) {
+			result[i] = stored
+		}
+	}
+	return result, nil
+}
+
+func (r *repository) putURLPair(urlPair model.URLPair) (*model.URLPair, error) {
+	if existingShortened, exists := r.origShort[urlPair.DedupKey]; exists && urlPair.DedupKey != "" {
+		existing := r.shortOrig[existingShortened]
+		return &existing, rep.ErrOriginalURLExist
 	}
 
 	if _, exists := r.shortOrig[urlPair.Shorted]; exists {
 		return &model.URLPair{}, rep.ErrShortedURLExist
 	}
+	if _, deleted := r.tombstones[urlPair.Shorted]; deleted {
+		return &model.URLPair{}, rep.ErrShortedURLExist
+	}
 
-	r.origShort[urlPair.Original] = urlPair.Shorted
-	r.shortOrig[urlPair.Shorted] = urlPair.Original
+	if urlPair.DedupKey != "" {
+		r.origShort[urlPair.DedupKey] = urlPair.Shorted
+	}
+	r.shortOrig[urlPair.Shorted] = urlPair
 
 	return &urlPair, nil
 }
@@ -47,22 +98,169 @@ func (r *repository) GetByURL(_ context.Context, typeOfURL, knownURL string) (*m
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	var url string
-	var exists bool
 	switch typeOfURL {
 	case "original_url":
-		url, exists = r.origShort[knownURL]
+		// the deduplicated pair wins over pairs pointed to the same original by hand
+		if shorted, exists := r.origShort[knownURL]; exists {
+			pair := r.shortOrig[shorted]
+			return &pair, nil
+		}
+		for _, pair := range r.shortOrig {
+			if pair.Original == knownURL {
+				return &pair, nil
+			}
+		}
+		return nil, rep.ErrNotFound
+	case "dedup_key":
+		shorted, exists := r.origShort[knownURL]
 		if !exists {
 			return nil, rep.ErrNotFound
 		}
-		return &model.URLPair{Original: knownURL, Shorted: url}, nil
+		pair := r.shortOrig[shorted]
+		return &pair, nil
 	case "shorted_url":
-		url, exists = r.shortOrig[knownURL]
+		pair, exists := r.shortOrig[knownURL]
 		if !exists {
 			return nil, rep.ErrNotFound
 		}
-		return &model.URLPair{Original: url, Shorted: knownURL}, nil
+		return &pair, nil
 	default:
 		return nil, rep.ErrUnknownURLType
 	}
 }
+
+func (r *repository) GetByShortedURLs(_ context.Context, shorted []string) ([]model.URLPair, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var pairs []model.URLPair
+	for _, code := range shorted {
+		if pair, exists := r.shortOrig[code]; exists {
+			pairs = append(pairs, pair)
+		}
+	}
+	return pairs, nil
+}
+
+func (r *repository) UpdateOriginal(_ context.Context, shorted, original string) (*model.URLPair, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	pair, exists := r.shortOrig[shorted]
+	if !exists {
+		return nil, rep.ErrNotFound
+	}
+	// a pair pointed somewhere else by hand is no longer the deduplicated pair of any original URL
+	r.dropDedupKey(pair)
+	pair.Original = original
+	pair.DedupKey = ""
+	r.shortOrig[shorted] = pair
+
+	return &pair, nil
+}
+
+func (r *repository) ConsumeClick(_ context.Context, shorted string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	pair, exists := r.shortOrig[shorted]
+	if !exists || pair.MaxClicks == 0 || pair.IsExhausted() {
+		return rep.ErrClicksExhausted
+	}
+	pair.ClicksUsed++
+	r.shortOrig[shorted] = pair
+
+	return nil
+}
+
+func (r *repository) DeleteURLPair(_ context.Context, shorted string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	pair, exists := r.shortOrig[shorted]
+	if !exists {
+		return rep.ErrNotFound
+	}
+	r.deletePair(pair)
+
+	return nil
+}
+
+func (r *repository) SetDisabled(_ context.Context, shorted string, disabled bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	pair, exists := r.shortOrig[shorted]
+	if !exists {
+		return rep.ErrNotFound
+	}
+	pair.Disabled = disabled
+	r.shortOrig[shorted] = pair
+
+	return nil
+}
+
+func (r *repository) DeleteExpired(_ context.Context, now time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	for _, pair := range r.shortOrig {
+		if pair.IsExpired(now) {
+			r.deletePair(pair)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (r *repository) GetDeduplicated(_ context.Context, after string, limit int) ([]model.URLPair, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var pairs []model.URLPair
+	for _, pair := range r.shortOrig {
+		if pair.DedupKey != "" && pair.Shorted > after {
+			pairs = append(pairs, pair)
+		}
+	}
+	slices.SortFunc(pairs, func(a, b model.URLPair) int {
+		return strings.Compare(a.Shorted, b.Shorted)
+	})
+	if len(pairs) > limit {
+		pairs = pairs[:limit]
+	}
+	return pairs, nil
+}
+
+func (r *repository) SetDedupKey(_ context.Context, shorted, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	pair, exists := r.shortOrig[shorted]
+	if !exists {
+		return rep.ErrNotFound
+	}
+	if owner, taken := r.origShort[key]; taken && owner != shorted {
+		return rep.ErrOriginalURLExist
+	}
+
+	r.dropDedupKey(pair)
+	pair.DedupKey = key
+	r.origShort[key] = shorted
+	r.shortOrig[shorted] = pair
+	return nil
+}
+
+// deletePair keeps the short code as a tombstone, so a printed link never leads to a new destination
+func (r *repository) deletePair(pair model.URLPair) {
+	delete(r.shortOrig, pair.Shorted)
+	r.dropDedupKey(pair)
+	r.tombstones[pair.Shorted] = struct{}{}
+}
+
+func (r *repository) dropDedupKey(pair model.URLPair) {
+	if pair.DedupKey != "" && r.origShort[pair.DedupKey] == pair.Shorted {
+		delete(r.origShort, pair.DedupKey)
+	}
+}
@@ -2,7 +2,9 @@ package inmemory
 
 import (
 	"context"
+	"sync"
 	"testing"
+	"time"
 
 	rep "link-shortener-service/internal/infastracture/repository"
 	"link-shortener-service/internal/model"
@@ -14,6 +16,7 @@ func TestPutURLPair(t *testing.T) {
 	pair := model.URLPair{
 		Original: "https://some.com/",
 		Shorted:  "https://somedomain.su/xHsvC_0NTU",
+		DedupKey: "https://some.com/",
 	}
 
 	tests := []struct {
@@ -31,11 +34,18 @@ func TestPutURLPair(t *testing.T) {
 		},
 		{
 			name:           "add URL with existing original URL",
-			preData:        &model.URLPair{Original: pair.Original},
+			preData:        &model.URLPair{Original: pair.Original, DedupKey: pair.DedupKey},
 			urlPair:        pair,
 			expectedError:  rep.ErrOriginalURLExist,
 			expectedResult: nil,
 		},
+		{
+			name:           "add URL with existing original URL without dedup key",
+			preData:        &model.URLPair{Original: pair.Original, Shorted: "other"},
+			urlPair:        pair,
+			expectedError:  nil,
+			expectedResult: &pair,
+		},
 		{
 			name:           "add URL with existing shortened URL",
 			preData:        &model.URLPair{Shorted: pair.Shorted},
@@ -50,8 +60,10 @@ func TestPutURLPair(t *testing.T) {
 			repo := NewMapRepository()
 
 			if tt.preData != nil {
-				repo.origShort[tt.preData.Original] = tt.preData.Shorted
-				repo.shortOrig[tt.preData.Shorted] = tt.preData.Original
+				if tt.preData.DedupKey != "" {
+					repo.origShort[tt.preData.DedupKey] = tt.preData.Shorted
+				}
+				repo.shortOrig[tt.preData.Shorted] = *tt.preData
 			}
 
 			result, err := repo.PutURLPair(context.Background(), tt.urlPair)
@@ -137,3 +149,207 @@ func TestGetByURL(t *testing.T) {
 		})
 	}
 }
+
+func TestDeleteExpired(t *testing.T) {
+	repo := NewMapRepository()
+	now := time.Now()
+
+	pairs := []model.URLPair{
+		{Original: "https://some.com/1", Shorted: "expired", ExpiresAt: now.Add(-time.Minute)},
+		{Original: "https://some.com/2", Shorted: "expiresNow", ExpiresAt: now},
+		{Original: "https://some.com/3", Shorted: "alive", ExpiresAt: now.Add(time.Minute)},
+		{Original: "https://some.com/4", Shorted: "forever"},
+	}
+	for _, pair := range pairs {
+		_, err := repo.PutURLPair(context.Background(), pair)
+		assert.NoError(t, err)
+	}
+
+	deleted, err := repo.DeleteExpired(context.Background(), now)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+
+	for _, code := range []string{"expired", "expiresNow"} {
+		_, err = repo.GetByURL(context.Background(), "shorted_url", code)
+		assert.Equal(t, rep.ErrNotFound, err)
+	}
+	for _, code := range []string{"alive", "forever"} {
+		_, err = repo.GetByURL(context.Background(), "shorted_url", code)
+		assert.NoError(t, err)
+	}
+	_, err = repo.GetByURL(context.Background(), "original_url", "https://some.com/1")
+	assert.Equal(t, rep.ErrNotFound, err)
+
+	// the codes of expired pairs are never issued again
+	_, err = repo.PutURLPair(context.Background(), model.URLPair{Original: "https://some.com/5", Shorted: "expired"})
+	assert.ErrorIs(t, err, rep.ErrShortedURLExist)
+}
+
+func TestConsumeClick(t *testing.T) {
+	repo := NewMapRepository()
+
+	_, err := repo.PutURLPair(context.Background(), model.URLPair{
+		Original:  "https://some.com/limited",
+		Shorted:   "limited",
+		MaxClicks: 5,
+	})
+	assert.NoError(t, err)
+	_, err = repo.PutURLPair(context.Background(), model.URLPair{
+		Original: "https://some.com/unlimited",
+		Shorted:  "unlimited",
+	})
+	assert.NoError(t, err)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	consumed := 0
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if repo.ConsumeClick(context.Background(), "limited") == nil {
+				mu.Lock()
+				consumed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 5, consumed)
+	assert.Equal(t, rep.ErrClicksExhausted, repo.ConsumeClick(context.Background(), "limited"))
+	assert.Equal(t, rep.ErrClicksExhausted, repo.ConsumeClick(context.Background(), "unlimited"))
+	assert.Equal(t, rep.ErrClicksExhausted, repo.ConsumeClick(context.Background(), "unknown"))
+
+	pair, err := repo.GetByURL(context.Background(), "shorted_url", "limited")
+	assert.NoError(t, err)
+	assert.True(t, pair.IsExhausted())
+}
+
+func TestDeleteURLPair(t *testing.T) {
+	repo := NewMapRepository()
+	pair := model.URLPair{Original: "https://some.com/", Shorted: "xHsvC_0NTU"}
+
+	_, err := repo.PutURLPair(context.Background(), pair)
+	assert.NoError(t, err)
+
+	assert.NoError(t, repo.DeleteURLPair(context.Background(), pair.Shorted))
+	assert.Equal(t, rep.ErrNotFound, repo.DeleteURLPair(context.Background(), pair.Shorted))
+
+	_, err = repo.GetByURL(context.Background(), "shorted_url", pair.Shorted)
+	assert.Equal(t, rep.ErrNotFound, err)
+
+	// deleted short code is never issued again
+	_, err = repo.PutURLPair(context.Background(), model.URLPair{Original: "https://other.com/", Shorted: pair.Shorted})
+	assert.Equal(t, rep.ErrShortedURLExist, err)
+
+	// while the original URL can be shortened again
+	_, err = repo.PutURLPair(context.Background(), model.URLPair{Original: pair.Original, Shorted: "newCode"})
+	assert.NoError(t, err)
+}
+
+func TestSetDisabled(t *testing.T) {
+	repo := NewMapRepository()
+	pair := model.URLPair{Original: "https://some.com/", Shorted: "xHsvC_0NTU"}
+
+	_, err := repo.PutURLPair(context.Background(), pair)
+	assert.NoError(t, err)
+
+	assert.NoError(t, repo.SetDisabled(context.Background(), pair.Shorted, true))
+	result, err := repo.GetByURL(context.Background(), "shorted_url", pair.Shorted)
+	assert.NoError(t, err)
+	assert.True(t, result.Disabled)
+
+	assert.NoError(t, repo.SetDisabled(context.Background(), pair.Shorted, false))
+	result, err = repo.GetByURL(context.Background(), "shorted_url", pair.Shorted)
+	assert.NoError(t, err)
+	assert.False(t, result.Disabled)
+
+	assert.Equal(t, rep.ErrNotFound, repo.SetDisabled(context.Background(), "unknown", true))
+}
+
+func TestUpdateOriginal(t *testing.T) {
+	repo := NewMapRepository()
+	pair := model.URLPair{Original: "https://some.com/", Shorted: "xHsvC_0NTU", DedupKey: "https://some.com/"}
+
+	_, err := repo.PutURLPair(context.Background(), pair)
+	assert.NoError(t, err)
+
+	result, err := repo.UpdateOriginal(context.Background(), pair.Shorted, "https://other.com/")
+	assert.NoError(t, err)
+	assert.Equal(t, &model.URLPair{Original: "https://other.com/", Shorted: pair.Shorted}, result)
+
+	result, err = repo.GetByURL(context.Background(), "shorted_url", pair.Shorted)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://other.com/", result.Original)
+
+	// the old original URL gets a fresh short URL instead of the edited one
+	_, err = repo.GetByURL(context.Background(), "dedup_key", pair.DedupKey)
+	assert.Equal(t, rep.ErrNotFound, err)
+	_, err = repo.PutURLPair(context.Background(), model.URLPair{
+		Original: pair.Original,
+		Shorted:  "newCode",
+		DedupKey: pair.DedupKey,
+	})
+	assert.NoError(t, err)
+
+	_, err = repo.UpdateOriginal(context.Background(), "unknown", "https://other.com/")
+	assert.Equal(t, rep.ErrNotFound, err)
+}
+
+func TestGetByShortedURLs(t *testing.T) {
+	repo := NewMapRepository()
+	pair := model.URLPair{Original: "https://some.com/", Shorted: "xHsvC_0NTU"}
+
+	_, err := repo.PutURLPair(context.Background(), pair)
+	assert.NoError(t, err)
+
+	result, err := repo.GetByShortedURLs(context.Background(), []string{"unknown", pair.Shorted})
+	assert.NoError(t, err)
+	assert.Equal(t, []model.URLPair{pair}, result)
+}
+
+func TestPutURLPairs(t *testing.T) {
+	repo := NewMapRepository()
+	existing := model.URLPair{Original: "https://some.com/3", Shorted: "existing", DedupKey: "https://some.com/3"}
+	_, err := repo.PutURLPair(context.Background(), existing)
+	assert.NoError(t, err)
+
+	pairs := []model.URLPair{
+		{Original: "https://some.com/1", Shorted: "first", DedupKey: "https://some.com/1"},
+		{Original: "https://some.com/2", Shorted: "existing"},
+		{Original: "https://some.com/3", Shorted: "third", DedupKey: "https://some.com/3"},
+		{Original: "https://some.com/1", Shorted: "again", DedupKey: "https://some.com/1"},
+	}
+	result, err := repo.PutURLPairs(context.Background(), pairs)
+	assert.NoError(t, err)
+	assert.Equal(t, []*model.URLPair{&pairs[0], nil, &existing, &pairs[0]}, result)
+}
+
+func TestSetDedupKey(t *testing.T) {
+	repo := NewMapRepository()
+	old := model.URLPair{Original: "HTTPS://Some.com/", Shorted: "old", DedupKey: "HTTPS://Some.com/"}
+	other := model.URLPair{Original: "https://other.com/", Shorted: "other", DedupKey: "https://other.com/"}
+	plain := model.URLPair{Original: "https://plain.com/", Shorted: "plain"}
+	for _, pair := range []model.URLPair{old, other, plain} {
+		_, err := repo.PutURLPair(context.Background(), pair)
+		assert.NoError(t, err)
+	}
+
+	pairs, err := repo.GetDeduplicated(context.Background(), "", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.URLPair{old}, pairs)
+	pairs, err = repo.GetDeduplicated(context.Background(), "old", 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.URLPair{other}, pairs)
+
+	assert.NoError(t, repo.SetDedupKey(context.Background(), "old", "https://some.com/"))
+	result, err := repo.GetByURL(context.Background(), "dedup_key", "https://some.com/")
+	assert.NoError(t, err)
+	assert.Equal(t, "old", result.Shorted)
+	_, err = repo.GetByURL(context.Background(), "dedup_key", "HTTPS://Some.com/")
+	assert.Equal(t, rep.ErrNotFound, err)
+
+	assert.Equal(t, rep.ErrOriginalURLExist, repo.SetDedupKey(context.Background(), "other", "https://some.com/"))
+	assert.Equal(t, rep.ErrNotFound, repo.SetDedupKey(context.Background(), "unknown", "https://some.com/"))
+}
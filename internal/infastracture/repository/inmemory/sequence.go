@@ -0,0 +1,18 @@
+package inmemory
+
+import (
+	"context"
+)
+
+func (r *repository) NextCodeSequences(_ context.Context, n int) ([]int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	values := make([]int64, n)
+	for i := range values {
+		r.sequence++
+		values[i] = r.sequence
+	}
+
+	return values, nil
+}
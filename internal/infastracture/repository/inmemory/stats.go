@@ -0,0 +1,142 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	"link-shortener-service/internal/model"
+)
+
+type dimensionValue struct {
+	dimension string
+	value     string
+}
+
+// linkRollup keeps the clicks of a link in hourly buckets
+type linkRollup struct {
+	clicks     map[time.Time]int64
+	visitors   map[time.Time]map[string]struct{}
+	dimensions map[time.Time]map[dimensionValue]int64
+}
+
+func newLinkRollup() *linkRollup {
+	return &linkRollup{
+		clicks:     make(map[time.Time]int64),
+		visitors:   make(map[time.Time]map[string]struct{}),
+		dimensions: make(map[time.Time]map[dimensionValue]int64),
+	}
+}
+
+func (r *repository) RollupClicks(_ context.Context, until time.Time, limit int) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var rolledUp int
+	for rolledUp < len(r.clicks) && rolledUp < limit {
+		click := r.clicks[rolledUp]
+		if !click.At.Before(until) {
+			break
+		}
+		r.rollup(click)
+		rolledUp++
+	}
+	r.clicks = append(r.clicks[:0], r.clicks[rolledUp:]...)
+
+	return int64(rolledUp), nil
+}
+
+func (r *repository) rollup(click model.Click) {
+	rollup, exists := r.rollups[click.Code]
+	if !exists {
+		rollup = newLinkRollup()
+		r.rollups[click.Code] = rollup
+	}
+	bucket := click.At.UTC().Truncate(time.Hour)
+
+	rollup.clicks[bucket]++
+
+	if rollup.visitors[bucket] == nil {
+		rollup.visitors[bucket] = make(map[string]struct{})
+	}
+	rollup.visitors[bucket][click.IP+"|"+click.UserAgent] = struct{}{}
+
+	if rollup.dimensions[bucket] == nil {
+		rollup.dimensions[bucket] = make(map[dimensionValue]int64)
+	}
+	rollup.dimensions[bucket][dimensionValue{rep.DimensionReferrer, rep.ReferrerHost(click.Referrer)}]++
+	rollup.dimensions[bucket][dimensionValue{rep.DimensionCountry, click.Country}]++
+	rollup.dimensions[bucket][dimensionValue{rep.DimensionUserAgent, model.UserAgentFamily(click.UserAgent)}]++
+	rollup.dimensions[bucket][dimensionValue{rep.DimensionVariant, click.Variant}]++
+}
+
+func (r *repository) GetClickStats(_ context.Context, code string, from, to time.Time, top int) (*model.ClickStats, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stats := &model.ClickStats{}
+	rollup, exists := r.rollups[code]
+	if !exists {
+		return stats, nil
+	}
+	inRange := func(bucket time.Time) bool {
+		return !bucket.Before(from) && bucket.Before(to)
+	}
+
+	for bucket, clicks := range rollup.clicks {
+		if inRange(bucket) {
+			stats.Total += clicks
+			stats.Buckets = append(stats.Buckets, model.ClickBucket{Start: bucket, Clicks: clicks})
+		}
+	}
+	sort.Slice(stats.Buckets, func(i, j int) bool {
+		return stats.Buckets[i].Start.Before(stats.Buckets[j].Start)
+	})
+
+	visitors := make(map[string]struct{})
+	for bucket, bucketVisitors := range rollup.visitors {
+		if inRange(bucket) {
+			for visitor := range bucketVisitors {
+				visitors[visitor] = struct{}{}
+			}
+		}
+	}
+	stats.UniqueVisitors = int64(len(visitors))
+
+	dimensions := make(map[dimensionValue]int64)
+	for bucket, counters := range rollup.dimensions {
+		if inRange(bucket) {
+			for dv, clicks := range counters {
+				dimensions[dv] += clicks
+			}
+		}
+	}
+	stats.Referrers = topCounters(dimensions, rep.DimensionReferrer, top)
+	stats.Countries = topCounters(dimensions, rep.DimensionCountry, top)
+	stats.UserAgents = topCounters(dimensions, rep.DimensionUserAgent, top)
+	// every variant is reported, a link has a few of them
+	stats.Variants = topCounters(dimensions, rep.DimensionVariant, len(dimensions))
+
+	return stats, nil
+}
+
+// topCounters leaves out empty values like direct visits and unknown countries
+func topCounters(dimensions map[dimensionValue]int64, dimension string, top int) []model.Counter {
+	var counters []model.Counter
+	for dv, clicks := range dimensions {
+		if dv.dimension == dimension && dv.value != "" {
+			counters = append(counters, model.Counter{Value: dv.value, Clicks: clicks})
+		}
+	}
+	sort.Slice(counters, func(i, j int) bool {
+		if counters[i].Clicks != counters[j].Clicks {
+			return counters[i].Clicks > counters[j].Clicks
+		}
+		return counters[i].Value < counters[j].Value
+	})
+	if len(counters) > top {
+		counters = counters[:top]
+	}
+	return counters
+}
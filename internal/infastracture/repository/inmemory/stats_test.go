@@ -0,0 +1,84 @@
+package inmemory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"link-shortener-service/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveClicksDropsOldest(t *testing.T) {
+	repo := NewMapRepository()
+	start := time.Date(2025, 5, 7, 10, 0, 0, 0, time.UTC)
+
+	clicks := make([]model.Click, maxPendingClicks+2)
+	for i := range clicks {
+		clicks[i] = model.Click{Code: "xHsvC_0NTU", At: start.Add(time.Duration(i) * time.Millisecond)}
+	}
+	require.NoError(t, repo.SaveClicks(context.Background(), clicks[:2]))
+	require.NoError(t, repo.SaveClicks(context.Background(), clicks[2:]))
+
+	require.Len(t, repo.clicks, maxPendingClicks)
+	assert.Equal(t, clicks[2].At, repo.clicks[0].At)
+	assert.Equal(t, clicks[len(clicks)-1].At, repo.clicks[maxPendingClicks-1].At)
+}
+
+func TestClickStats(t *testing.T) {
+	repo := NewMapRepository()
+	hour := time.Date(2025, 5, 7, 10, 0, 0, 0, time.UTC)
+
+	chrome := model.Visitor{
+		Referrer:  "https://Ref.com/page",
+		UserAgent: "Mozilla/5.0 Chrome/124.0 Safari/537.36",
+		IP:        "10.0.0.1",
+		Country:   "DE",
+	}
+	curl := model.Visitor{UserAgent: "curl/8.0", IP: "10.0.0.2"}
+	clicks := []model.Click{
+		{Code: "xHsvC_0NTU", At: hour.Add(5 * time.Minute), Visitor: chrome},
+		{Code: "xHsvC_0NTU", At: hour.Add(10 * time.Minute), Variant: "b", Visitor: chrome},
+		{Code: "xHsvC_0NTU", At: hour.Add(70 * time.Minute), Visitor: curl},
+		{Code: "other", At: hour.Add(80 * time.Minute), Visitor: curl},
+		{Code: "xHsvC_0NTU", At: hour.Add(3 * time.Hour), Visitor: curl},
+	}
+	require.NoError(t, repo.SaveClicks(context.Background(), clicks))
+
+	// the last click is too recent to be rolled up
+	rolledUp, err := repo.RollupClicks(context.Background(), hour.Add(2*time.Hour), 3)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), rolledUp)
+	rolledUp, err = repo.RollupClicks(context.Background(), hour.Add(2*time.Hour), 3)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), rolledUp)
+	// rolled up clicks are not kept
+	assert.Len(t, repo.clicks, 1)
+
+	stats, err := repo.GetClickStats(context.Background(), "xHsvC_0NTU", hour, hour.Add(24*time.Hour), 10)
+	require.NoError(t, err)
+	assert.Equal(t, &model.ClickStats{
+		Total:          3,
+		UniqueVisitors: 2,
+		Buckets: []model.ClickBucket{
+			{Start: hour, Clicks: 2},
+			{Start: hour.Add(time.Hour), Clicks: 1},
+		},
+		Referrers:  []model.Counter{{Value: "ref.com", Clicks: 2}},
+		Countries:  []model.Counter{{Value: "DE", Clicks: 2}},
+		UserAgents: []model.Counter{{Value: "Chrome", Clicks: 2}, {Value: "curl", Clicks: 1}},
+		Variants:   []model.Counter{{Value: "b", Clicks: 1}},
+	}, stats)
+
+	stats, err = repo.GetClickStats(context.Background(), "xHsvC_0NTU", hour.Add(time.Hour), hour.Add(24*time.Hour), 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats.Total)
+	assert.Equal(t, []model.Counter{{Value: "curl", Clicks: 1}}, stats.UserAgents)
+	assert.Empty(t, stats.Referrers)
+
+	stats, err = repo.GetClickStats(context.Background(), "unknown", hour, hour.Add(24*time.Hour), 10)
+	require.NoError(t, err)
+	assert.Equal(t, &model.ClickStats{}, stats)
+}
@@ -0,0 +1,61 @@
+package inmemory
+
+import (
+	"context"
+	"fmt"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	"link-shortener-service/internal/model"
+)
+
+func (r *repository) PutWorkspace(_ context.Context, workspace model.Workspace) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.workspaces[workspace.ID]; exists {
+		return fmt.Errorf("%w: %s", rep.ErrWorkspaceExist, workspace.ID)
+	}
+	for _, domain := range workspace.Domains {
+		if _, exists := r.domainWorkspaces[domain]; exists {
+			return fmt.Errorf("%w: %s", rep.ErrDomainExist, domain)
+		}
+	}
+
+	workspace.Domains = append([]string(nil), workspace.Domains...)
+	r.workspaces[workspace.ID] = workspace
+	for _, domain := range workspace.Domains {
+		r.domainWorkspaces[domain] = workspace.ID
+	}
+
+	return nil
+}
+
+func (r *repository) AddWorkspaceDomain(_ context.Context, workspaceID, domain string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	workspace, exists := r.workspaces[workspaceID]
+	if !exists {
+		return fmt.Errorf("%w: workspace %s", rep.ErrNotFound, workspaceID)
+	}
+	if _, exists = r.domainWorkspaces[domain]; exists {
+		return fmt.Errorf("%w: %s", rep.ErrDomainExist, domain)
+	}
+
+	workspace.Domains = append(workspace.Domains, domain)
+	r.workspaces[workspaceID] = workspace
+	r.domainWorkspaces[domain] = workspaceID
+
+	return nil
+}
+
+func (r *repository) GetDomainWorkspace(_ context.Context, domain string) (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	workspaceID, exists := r.domainWorkspaces[domain]
+	if !exists {
+		return "", fmt.Errorf("%w: domain %s", rep.ErrNotFound, domain)
+	}
+	return workspaceID, nil
+}
@@ -0,0 +1,32 @@
+package inmemory
+
+import (
+	"context"
+	"testing"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	"link-shortener-service/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkspaces(t *testing.T) {
+	repo := NewMapRepository()
+	ctx := context.Background()
+
+	assert.NoError(t, repo.PutWorkspace(ctx, model.Workspace{ID: "acme", Domains: []string{"go.acme.io"}}))
+	assert.ErrorIs(t, repo.PutWorkspace(ctx, model.Workspace{ID: "acme"}), rep.ErrWorkspaceExist)
+	assert.ErrorIs(t, repo.PutWorkspace(ctx, model.Workspace{ID: "other", Domains: []string{"go.acme.io"}}), rep.ErrDomainExist)
+
+	assert.NoError(t, repo.AddWorkspaceDomain(ctx, "acme", "acme.link"))
+	assert.ErrorIs(t, repo.AddWorkspaceDomain(ctx, "acme", "acme.link"), rep.ErrDomainExist)
+	assert.ErrorIs(t, repo.AddWorkspaceDomain(ctx, "unknown", "unknown.link"), rep.ErrNotFound)
+
+	for _, domain := range []string{"go.acme.io", "acme.link"} {
+		workspaceID, err := repo.GetDomainWorkspace(ctx, domain)
+		assert.NoError(t, err)
+		assert.Equal(t, "acme", workspaceID)
+	}
+	_, err := repo.GetDomainWorkspace(ctx, "unknown.link")
+	assert.ErrorIs(t, err, rep.ErrNotFound)
+}
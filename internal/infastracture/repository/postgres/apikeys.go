@@ -0,0 +1,116 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	"link-shortener-service/internal/model"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	apiKeysTableName = "api_keys"
+)
+
+var apiKeyColumns = []string{"id", "owner_id", "name", "key_hash", "scopes", "created_at", "revoked_at"}
+
+type apiKeyRow struct {
+	ID        string     `db:"id"`
+	OwnerID   string     `db:"owner_id"`
+	Name      string     `db:"name"`
+	KeyHash   string     `db:"key_hash"`
+	Scopes    []string   `db:"scopes"`
+	CreatedAt time.Time  `db:"created_at"`
+	RevokedAt *time.Time `db:"revoked_at"`
+}
+
+func (r *repository) PutAPIKey(ctx context.Context, key model.APIKey) error {
+	scopes := make([]string, len(key.Scopes))
+	for i, scope := range key.Scopes {
+		scopes[i] = string(scope)
+	}
+
+	queryBuilder := squirrel.Insert(apiKeysTableName).
+		PlaceholderFormat(squirrel.Dollar).
+		Columns(apiKeyColumns...).
+		Values(key.ID, key.OwnerID, key.Name, key.Hash, scopes, key.CreatedAt, nullTime(key.RevokedAt))
+
+	sql, args, err := queryBuilder.ToSql()
+	if err != nil {
+		return fmt.Errorf("%w: %v", rep.ErrBuildQuery, err)
+	}
+
+	if _, err = r.db.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("%w: %v", rep.ErrExecuteQuery, err)
+	}
+
+	return nil
+}
+
+func (r *repository) GetAPIKey(ctx context.Context, id string) (*model.APIKey, error) {
+	queryBuilder := squirrel.Select(apiKeyColumns...).
+		PlaceholderFormat(squirrel.Dollar).
+		From(apiKeysTableName).
+		Where(squirrel.Eq{"id": id})
+
+	sql, args, err := queryBuilder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", rep.ErrBuildQuery, err)
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", rep.ErrExecuteQuery, err)
+	}
+	defer rows.Close()
+
+	result, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[apiKeyRow])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, rep.ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", rep.ErrScanResult, err)
+	}
+
+	scopes := make([]model.Scope, len(result.Scopes))
+	for i, scope := range result.Scopes {
+		scopes[i] = model.Scope(scope)
+	}
+	return &model.APIKey{
+		ID:        result.ID,
+		OwnerID:   result.OwnerID,
+		Name:      result.Name,
+		Hash:      result.KeyHash,
+		Scopes:    scopes,
+		CreatedAt: result.CreatedAt,
+		RevokedAt: timeOrZero(result.RevokedAt),
+	}, nil
+}
+
+func (r *repository) RevokeAPIKey(ctx context.Context, id string, at time.Time) error {
+	// revoking twice keeps the first revocation time
+	queryBuilder := squirrel.Update(apiKeysTableName).
+		PlaceholderFormat(squirrel.Dollar).
+		Set("revoked_at", squirrel.Expr("COALESCE(revoked_at, ?)", at)).
+		Where(squirrel.Eq{"id": id})
+
+	sql, args, err := queryBuilder.ToSql()
+	if err != nil {
+		return fmt.Errorf("%w: %v", rep.ErrBuildQuery, err)
+	}
+
+	tag, err := r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("%w: %v", rep.ErrExecuteQuery, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return rep.ErrNotFound
+	}
+
+	return nil
+}
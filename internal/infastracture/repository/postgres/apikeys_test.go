@@ -0,0 +1,132 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	mockdb "link-shortener-service/internal/infastracture/repository/postgres/mocks"
+	"link-shortener-service/internal/model"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	createdAt := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		setupMock     func(*mockdb.MockDBQuery)
+		expected      *model.APIKey
+		expectedError error
+	}{
+		{
+			name: "key is found",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Query(gomock.Any(),
+						"SELECT id, owner_id, name, key_hash, scopes, created_at, revoked_at FROM api_keys WHERE id = $1",
+						"k1").
+					Return(pgxmock.NewRows(apiKeyColumns).
+						AddRow("k1", "team-a", "ci", "hash", []string{"read", "write"}, createdAt, (*time.Time)(nil)).
+						Kind(), nil)
+			},
+			expected: &model.APIKey{
+				ID:        "k1",
+				OwnerID:   "team-a",
+				Name:      "ci",
+				Hash:      "hash",
+				Scopes:    []model.Scope{model.ScopeRead, model.ScopeWrite},
+				CreatedAt: createdAt,
+			},
+		},
+		{
+			name: "unknown key",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Query(gomock.Any(), gomock.Any(), "k1").
+					Return(pgxmock.NewRows(apiKeyColumns).Kind(), nil)
+			},
+			expectedError: rep.ErrNotFound,
+		},
+		{
+			name: "error db - execute error",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Query(gomock.Any(), gomock.Any(), "k1").
+					Return(nil, errors.New("db is down"))
+			},
+			expectedError: rep.ErrExecuteQuery,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mockdb.NewMockDBQuery(ctrl)
+			repo := &repository{db: mockDB}
+
+			tt.setupMock(mockDB)
+
+			result, err := repo.GetAPIKey(context.Background(), "k1")
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Now()
+
+	tests := []struct {
+		name          string
+		setupMock     func(*mockdb.MockDBQuery)
+		expectedError error
+	}{
+		{
+			name: "key is revoked",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Exec(gomock.Any(), "UPDATE api_keys SET revoked_at = COALESCE(revoked_at, $1) WHERE id = $2", now, "k1").
+					Return(pgconn.NewCommandTag("UPDATE 1"), nil)
+			},
+		},
+		{
+			name: "unknown key",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Exec(gomock.Any(), gomock.Any(), now, "k1").
+					Return(pgconn.NewCommandTag("UPDATE 0"), nil)
+			},
+			expectedError: rep.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mockdb.NewMockDBQuery(ctrl)
+			repo := &repository{db: mockDB}
+
+			tt.setupMock(mockDB)
+
+			err := repo.RevokeAPIKey(context.Background(), "k1", now)
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	"link-shortener-service/internal/model"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// putURLPairsSQL inserts the pairs passed as column arrays in a single statement, so the batch
+// is atomic and the number of parameters doesn't grow with it. Pairs conflicting with an existing
+// short URL or dedup key are skipped, the existing pairs with the same dedup keys are returned
+// along with the inserted ones; the second select sees the table as it was before the insert.
+// The split targets of the inserted pairs are inserted along with them, pairs with targets are never deduplicated,
+// so no targets are selected
+var putURLPairsSQL = fmt.Sprintf(`
+WITH input AS (
+    SELECT * FROM unnest($1::text[], $2::text[], $3::int[], $4::timestamptz[], $5::int[], $6::int[],
+                         $7::text[], $8::bool[], $9::text[], $10::text[], $11::jsonb[], $12::bool[],
+                         $13::jsonb[])
+        AS t (%[1]s, targets)
+), inserted AS (
+    INSERT INTO urls (%[1]s)
+    SELECT %[1]s FROM input
+    ON CONFLICT DO NOTHING
+    RETURNING %[1]s
+), split_targets AS (
+    INSERT INTO link_targets (shorted_url, ordinal, variant, url, weight)
+    SELECT inserted.shorted_url, t.ordinal, t.variant, t.url, t.weight
+    FROM inserted JOIN input USING (shorted_url, original_url)
+    CROSS JOIN LATERAL jsonb_to_recordset(input.targets) AS t (ordinal INT, variant TEXT, url TEXT, weight INT)
+    ON CONFLICT DO NOTHING
+)
+SELECT %[1]s, NULL::jsonb AS targets, TRUE AS inserted FROM inserted
+UNION ALL
+SELECT %[1]s, NULL::jsonb AS targets, FALSE AS inserted FROM urls
+WHERE deleted_at IS NULL AND dedup_key IN (SELECT dedup_key FROM input WHERE dedup_key IS NOT NULL)`,
+	strings.Join(urlColumns, ", "))
+
+type batchRow struct {
+	urlRow
+	Inserted bool `db:"inserted"`
+}
+
+func (r *repository) PutURLPairs(ctx context.Context, urlPairs []model.URLPair) ([]*model.URLPair, error) {
+	var (
+		originals      = make([]string, len(urlPairs))
+		shorted        = make([]string, len(urlPairs))
+		redirects      = make([]int, len(urlPairs))
+		expires        = make([]*time.Time, len(urlPairs))
+		maxClicks      = make([]int, len(urlPairs))
+		clicksUsed     = make([]int, len(urlPairs))
+		passwordHashes = make([]string, len(urlPairs))
+		disabled       = make([]bool, len(urlPairs))
+		dedupKeys      = make([]*string, len(urlPairs))
+		ownerIDs       = make([]*string, len(urlPairs))
+		rules          = make([]*string, len(urlPairs))
+		interstitials  = make([]bool, len(urlPairs))
+		targets        = make([]*string, len(urlPairs))
+	)
+	for i, pair := range urlPairs {
+		originals[i] = pair.Original
+		shorted[i] = pair.Shorted
+		redirects[i] = pair.RedirectStatus
+		expires[i] = nullTime(pair.ExpiresAt)
+		maxClicks[i] = pair.MaxClicks
+		clicksUsed[i] = pair.ClicksUsed
+		passwordHashes[i] = pair.PasswordHash
+		disabled[i] = pair.Disabled
+		dedupKeys[i] = nullString(pair.DedupKey)
+		ownerIDs[i] = nullString(pair.OwnerID)
+		rules[i] = rulesJSON(pair.Rules)
+		interstitials[i] = pair.Interstitial
+		targets[i] = targetsJSON(pair.Targets)
+	}
+
+	rows, err := r.db.Query(ctx, putURLPairsSQL,
+		originals, shorted, redirects, expires, maxClicks, clicksUsed, passwordHashes, disabled, dedupKeys,
+		ownerIDs, rules, interstitials, targets)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", rep.ErrExecuteQuery, err)
+	}
+	defer rows.Close()
+
+	stored, err := pgx.CollectRows(rows, pgx.RowToStructByName[batchRow])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", rep.ErrScanResult, err)
+	}
+
+	inserted := make(map[string]*model.URLPair)
+	byDedupKey := make(map[string]*model.URLPair)
+	for _, row := range stored {
+		pair := row.toModel()
+		if row.Inserted {
+			inserted[pair.Shorted] = pair
+		}
+		if pair.DedupKey != "" {
+			byDedupKey[pair.DedupKey] = pair
+		}
+	}
+
+	result := make([]*model.URLPair, len(urlPairs))
+	for i, pair := range urlPairs {
+		// the same short URL may be requested twice in a batch, only the first one is inserted
+		if stored, ok := inserted[pair.Shorted]; ok && stored.Original == pair.Original {
+			stored.Targets = pair.Targets
+			result[i] = stored
+			delete(inserted, pair.Shorted)
+			continue
+		}
+		if pair.DedupKey != "" {
+			result[i] = byDedupKey[pair.DedupKey]
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	mockdb "link-shortener-service/internal/infastracture/repository/postgres/mocks"
+	"link-shortener-service/internal/model"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPutURLPairs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pairs := []model.URLPair{
+		{Original: "https://some.com/1", Shorted: "first", DedupKey: "https://some.com/1"},
+		{Original: "https://some.com/2", Shorted: "taken"},
+		{Original: "https://some.com/3", Shorted: "third", DedupKey: "https://some.com/3"},
+		{Original: "https://some.com/1", Shorted: "again", DedupKey: "https://some.com/1"},
+	}
+	batchRows := func() *pgxmock.Rows {
+		rows := pgxmock.NewRows(append(urlColumns[:len(urlColumns):len(urlColumns)], targetsColumnName, "inserted"))
+		for _, row := range []batchRow{
+			{urlRow: urlRow{OriginalURL: "https://some.com/1", ShortedURL: "first", DedupKey: nullString("https://some.com/1")}, Inserted: true},
+			{urlRow: urlRow{OriginalURL: "https://some.com/3", ShortedURL: "existing", DedupKey: nullString("https://some.com/3")}},
+		} {
+			rows.AddRow(row.OriginalURL, row.ShortedURL, row.RedirectStatus, row.ExpiresAt, row.MaxClicks,
+				row.ClicksUsed, row.PasswordHash, row.Disabled, row.DedupKey, row.OwnerID, row.RedirectRules, row.Interstitial, row.Targets, row.Inserted)
+		}
+		return rows
+	}
+
+	tests := []struct {
+		name          string
+		setupMock     func(*mockdb.MockDBQuery)
+		expected      []*model.URLPair
+		expectedError error
+	}{
+		{
+			name: "pairs are inserted or matched with existing ones",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Query(gomock.Any(), putURLPairsSQL,
+						[]string{"https://some.com/1", "https://some.com/2", "https://some.com/3", "https://some.com/1"},
+						[]string{"first", "taken", "third", "again"},
+						[]int{0, 0, 0, 0},
+						gomock.Len(4),
+						[]int{0, 0, 0, 0},
+						[]int{0, 0, 0, 0},
+						[]string{"", "", "", ""},
+						[]bool{false, false, false, false},
+						gomock.Len(4),
+						gomock.Len(4),
+						gomock.Len(4),
+						[]bool{false, false, false, false},
+						gomock.Len(4)).
+					Return(batchRows().Kind(), nil)
+			},
+			expected: []*model.URLPair{
+				{Original: "https://some.com/1", Shorted: "first", DedupKey: "https://some.com/1"},
+				nil,
+				{Original: "https://some.com/3", Shorted: "existing", DedupKey: "https://some.com/3"},
+				{Original: "https://some.com/1", Shorted: "first", DedupKey: "https://some.com/1"},
+			},
+		},
+		{
+			name: "error db - execute error",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Query(gomock.Any(), putURLPairsSQL, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
+						gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
+						gomock.Any()).
+					Return(nil, errors.New("db is down"))
+			},
+			expectedError: rep.ErrExecuteQuery,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mockdb.NewMockDBQuery(ctrl)
+			repo := &repository{db: mockDB}
+
+			tt.setupMock(mockDB)
+
+			result, err := repo.PutURLPairs(context.Background(), pairs)
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	"link-shortener-service/internal/model"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	clicksTableName = "clicks"
+)
+
+var clickColumns = []string{
+	"code", "clicked_at", "referrer", "user_agent", "ip", "accept_language", "country", "ua_family", "referrer_host", "variant",
+}
+
+func (r *repository) SaveClicks(ctx context.Context, clicks []model.Click) error {
+	// COPY is much cheaper than a multi-row INSERT for the batches the click writer flushes
+	_, err := r.db.CopyFrom(ctx, pgx.Identifier{clicksTableName}, clickColumns,
+		pgx.CopyFromSlice(len(clicks), func(i int) ([]any, error) {
+			click := clicks[i]
+			return []any{
+				click.Code,
+				click.At,
+				click.Referrer,
+				click.UserAgent,
+				click.IP,
+				click.AcceptLanguage,
+				click.Country,
+				model.UserAgentFamily(click.UserAgent),
+				rep.ReferrerHost(click.Referrer),
+				click.Variant,
+			}, nil
+		}),
+	)
+	if err != nil {
+		return fmt.Errorf("%w: %v", rep.ErrExecuteQuery, err)
+	}
+
+	return nil
+}
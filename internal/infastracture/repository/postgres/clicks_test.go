@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	mockdb "link-shortener-service/internal/infastracture/repository/postgres/mocks"
+	"link-shortener-service/internal/model"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveClicks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Now()
+	clicks := []model.Click{
+		{
+			Code:    "xHsvC_0NTU",
+			At:      now,
+			Variant: "b",
+			Visitor: model.Visitor{
+				Referrer:       "https://ref.com/",
+				UserAgent:      "curl/8.0",
+				IP:             "10.0.0.1",
+				AcceptLanguage: "en-US",
+				Country:        "US",
+			},
+		},
+		{Code: "spring-sale", At: now},
+	}
+
+	tests := []struct {
+		name          string
+		setupMock     func(*mockdb.MockDBQuery)
+		expectedError error
+	}{
+		{
+			name: "clicks are copied",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					CopyFrom(gomock.Any(), pgx.Identifier{"clicks"}, clickColumns, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ pgx.Identifier, _ []string, src pgx.CopyFromSource) (int64, error) {
+						var rows [][]any
+						for src.Next() {
+							values, err := src.Values()
+							if err != nil {
+								return 0, err
+							}
+							rows = append(rows, values)
+						}
+						assert.Equal(t, [][]any{
+							{"xHsvC_0NTU", now, "https://ref.com/", "curl/8.0", "10.0.0.1", "en-US", "US", "curl", "ref.com", "b"},
+							{"spring-sale", now, "", "", "", "", "", "Other", "", ""},
+						}, rows)
+						return int64(len(rows)), nil
+					})
+			},
+		},
+		{
+			name: "error db - copy error",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					CopyFrom(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(int64(0), errors.New("db is down"))
+			},
+			expectedError: rep.ErrExecuteQuery,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mockdb.NewMockDBQuery(ctrl)
+			repo := &repository{db: mockDB}
+
+			tt.setupMock(mockDB)
+
+			err := repo.SaveClicks(context.Background(), clicks)
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	"link-shortener-service/internal/model"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// healthColumns are the link and its last health, links never checked get zero values
+const healthColumns = `
+    u.shorted_url, u.original_url, COALESCE(u.owner_id, '') AS owner_id,
+    COALESCE(h.status, 0) AS status, COALESCE(h.error, '') AS error,
+    COALESCE(h.response_time_ms, 0) AS response_time_ms, COALESCE(h.redirects, '{}') AS redirects,
+    h.checked_at, COALESCE(h.failures, 0) AS failures, h.next_check_at`
+
+var getLinksToCheckSQL = fmt.Sprintf(`
+SELECT %s
+FROM urls u LEFT JOIN link_health h ON h.shorted_url = u.shorted_url
+WHERE u.deleted_at IS NULL AND NOT u.disabled AND (u.expires_at IS NULL OR u.expires_at > $1)
+  AND (h.next_check_at IS NULL OR h.next_check_at <= $1)
+ORDER BY h.next_check_at NULLS FIRST
+LIMIT $2`, healthColumns)
+
+var getBrokenLinksSQL = fmt.Sprintf(`
+SELECT %s
+FROM link_health h JOIN urls u ON u.shorted_url = h.shorted_url
+WHERE h.broken AND u.deleted_at IS NULL AND NOT u.disabled AND ($1 = '' OR u.owner_id = $1)
+ORDER BY h.checked_at DESC
+LIMIT $2`, healthColumns)
+
+// saveLinkHealthSQL upserts the checks passed as column arrays, redirect chains are passed
+// as newline separated text since arrays of arrays are flattened by unnest
+const saveLinkHealthSQL = `
+INSERT INTO link_health (shorted_url, status, error, response_time_ms, redirects, broken, checked_at, failures, next_check_at)
+SELECT shorted_url, status, error, response_time_ms, string_to_array(redirects, E'\n'), broken, checked_at, failures, next_check_at
+FROM unnest($1::text[], $2::int[], $3::text[], $4::int[], $5::text[], $6::bool[], $7::timestamptz[], $8::int[], $9::timestamptz[])
+    AS t (shorted_url, status, error, response_time_ms, redirects, broken, checked_at, failures, next_check_at)
+ON CONFLICT (shorted_url) DO UPDATE
+SET status = EXCLUDED.status,
+    error = EXCLUDED.error,
+    response_time_ms = EXCLUDED.response_time_ms,
+    redirects = EXCLUDED.redirects,
+    broken = EXCLUDED.broken,
+    checked_at = EXCLUDED.checked_at,
+    failures = EXCLUDED.failures,
+    next_check_at = EXCLUDED.next_check_at`
+
+type healthRow struct {
+	ShortedURL     string     `db:"shorted_url"`
+	OriginalURL    string     `db:"original_url"`
+	OwnerID        string     `db:"owner_id"`
+	Status         int        `db:"status"`
+	Error          string     `db:"error"`
+	ResponseTimeMS int64      `db:"response_time_ms"`
+	Redirects      []string   `db:"redirects"`
+	CheckedAt      *time.Time `db:"checked_at"`
+	Failures       int        `db:"failures"`
+	NextCheckAt    *time.Time `db:"next_check_at"`
+}
+
+func (row healthRow) toModel() model.LinkHealth {
+	return model.LinkHealth{
+		Shorted:  row.ShortedURL,
+		Original: row.OriginalURL,
+		OwnerID:  row.OwnerID,
+		LinkCheck: model.LinkCheck{
+			Status:       row.Status,
+			Error:        row.Error,
+			ResponseTime: time.Duration(row.ResponseTimeMS) * time.Millisecond,
+			Redirects:    row.Redirects,
+		},
+		CheckedAt:   timeOrZero(row.CheckedAt),
+		Failures:    row.Failures,
+		NextCheckAt: timeOrZero(row.NextCheckAt),
+	}
+}
+
+func (r *repository) GetLinksToCheck(ctx context.Context, now time.Time, limit int) ([]model.LinkHealth, error) {
+	return r.queryHealth(ctx, getLinksToCheckSQL, now, limit)
+}
+
+func (r *repository) GetBrokenLinks(ctx context.Context, ownerID string, limit int) ([]model.LinkHealth, error) {
+	return r.queryHealth(ctx, getBrokenLinksSQL, ownerID, limit)
+}
+
+func (r *repository) queryHealth(ctx context.Context, sql string, args ...any) ([]model.LinkHealth, error) {
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", rep.ErrExecuteQuery, err)
+	}
+	defer rows.Close()
+
+	result, err := pgx.CollectRows(rows, pgx.RowToStructByName[healthRow])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", rep.ErrScanResult, err)
+	}
+
+	health := make([]model.LinkHealth, len(result))
+	for i, row := range result {
+		health[i] = row.toModel()
+	}
+	return health, nil
+}
+
+func (r *repository) SaveLinkHealth(ctx context.Context, health []model.LinkHealth) error {
+	var (
+		shorted       = make([]string, len(health))
+		statuses      = make([]int, len(health))
+		errs          = make([]string, len(health))
+		responseTimes = make([]int64, len(health))
+		redirects     = make([]string, len(health))
+		broken        = make([]bool, len(health))
+		checkedAt     = make([]time.Time, len(health))
+		failures      = make([]int, len(health))
+		nextCheckAt   = make([]time.Time, len(health))
+	)
+	for i, h := range health {
+		shorted[i] = h.Shorted
+		statuses[i] = h.Status
+		errs[i] = h.Error
+		responseTimes[i] = h.ResponseTime.Milliseconds()
+		redirects[i] = strings.Join(h.Redirects, "\n")
+		broken[i] = h.IsBroken()
+		checkedAt[i] = h.CheckedAt
+		failures[i] = h.Failures
+		nextCheckAt[i] = h.NextCheckAt
+	}
+
+	_, err := r.db.Exec(ctx, saveLinkHealthSQL,
+		shorted, statuses, errs, responseTimes, redirects, broken, checkedAt, failures, nextCheckAt)
+	if err != nil {
+		return fmt.Errorf("%w: %v", rep.ErrExecuteQuery, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,178 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	mockdb "link-shortener-service/internal/infastracture/repository/postgres/mocks"
+	"link-shortener-service/internal/model"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+var healthRowColumns = []string{
+	"shorted_url", "original_url", "owner_id", "status", "error", "response_time_ms", "redirects",
+	"checked_at", "failures", "next_check_at",
+}
+
+func TestGetLinksToCheck(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	checkedAt := now.Add(-time.Hour)
+	nextCheckAt := now.Add(-time.Minute)
+
+	tests := []struct {
+		name          string
+		setupMock     func(*mockdb.MockDBQuery)
+		expected      []model.LinkHealth
+		expectedError error
+	}{
+		{
+			name: "links are due",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Query(gomock.Any(), getLinksToCheckSQL, now, 100).
+					Return(pgxmock.NewRows(healthRowColumns).
+						AddRow("fresh", "https://some.com/1", "team-a", 0, "", int64(0), []string{}, (*time.Time)(nil), 0, (*time.Time)(nil)).
+						AddRow("due", "https://some.com/2", "", 404, "", int64(120), []string{"https://some.com/2/"}, &checkedAt, 1, &nextCheckAt).
+						Kind(), nil)
+			},
+			expected: []model.LinkHealth{
+				{Shorted: "fresh", Original: "https://some.com/1", OwnerID: "team-a", LinkCheck: model.LinkCheck{Redirects: []string{}}},
+				{
+					Shorted:  "due",
+					Original: "https://some.com/2",
+					LinkCheck: model.LinkCheck{
+						Status:       404,
+						ResponseTime: 120 * time.Millisecond,
+						Redirects:    []string{"https://some.com/2/"},
+					},
+					CheckedAt:   checkedAt,
+					Failures:    1,
+					NextCheckAt: nextCheckAt,
+				},
+			},
+		},
+		{
+			name: "db.Query error",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Query(gomock.Any(), getLinksToCheckSQL, now, 100).
+					Return(nil, errors.New("query error"))
+			},
+			expectedError: rep.ErrExecuteQuery,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mockdb.NewMockDBQuery(ctrl)
+			repo := &repository{db: mockDB}
+
+			tt.setupMock(mockDB)
+
+			due, err := repo.GetLinksToCheck(context.Background(), now, 100)
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+			assert.Equal(t, tt.expected, due)
+		})
+	}
+}
+
+func TestGetBrokenLinks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	checkedAt := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	mockDB := mockdb.NewMockDBQuery(ctrl)
+	mockDB.EXPECT().
+		Query(gomock.Any(), getBrokenLinksSQL, "team-a", 50).
+		Return(pgxmock.NewRows(healthRowColumns).
+			AddRow("gone", "https://some.com/1", "team-a", 0, "connection refused", int64(5), []string{}, &checkedAt, 3, &checkedAt).
+			Kind(), nil)
+
+	repo := &repository{db: mockDB}
+	broken, err := repo.GetBrokenLinks(context.Background(), "team-a", 50)
+
+	assert.NoError(t, err)
+	assert.Len(t, broken, 1)
+	assert.Equal(t, "connection refused", broken[0].Error)
+	assert.Equal(t, 3, broken[0].Failures)
+}
+
+func TestSaveLinkHealth(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	checkedAt := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	health := []model.LinkHealth{
+		{
+			Shorted: "moved",
+			LinkCheck: model.LinkCheck{
+				Status:       200,
+				ResponseTime: 1500 * time.Millisecond,
+				Redirects:    []string{"http://some.com/a", "https://some.com/a"},
+			},
+			CheckedAt:   checkedAt,
+			NextCheckAt: checkedAt.Add(24 * time.Hour),
+		},
+		{
+			Shorted:     "gone",
+			LinkCheck:   model.LinkCheck{Status: 410},
+			CheckedAt:   checkedAt,
+			Failures:    2,
+			NextCheckAt: checkedAt.Add(time.Hour),
+		},
+	}
+
+	tests := []struct {
+		name          string
+		execErr       error
+		expectedError error
+	}{
+		{
+			name: "successful save",
+		},
+		{
+			name:          "db.Exec error",
+			execErr:       errors.New("exec error"),
+			expectedError: rep.ErrExecuteQuery,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mockdb.NewMockDBQuery(ctrl)
+			mockDB.EXPECT().
+				Exec(gomock.Any(), saveLinkHealthSQL,
+					[]string{"moved", "gone"},
+					[]int{200, 410},
+					[]string{"", ""},
+					[]int64{1500, 0},
+					[]string{"http://some.com/a\nhttps://some.com/a", ""},
+					[]bool{false, true},
+					[]time.Time{checkedAt, checkedAt},
+					[]int{0, 2},
+					[]time.Time{checkedAt.Add(24 * time.Hour), checkedAt.Add(time.Hour)}).
+				Return(pgconn.NewCommandTag("INSERT 0 2"), tt.execErr)
+
+			repo := &repository{db: mockDB}
+			err := repo.SaveLinkHealth(context.Background(), health)
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
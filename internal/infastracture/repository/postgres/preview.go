@@ -0,0 +1,150 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	"link-shortener-service/internal/model"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// getLinksToFetchSQL joins the previews fetched from the current destinations only,
+// so changed links look never fetched and get empty previews
+const getLinksToFetchSQL = `
+SELECT u.shorted_url, u.original_url,
+    COALESCE(p.title, '') AS title, COALESCE(p.description, '') AS description,
+    COALESCE(p.favicon, '') AS favicon, COALESCE(p.image, '') AS image,
+    p.fetched_at, COALESCE(p.error, '') AS error, COALESCE(p.failures, 0) AS failures, p.next_fetch_at
+FROM urls u LEFT JOIN link_previews p ON p.shorted_url = u.shorted_url AND p.original_url = u.original_url
+WHERE u.deleted_at IS NULL AND NOT u.disabled AND (u.expires_at IS NULL OR u.expires_at > $1)
+  AND (p.next_fetch_at IS NULL OR p.next_fetch_at <= $1)
+ORDER BY p.next_fetch_at NULLS FIRST
+LIMIT $2`
+
+const getLinkPreviewSQL = `
+SELECT shorted_url, original_url, title, description, favicon, image, fetched_at, error, failures, next_fetch_at
+FROM link_previews
+WHERE shorted_url = $1`
+
+// saveLinkPreviewsSQL upserts the previews passed as column arrays
+const saveLinkPreviewsSQL = `
+INSERT INTO link_previews (shorted_url, original_url, title, description, favicon, image, fetched_at, error, failures, next_fetch_at)
+SELECT *
+FROM unnest($1::text[], $2::text[], $3::text[], $4::text[], $5::text[], $6::text[], $7::timestamptz[], $8::text[], $9::int[], $10::timestamptz[])
+ON CONFLICT (shorted_url) DO UPDATE
+SET original_url = EXCLUDED.original_url,
+    title = EXCLUDED.title,
+    description = EXCLUDED.description,
+    favicon = EXCLUDED.favicon,
+    image = EXCLUDED.image,
+    fetched_at = EXCLUDED.fetched_at,
+    error = EXCLUDED.error,
+    failures = EXCLUDED.failures,
+    next_fetch_at = EXCLUDED.next_fetch_at`
+
+type previewRow struct {
+	ShortedURL  string     `db:"shorted_url"`
+	OriginalURL string     `db:"original_url"`
+	Title       string     `db:"title"`
+	Description string     `db:"description"`
+	Favicon     string     `db:"favicon"`
+	Image       string     `db:"image"`
+	FetchedAt   *time.Time `db:"fetched_at"`
+	Error       string     `db:"error"`
+	Failures    int        `db:"failures"`
+	NextFetchAt *time.Time `db:"next_fetch_at"`
+}
+
+func (row previewRow) toModel() model.LinkPreview {
+	return model.LinkPreview{
+		Shorted:  row.ShortedURL,
+		Original: row.OriginalURL,
+		LinkMetadata: model.LinkMetadata{
+			Title:       row.Title,
+			Description: row.Description,
+			Favicon:     row.Favicon,
+			Image:       row.Image,
+		},
+		FetchedAt:   timeOrZero(row.FetchedAt),
+		Error:       row.Error,
+		Failures:    row.Failures,
+		NextFetchAt: timeOrZero(row.NextFetchAt),
+	}
+}
+
+func (r *repository) GetLinksToFetch(ctx context.Context, now time.Time, limit int) ([]model.LinkPreview, error) {
+	rows, err := r.db.Query(ctx, getLinksToFetchSQL, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", rep.ErrExecuteQuery, err)
+	}
+	defer rows.Close()
+
+	result, err := pgx.CollectRows(rows, pgx.RowToStructByName[previewRow])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", rep.ErrScanResult, err)
+	}
+
+	previews := make([]model.LinkPreview, len(result))
+	for i, row := range result {
+		previews[i] = row.toModel()
+	}
+	return previews, nil
+}
+
+func (r *repository) GetLinkPreview(ctx context.Context, shorted string) (*model.LinkPreview, error) {
+	rows, err := r.db.Query(ctx, getLinkPreviewSQL, shorted)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", rep.ErrExecuteQuery, err)
+	}
+	defer rows.Close()
+
+	row, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[previewRow])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: preview of %s", rep.ErrNotFound, shorted)
+		}
+		return nil, fmt.Errorf("%w: %v", rep.ErrScanResult, err)
+	}
+
+	preview := row.toModel()
+	return &preview, nil
+}
+
+func (r *repository) SaveLinkPreviews(ctx context.Context, previews []model.LinkPreview) error {
+	var (
+		shorted      = make([]string, len(previews))
+		originals    = make([]string, len(previews))
+		titles       = make([]string, len(previews))
+		descriptions = make([]string, len(previews))
+		favicons     = make([]string, len(previews))
+		images       = make([]string, len(previews))
+		fetchedAt    = make([]*time.Time, len(previews))
+		errs         = make([]string, len(previews))
+		failures     = make([]int, len(previews))
+		nextFetchAt  = make([]time.Time, len(previews))
+	)
+	for i, p := range previews {
+		shorted[i] = p.Shorted
+		originals[i] = p.Original
+		titles[i] = p.Title
+		descriptions[i] = p.Description
+		favicons[i] = p.Favicon
+		images[i] = p.Image
+		fetchedAt[i] = nullTime(p.FetchedAt)
+		errs[i] = p.Error
+		failures[i] = p.Failures
+		nextFetchAt[i] = p.NextFetchAt
+	}
+
+	_, err := r.db.Exec(ctx, saveLinkPreviewsSQL,
+		shorted, originals, titles, descriptions, favicons, images, fetchedAt, errs, failures, nextFetchAt)
+	if err != nil {
+		return fmt.Errorf("%w: %v", rep.ErrExecuteQuery, err)
+	}
+
+	return nil
+}
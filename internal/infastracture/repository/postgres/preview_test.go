@@ -0,0 +1,194 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	mockdb "link-shortener-service/internal/infastracture/repository/postgres/mocks"
+	"link-shortener-service/internal/model"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+var previewRowColumns = []string{
+	"shorted_url", "original_url", "title", "description", "favicon", "image",
+	"fetched_at", "error", "failures", "next_fetch_at",
+}
+
+func TestGetLinksToFetch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 7, 8, 12, 0, 0, 0, time.UTC)
+	nextFetchAt := now.Add(-time.Minute)
+
+	tests := []struct {
+		name          string
+		setupMock     func(*mockdb.MockDBQuery)
+		expected      []model.LinkPreview
+		expectedError error
+	}{
+		{
+			name: "links are due",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Query(gomock.Any(), getLinksToFetchSQL, now, 100).
+					Return(pgxmock.NewRows(previewRowColumns).
+						AddRow("fresh", "https://some.com/1", "", "", "", "", (*time.Time)(nil), "", 0, (*time.Time)(nil)).
+						AddRow("due", "https://some.com/2", "", "", "", "", (*time.Time)(nil), "timeout", 1, &nextFetchAt).
+						Kind(), nil)
+			},
+			expected: []model.LinkPreview{
+				{Shorted: "fresh", Original: "https://some.com/1"},
+				{Shorted: "due", Original: "https://some.com/2", Error: "timeout", Failures: 1, NextFetchAt: nextFetchAt},
+			},
+		},
+		{
+			name: "db.Query error",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Query(gomock.Any(), getLinksToFetchSQL, now, 100).
+					Return(nil, errors.New("query error"))
+			},
+			expectedError: rep.ErrExecuteQuery,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mockdb.NewMockDBQuery(ctrl)
+			repo := &repository{db: mockDB}
+
+			tt.setupMock(mockDB)
+
+			due, err := repo.GetLinksToFetch(context.Background(), now, 100)
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+			assert.Equal(t, tt.expected, due)
+		})
+	}
+}
+
+func TestGetLinkPreview(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	fetchedAt := time.Date(2025, 7, 8, 12, 0, 0, 0, time.UTC)
+	nextFetchAt := fetchedAt.Add(7 * 24 * time.Hour)
+
+	tests := []struct {
+		name          string
+		setupMock     func(*mockdb.MockDBQuery)
+		expected      *model.LinkPreview
+		expectedError error
+	}{
+		{
+			name: "preview found",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Query(gomock.Any(), getLinkPreviewSQL, "abc").
+					Return(pgxmock.NewRows(previewRowColumns).
+						AddRow("abc", "https://some.com", "Some", "Some page", "https://some.com/favicon.ico",
+							"https://some.com/og.png", &fetchedAt, "", 0, &nextFetchAt).
+						Kind(), nil)
+			},
+			expected: &model.LinkPreview{
+				Shorted:  "abc",
+				Original: "https://some.com",
+				LinkMetadata: model.LinkMetadata{
+					Title:       "Some",
+					Description: "Some page",
+					Favicon:     "https://some.com/favicon.ico",
+					Image:       "https://some.com/og.png",
+				},
+				FetchedAt:   fetchedAt,
+				NextFetchAt: nextFetchAt,
+			},
+		},
+		{
+			name: "preview not found",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Query(gomock.Any(), getLinkPreviewSQL, "abc").
+					Return(pgxmock.NewRows(previewRowColumns).Kind(), nil)
+			},
+			expectedError: rep.ErrNotFound,
+		},
+		{
+			name: "db.Query error",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Query(gomock.Any(), getLinkPreviewSQL, "abc").
+					Return(nil, errors.New("query error"))
+			},
+			expectedError: rep.ErrExecuteQuery,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mockdb.NewMockDBQuery(ctrl)
+			repo := &repository{db: mockDB}
+
+			tt.setupMock(mockDB)
+
+			preview, err := repo.GetLinkPreview(context.Background(), "abc")
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+			assert.Equal(t, tt.expected, preview)
+		})
+	}
+}
+
+func TestSaveLinkPreviews(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	fetchedAt := time.Date(2025, 7, 8, 12, 0, 0, 0, time.UTC)
+	previews := []model.LinkPreview{
+		{
+			Shorted:      "abc",
+			Original:     "https://some.com",
+			LinkMetadata: model.LinkMetadata{Title: "Some", Image: "https://some.com/og.png"},
+			FetchedAt:    fetchedAt,
+			NextFetchAt:  fetchedAt.Add(time.Hour),
+		},
+		{
+			Shorted:     "down",
+			Original:    "https://down.com",
+			Error:       "timeout",
+			Failures:    1,
+			NextFetchAt: fetchedAt.Add(time.Minute),
+		},
+	}
+
+	mockDB := mockdb.NewMockDBQuery(ctrl)
+	mockDB.EXPECT().
+		Exec(gomock.Any(), saveLinkPreviewsSQL,
+			[]string{"abc", "down"},
+			[]string{"https://some.com", "https://down.com"},
+			[]string{"Some", ""},
+			[]string{"", ""},
+			[]string{"", ""},
+			[]string{"https://some.com/og.png", ""},
+			[]*time.Time{&fetchedAt, nil},
+			[]string{"", "timeout"},
+			[]int{0, 1},
+			[]time.Time{fetchedAt.Add(time.Hour), fetchedAt.Add(time.Minute)}).
+		Return(pgconn.NewCommandTag("INSERT 0 2"), nil)
+
+	repo := &repository{db: mockDB}
+	err := repo.SaveLinkPreviews(context.Background(), previews)
+
+	assert.NoError(t, err)
+}
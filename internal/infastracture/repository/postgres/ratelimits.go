@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	"link-shortener-service/internal/model"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	rateLimitBucketsTableName = "rate_limit_buckets"
+)
+
+// takeTokenSQL refills the bucket and takes a token in a single statement, so instances sharing
+// the bucket never take the same token. A bucket without a token is left as is and read back refilled
+const takeTokenSQL = `
+WITH taken AS (
+    INSERT INTO rate_limit_buckets AS bucket (key, tokens, updated_at) VALUES ($1, $3::float8 - 1, $4)
+    ON CONFLICT (key) DO UPDATE
+    SET tokens = LEAST($3::float8, bucket.tokens + GREATEST(0, EXTRACT(EPOCH FROM $4::timestamptz - bucket.updated_at)::float8) * $2::float8) - 1,
+        updated_at = $4
+    WHERE LEAST($3::float8, bucket.tokens + GREATEST(0, EXTRACT(EPOCH FROM $4::timestamptz - bucket.updated_at)::float8) * $2::float8) >= 1
+    RETURNING tokens
+)
+SELECT tokens, true AS taken FROM taken
+UNION ALL
+SELECT LEAST($3::float8, tokens + GREATEST(0, EXTRACT(EPOCH FROM $4::timestamptz - updated_at)::float8) * $2::float8), false
+FROM rate_limit_buckets
+WHERE key = $1 AND NOT EXISTS (SELECT 1 FROM taken)`
+
+type tokenBucketRow struct {
+	Tokens float64 `db:"tokens"`
+	Taken  bool    `db:"taken"`
+}
+
+func (r *repository) TakeToken(ctx context.Context, key string, limit model.RateLimit, now time.Time) (float64, bool, error) {
+	rows, err := r.db.Query(ctx, takeTokenSQL, key, limit.Rate, limit.Burst, now)
+	if err != nil {
+		return 0, false, fmt.Errorf("%w: %v", rep.ErrExecuteQuery, err)
+	}
+	defer rows.Close()
+
+	result, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[tokenBucketRow])
+	if err != nil {
+		// the bucket was created by a concurrent request after the statement started and has no token for us
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("%w: %v", rep.ErrScanResult, err)
+	}
+
+	return result.Tokens, result.Taken, nil
+}
+
+func (r *repository) DeleteIdleBuckets(ctx context.Context, before time.Time) (int64, error) {
+	queryBuilder := squirrel.Delete(rateLimitBucketsTableName).
+		PlaceholderFormat(squirrel.Dollar).
+		Where(squirrel.Lt{"updated_at": before})
+
+	sql, args, err := queryBuilder.ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", rep.ErrBuildQuery, err)
+	}
+
+	tag, err := r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", rep.ErrExecuteQuery, err)
+	}
+
+	return tag.RowsAffected(), nil
+}
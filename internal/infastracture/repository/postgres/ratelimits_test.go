@@ -0,0 +1,137 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	mockdb "link-shortener-service/internal/infastracture/repository/postgres/mocks"
+	"link-shortener-service/internal/model"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTakeToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	limit := model.RateLimit{Rate: 2, Burst: 10}
+	now := time.Date(2025, 6, 24, 12, 0, 0, 0, time.UTC)
+	bucketColumns := []string{"tokens", "taken"}
+
+	tests := []struct {
+		name           string
+		setupMock      func(*mockdb.MockDBQuery)
+		expectedTokens float64
+		expectedTaken  bool
+		expectedError  error
+	}{
+		{
+			name: "token is taken",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Query(gomock.Any(), takeTokenSQL, "ip:10.0.0.1", 2.0, 10, now).
+					Return(pgxmock.NewRows(bucketColumns).AddRow(8.5, true).Kind(), nil)
+			},
+			expectedTokens: 8.5,
+			expectedTaken:  true,
+		},
+		{
+			name: "bucket is empty",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Query(gomock.Any(), takeTokenSQL, "ip:10.0.0.1", 2.0, 10, now).
+					Return(pgxmock.NewRows(bucketColumns).AddRow(0.25, false).Kind(), nil)
+			},
+			expectedTokens: 0.25,
+		},
+		{
+			name: "bucket is created concurrently",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Query(gomock.Any(), takeTokenSQL, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(pgxmock.NewRows(bucketColumns).Kind(), nil)
+			},
+		},
+		{
+			name: "db.Query error",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Query(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil, errors.New("query error"))
+			},
+			expectedError: rep.ErrExecuteQuery,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mockdb.NewMockDBQuery(ctrl)
+			repo := &repository{db: mockDB}
+
+			tt.setupMock(mockDB)
+
+			tokens, taken, err := repo.TakeToken(context.Background(), "ip:10.0.0.1", limit, now)
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+			assert.Equal(t, tt.expectedTokens, tokens)
+			assert.Equal(t, tt.expectedTaken, taken)
+		})
+	}
+}
+
+func TestDeleteIdleBuckets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	before := time.Date(2025, 6, 24, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		setupMock     func(*mockdb.MockDBQuery)
+		expected      int64
+		expectedError error
+	}{
+		{
+			name: "successful delete",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Exec(gomock.Any(), "DELETE FROM rate_limit_buckets WHERE updated_at < $1", before).
+					Return(pgconn.NewCommandTag("DELETE 7"), nil)
+			},
+			expected: 7,
+		},
+		{
+			name: "db.Exec error",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Exec(gomock.Any(), gomock.Any(), before).
+					Return(pgconn.NewCommandTag(""), errors.New("exec error"))
+			},
+			expectedError: rep.ErrExecuteQuery,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mockdb.NewMockDBQuery(ctrl)
+			repo := &repository{db: mockDB}
+
+			tt.setupMock(mockDB)
+
+			deleted, err := repo.DeleteIdleBuckets(context.Background(), before)
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+			assert.Equal(t, tt.expected, deleted)
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	rep "link-shortener-service/internal/infastracture/repository"
 	"link-shortener-service/internal/model"
@@ -19,15 +20,49 @@ const (
 	tableName          = "urls"
 	origURLColumnName  = "original_url"
 	shortURLColumnName = "shorted_url"
+	redirectColumnName = "redirect_status"
+	expiresColumnName  = "expires_at"
+	maxClicksName      = "max_clicks"
+	clicksUsedName     = "clicks_used"
+	passwordHashName   = "password_hash"
+	disabledColumnName = "disabled"
+	deletedColumnName  = "deleted_at"
+	dedupKeyColumnName = "dedup_key"
+	ownerIDColumnName  = "owner_id"
+	rulesColumnName    = "redirect_rules"
+	interstitialName   = "interstitial"
 
 	duplicatePgSQLErrCode = "23505"
 )
 
 type urlRow struct {
-	OriginalURL string `db:"original_url"`
-	ShortedURL  string `db:"shorted_url"`
+	OriginalURL    string     `db:"original_url"`
+	ShortedURL     string     `db:"shorted_url"`
+	RedirectStatus int        `db:"redirect_status"`
+	ExpiresAt      *time.Time `db:"expires_at"`
+	MaxClicks      int        `db:"max_clicks"`
+	ClicksUsed     int        `db:"clicks_used"`
+	PasswordHash   string     `db:"password_hash"`
+	Disabled       bool       `db:"disabled"`
+	DedupKey       *string    `db:"dedup_key"`
+	OwnerID        *string    `db:"owner_id"`
+	RedirectRules  *string    `db:"redirect_rules"`
+	Interstitial   bool       `db:"interstitial"`
+	Targets        *string    `db:"targets"`
 }
 
+var urlColumns = []string{
+	origURLColumnName, shortURLColumnName, redirectColumnName, expiresColumnName, maxClicksName, clicksUsedName,
+	passwordHashName, disabledColumnName, dedupKeyColumnName, ownerIDColumnName, rulesColumnName,
+	interstitialName,
+}
+
+// selectColumns are the columns of urlRow, the split targets are stored in their own table
+var selectColumns = append(urlColumns[:len(urlColumns):len(urlColumns)], targetsColumn)
+
+// deleted pairs stay in the table as tombstones, so their short codes are never issued again
+var notDeleted = squirrel.Eq{deletedColumnName: nil}
+
 type repository struct {
 	db DBQuery
 }
@@ -41,8 +76,27 @@ func NewDBRepository(pool *pgxpool.Pool) *repository {
 func (r *repository) PutURLPair(ctx context.Context, urlPair model.URLPair) (*model.URLPair, error) {
 	queryBuilder := squirrel.Insert(tableName).
 		PlaceholderFormat(squirrel.Dollar).
-		Columns(origURLColumnName, shortURLColumnName).
-		Values(urlPair.Original, urlPair.Shorted)
+		Columns(urlColumns...).
+		Values(
+			urlPair.Original,
+			urlPair.Shorted,
+			urlPair.RedirectStatus,
+			nullTime(urlPair.ExpiresAt),
+			urlPair.MaxClicks,
+			urlPair.ClicksUsed,
+			urlPair.PasswordHash,
+			urlPair.Disabled,
+			nullString(urlPair.DedupKey),
+			nullString(urlPair.OwnerID),
+			rulesJSON(urlPair.Rules),
+			urlPair.Interstitial,
+		)
+	if len(urlPair.Targets) > 0 {
+		// the pair and its targets are inserted in a single statement, so a pair is never stored without them
+		queryBuilder = queryBuilder.
+			Prefix("WITH pair AS (").
+			Suffix("RETURNING "+shortURLColumnName+")"+insertTargetsSQL, targetsJSON(urlPair.Targets))
+	}
 
 	sql, args, err := queryBuilder.ToSql()
 	if err != nil {
@@ -58,16 +112,13 @@ func (r *repository) PutURLPair(ctx context.Context, urlPair model.URLPair) (*mo
 	if errors.As(err, &pgErr) {
 		switch pgErr.Code {
 		case duplicatePgSQLErrCode:
-			if strings.Contains(pgErr.ConstraintName, origURLColumnName) {
+			if strings.Contains(pgErr.ConstraintName, dedupKeyColumnName) {
 				// if long URLPair already in db - need to find long URLPair
-				URLPair, err_ := r.GetByURL(ctx, origURLColumnName, urlPair.Original)
+				URLPair, err_ := r.GetByURL(ctx, dedupKeyColumnName, urlPair.DedupKey)
 				if err_ != nil {
 					return nil, fmt.Errorf("%w: %v", rep.ErrOriginalURLExist, errors.Join(err_, err))
 				}
-				return &model.URLPair{
-					Original: urlPair.Original,
-					Shorted:  URLPair.Shorted,
-				}, nil
+				return URLPair, nil
 			} else if strings.Contains(pgErr.ConstraintName, shortURLColumnName) {
 				// no matter which data refers to existing short URLPair in db
 				return &model.URLPair{}, fmt.Errorf("%w: %v", rep.ErrShortedURLExist, err)
@@ -78,10 +129,11 @@ func (r *repository) PutURLPair(ctx context.Context, urlPair model.URLPair) (*mo
 }
 
 func (r *repository) GetByURL(ctx context.Context, urlType string, knownURL string) (*model.URLPair, error) {
-	queryBuilder := squirrel.Select(origURLColumnName, shortURLColumnName).
+	queryBuilder := squirrel.Select(selectColumns...).
 		PlaceholderFormat(squirrel.Dollar).
 		From(tableName).
-		Where(squirrel.Eq{urlType: knownURL})
+		Where(squirrel.Eq{urlType: knownURL}).
+		Where(notDeleted)
 
 	sql, args, err := queryBuilder.ToSql()
 	if err != nil {
@@ -102,8 +154,256 @@ func (r *repository) GetByURL(ctx context.Context, urlType string, knownURL stri
 		return nil, fmt.Errorf("%w: %v", rep.ErrScanResult, err)
 	}
 
+	return result.toModel(), nil
+}
+
+func (r *repository) GetByShortedURLs(ctx context.Context, shorted []string) ([]model.URLPair, error) {
+	// a single array parameter keeps the query the same for any number of short URLs
+	queryBuilder := squirrel.Select(selectColumns...).
+		PlaceholderFormat(squirrel.Dollar).
+		From(tableName).
+		Where(squirrel.Expr(shortURLColumnName+" = ANY(?)", shorted)).
+		Where(notDeleted)
+
+	sql, args, err := queryBuilder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", rep.ErrBuildQuery, err)
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", rep.ErrExecuteQuery, err)
+	}
+	defer rows.Close()
+
+	result, err := pgx.CollectRows(rows, pgx.RowToStructByName[urlRow])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", rep.ErrScanResult, err)
+	}
+
+	pairs := make([]model.URLPair, 0, len(result))
+	for _, row := range result {
+		pairs = append(pairs, *row.toModel())
+	}
+	return pairs, nil
+}
+
+func (r *repository) UpdateOriginal(ctx context.Context, shorted, original string) (*model.URLPair, error) {
+	// a pair pointed somewhere else by hand is no longer the deduplicated pair of any original URL
+	queryBuilder := squirrel.Update(tableName).
+		PlaceholderFormat(squirrel.Dollar).
+		Set(origURLColumnName, original).
+		Set(dedupKeyColumnName, nil).
+		Where(squirrel.Eq{shortURLColumnName: shorted}).
+		Where(notDeleted).
+		Suffix("RETURNING " + strings.Join(selectColumns, ", "))
+
+	sql, args, err := queryBuilder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", rep.ErrBuildQuery, err)
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", rep.ErrExecuteQuery, err)
+	}
+	defer rows.Close()
+
+	result, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[urlRow])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %v", rep.ErrNotFound, shorted)
+		}
+		return nil, fmt.Errorf("%w: %v", rep.ErrScanResult, err)
+	}
+
+	return result.toModel(), nil
+}
+
+func (r *repository) DeleteURLPair(ctx context.Context, shorted string) error {
+	queryBuilder := squirrel.Update(tableName).
+		PlaceholderFormat(squirrel.Dollar).
+		Set(deletedColumnName, squirrel.Expr("now()")).
+		Where(squirrel.Eq{shortURLColumnName: shorted}).
+		Where(notDeleted)
+
+	return r.updatePair(ctx, queryBuilder, shorted)
+}
+
+func (r *repository) SetDisabled(ctx context.Context, shorted string, disabled bool) error {
+	queryBuilder := squirrel.Update(tableName).
+		PlaceholderFormat(squirrel.Dollar).
+		Set(disabledColumnName, disabled).
+		Where(squirrel.Eq{shortURLColumnName: shorted}).
+		Where(notDeleted)
+
+	return r.updatePair(ctx, queryBuilder, shorted)
+}
+
+func (r *repository) updatePair(ctx context.Context, queryBuilder squirrel.UpdateBuilder, shorted string) error {
+	sql, args, err := queryBuilder.ToSql()
+	if err != nil {
+		return fmt.Errorf("%w: %v", rep.ErrBuildQuery, err)
+	}
+
+	tag, err := r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("%w: %v", rep.ErrExecuteQuery, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: %s", rep.ErrNotFound, shorted)
+	}
+
+	return nil
+}
+
+func (r *repository) ConsumeClick(ctx context.Context, shorted string) error {
+	// the condition makes the decrement atomic, concurrent resolutions can't overspend the limit
+	queryBuilder := squirrel.Update(tableName).
+		PlaceholderFormat(squirrel.Dollar).
+		Set(clicksUsedName, squirrel.Expr(clicksUsedName+" + 1")).
+		Where(squirrel.Eq{shortURLColumnName: shorted}).
+		Where(squirrel.Expr(clicksUsedName + " < " + maxClicksName))
+
+	sql, args, err := queryBuilder.ToSql()
+	if err != nil {
+		return fmt.Errorf("%w: %v", rep.ErrBuildQuery, err)
+	}
+
+	tag, err := r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("%w: %v", rep.ErrExecuteQuery, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: %s", rep.ErrClicksExhausted, shorted)
+	}
+
+	return nil
+}
+
+// DeleteExpired tombstones the expired pairs like deleted ones, their short codes are never issued again
+func (r *repository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	queryBuilder := squirrel.Update(tableName).
+		PlaceholderFormat(squirrel.Dollar).
+		Set(deletedColumnName, squirrel.Expr("now()")).
+		Where(squirrel.LtOrEq{expiresColumnName: now}).
+		Where(notDeleted)
+
+	sql, args, err := queryBuilder.ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", rep.ErrBuildQuery, err)
+	}
+
+	tag, err := r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", rep.ErrExecuteQuery, err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+func (r *repository) GetDeduplicated(ctx context.Context, after string, limit int) ([]model.URLPair, error) {
+	queryBuilder := squirrel.Select(selectColumns...).
+		PlaceholderFormat(squirrel.Dollar).
+		From(tableName).
+		Where(squirrel.NotEq{dedupKeyColumnName: nil}).
+		Where(squirrel.Gt{shortURLColumnName: after}).
+		Where(notDeleted).
+		OrderBy(shortURLColumnName).
+		Limit(uint64(limit))
+
+	sql, args, err := queryBuilder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", rep.ErrBuildQuery, err)
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", rep.ErrExecuteQuery, err)
+	}
+	defer rows.Close()
+
+	result, err := pgx.CollectRows(rows, pgx.RowToStructByName[urlRow])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", rep.ErrScanResult, err)
+	}
+
+	pairs := make([]model.URLPair, 0, len(result))
+	for _, row := range result {
+		pairs = append(pairs, *row.toModel())
+	}
+	return pairs, nil
+}
+
+func (r *repository) SetDedupKey(ctx context.Context, shorted, key string) error {
+	queryBuilder := squirrel.Update(tableName).
+		PlaceholderFormat(squirrel.Dollar).
+		Set(dedupKeyColumnName, key).
+		Where(squirrel.Eq{shortURLColumnName: shorted}).
+		Where(notDeleted)
+
+	sql, args, err := queryBuilder.ToSql()
+	if err != nil {
+		return fmt.Errorf("%w: %v", rep.ErrBuildQuery, err)
+	}
+
+	tag, err := r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == duplicatePgSQLErrCode {
+			return fmt.Errorf("%w: %v", rep.ErrOriginalURLExist, err)
+		}
+		return fmt.Errorf("%w: %v", rep.ErrExecuteQuery, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: %s", rep.ErrNotFound, shorted)
+	}
+
+	return nil
+}
+
+func (row urlRow) toModel() *model.URLPair {
 	return &model.URLPair{
-		Original: result.OriginalURL,
-		Shorted:  result.ShortedURL,
-	}, nil
+		Original:       row.OriginalURL,
+		Shorted:        row.ShortedURL,
+		RedirectStatus: row.RedirectStatus,
+		ExpiresAt:      timeOrZero(row.ExpiresAt),
+		MaxClicks:      row.MaxClicks,
+		ClicksUsed:     row.ClicksUsed,
+		PasswordHash:   row.PasswordHash,
+		Disabled:       row.Disabled,
+		DedupKey:       stringOrEmpty(row.DedupKey),
+		OwnerID:        stringOrEmpty(row.OwnerID),
+		Rules:          rulesFromJSON(row.RedirectRules),
+		Interstitial:   row.Interstitial,
+		Targets:        targetsFromJSON(row.Targets),
+	}
+}
+
+func nullTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+func timeOrZero(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
+
+func nullString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func stringOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
 }
@@ -3,7 +3,9 @@ package postgres
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	rep "link-shortener-service/internal/infastracture/repository"
 	mockdb "link-shortener-service/internal/infastracture/repository/postgres/mocks"
@@ -21,12 +23,16 @@ func TestPutURLPair(t *testing.T) {
 	defer ctrl.Finish()
 
 	reqURL := model.URLPair{
-		Original: "https://some.com/asdasd",
-		Shorted:  "xHsvC_0NTU",
+		Original:       "https://some.com/asdasd",
+		Shorted:        "xHsvC_0NTU",
+		RedirectStatus: 301,
+		DedupKey:       "https://some.com/asdasd",
 	}
 	dbURL := urlRow{
-		OriginalURL: "https://some.com/asdasd",
-		ShortedURL:  "xHsvC_0NTU",
+		OriginalURL:    "https://some.com/asdasd",
+		ShortedURL:     "xHsvC_0NTU",
+		RedirectStatus: 301,
+		DedupKey:       nullString("https://some.com/asdasd"),
 	}
 
 	tests := []struct {
@@ -39,7 +45,7 @@ func TestPutURLPair(t *testing.T) {
 			name: "successful insertion",
 			setupMock: func(mockDB *mockdb.MockDBQuery) {
 				mockDB.EXPECT().
-					Exec(gomock.Any(), gomock.Any(), reqURL.Original, reqURL.Shorted).
+					Exec(gomock.Any(), gomock.Any(), insertArgs(reqURL)...).
 					Return(pgconn.NewCommandTag("INSERT 0 1"), nil)
 			},
 			expected:      &reqURL,
@@ -49,19 +55,16 @@ func TestPutURLPair(t *testing.T) {
 			name: "duplicate original URL - return existing long-short URL pair",
 			setupMock: func(mockDB *mockdb.MockDBQuery) {
 				mockDB.EXPECT().
-					Exec(gomock.Any(), gomock.Any(), reqURL.Original, reqURL.Shorted).
+					Exec(gomock.Any(), gomock.Any(), insertArgs(reqURL)...).
 					Return(pgconn.NewCommandTag(""), &pgconn.PgError{
 						Code:           duplicatePgSQLErrCode,
-						ConstraintName: "urls_original_url_key",
+						ConstraintName: "urls_dedup_key_key",
 					})
 
-				rows := pgxmock.
-					NewRows([]string{"original_url", "shorted_url"}).
-					AddRow(dbURL.OriginalURL, dbURL.ShortedURL).
-					Kind()
+				rows := newURLRows(dbURL).Kind()
 
 				mockDB.EXPECT().
-					Query(gomock.Any(), gomock.Any(), reqURL.Original).
+					Query(gomock.Any(), gomock.Any(), reqURL.DedupKey).
 					Return(rows, nil)
 			},
 			expected:      &reqURL,
@@ -71,19 +74,18 @@ func TestPutURLPair(t *testing.T) {
 			name: "duplicate original URL - db error while GetByURL request happened",
 			setupMock: func(mockDB *mockdb.MockDBQuery) {
 				mockDB.EXPECT().
-					Exec(gomock.Any(), gomock.Any(), reqURL.Original, reqURL.Shorted).
+					Exec(gomock.Any(), gomock.Any(), insertArgs(reqURL)...).
 					Return(pgconn.NewCommandTag(""), &pgconn.PgError{
 						Code:           duplicatePgSQLErrCode,
-						ConstraintName: "urls_original_url_key",
+						ConstraintName: "urls_dedup_key_key",
 					})
 
-				rows := pgxmock.
-					NewRows([]string{"original_url", "shorted_url"}).
+				rows := newURLRows().
 					RowError(1, rep.ErrBuildQuery).
 					Kind()
 
 				mockDB.EXPECT().
-					Query(gomock.Any(), gomock.Any(), reqURL.Original).
+					Query(gomock.Any(), gomock.Any(), reqURL.DedupKey).
 					Return(rows, nil)
 			},
 			expected:      nil,
@@ -93,7 +95,7 @@ func TestPutURLPair(t *testing.T) {
 			name: "duplicate short URL",
 			setupMock: func(mockDB *mockdb.MockDBQuery) {
 				mockDB.EXPECT().
-					Exec(gomock.Any(), gomock.Any(), reqURL.Original, reqURL.Shorted).
+					Exec(gomock.Any(), gomock.Any(), insertArgs(reqURL)...).
 					Return(pgconn.NewCommandTag(""), &pgconn.PgError{
 						Code:           duplicatePgSQLErrCode,
 						ConstraintName: "urls_shorted_url_key",
@@ -106,7 +108,7 @@ func TestPutURLPair(t *testing.T) {
 			name: "error db - execute error",
 			setupMock: func(mockDB *mockdb.MockDBQuery) {
 				mockDB.EXPECT().
-					Exec(gomock.Any(), gomock.Any(), reqURL.Original, reqURL.Shorted).
+					Exec(gomock.Any(), gomock.Any(), insertArgs(reqURL)...).
 					Return(pgconn.NewCommandTag(""), &pgconn.PgError{
 						Code: "2281337", // some unexpected error
 					})
@@ -139,6 +141,35 @@ func TestPutURLPair(t *testing.T) {
 	}
 }
 
+func TestPutURLPairWithTargets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pair := model.URLPair{
+		Original: "https://some.com/",
+		Shorted:  "xHsvC_0NTU",
+		Targets: []model.SplitTarget{
+			{Variant: "a", URL: "https://some.com/a", Weight: 70},
+			{Variant: "b", URL: "https://some.com/b", Weight: 30},
+		},
+	}
+
+	// the targets are inserted along with the pair in the same statement
+	mockDB := mockdb.NewMockDBQuery(ctrl)
+	mockDB.EXPECT().
+		Exec(gomock.Any(),
+			"WITH pair AS ( INSERT INTO urls (original_url,shorted_url,redirect_status,expires_at,max_clicks,clicks_used,"+
+				"password_hash,disabled,dedup_key,owner_id,redirect_rules,interstitial) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12) "+
+				"RETURNING shorted_url)"+strings.ReplaceAll(insertTargetsSQL, "?", "$13"),
+			append(insertArgs(pair), targetsJSON(pair.Targets))...).
+		Return(pgconn.NewCommandTag("INSERT 0 2"), nil)
+
+	repo := &repository{db: mockDB}
+	result, err := repo.PutURLPair(context.Background(), pair)
+	require.NoError(t, err)
+	assert.Equal(t, &pair, result)
+}
+
 func TestGetByURL(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -147,8 +178,9 @@ func TestGetByURL(t *testing.T) {
 		Original: "https://some.com/asdasd",
 	}
 	dbURL := urlRow{
-		OriginalURL: "https://some.com/asdasd",
-		ShortedURL:  "xHsvC_0NTU",
+		OriginalURL:    "https://some.com/asdasd",
+		ShortedURL:     "xHsvC_0NTU",
+		RedirectStatus: 308,
 	}
 
 	tests := []struct {
@@ -162,18 +194,16 @@ func TestGetByURL(t *testing.T) {
 			name:             "successful get",
 			requestedURLType: "original_url",
 			setupMock: func(mockDB *mockdb.MockDBQuery) {
-				rows := pgxmock.
-					NewRows([]string{"original_url", "shorted_url"}).
-					AddRow(dbURL.OriginalURL, dbURL.ShortedURL).
-					Kind()
+				rows := newURLRows(dbURL).Kind()
 
 				mockDB.EXPECT().
 					Query(gomock.Any(), gomock.Any(), reqURL.Original).
 					Return(rows, nil)
 			},
 			expected: &model.URLPair{
-				Original: dbURL.OriginalURL,
-				Shorted:  dbURL.ShortedURL,
+				Original:       dbURL.OriginalURL,
+				Shorted:        dbURL.ShortedURL,
+				RedirectStatus: dbURL.RedirectStatus,
 			},
 			expectedError: nil,
 		},
@@ -192,9 +222,7 @@ func TestGetByURL(t *testing.T) {
 			name:             "no URL associated with requested URL",
 			requestedURLType: "original_url",
 			setupMock: func(mockDB *mockdb.MockDBQuery) {
-				rows := pgxmock.
-					NewRows([]string{"original_url", "shorted_url"}).
-					Kind()
+				rows := newURLRows().Kind()
 
 				mockDB.EXPECT().
 					Query(gomock.Any(), gomock.Any(), reqURL.Original).
@@ -243,3 +271,436 @@ func TestGetByURL(t *testing.T) {
 		})
 	}
 }
+
+func TestDeleteExpired(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Now()
+
+	tests := []struct {
+		name          string
+		setupMock     func(*mockdb.MockDBQuery)
+		expected      int64
+		expectedError error
+	}{
+		{
+			name: "expired pairs are tombstoned",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Exec(gomock.Any(), "UPDATE urls SET deleted_at = now() WHERE expires_at <= $1 AND deleted_at IS NULL", now).
+					Return(pgconn.NewCommandTag("UPDATE 3"), nil)
+			},
+			expected: 3,
+		},
+		{
+			name: "db.Exec error",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Exec(gomock.Any(), gomock.Any(), now).
+					Return(pgconn.NewCommandTag(""), errors.New("exec error"))
+			},
+			expectedError: rep.ErrExecuteQuery,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mockdb.NewMockDBQuery(ctrl)
+			repo := &repository{db: mockDB}
+
+			tt.setupMock(mockDB)
+
+			deleted, err := repo.DeleteExpired(context.Background(), now)
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+			assert.Equal(t, tt.expected, deleted)
+		})
+	}
+}
+
+func TestConsumeClick(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name          string
+		setupMock     func(*mockdb.MockDBQuery)
+		expectedError error
+	}{
+		{
+			name: "click consumed",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Exec(gomock.Any(),
+						"UPDATE urls SET clicks_used = clicks_used + 1 WHERE shorted_url = $1 AND clicks_used < max_clicks",
+						"xHsvC_0NTU").
+					Return(pgconn.NewCommandTag("UPDATE 1"), nil)
+			},
+		},
+		{
+			name: "no clicks left",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Exec(gomock.Any(), gomock.Any(), "xHsvC_0NTU").
+					Return(pgconn.NewCommandTag("UPDATE 0"), nil)
+			},
+			expectedError: rep.ErrClicksExhausted,
+		},
+		{
+			name: "db.Exec error",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Exec(gomock.Any(), gomock.Any(), "xHsvC_0NTU").
+					Return(pgconn.NewCommandTag(""), errors.New("exec error"))
+			},
+			expectedError: rep.ErrExecuteQuery,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mockdb.NewMockDBQuery(ctrl)
+			repo := &repository{db: mockDB}
+
+			tt.setupMock(mockDB)
+
+			err := repo.ConsumeClick(context.Background(), "xHsvC_0NTU")
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestDeleteURLPair(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name          string
+		setupMock     func(*mockdb.MockDBQuery)
+		expectedError error
+	}{
+		{
+			name: "pair is tombstoned",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Exec(gomock.Any(),
+						"UPDATE urls SET deleted_at = now() WHERE shorted_url = $1 AND deleted_at IS NULL",
+						"xHsvC_0NTU").
+					Return(pgconn.NewCommandTag("UPDATE 1"), nil)
+			},
+		},
+		{
+			name: "unknown or already deleted pair",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Exec(gomock.Any(), gomock.Any(), "xHsvC_0NTU").
+					Return(pgconn.NewCommandTag("UPDATE 0"), nil)
+			},
+			expectedError: rep.ErrNotFound,
+		},
+		{
+			name: "db.Exec error",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Exec(gomock.Any(), gomock.Any(), "xHsvC_0NTU").
+					Return(pgconn.NewCommandTag(""), errors.New("exec error"))
+			},
+			expectedError: rep.ErrExecuteQuery,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mockdb.NewMockDBQuery(ctrl)
+			repo := &repository{db: mockDB}
+
+			tt.setupMock(mockDB)
+
+			err := repo.DeleteURLPair(context.Background(), "xHsvC_0NTU")
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestSetDisabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name          string
+		setupMock     func(*mockdb.MockDBQuery)
+		expectedError error
+	}{
+		{
+			name: "pair is disabled",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Exec(gomock.Any(),
+						"UPDATE urls SET disabled = $1 WHERE shorted_url = $2 AND deleted_at IS NULL",
+						true, "xHsvC_0NTU").
+					Return(pgconn.NewCommandTag("UPDATE 1"), nil)
+			},
+		},
+		{
+			name: "unknown pair",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Exec(gomock.Any(), gomock.Any(), true, "xHsvC_0NTU").
+					Return(pgconn.NewCommandTag("UPDATE 0"), nil)
+			},
+			expectedError: rep.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mockdb.NewMockDBQuery(ctrl)
+			repo := &repository{db: mockDB}
+
+			tt.setupMock(mockDB)
+
+			err := repo.SetDisabled(context.Background(), "xHsvC_0NTU", true)
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestUpdateOriginal(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dbURL := urlRow{
+		OriginalURL: "https://other.com/",
+		ShortedURL:  "xHsvC_0NTU",
+	}
+
+	tests := []struct {
+		name          string
+		setupMock     func(*mockdb.MockDBQuery)
+		expected      *model.URLPair
+		expectedError error
+	}{
+		{
+			name: "original URL is updated",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Query(gomock.Any(),
+						"UPDATE urls SET original_url = $1, dedup_key = $2 WHERE shorted_url = $3 AND deleted_at IS NULL "+
+							"RETURNING original_url, shorted_url, redirect_status, expires_at, max_clicks, clicks_used, "+
+							"password_hash, disabled, dedup_key, owner_id, redirect_rules, interstitial, "+targetsColumn,
+						"https://other.com/", nil, "xHsvC_0NTU").
+					Return(newURLRows(dbURL).Kind(), nil)
+			},
+			expected: &model.URLPair{Original: "https://other.com/", Shorted: "xHsvC_0NTU"},
+		},
+		{
+			name: "unknown pair",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Query(gomock.Any(), gomock.Any(), "https://other.com/", nil, "xHsvC_0NTU").
+					Return(newURLRows().Kind(), nil)
+			},
+			expectedError: rep.ErrNotFound,
+		},
+		{
+			name: "error db - execute error",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Query(gomock.Any(), gomock.Any(), "https://other.com/", nil, "xHsvC_0NTU").
+					Return(nil, errors.New("db is down"))
+			},
+			expectedError: rep.ErrExecuteQuery,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mockdb.NewMockDBQuery(ctrl)
+			repo := &repository{db: mockDB}
+
+			tt.setupMock(mockDB)
+
+			result, err := repo.UpdateOriginal(context.Background(), "xHsvC_0NTU", "https://other.com/")
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestGetByShortedURLs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	shorted := []string{"xHsvC_0NTU", "unknown"}
+	dbURL := urlRow{
+		OriginalURL: "https://some.com/",
+		ShortedURL:  "xHsvC_0NTU",
+	}
+
+	tests := []struct {
+		name          string
+		setupMock     func(*mockdb.MockDBQuery)
+		expected      []model.URLPair
+		expectedError error
+	}{
+		{
+			name: "known pairs are returned",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Query(gomock.Any(),
+						"SELECT original_url, shorted_url, redirect_status, expires_at, max_clicks, clicks_used, "+
+							"password_hash, disabled, dedup_key, owner_id, redirect_rules, interstitial, "+targetsColumn+
+							" FROM urls WHERE shorted_url = ANY($1) AND deleted_at IS NULL",
+						shorted).
+					Return(newURLRows(dbURL).Kind(), nil)
+			},
+			expected: []model.URLPair{{Original: "https://some.com/", Shorted: "xHsvC_0NTU"}},
+		},
+		{
+			name: "no known pairs",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Query(gomock.Any(), gomock.Any(), shorted).
+					Return(newURLRows().Kind(), nil)
+			},
+			expected: []model.URLPair{},
+		},
+		{
+			name: "error db - execute error",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Query(gomock.Any(), gomock.Any(), shorted).
+					Return(nil, errors.New("db is down"))
+			},
+			expectedError: rep.ErrExecuteQuery,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mockdb.NewMockDBQuery(ctrl)
+			repo := &repository{db: mockDB}
+
+			tt.setupMock(mockDB)
+
+			result, err := repo.GetByShortedURLs(context.Background(), shorted)
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func insertArgs(pair model.URLPair) []any {
+	return []any{
+		pair.Original,
+		pair.Shorted,
+		pair.RedirectStatus,
+		nullTime(pair.ExpiresAt),
+		pair.MaxClicks,
+		pair.ClicksUsed,
+		pair.PasswordHash,
+		pair.Disabled,
+		nullString(pair.DedupKey),
+		nullString(pair.OwnerID),
+		rulesJSON(pair.Rules),
+		pair.Interstitial,
+	}
+}
+
+func newURLRows(rows ...urlRow) *pgxmock.Rows {
+	result := pgxmock.NewRows(append(urlColumns[:len(urlColumns):len(urlColumns)], targetsColumnName))
+	for _, row := range rows {
+		result.AddRow(
+			row.OriginalURL,
+			row.ShortedURL,
+			row.RedirectStatus,
+			row.ExpiresAt,
+			row.MaxClicks,
+			row.ClicksUsed,
+			row.PasswordHash,
+			row.Disabled,
+			row.DedupKey,
+			row.OwnerID,
+			row.RedirectRules,
+			row.Interstitial,
+			row.Targets,
+		)
+	}
+	return result
+}
+
+func TestSetDedupKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name          string
+		setupMock     func(*mockdb.MockDBQuery)
+		expectedError error
+	}{
+		{
+			name: "key is replaced",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Exec(gomock.Any(),
+						"UPDATE urls SET dedup_key = $1 WHERE shorted_url = $2 AND deleted_at IS NULL",
+						"https://some.com/", "xHsvC_0NTU").
+					Return(pgconn.NewCommandTag("UPDATE 1"), nil)
+			},
+		},
+		{
+			name: "key belongs to another pair",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Exec(gomock.Any(), gomock.Any(), "https://some.com/", "xHsvC_0NTU").
+					Return(pgconn.NewCommandTag(""), &pgconn.PgError{
+						Code:           duplicatePgSQLErrCode,
+						ConstraintName: "urls_dedup_key_key",
+					})
+			},
+			expectedError: rep.ErrOriginalURLExist,
+		},
+		{
+			name: "unknown pair",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Exec(gomock.Any(), gomock.Any(), "https://some.com/", "xHsvC_0NTU").
+					Return(pgconn.NewCommandTag("UPDATE 0"), nil)
+			},
+			expectedError: rep.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mockdb.NewMockDBQuery(ctrl)
+			repo := &repository{db: mockDB}
+
+			tt.setupMock(mockDB)
+
+			err := repo.SetDedupKey(context.Background(), "xHsvC_0NTU", "https://some.com/")
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package postgres
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"link-shortener-service/internal/model"
+)
+
+// ruleRow is a redirect rule as it is stored in the redirect_rules JSON array
+type ruleRow struct {
+	Target    string     `json:"target"`
+	Devices   []string   `json:"devices,omitempty"`
+	Languages []string   `json:"languages,omitempty"`
+	Countries []string   `json:"countries,omitempty"`
+	From      *time.Time `json:"from,omitempty"`
+	Until     *time.Time `json:"until,omitempty"`
+}
+
+// rulesJSON is nil for links without rules
+func rulesJSON(rules []model.RedirectRule) *string {
+	if len(rules) == 0 {
+		return nil
+	}
+	rows := make([]ruleRow, 0, len(rules))
+	for _, rule := range rules {
+		rows = append(rows, ruleRow{
+			Target:    rule.Target,
+			Devices:   rule.Devices,
+			Languages: rule.Languages,
+			Countries: rule.Countries,
+			From:      nullTime(rule.From),
+			Until:     nullTime(rule.Until),
+		})
+	}
+	// plain structs of strings and times always marshal
+	data, _ := json.Marshal(rows)
+	s := string(data)
+	return &s
+}
+
+func rulesFromJSON(data *string) []model.RedirectRule {
+	if data == nil {
+		return nil
+	}
+	var rows []ruleRow
+	if err := json.Unmarshal([]byte(*data), &rows); err != nil {
+		// the column is written by the service only, a broken value leaves the link with its original URL
+		log.Printf("failed to decode redirect rules: %v", err)
+		return nil
+	}
+	rules := make([]model.RedirectRule, 0, len(rows))
+	for _, row := range rows {
+		rules = append(rules, model.RedirectRule{
+			Target:    row.Target,
+			Devices:   row.Devices,
+			Languages: row.Languages,
+			Countries: row.Countries,
+			From:      timeOrZero(row.From),
+			Until:     timeOrZero(row.Until),
+		})
+	}
+	return rules
+}
@@ -0,0 +1,38 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	"link-shortener-service/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRulesJSON(t *testing.T) {
+	assert.Nil(t, rulesJSON(nil), "links without rules store null")
+	assert.Nil(t, rulesFromJSON(nil))
+
+	rules := []model.RedirectRule{
+		{Target: "https://apps.apple.com/app/some", Devices: []string{"ios"}},
+		{
+			Target:    "https://some.de/sale",
+			Languages: []string{"de"},
+			Countries: []string{"DE", "AT"},
+			From:      time.Date(2025, 7, 15, 0, 0, 0, 0, time.UTC),
+			Until:     time.Date(2025, 7, 22, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	data := rulesJSON(rules)
+	require.NotNil(t, data)
+	assert.JSONEq(t, `[
+		{"target": "https://apps.apple.com/app/some", "devices": ["ios"]},
+		{"target": "https://some.de/sale", "languages": ["de"], "countries": ["DE", "AT"],
+		 "from": "2025-07-15T00:00:00Z", "until": "2025-07-22T00:00:00Z"}
+	]`, *data)
+	assert.Equal(t, rules, rulesFromJSON(data))
+
+	broken := "{"
+	assert.Nil(t, rulesFromJSON(&broken))
+}
@@ -0,0 +1,27 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const nextCodeSequencesSQL = `SELECT nextval('short_code_seq') FROM generate_series(1, $1)`
+
+func (r *repository) NextCodeSequences(ctx context.Context, n int) ([]int64, error) {
+	rows, err := r.db.Query(ctx, nextCodeSequencesSQL, n)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", rep.ErrExecuteQuery, err)
+	}
+	defer rows.Close()
+
+	values, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", rep.ErrScanResult, err)
+	}
+
+	return values, nil
+}
@@ -0,0 +1,178 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	"link-shortener-service/internal/model"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	rollupsTableName    = "click_rollups"
+	visitorsTableName   = "click_visitors"
+	dimensionsTableName = "click_dimensions"
+)
+
+// rollupClicksSQL adds the next clicks after the watermark into the hourly rollups and moves the watermark
+// in a single statement. The batch stops before the first click recorded at or after $1, so clicks are
+// rolled up strictly in id order and a click is never skipped by the watermark.
+const rollupClicksSQL = `
+WITH state AS (
+    SELECT last_click_id FROM click_rollup_state FOR UPDATE
+), batch AS (
+    SELECT c.id, c.code, date_trunc('hour', c.clicked_at AT TIME ZONE 'UTC') AT TIME ZONE 'UTC' AS bucket,
+           c.ip, c.user_agent, c.referrer_host, c.country, c.ua_family, c.variant
+    FROM clicks c, state
+    WHERE c.id > state.last_click_id
+      AND c.id < COALESCE(
+          (SELECT min(l.id) FROM clicks l, state WHERE l.id > state.last_click_id AND l.clicked_at >= $1),
+          9223372036854775807)
+    ORDER BY c.id
+    LIMIT $2
+), rollups AS (
+    INSERT INTO click_rollups (code, bucket, clicks)
+    SELECT code, bucket, count(*) FROM batch GROUP BY code, bucket
+    ON CONFLICT (code, bucket) DO UPDATE SET clicks = click_rollups.clicks + EXCLUDED.clicks
+), visitors AS (
+    INSERT INTO click_visitors (code, bucket, visitor)
+    SELECT DISTINCT code, bucket, md5(ip || '|' || user_agent) FROM batch
+    ON CONFLICT DO NOTHING
+), dimensions AS (
+    INSERT INTO click_dimensions (code, bucket, dimension, value, clicks)
+    SELECT code, bucket, d.dimension, d.value, count(*)
+    FROM batch CROSS JOIN LATERAL (VALUES
+        ('referrer', referrer_host),
+        ('country', country),
+        ('user_agent', ua_family),
+        ('variant', variant)
+    ) AS d (dimension, value)
+    GROUP BY code, bucket, d.dimension, d.value
+    ON CONFLICT (code, bucket, dimension, value) DO UPDATE SET clicks = click_dimensions.clicks + EXCLUDED.clicks
+)
+UPDATE click_rollup_state SET last_click_id = (SELECT max(id) FROM batch)
+WHERE EXISTS (SELECT 1 FROM batch)
+RETURNING (SELECT count(*) FROM batch)`
+
+type bucketRow struct {
+	Bucket time.Time `db:"bucket"`
+	Clicks int64     `db:"clicks"`
+}
+
+type dimensionRow struct {
+	Dimension string `db:"dimension"`
+	Value     string `db:"value"`
+	Clicks    int64  `db:"clicks"`
+}
+
+func (r *repository) RollupClicks(ctx context.Context, until time.Time, limit int) (int64, error) {
+	rows, err := r.db.Query(ctx, rollupClicksSQL, until, limit)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", rep.ErrExecuteQuery, err)
+	}
+	defer rows.Close()
+
+	rolledUp, err := pgx.CollectOneRow(rows, pgx.RowTo[int64])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("%w: %v", rep.ErrScanResult, err)
+	}
+
+	return rolledUp, nil
+}
+
+func (r *repository) GetClickStats(ctx context.Context, code string, from, to time.Time, top int) (*model.ClickStats, error) {
+	inRange := squirrel.And{
+		squirrel.Eq{"code": code},
+		squirrel.GtOrEq{"bucket": from},
+		squirrel.Lt{"bucket": to},
+	}
+
+	buckets, err := collect(ctx, r, squirrel.Select("bucket", "clicks").
+		PlaceholderFormat(squirrel.Dollar).
+		From(rollupsTableName).
+		Where(inRange).
+		OrderBy("bucket"),
+		pgx.RowToStructByName[bucketRow])
+	if err != nil {
+		return nil, err
+	}
+
+	visitors, err := collect(ctx, r, squirrel.Select("count(DISTINCT visitor)").
+		PlaceholderFormat(squirrel.Dollar).
+		From(visitorsTableName).
+		Where(inRange),
+		pgx.RowTo[int64])
+	if err != nil {
+		return nil, err
+	}
+
+	// direct visits, unknown countries and clicks of links without targets are left out of the top lists,
+	// every variant is kept
+	ranked := squirrel.Select("dimension", "value", "sum(clicks)::bigint AS clicks",
+		"row_number() OVER (PARTITION BY dimension ORDER BY sum(clicks) DESC, value) AS rank").
+		From(dimensionsTableName).
+		Where(inRange).
+		Where(squirrel.NotEq{"value": ""}).
+		GroupBy("dimension", "value")
+	dimensions, err := collect(ctx, r, squirrel.Select("dimension", "value", "clicks").
+		PlaceholderFormat(squirrel.Dollar).
+		FromSelect(ranked, "ranked").
+		Where(squirrel.Or{squirrel.LtOrEq{"rank": top}, squirrel.Eq{"dimension": rep.DimensionVariant}}).
+		OrderBy("dimension", "clicks DESC", "value"),
+		pgx.RowToStructByName[dimensionRow])
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &model.ClickStats{}
+	for _, b := range buckets {
+		stats.Total += b.Clicks
+		stats.Buckets = append(stats.Buckets, model.ClickBucket{Start: b.Bucket.UTC(), Clicks: b.Clicks})
+	}
+	if len(visitors) > 0 {
+		stats.UniqueVisitors = visitors[0]
+	}
+	for _, d := range dimensions {
+		counter := model.Counter{Value: d.Value, Clicks: d.Clicks}
+		switch d.Dimension {
+		case rep.DimensionReferrer:
+			stats.Referrers = append(stats.Referrers, counter)
+		case rep.DimensionCountry:
+			stats.Countries = append(stats.Countries, counter)
+		case rep.DimensionUserAgent:
+			stats.UserAgents = append(stats.UserAgents, counter)
+		case rep.DimensionVariant:
+			stats.Variants = append(stats.Variants, counter)
+		}
+	}
+
+	return stats, nil
+}
+
+func collect[T any](ctx context.Context, r *repository, queryBuilder squirrel.SelectBuilder, scan pgx.RowToFunc[T]) ([]T, error) {
+	sql, args, err := queryBuilder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", rep.ErrBuildQuery, err)
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", rep.ErrExecuteQuery, err)
+	}
+	defer rows.Close()
+
+	result, err := pgx.CollectRows(rows, scan)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", rep.ErrScanResult, err)
+	}
+
+	return result, nil
+}
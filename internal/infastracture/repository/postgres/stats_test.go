@@ -0,0 +1,159 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	mockdb "link-shortener-service/internal/infastracture/repository/postgres/mocks"
+	"link-shortener-service/internal/model"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRollupClicks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	until := time.Now()
+
+	tests := []struct {
+		name          string
+		setupMock     func(*mockdb.MockDBQuery)
+		expected      int64
+		expectedError error
+	}{
+		{
+			name: "clicks are rolled up",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Query(gomock.Any(), rollupClicksSQL, until, 100).
+					Return(pgxmock.NewRows([]string{"count"}).AddRow(int64(42)).Kind(), nil)
+			},
+			expected: 42,
+		},
+		{
+			name: "nothing to roll up",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Query(gomock.Any(), rollupClicksSQL, until, 100).
+					Return(pgxmock.NewRows([]string{"count"}).Kind(), nil)
+			},
+			expected: 0,
+		},
+		{
+			name: "error db - execute error",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Query(gomock.Any(), rollupClicksSQL, until, 100).
+					Return(nil, errors.New("db is down"))
+			},
+			expectedError: rep.ErrExecuteQuery,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mockdb.NewMockDBQuery(ctrl)
+			repo := &repository{db: mockDB}
+
+			tt.setupMock(mockDB)
+
+			rolledUp, err := repo.RollupClicks(context.Background(), until, 100)
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+			assert.Equal(t, tt.expected, rolledUp)
+		})
+	}
+}
+
+func TestGetClickStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	from := time.Date(2025, 5, 7, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+
+	tests := []struct {
+		name          string
+		setupMock     func(*mockdb.MockDBQuery)
+		expected      *model.ClickStats
+		expectedError error
+	}{
+		{
+			name: "stats are collected",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				gomock.InOrder(
+					mockDB.EXPECT().
+						Query(gomock.Any(),
+							"SELECT bucket, clicks FROM click_rollups "+
+								"WHERE (code = $1 AND bucket >= $2 AND bucket < $3) ORDER BY bucket",
+							"xHsvC_0NTU", from, to).
+						Return(pgxmock.NewRows([]string{"bucket", "clicks"}).
+							AddRow(from, int64(2)).
+							AddRow(from.Add(time.Hour), int64(3)).
+							Kind(), nil),
+					mockDB.EXPECT().
+						Query(gomock.Any(),
+							"SELECT count(DISTINCT visitor) FROM click_visitors "+
+								"WHERE (code = $1 AND bucket >= $2 AND bucket < $3)",
+							"xHsvC_0NTU", from, to).
+						Return(pgxmock.NewRows([]string{"count"}).AddRow(int64(4)).Kind(), nil),
+					mockDB.EXPECT().
+						Query(gomock.Any(), gomock.Any(), "xHsvC_0NTU", from, to, "", 5, "variant").
+						Return(pgxmock.NewRows([]string{"dimension", "value", "clicks"}).
+							AddRow("country", "DE", int64(5)).
+							AddRow("referrer", "ref.com", int64(3)).
+							AddRow("user_agent", "Chrome", int64(4)).
+							AddRow("user_agent", "curl", int64(1)).
+							AddRow("variant", "a", int64(4)).
+							AddRow("variant", "b", int64(1)).
+							Kind(), nil),
+				)
+			},
+			expected: &model.ClickStats{
+				Total:          5,
+				UniqueVisitors: 4,
+				Buckets: []model.ClickBucket{
+					{Start: from, Clicks: 2},
+					{Start: from.Add(time.Hour), Clicks: 3},
+				},
+				Referrers:  []model.Counter{{Value: "ref.com", Clicks: 3}},
+				Countries:  []model.Counter{{Value: "DE", Clicks: 5}},
+				UserAgents: []model.Counter{{Value: "Chrome", Clicks: 4}, {Value: "curl", Clicks: 1}},
+				Variants:   []model.Counter{{Value: "a", Clicks: 4}, {Value: "b", Clicks: 1}},
+			},
+		},
+		{
+			name: "error db - execute error",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Query(gomock.Any(), gomock.Any(), "xHsvC_0NTU", from, to).
+					Return(nil, errors.New("db is down"))
+			},
+			expectedError: rep.ErrExecuteQuery,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mockdb.NewMockDBQuery(ctrl)
+			repo := &repository{db: mockDB}
+
+			tt.setupMock(mockDB)
+
+			result, err := repo.GetClickStats(context.Background(), "xHsvC_0NTU", from, to, 5)
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package postgres
+
+import (
+	"encoding/json"
+	"log"
+
+	"link-shortener-service/internal/model"
+)
+
+const (
+	targetsTableName  = "link_targets"
+	targetsColumnName = "targets"
+)
+
+// targetsColumn aggregates the split targets of the pair into a JSON array in their order, null for pairs without targets
+var targetsColumn = `(SELECT jsonb_agg(jsonb_build_object('ordinal', t.ordinal, 'variant', t.variant, 'url', t.url, 'weight', t.weight)
+    ORDER BY t.ordinal) FROM ` + targetsTableName + ` t WHERE t.shorted_url = urls.shorted_url) AS ` + targetsColumnName
+
+// insertTargetsSQL follows the insert of a pair wrapped into the pair CTE returning its short URL,
+// the targets are passed as a JSON array in the form of targetsJSON
+const insertTargetsSQL = `
+INSERT INTO ` + targetsTableName + ` (shorted_url, ordinal, variant, url, weight)
+SELECT pair.shorted_url, t.ordinal, t.variant, t.url, t.weight
+FROM pair CROSS JOIN jsonb_to_recordset(?::jsonb) AS t (ordinal INT, variant TEXT, url TEXT, weight INT)`
+
+// targetRow is a split target as it is passed to and aggregated from link_targets
+type targetRow struct {
+	Ordinal int    `json:"ordinal"`
+	Variant string `json:"variant"`
+	URL     string `json:"url"`
+	Weight  int    `json:"weight"`
+}
+
+// targetsJSON is nil for links without targets
+func targetsJSON(targets []model.SplitTarget) *string {
+	if len(targets) == 0 {
+		return nil
+	}
+	rows := make([]targetRow, 0, len(targets))
+	for i, target := range targets {
+		rows = append(rows, targetRow{Ordinal: i, Variant: target.Variant, URL: target.URL, Weight: target.Weight})
+	}
+	// plain structs of strings and ints always marshal
+	data, _ := json.Marshal(rows)
+	s := string(data)
+	return &s
+}
+
+func targetsFromJSON(data *string) []model.SplitTarget {
+	if data == nil {
+		return nil
+	}
+	var rows []targetRow
+	if err := json.Unmarshal([]byte(*data), &rows); err != nil {
+		// the targets are written by the service only, a broken value leaves the link with its original URL
+		log.Printf("failed to decode split targets: %v", err)
+		return nil
+	}
+	targets := make([]model.SplitTarget, 0, len(rows))
+	for _, row := range rows {
+		targets = append(targets, model.SplitTarget{Variant: row.Variant, URL: row.URL, Weight: row.Weight})
+	}
+	return targets
+}
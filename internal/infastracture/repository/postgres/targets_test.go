@@ -0,0 +1,30 @@
+package postgres
+
+import (
+	"testing"
+
+	"link-shortener-service/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTargetsJSON(t *testing.T) {
+	assert.Nil(t, targetsJSON(nil), "links without targets insert none")
+	assert.Nil(t, targetsFromJSON(nil))
+
+	targets := []model.SplitTarget{
+		{Variant: "a", URL: "https://some.com/a", Weight: 70},
+		{Variant: "new-landing", URL: "https://some.com/new", Weight: 0},
+	}
+	data := targetsJSON(targets)
+	require.NotNil(t, data)
+	assert.JSONEq(t, `[
+		{"ordinal": 0, "variant": "a", "url": "https://some.com/a", "weight": 70},
+		{"ordinal": 1, "variant": "new-landing", "url": "https://some.com/new", "weight": 0}
+	]`, *data)
+	assert.Equal(t, targets, targetsFromJSON(data))
+
+	broken := "{"
+	assert.Nil(t, targetsFromJSON(&broken))
+}
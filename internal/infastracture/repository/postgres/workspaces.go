@@ -0,0 +1,108 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	"link-shortener-service/internal/model"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	workspacesTableName       = "workspaces"
+	workspaceDomainsTableName = "workspace_domains"
+)
+
+// putWorkspaceSQL inserts the workspace and its domains in a single statement, so a taken domain
+// leaves no workspace behind
+const putWorkspaceSQL = `
+WITH workspace AS (
+    INSERT INTO workspaces (id, name, created_at) VALUES ($1, $2, $3)
+    RETURNING id
+)
+INSERT INTO workspace_domains (domain, workspace_id)
+SELECT domain, workspace.id FROM workspace, unnest($4::text[]) AS domain`
+
+func (r *repository) PutWorkspace(ctx context.Context, workspace model.Workspace) error {
+	domains := workspace.Domains
+	if domains == nil {
+		domains = []string{}
+	}
+
+	_, err := r.db.Exec(ctx, putWorkspaceSQL, workspace.ID, workspace.Name, workspace.CreatedAt, domains)
+	if err != nil {
+		return workspaceError(err)
+	}
+
+	return nil
+}
+
+func (r *repository) AddWorkspaceDomain(ctx context.Context, workspaceID, domain string) error {
+	queryBuilder := squirrel.Insert(workspaceDomainsTableName).
+		PlaceholderFormat(squirrel.Dollar).
+		Columns("domain", "workspace_id").
+		Select(squirrel.Select().
+			Column("?::text", domain).
+			Column("id").
+			From(workspacesTableName).
+			Where(squirrel.Eq{"id": workspaceID}))
+
+	sql, args, err := queryBuilder.ToSql()
+	if err != nil {
+		return fmt.Errorf("%w: %v", rep.ErrBuildQuery, err)
+	}
+
+	tag, err := r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return workspaceError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: workspace %s", rep.ErrNotFound, workspaceID)
+	}
+
+	return nil
+}
+
+func (r *repository) GetDomainWorkspace(ctx context.Context, domain string) (string, error) {
+	queryBuilder := squirrel.Select("workspace_id").
+		PlaceholderFormat(squirrel.Dollar).
+		From(workspaceDomainsTableName).
+		Where(squirrel.Eq{"domain": domain})
+
+	sql, args, err := queryBuilder.ToSql()
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", rep.ErrBuildQuery, err)
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", rep.ErrExecuteQuery, err)
+	}
+	defer rows.Close()
+
+	workspaceID, err := pgx.CollectOneRow(rows, pgx.RowTo[string])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", fmt.Errorf("%w: domain %s", rep.ErrNotFound, domain)
+		}
+		return "", fmt.Errorf("%w: %v", rep.ErrScanResult, err)
+	}
+
+	return workspaceID, nil
+}
+
+func workspaceError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == duplicatePgSQLErrCode {
+		if pgErr.TableName == workspaceDomainsTableName {
+			return fmt.Errorf("%w: %v", rep.ErrDomainExist, err)
+		}
+		return fmt.Errorf("%w: %v", rep.ErrWorkspaceExist, err)
+	}
+	return fmt.Errorf("%w: %v", rep.ErrExecuteQuery, err)
+}
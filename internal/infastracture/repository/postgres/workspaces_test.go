@@ -0,0 +1,186 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	mockdb "link-shortener-service/internal/infastracture/repository/postgres/mocks"
+	"link-shortener-service/internal/model"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPutWorkspace(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	createdAt := time.Date(2025, 6, 17, 10, 0, 0, 0, time.UTC)
+	workspace := model.Workspace{ID: "acme", Name: "Acme", Domains: []string{"go.acme.io"}, CreatedAt: createdAt}
+
+	tests := []struct {
+		name          string
+		setupMock     func(*mockdb.MockDBQuery)
+		expectedError error
+	}{
+		{
+			name: "workspace is stored",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Exec(gomock.Any(), putWorkspaceSQL, "acme", "Acme", createdAt, []string{"go.acme.io"}).
+					Return(pgconn.NewCommandTag("INSERT 0 1"), nil)
+			},
+		},
+		{
+			name: "workspace exists",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Exec(gomock.Any(), putWorkspaceSQL, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(pgconn.CommandTag{}, &pgconn.PgError{Code: duplicatePgSQLErrCode, TableName: workspacesTableName})
+			},
+			expectedError: rep.ErrWorkspaceExist,
+		},
+		{
+			name: "domain is taken",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Exec(gomock.Any(), putWorkspaceSQL, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(pgconn.CommandTag{}, &pgconn.PgError{Code: duplicatePgSQLErrCode, TableName: workspaceDomainsTableName})
+			},
+			expectedError: rep.ErrDomainExist,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mockdb.NewMockDBQuery(ctrl)
+			repo := &repository{db: mockDB}
+
+			tt.setupMock(mockDB)
+
+			err := repo.PutWorkspace(context.Background(), workspace)
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestAddWorkspaceDomain(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	addSQL := "INSERT INTO workspace_domains (domain,workspace_id) SELECT $1::text, id FROM workspaces WHERE id = $2"
+
+	tests := []struct {
+		name          string
+		setupMock     func(*mockdb.MockDBQuery)
+		expectedError error
+	}{
+		{
+			name: "domain is added",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Exec(gomock.Any(), addSQL, "acme.link", "acme").
+					Return(pgconn.NewCommandTag("INSERT 0 1"), nil)
+			},
+		},
+		{
+			name: "unknown workspace",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Exec(gomock.Any(), addSQL, "acme.link", "acme").
+					Return(pgconn.NewCommandTag("INSERT 0 0"), nil)
+			},
+			expectedError: rep.ErrNotFound,
+		},
+		{
+			name: "domain is taken",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Exec(gomock.Any(), addSQL, "acme.link", "acme").
+					Return(pgconn.CommandTag{}, &pgconn.PgError{Code: duplicatePgSQLErrCode, TableName: workspaceDomainsTableName})
+			},
+			expectedError: rep.ErrDomainExist,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mockdb.NewMockDBQuery(ctrl)
+			repo := &repository{db: mockDB}
+
+			tt.setupMock(mockDB)
+
+			err := repo.AddWorkspaceDomain(context.Background(), "acme", "acme.link")
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestGetDomainWorkspace(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	getSQL := "SELECT workspace_id FROM workspace_domains WHERE domain = $1"
+
+	tests := []struct {
+		name          string
+		setupMock     func(*mockdb.MockDBQuery)
+		expected      string
+		expectedError error
+	}{
+		{
+			name: "domain is found",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Query(gomock.Any(), getSQL, "go.acme.io").
+					Return(pgxmock.NewRows([]string{"workspace_id"}).AddRow("acme").Kind(), nil)
+			},
+			expected: "acme",
+		},
+		{
+			name: "unknown domain",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Query(gomock.Any(), getSQL, "go.acme.io").
+					Return(pgxmock.NewRows([]string{"workspace_id"}).Kind(), nil)
+			},
+			expectedError: rep.ErrNotFound,
+		},
+		{
+			name: "error db - execute error",
+			setupMock: func(mockDB *mockdb.MockDBQuery) {
+				mockDB.EXPECT().
+					Query(gomock.Any(), getSQL, "go.acme.io").
+					Return(nil, errors.New("db is down"))
+			},
+			expectedError: rep.ErrExecuteQuery,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mockdb.NewMockDBQuery(ctrl)
+			repo := &repository{db: mockDB}
+
+			tt.setupMock(mockDB)
+
+			result, err := repo.GetDomainWorkspace(context.Background(), "go.acme.io")
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"net/url"
+	"strings"
+)
+
+// dimensions of click rollups
+const (
+	DimensionReferrer  = "referrer"
+	DimensionCountry   = "country"
+	DimensionUserAgent = "user_agent"
+	DimensionVariant   = "variant"
+)
+
+// ReferrerHost reduces a Referer header to its host, empty for direct visits
+func ReferrerHost(referrer string) string {
+	u, err := url.Parse(referrer)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
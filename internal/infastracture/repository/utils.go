@@ -10,4 +10,7 @@ var (
 	ErrOriginalURLExist = errors.New("original URL already exists")
 	ErrShortedURLExist  = errors.New("short URL already exists")
 	ErrUnknownURLType   = errors.New("got unexpected URL type")
+	ErrClicksExhausted  = errors.New("no clicks left")
+	ErrWorkspaceExist   = errors.New("workspace already exists")
+	ErrDomainExist      = errors.New("domain already belongs to a workspace")
 )
@@ -0,0 +1,221 @@
+package linkcheck
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"link-shortener-service/internal/model"
+)
+
+// maxHostDelay caps the delay a host asking to slow down gets between the requests
+const maxHostDelay = time.Minute
+
+var (
+	ErrInvalidURL = errors.New("invalid destination URL")
+	ErrNilClient  = errors.New("link checker needs an HTTP client")
+)
+
+type Options struct {
+	// Concurrency is how many hosts are checked at once
+	Concurrency int
+	// HostDelay is the pause between the requests to the same host
+	HostDelay time.Duration
+	// Timeout limits a single request, redirects included
+	Timeout   time.Duration
+	UserAgent string
+}
+
+// Checker requests the destinations of links. Requests to a host are sent one at a time with
+// a delay between them, the delay doubles while the host answers 429 or 503
+type Checker struct {
+	client  *http.Client
+	options Options
+	now     func() time.Time
+}
+
+// New returns a checker sending the requests with client. The client decides which addresses
+// may be requested, pass one refusing the internal network such as the safehttp one
+func New(client *http.Client, options Options) (*Checker, error) {
+	if client == nil {
+		return nil, ErrNilClient
+	}
+	if options.Concurrency < 1 {
+		options.Concurrency = 1
+	}
+	return &Checker{
+		client:  client,
+		options: options,
+		now:     time.Now,
+	}, nil
+}
+
+// CheckAll checks the URLs and returns the results in the same order. URLs not checked
+// before the context is done get the context error
+func (c *Checker) CheckAll(ctx context.Context, urls []string) []model.LinkCheck {
+	checks := make([]model.LinkCheck, len(urls))
+
+	var hosts []string
+	byHost := make(map[string][]int)
+	for i, raw := range urls {
+		parsed, err := url.Parse(raw)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			checks[i] = model.LinkCheck{Error: ErrInvalidURL.Error()}
+			continue
+		}
+		host := strings.ToLower(parsed.Host)
+		if _, ok := byHost[host]; !ok {
+			hosts = append(hosts, host)
+		}
+		byHost[host] = append(byHost[host], i)
+	}
+
+	sem := make(chan struct{}, c.options.Concurrency)
+	var wg sync.WaitGroup
+	for _, host := range hosts {
+		wg.Add(1)
+		go func(indexes []int) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				for _, i := range indexes {
+					checks[i] = model.LinkCheck{Error: ctx.Err().Error()}
+				}
+				return
+			}
+			c.checkHost(ctx, urls, indexes, checks)
+		}(byHost[host])
+	}
+	wg.Wait()
+
+	return checks
+}
+
+func (c *Checker) checkHost(ctx context.Context, urls []string, indexes []int, checks []model.LinkCheck) {
+	delay := c.options.HostDelay
+	for n, i := range indexes {
+		if n > 0 && !sleep(ctx, delay) {
+			for _, j := range indexes[n:] {
+				checks[j] = model.LinkCheck{Error: ctx.Err().Error()}
+			}
+			return
+		}
+
+		var retryAfter time.Duration
+		checks[i], retryAfter = c.Check(ctx, urls[i])
+
+		switch checks[i].Status {
+		case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+			delay = max(2*delay, retryAfter, time.Second)
+			delay = min(delay, maxHostDelay)
+		default:
+			delay = c.options.HostDelay
+		}
+	}
+}
+
+// Check requests the URL with HEAD and falls back to GET for servers not supporting HEAD.
+// It also returns the delay asked for by the Retry-After header
+func (c *Checker) Check(ctx context.Context, rawURL string) (model.LinkCheck, time.Duration) {
+	start := c.now()
+	resp, err := c.do(ctx, http.MethodHead, rawURL)
+	if err != nil || resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented {
+		if resp != nil {
+			resp.Body.Close()
+		}
+		start = c.now()
+		resp, err = c.do(ctx, http.MethodGet, rawURL)
+	}
+	elapsed := c.now().Sub(start)
+	if err != nil {
+		return model.LinkCheck{Error: err.Error(), ResponseTime: elapsed}, 0
+	}
+	defer resp.Body.Close()
+
+	return model.LinkCheck{
+		Status:       resp.StatusCode,
+		ResponseTime: elapsed,
+		Redirects:    redirects(resp),
+	}, retryAfter(resp.Header.Get("Retry-After"), c.now())
+}
+
+func (c *Checker) do(ctx context.Context, method, rawURL string) (*http.Response, error) {
+	cancel := context.CancelFunc(func() {})
+	if c.options.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, c.options.Timeout)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if c.options.UserAgent != "" {
+		req.Header.Set("User-Agent", c.options.UserAgent)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	// the timeout is released once the body is closed
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// redirects walks back from the final request and returns the URLs redirected to, in order
+func redirects(resp *http.Response) []string {
+	var chain []string
+	for req := resp.Request; req != nil && req.Response != nil; req = req.Response.Request {
+		chain = append(chain, req.URL.String())
+	}
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+	return chain
+}
+
+func retryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
+
+func sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,208 @@
+package linkcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"link-shortener-service/internal/safehttp"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	var methods []string
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		methods = append(methods, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+		assert.Equal(t, "test-checker", r.UserAgent())
+
+		switch r.URL.Path {
+		case "/ok":
+			w.WriteHeader(http.StatusOK)
+		case "/moved":
+			http.Redirect(w, r, "/moved-again", http.StatusMovedPermanently)
+		case "/moved-again":
+			http.Redirect(w, r, "/ok", http.StatusFound)
+		case "/no-head":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		case "/slow-down":
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	checker, err := New(server.Client(), Options{Timeout: time.Second, UserAgent: "test-checker"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name               string
+		path               string
+		expectedStatus     int
+		expectedRedirects  []string
+		expectedRetryAfter time.Duration
+		expectedMethods    []string
+	}{
+		{
+			name:            "ok",
+			path:            "/ok",
+			expectedStatus:  http.StatusOK,
+			expectedMethods: []string{"HEAD /ok"},
+		},
+		{
+			name:              "redirect chain",
+			path:              "/moved",
+			expectedStatus:    http.StatusOK,
+			expectedRedirects: []string{server.URL + "/moved-again", server.URL + "/ok"},
+			expectedMethods:   []string{"HEAD /moved", "HEAD /moved-again", "HEAD /ok"},
+		},
+		{
+			name:            "falls back to GET",
+			path:            "/no-head",
+			expectedStatus:  http.StatusOK,
+			expectedMethods: []string{"HEAD /no-head", "GET /no-head"},
+		},
+		{
+			name:               "retry after",
+			path:               "/slow-down",
+			expectedStatus:     http.StatusTooManyRequests,
+			expectedRetryAfter: 7 * time.Second,
+			expectedMethods:    []string{"HEAD /slow-down"},
+		},
+		{
+			name:            "not found",
+			path:            "/gone",
+			expectedStatus:  http.StatusNotFound,
+			expectedMethods: []string{"HEAD /gone"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			methods = nil
+
+			check, retryAfter := checker.Check(context.Background(), server.URL+tt.path)
+
+			assert.Empty(t, check.Error)
+			assert.Equal(t, tt.expectedStatus, check.Status)
+			assert.Equal(t, tt.expectedRedirects, check.Redirects)
+			assert.Equal(t, tt.expectedRetryAfter, retryAfter)
+			assert.Equal(t, tt.expectedMethods, methods)
+		})
+	}
+}
+
+func TestCheckUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	unreachable := server.URL
+	server.Close()
+
+	checker, err := New(http.DefaultClient, Options{Timeout: time.Second})
+	require.NoError(t, err)
+	check, _ := checker.Check(context.Background(), unreachable)
+
+	assert.NotEmpty(t, check.Error)
+	assert.Zero(t, check.Status)
+	assert.True(t, check.IsBroken())
+}
+
+func TestNewRequiresClient(t *testing.T) {
+	checker, err := New(nil, Options{})
+
+	assert.ErrorIs(t, err, ErrNilClient)
+	assert.Nil(t, checker)
+}
+
+func TestCheckRefusesInternalAddresses(t *testing.T) {
+	var requested bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = true
+	}))
+	defer server.Close()
+
+	checker, err := New(safehttp.NewClient(0), Options{Timeout: time.Second})
+	require.NoError(t, err)
+	check, _ := checker.Check(context.Background(), server.URL)
+
+	assert.Contains(t, check.Error, safehttp.ErrForbiddenAddress.Error())
+	assert.True(t, check.IsBroken())
+	assert.False(t, requested)
+}
+
+func TestCheckAll(t *testing.T) {
+	var mu sync.Mutex
+	var inFlight, maxInFlight int
+	var last time.Time
+	var minGap time.Duration = time.Hour
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		if !last.IsZero() {
+			minGap = min(minGap, time.Since(last))
+		}
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		mu.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		last = time.Now()
+		mu.Unlock()
+
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	checker, err := New(server.Client(), Options{Concurrency: 4, HostDelay: 20 * time.Millisecond})
+	require.NoError(t, err)
+	checks := checker.CheckAll(context.Background(), []string{
+		server.URL + "/a",
+		"ftp://some.com/file",
+		server.URL + "/broken",
+		server.URL + "/b",
+	})
+
+	assert.Len(t, checks, 4)
+	assert.Equal(t, http.StatusOK, checks[0].Status)
+	assert.Equal(t, ErrInvalidURL.Error(), checks[1].Error)
+	assert.Equal(t, http.StatusInternalServerError, checks[2].Status)
+	assert.True(t, checks[2].IsBroken())
+	assert.Equal(t, http.StatusOK, checks[3].Status)
+
+	// the requests to one host are sent one at a time with the delay between them
+	assert.Equal(t, 1, maxInFlight)
+	assert.GreaterOrEqual(t, minGap, 15*time.Millisecond)
+}
+
+func TestCheckAllCanceled(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	checker, err := New(server.Client(), Options{})
+	require.NoError(t, err)
+	checks := checker.CheckAll(ctx, []string{server.URL + "/a", server.URL + "/b"})
+
+	for _, check := range checks {
+		assert.NotEmpty(t, check.Error)
+	}
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"link-shortener-service/internal/handler"
+	"link-shortener-service/internal/model"
+	"link-shortener-service/internal/usecase/authenticate_key"
+)
+
+//go:generate mockgen -source=auth.go -destination=mocks/auth_mock.go -package=middleware authenticator
+type authenticator interface {
+	Run(ctx context.Context, req authenticate_key.In) (*model.APIKey, error)
+}
+
+type principalKey struct{}
+
+// ContextWithPrincipal stores who performs the request
+func ContextWithPrincipal(ctx context.Context, principal model.Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns who performs the request, ok is false for unauthenticated requests
+func PrincipalFromContext(ctx context.Context) (model.Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(model.Principal)
+	return principal, ok
+}
+
+type Auth struct {
+	authenticator authenticator
+}
+
+func NewAuth(authenticator authenticator) *Auth {
+	return &Auth{authenticator: authenticator}
+}
+
+// Require lets through requests with an API key granting the scope,
+// the key is taken from the Authorization bearer token or the X-API-Key header
+func (a *Auth) Require(scope model.Scope) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			raw := keyFromRequest(r)
+			if raw == "" {
+				unauthorized(w, errors.New("API key is missing"))
+				return
+			}
+
+			key, err := a.authenticator.Run(r.Context(), authenticate_key.In{Key: raw})
+			if err != nil {
+				switch {
+				case errors.Is(err, authenticate_key.ErrInvalidKey), errors.Is(err, authenticate_key.ErrKeyRevoked):
+					unauthorized(w, err)
+				default:
+					w.Header().Set("Content-Type", "application/json")
+					handler.RespondWithError(w, http.StatusInternalServerError, "failed to check API key", err)
+				}
+				return
+			}
+			if !key.HasScope(scope) {
+				w.Header().Set("Content-Type", "application/json")
+				handler.RespondWithError(w, http.StatusForbidden, "API key lacks the "+string(scope)+" scope",
+					errors.New("insufficient scope"))
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(ContextWithPrincipal(r.Context(), key.Principal())))
+		})
+	}
+}
+
+func keyFromRequest(r *http.Request) string {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	return strings.TrimSpace(r.Header.Get("X-API-Key"))
+}
+
+func unauthorized(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+	handler.RespondWithError(w, http.StatusUnauthorized, "invalid or missing API key", err)
+}
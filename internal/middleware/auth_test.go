@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mockauth "link-shortener-service/internal/middleware/mocks"
+	"link-shortener-service/internal/model"
+	"link-shortener-service/internal/usecase/authenticate_key"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthRequire(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	writeKey := &model.APIKey{ID: "k1", OwnerID: "team-a", Scopes: []model.Scope{model.ScopeWrite}}
+
+	tests := []struct {
+		name          string
+		headers       map[string]string
+		scope         model.Scope
+		setupMock     func(*mockauth.Mockauthenticator)
+		expectedCode  int
+		expectedError string
+	}{
+		{
+			name:    "bearer token grants the scope",
+			headers: map[string]string{"Authorization": "Bearer lsk_k1_secret"},
+			scope:   model.ScopeRead,
+			setupMock: func(m *mockauth.Mockauthenticator) {
+				m.EXPECT().Run(gomock.Any(), authenticate_key.In{Key: "lsk_k1_secret"}).Return(writeKey, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:    "X-API-Key header grants the scope",
+			headers: map[string]string{"X-API-Key": "lsk_k1_secret"},
+			scope:   model.ScopeWrite,
+			setupMock: func(m *mockauth.Mockauthenticator) {
+				m.EXPECT().Run(gomock.Any(), authenticate_key.In{Key: "lsk_k1_secret"}).Return(writeKey, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:          "missing key",
+			scope:         model.ScopeRead,
+			setupMock:     func(m *mockauth.Mockauthenticator) {},
+			expectedCode:  http.StatusUnauthorized,
+			expectedError: "invalid or missing API key",
+		},
+		{
+			name:    "revoked key",
+			headers: map[string]string{"X-API-Key": "lsk_k1_secret"},
+			scope:   model.ScopeRead,
+			setupMock: func(m *mockauth.Mockauthenticator) {
+				m.EXPECT().Run(gomock.Any(), gomock.Any()).Return(nil, authenticate_key.ErrKeyRevoked)
+			},
+			expectedCode:  http.StatusUnauthorized,
+			expectedError: "invalid or missing API key",
+		},
+		{
+			name:    "insufficient scope",
+			headers: map[string]string{"X-API-Key": "lsk_k1_secret"},
+			scope:   model.ScopeAdmin,
+			setupMock: func(m *mockauth.Mockauthenticator) {
+				m.EXPECT().Run(gomock.Any(), gomock.Any()).Return(writeKey, nil)
+			},
+			expectedCode:  http.StatusForbidden,
+			expectedError: "API key lacks the admin scope",
+		},
+		{
+			name:    "error from storage",
+			headers: map[string]string{"X-API-Key": "lsk_k1_secret"},
+			scope:   model.ScopeRead,
+			setupMock: func(m *mockauth.Mockauthenticator) {
+				m.EXPECT().Run(gomock.Any(), gomock.Any()).Return(nil, errors.New("db is down"))
+			},
+			expectedCode:  http.StatusInternalServerError,
+			expectedError: "failed to check API key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAuth := mockauth.NewMockauthenticator(ctrl)
+			tt.setupMock(mockAuth)
+
+			var principal model.Principal
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				principal, _ = PrincipalFromContext(r.Context())
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/api/links/abc/stats", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+
+			NewAuth(mockAuth).Require(tt.scope)(next).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedCode == http.StatusOK {
+				assert.Equal(t, model.Principal{KeyID: "k1", OwnerID: "team-a"}, principal)
+			}
+			if tt.expectedCode == http.StatusUnauthorized {
+				assert.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+			}
+			if tt.expectedError != "" {
+				var errorResponse map[string]interface{}
+				err := json.NewDecoder(w.Body).Decode(&errorResponse)
+				require.NoError(t, err)
+				assert.Contains(t, errorResponse["error"], tt.expectedError)
+			}
+		})
+	}
+}
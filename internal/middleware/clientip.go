@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// TrustedProxies are the proxies allowed to tell the client address in the X-Forwarded-For
+// and X-Real-IP headers, the headers from anyone else are ignored
+type TrustedProxies []netip.Prefix
+
+// ParseTrustedProxies accepts CIDRs and single addresses
+func ParseTrustedProxies(proxies []string) (TrustedProxies, error) {
+	var trusted TrustedProxies
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+		if !strings.Contains(proxy, "/") {
+			addr, err := netip.ParseAddr(proxy)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+			}
+			trusted = append(trusted, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+		trusted = append(trusted, prefix.Masked())
+	}
+	return trusted, nil
+}
+
+func (p TrustedProxies) contains(addr netip.Addr) bool {
+	for _, prefix := range p {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// remote returns the address the request came from and whether it is one of the trusted proxies
+func (p TrustedProxies) remote(r *http.Request) (string, netip.Addr, bool) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return host, netip.Addr{}, false
+	}
+	return host, addr.Unmap(), p.contains(addr.Unmap())
+}
+
+// Proxied reports whether the request came from a trusted proxy, only they may tell about the client in headers
+func (p TrustedProxies) Proxied(r *http.Request) bool {
+	_, _, trusted := p.remote(r)
+	return trusted
+}
+
+// ClientIP returns the address of the client. Behind trusted proxies it is the rightmost untrusted
+// address of X-Forwarded-For, the clients are free to put anything to the left of it
+func (p TrustedProxies) ClientIP(r *http.Request) string {
+	host, remote, trusted := p.remote(r)
+	if !trusted {
+		return host
+	}
+
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+	if len(hops) == 0 {
+		if realIP, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get("X-Real-IP"))); err == nil {
+			return realIP.Unmap().String()
+		}
+		return host
+	}
+
+	client := remote
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			// garbage in the header, the last trusted proxy is the best guess
+			break
+		}
+		client = hop.Unmap()
+		if !p.contains(client) {
+			break
+		}
+	}
+	return client.String()
+}
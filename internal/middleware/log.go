@@ -5,9 +5,13 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"regexp"
 	"time"
 )
 
+// passwords from JSON bodies and submitted forms must never reach the logs
+var passwordField = regexp.MustCompile(`("password"\s*:\s*")(?:[^"\\]|\\.)*(")|(password=)[^&]*`)
+
 func LoggerMiddleware(next http.Handler) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
@@ -16,7 +20,7 @@ func LoggerMiddleware(next http.Handler) http.HandlerFunc {
 
 		if r.Body != nil {
 			bodyBytes, _ := io.ReadAll(r.Body)
-			log.Printf("Request Body: %s", string(bodyBytes))
+			log.Printf("Request Body: %s", redactPasswords(bodyBytes))
 
 			r.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
 		}
@@ -45,3 +49,7 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.statusCode = code
 	rw.ResponseWriter.WriteHeader(code)
 }
+
+func redactPasswords(body []byte) string {
+	return string(passwordField.ReplaceAll(body, []byte("$1$3***$2")))
+}
@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"link-shortener-service/internal/handler"
+	"link-shortener-service/internal/model"
+	"link-shortener-service/internal/usecase/limit_rate"
+)
+
+//go:generate mockgen -source=ratelimit.go -destination=mocks/ratelimit_mock.go -package=middleware limiter
+type limiter interface {
+	Run(ctx context.Context, req limit_rate.In) (*limit_rate.Out, error)
+}
+
+type RateLimiter struct {
+	limiter limiter
+	proxies TrustedProxies
+}
+
+func NewRateLimiter(limiter limiter, proxies TrustedProxies) *RateLimiter {
+	return &RateLimiter{
+		limiter: limiter,
+		proxies: proxies,
+	}
+}
+
+// rateLimitsNotEnforced counts the requests let through because the store was failing
+var rateLimitsNotEnforced = expvar.NewInt("rate_limits_not_enforced")
+
+// Limit lets through the requests within the limit, routes sharing the name share the limit.
+// Requests authenticated by an API key are limited per key, the rest per client IP.
+// The limit is not enforced while the store is failing
+func (l *RateLimiter) Limit(name string, limit model.RateLimit) func(http.Handler) http.Handler {
+	return l.limit(name, limit, l.clientKey)
+}
+
+// LimitIP is Limit keyed by the client IP only. It goes before authentication,
+// so requests with wrong API keys use up the limit of the client guessing them
+func (l *RateLimiter) LimitIP(name string, limit model.RateLimit) func(http.Handler) http.Handler {
+	return l.limit(name, limit, l.ipKey)
+}
+
+func (l *RateLimiter) limit(name string, limit model.RateLimit, key func(r *http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if !limit.IsEnabled() {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			out, err := l.limiter.Run(r.Context(), limit_rate.In{
+				Key:   name + "|" + key(r),
+				Limit: limit,
+			})
+			if err != nil {
+				rateLimitsNotEnforced.Add(1)
+				log.Printf("rate limit %s is not enforced: %v", name, err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(out.Limit))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(out.Remaining))
+			w.Header().Set("RateLimit-Reset", seconds(out.Reset))
+			if !out.Allowed {
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Retry-After", seconds(out.RetryAfter))
+				handler.RespondWithError(w, http.StatusTooManyRequests, "rate limit exceeded",
+					errors.New("too many "+name+" requests"))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (l *RateLimiter) clientKey(r *http.Request) string {
+	if principal, ok := PrincipalFromContext(r.Context()); ok && principal.KeyID != "" {
+		return "key:" + principal.KeyID
+	}
+	return l.ipKey(r)
+}
+
+func (l *RateLimiter) ipKey(r *http.Request) string {
+	return "ip:" + l.proxies.ClientIP(r)
+}
+
+// seconds rounds up, so clients coming back after the time are never early
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,255 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mocklimiter "link-shortener-service/internal/middleware/mocks"
+	"link-shortener-service/internal/model"
+	"link-shortener-service/internal/usecase/limit_rate"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiterLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	limit := model.RateLimit{Rate: 1, Burst: 10}
+	proxies, err := ParseTrustedProxies([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name            string
+		remoteAddr      string
+		headers         map[string]string
+		principal       *model.Principal
+		setupMock       func(*mocklimiter.Mocklimiter)
+		expectedCode    int
+		expectedHeaders map[string]string
+		expectedError   string
+	}{
+		{
+			name:       "request within the limit",
+			remoteAddr: "203.0.113.7:51000",
+			setupMock: func(m *mocklimiter.Mocklimiter) {
+				m.EXPECT().
+					Run(gomock.Any(), limit_rate.In{Key: "create|ip:203.0.113.7", Limit: limit}).
+					Return(&limit_rate.Out{Allowed: true, Limit: 10, Remaining: 9, Reset: 1500 * time.Millisecond}, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedHeaders: map[string]string{
+				"RateLimit-Limit":     "10",
+				"RateLimit-Remaining": "9",
+				"RateLimit-Reset":     "2",
+			},
+		},
+		{
+			name:       "request over the limit",
+			remoteAddr: "203.0.113.7:51000",
+			setupMock: func(m *mocklimiter.Mocklimiter) {
+				m.EXPECT().
+					Run(gomock.Any(), gomock.Any()).
+					Return(&limit_rate.Out{Limit: 10, Reset: 10 * time.Second, RetryAfter: 300 * time.Millisecond}, nil)
+			},
+			expectedCode: http.StatusTooManyRequests,
+			expectedHeaders: map[string]string{
+				"RateLimit-Remaining": "0",
+				"RateLimit-Reset":     "10",
+				"Retry-After":         "1",
+			},
+			expectedError: "rate limit exceeded",
+		},
+		{
+			name:       "API key is limited on its own",
+			remoteAddr: "203.0.113.7:51000",
+			principal:  &model.Principal{KeyID: "k1", OwnerID: "team-a"},
+			setupMock: func(m *mocklimiter.Mocklimiter) {
+				m.EXPECT().
+					Run(gomock.Any(), limit_rate.In{Key: "create|key:k1", Limit: limit}).
+					Return(&limit_rate.Out{Allowed: true, Limit: 10, Remaining: 9}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:       "client behind a trusted proxy",
+			remoteAddr: "10.0.0.2:40000",
+			headers:    map[string]string{"X-Forwarded-For": "198.51.100.1, 203.0.113.9, 10.0.0.3"},
+			setupMock: func(m *mocklimiter.Mocklimiter) {
+				m.EXPECT().
+					Run(gomock.Any(), limit_rate.In{Key: "create|ip:203.0.113.9", Limit: limit}).
+					Return(&limit_rate.Out{Allowed: true, Limit: 10, Remaining: 9}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:       "store is failing",
+			remoteAddr: "203.0.113.7:51000",
+			setupMock: func(m *mocklimiter.Mocklimiter) {
+				m.EXPECT().
+					Run(gomock.Any(), gomock.Any()).
+					Return(nil, limit_rate.ErrRateLimitCheck)
+			},
+			expectedCode: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLimiter := mocklimiter.NewMocklimiter(ctrl)
+			tt.setupMock(mockLimiter)
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			if tt.principal != nil {
+				req = req.WithContext(ContextWithPrincipal(req.Context(), *tt.principal))
+			}
+
+			NewRateLimiter(mockLimiter, proxies).Limit("create", limit)(next).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			for k, v := range tt.expectedHeaders {
+				assert.Equal(t, v, w.Header().Get(k), k)
+			}
+			if tt.expectedError != "" {
+				var errorResponse map[string]interface{}
+				err := json.NewDecoder(w.Body).Decode(&errorResponse)
+				require.NoError(t, err)
+				assert.Contains(t, errorResponse["error"], tt.expectedError)
+			}
+		})
+	}
+
+	t.Run("store failures are counted", func(t *testing.T) {
+		mockLimiter := mocklimiter.NewMocklimiter(ctrl)
+		mockLimiter.EXPECT().Run(gomock.Any(), gomock.Any()).Return(nil, limit_rate.ErrRateLimitCheck)
+		before := rateLimitsNotEnforced.Value()
+
+		w := httptest.NewRecorder()
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+		NewRateLimiter(mockLimiter, nil).Limit("create", limit)(next).ServeHTTP(w, httptest.NewRequest("POST", "/", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, before+1, rateLimitsNotEnforced.Value())
+	})
+
+	t.Run("disabled limit", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+		NewRateLimiter(mocklimiter.NewMocklimiter(ctrl), nil).Limit("create", model.RateLimit{})(next).
+			ServeHTTP(w, httptest.NewRequest("POST", "/", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+	})
+}
+
+func TestRateLimiterLimitIP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	limit := model.RateLimit{Rate: 10, Burst: 100}
+	mockLimiter := mocklimiter.NewMocklimiter(ctrl)
+	gomock.InOrder(
+		mockLimiter.EXPECT().
+			Run(gomock.Any(), limit_rate.In{Key: "auth|ip:203.0.113.7", Limit: limit}).
+			Return(&limit_rate.Out{Allowed: true, Limit: 100, Remaining: 99}, nil),
+		mockLimiter.EXPECT().
+			Run(gomock.Any(), limit_rate.In{Key: "auth|ip:203.0.113.7", Limit: limit}).
+			Return(&limit_rate.Out{Limit: 100, RetryAfter: time.Second}, nil),
+	)
+	authenticated := 0
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authenticated++
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+	limited := NewRateLimiter(mockLimiter, nil).LimitIP("auth", limit)(next)
+
+	codes := make([]int, 0, 2)
+	for range 2 {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/api/links/abc", nil)
+		req.RemoteAddr = "203.0.113.7:51000"
+		// the principal is not known yet, the key is never used for the limit
+		req = req.WithContext(ContextWithPrincipal(req.Context(), model.Principal{KeyID: "k1"}))
+		limited.ServeHTTP(w, req)
+		codes = append(codes, w.Code)
+	}
+
+	assert.Equal(t, []int{http.StatusUnauthorized, http.StatusTooManyRequests}, codes)
+	assert.Equal(t, 1, authenticated)
+}
+
+func TestClientIP(t *testing.T) {
+	proxies, err := ParseTrustedProxies([]string{"10.0.0.0/8", "2001:db8::1"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		expected   string
+	}{
+		{
+			name:       "headers from untrusted clients are ignored",
+			remoteAddr: "203.0.113.7:51000",
+			headers:    map[string]string{"X-Forwarded-For": "198.51.100.1", "X-Real-IP": "198.51.100.2"},
+			expected:   "203.0.113.7",
+		},
+		{
+			name:       "rightmost untrusted hop",
+			remoteAddr: "10.0.0.2:40000",
+			headers:    map[string]string{"X-Forwarded-For": "198.51.100.1, 203.0.113.9"},
+			expected:   "203.0.113.9",
+		},
+		{
+			name:       "every hop is trusted",
+			remoteAddr: "10.0.0.2:40000",
+			headers:    map[string]string{"X-Forwarded-For": "10.1.1.1, 10.0.0.3"},
+			expected:   "10.1.1.1",
+		},
+		{
+			name:       "garbage in the header",
+			remoteAddr: "10.0.0.2:40000",
+			headers:    map[string]string{"X-Forwarded-For": "203.0.113.9, unknown, 10.0.0.3"},
+			expected:   "10.0.0.3",
+		},
+		{
+			name:       "real IP header",
+			remoteAddr: "[2001:db8::1]:40000",
+			headers:    map[string]string{"X-Real-IP": "203.0.113.9"},
+			expected:   "203.0.113.9",
+		},
+		{
+			name:       "trusted proxy without headers",
+			remoteAddr: "10.0.0.2:40000",
+			expected:   "10.0.0.2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/abc", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			assert.Equal(t, tt.expected, proxies.ClientIP(req))
+		})
+	}
+
+	_, err = ParseTrustedProxies([]string{"10.0.0.0/33"})
+	assert.Error(t, err)
+	_, err = ParseTrustedProxies([]string{"proxy.local"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,98 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+type Scope string
+
+const (
+	ScopeRead  Scope = "read"
+	ScopeWrite Scope = "write"
+	// ScopeAdmin manages API keys and links of every owner
+	ScopeAdmin Scope = "admin"
+)
+
+// scopeLevels orders the scopes, a scope grants every scope below it
+var scopeLevels = map[Scope]int{
+	ScopeRead:  1,
+	ScopeWrite: 2,
+	ScopeAdmin: 3,
+}
+
+func (s Scope) IsValid() bool {
+	_, ok := scopeLevels[s]
+	return ok
+}
+
+type APIKey struct {
+	ID      string
+	OwnerID string
+	Name    string
+	// Hash is a SHA-256 hash of the key secret, the secret itself is never stored
+	Hash      string
+	Scopes    []Scope
+	CreatedAt time.Time
+	// RevokedAt is zero for active keys
+	RevokedAt time.Time
+}
+
+func (k *APIKey) HasScope(required Scope) bool {
+	for _, scope := range k.Scopes {
+		if scopeLevels[scope] >= scopeLevels[required] {
+			return true
+		}
+	}
+	return false
+}
+
+func (k *APIKey) IsRevoked() bool {
+	return !k.RevokedAt.IsZero()
+}
+
+// Principal is who performs a request authenticated by an API key
+type Principal struct {
+	KeyID   string
+	OwnerID string
+	Admin   bool
+}
+
+func (k *APIKey) Principal() Principal {
+	return Principal{KeyID: k.ID, OwnerID: k.OwnerID, Admin: k.HasScope(ScopeAdmin)}
+}
+
+// CanManage reports whether the principal may change or inspect the link,
+// links without an owner are managed by admins only
+func (p Principal) CanManage(pair *URLPair) bool {
+	return p.Admin || (pair.OwnerID != "" && pair.OwnerID == p.OwnerID)
+}
+
+// apiKeyPrefix marks the service keys, so leaked keys are easy to find in code and logs
+const apiKeyPrefix = "lsk_"
+
+// FormatAPIKey builds the key handed out to the owner, the id part is used to find the key
+func FormatAPIKey(id, secret string) string {
+	return apiKeyPrefix + id + "_" + secret
+}
+
+// ParseAPIKey splits the key into its id and secret
+func ParseAPIKey(raw string) (id, secret string, ok bool) {
+	rest, found := strings.CutPrefix(raw, apiKeyPrefix)
+	if !found {
+		return "", "", false
+	}
+	id, secret, found = strings.Cut(rest, "_")
+	if !found || id == "" || secret == "" {
+		return "", "", false
+	}
+	return id, secret, true
+}
+
+// HashAPIKeySecret hashes the random key secret, a fast hash is enough for secrets this long
+func HashAPIKeySecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
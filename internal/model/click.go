@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// Visitor describes who followed a link, taken from the resolving request
+type Visitor struct {
+	Referrer       string
+	UserAgent      string
+	IP             string
+	AcceptLanguage string
+	// Country is an ISO 3166-1 alpha-2 code, empty when unknown
+	Country string
+}
+
+// Click is a single resolution of a short link
+type Click struct {
+	Code string
+	At   time.Time
+	// Variant is the split target the visitor was sent to, empty for links without targets
+	Variant string
+	Visitor
+}
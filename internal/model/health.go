@@ -0,0 +1,37 @@
+package model
+
+import (
+	"net/http"
+	"time"
+)
+
+// LinkCheck is the outcome of requesting the destination of a link
+type LinkCheck struct {
+	// Status is the status of the final response, 0 when there was no response
+	Status       int
+	Error        string
+	ResponseTime time.Duration
+	// Redirects are the URLs the destination redirected through before the final response
+	Redirects []string
+}
+
+// IsBroken reports destinations that are gone, failing or unreachable, pages behind
+// authentication or rate limiting are not broken
+func (c LinkCheck) IsBroken() bool {
+	return c.Error != "" || c.Status == 0 ||
+		c.Status == http.StatusNotFound || c.Status == http.StatusGone || c.Status >= http.StatusInternalServerError
+}
+
+// LinkHealth is the last check of the destination of a link
+type LinkHealth struct {
+	// Shorted is the key of the link, the short URL when returned to clients
+	Shorted  string
+	Original string
+	OwnerID  string
+	LinkCheck
+	// CheckedAt is zero for links never checked
+	CheckedAt time.Time
+	// Failures counts broken checks in a row, the rechecks back off with them
+	Failures    int
+	NextCheckAt time.Time
+}
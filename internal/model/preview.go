@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// LinkMetadata describes the destination page of a link, fields missing on the page are empty
+type LinkMetadata struct {
+	Title       string
+	Description string
+	// Favicon and Image are absolute URLs, Image comes from og:image
+	Favicon string
+	Image   string
+}
+
+// LinkPreview is the metadata fetched from the destination of a link and its fetch schedule
+type LinkPreview struct {
+	// Shorted is the key of the link
+	Shorted string
+	// Original is the destination the metadata was fetched from, it differs from the link
+	// destination when the link was changed since
+	Original string
+	LinkMetadata
+	// FetchedAt is the last successful fetch, zero when the metadata was never fetched
+	FetchedAt time.Time
+	// Error is the error of the last fetch, the metadata of the last successful fetch is kept
+	Error string
+	// Failures counts failed fetches in a row, the retries back off with them
+	Failures    int
+	NextFetchAt time.Time
+}
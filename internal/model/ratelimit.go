@@ -0,0 +1,47 @@
+package model
+
+import (
+	"math"
+	"time"
+)
+
+// RateLimit lets Burst requests through at once and refills at Rate requests per second
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+func (l RateLimit) IsEnabled() bool {
+	return l.Rate > 0 && l.Burst > 0
+}
+
+// FullAfter is how long an empty bucket takes to refill, idle buckets are full by then and may be forgotten
+func (l RateLimit) FullAfter() time.Duration {
+	if !l.IsEnabled() {
+		return 0
+	}
+	return time.Duration(float64(l.Burst) / l.Rate * float64(time.Second))
+}
+
+// TokenBucket is the state of a rate limited client, a zero bucket is full
+type TokenBucket struct {
+	Tokens    float64
+	UpdatedAt time.Time
+}
+
+// Take refills the bucket for the time passed since its last update and takes a token from it
+// when there is a whole one
+func (b TokenBucket) Take(limit RateLimit, now time.Time) (TokenBucket, bool) {
+	tokens := float64(limit.Burst)
+	if !b.UpdatedAt.IsZero() {
+		elapsed := math.Max(0, now.Sub(b.UpdatedAt).Seconds())
+		tokens = math.Min(tokens, b.Tokens+elapsed*limit.Rate)
+	}
+
+	b = TokenBucket{Tokens: tokens, UpdatedAt: now}
+	if tokens < 1 {
+		return b, false
+	}
+	b.Tokens--
+	return b, true
+}
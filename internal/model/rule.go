@@ -0,0 +1,123 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RedirectRule sends the visitors matching all of its conditions to Target, a condition left empty matches everyone
+type RedirectRule struct {
+	Target string
+	// Devices are the devices of User-Agent, see Device
+	Devices []string
+	// Languages are lowercase language tags matched against the most preferred language of the visitor,
+	// "pt" matches every Portuguese and "pt-br" the Brazilian one only
+	Languages []string
+	// Countries are uppercase ISO 3166-1 alpha-2 codes
+	Countries []string
+	// From and Until bound the time the rule is active, zero for no bound
+	From  time.Time
+	Until time.Time
+}
+
+// Audience is what the rules know about a visitor
+type Audience struct {
+	Device   string
+	Language string
+	// Country is empty when unknown, rules with countries never match it then
+	Country string
+	At      time.Time
+}
+
+func NewAudience(visitor Visitor, at time.Time) Audience {
+	return Audience{
+		Device:   Device(visitor.UserAgent),
+		Language: PreferredLanguage(visitor.AcceptLanguage),
+		Country:  strings.ToUpper(visitor.Country),
+		At:       at,
+	}
+}
+
+// Mismatches explains why the rule does not match the audience, empty when it matches
+func (r RedirectRule) Mismatches(a Audience) []string {
+	var reasons []string
+	if len(r.Devices) > 0 && !slices.Contains(r.Devices, a.Device) {
+		reasons = append(reasons, fmt.Sprintf("device %s is not one of %s", a.Device, strings.Join(r.Devices, ", ")))
+	}
+	if len(r.Languages) > 0 && !slices.ContainsFunc(r.Languages, func(tag string) bool { return languageMatches(tag, a.Language) }) {
+		reasons = append(reasons, fmt.Sprintf("language %s is not one of %s", orUnknown(a.Language), strings.Join(r.Languages, ", ")))
+	}
+	if len(r.Countries) > 0 && !slices.Contains(r.Countries, a.Country) {
+		reasons = append(reasons, fmt.Sprintf("country %s is not one of %s", orUnknown(a.Country), strings.Join(r.Countries, ", ")))
+	}
+	if !r.From.IsZero() && a.At.Before(r.From) {
+		reasons = append(reasons, "the rule is active from "+r.From.Format(time.RFC3339))
+	}
+	if !r.Until.IsZero() && !a.At.Before(r.Until) {
+		reasons = append(reasons, "the rule was active until "+r.Until.Format(time.RFC3339))
+	}
+	return reasons
+}
+
+func (r RedirectRule) Matches(a Audience) bool {
+	return len(r.Mismatches(a)) == 0
+}
+
+// Route returns the index of the first rule matching the audience with its target,
+// -1 with the original URL when none matches
+func (p *URLPair) Route(a Audience) (int, string) {
+	for i, rule := range p.Rules {
+		if rule.Matches(a) {
+			return i, rule.Target
+		}
+	}
+	return -1, p.Original
+}
+
+func languageMatches(tag, language string) bool {
+	return language == tag || strings.HasPrefix(language, tag+"-")
+}
+
+func orUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
+
+// PreferredLanguage returns the lowercase tag of the language with the highest weight in an Accept-Language header,
+// the first one of equal weights. It is empty for headers with no languages or with the wildcard on top
+func PreferredLanguage(acceptLanguage string) string {
+	var best string
+	bestWeight := 0.0
+	for _, item := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(item, ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			continue
+		}
+
+		weight := 1.0
+		for _, param := range strings.Split(params, ";") {
+			name, value, found := strings.Cut(strings.TrimSpace(param), "=")
+			if !found || strings.TrimSpace(name) != "q" {
+				continue
+			}
+			q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil || q < 0 || q > 1 {
+				q = 0
+			}
+			weight = q
+		}
+		if weight > bestWeight {
+			best, bestWeight = tag, weight
+		}
+	}
+	if best == "*" {
+		return ""
+	}
+	return best
+}
@@ -0,0 +1,42 @@
+package model
+
+// SplitTarget is one destination of an A/B split link, visitors are spread over the targets by weight
+type SplitTarget struct {
+	// Variant names the target in the sticky cookie and in the click analytics
+	Variant string
+	URL     string
+	// Weight is the share of new visitors relative to the other targets, 0 pauses the target
+	Weight int
+}
+
+// PickTarget returns the target of variant when it still takes visitors, so returning visitors keep their variant,
+// a target picked by weight otherwise. roll returns a random number in [0, n).
+// It reports false for links without targets
+func (p *URLPair) PickTarget(variant string, roll func(n int) int) (SplitTarget, bool) {
+	total := 0
+	for _, target := range p.Targets {
+		if target.Weight <= 0 {
+			continue
+		}
+		if variant != "" && target.Variant == variant {
+			return target, true
+		}
+		total += target.Weight
+	}
+	if total == 0 {
+		return SplitTarget{}, false
+	}
+
+	n := roll(total)
+	for _, target := range p.Targets {
+		if target.Weight <= 0 {
+			continue
+		}
+		if n < target.Weight {
+			return target, true
+		}
+		n -= target.Weight
+	}
+	// unreachable for roll within [0, total)
+	return SplitTarget{}, false
+}
@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+type ClickBucket struct {
+	Start  time.Time
+	Clicks int64
+}
+
+type Counter struct {
+	Value  string
+	Clicks int64
+}
+
+// ClickStats are the clicks of a link over a time range,
+// Buckets are hourly as stored in rollups or regrouped by the requested granularity
+type ClickStats struct {
+	// From and To is the range covered by the buckets, To is exclusive
+	From           time.Time
+	To             time.Time
+	Total          int64
+	UniqueVisitors int64
+	Buckets        []ClickBucket
+	Referrers      []Counter
+	Countries      []Counter
+	UserAgents     []Counter
+	// Variants are the clicks of every split target, they are not limited to the top ones
+	Variants []Counter
+}
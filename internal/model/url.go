@@ -1,6 +1,42 @@
 package model
 
+import "time"
+
 type URLPair struct {
 	Original string
 	Shorted  string
+	// RedirectStatus is the HTTP status used for redirects, 0 means the global default
+	RedirectStatus int
+	// ExpiresAt is zero for links that never expire
+	ExpiresAt time.Time
+	// MaxClicks limits how many times the link resolves, 0 means unlimited
+	MaxClicks  int
+	ClicksUsed int
+	// PasswordHash is a bcrypt hash, empty for links without a password
+	PasswordHash string
+	Disabled     bool
+	// DedupKey is set for plain links only, creating a link with the same key returns the existing one
+	DedupKey string
+	// OwnerID is the owner of the API key the link was created with, empty for links created before API keys
+	OwnerID string
+	// Rules are checked in order before redirecting, the first matching rule replaces Original
+	Rules []RedirectRule
+	// Targets split the visitors no rule matched between weighted destinations, Original stays the main destination
+	Targets []SplitTarget
+	// Interstitial shows a page with the destination and a countdown before redirecting
+	Interstitial bool
+	// Variant is the target the pair was resolved to, it is set by resolving only
+	Variant string
+}
+
+func (p *URLPair) IsExpired(now time.Time) bool {
+	return !p.ExpiresAt.IsZero() && !now.Before(p.ExpiresAt)
+}
+
+func (p *URLPair) IsExhausted() bool {
+	return p.MaxClicks > 0 && p.ClicksUsed >= p.MaxClicks
+}
+
+func (p *URLPair) IsProtected() bool {
+	return p.PasswordHash != ""
 }
@@ -0,0 +1,62 @@
+package model
+
+import "strings"
+
+// userAgentFamilies are checked in order, since most browsers mention the engines of each other
+var userAgentFamilies = []struct {
+	marker string
+	family string
+}{
+	{"bot", "Bot"},
+	{"spider", "Bot"},
+	{"crawl", "Bot"},
+	{"curl/", "curl"},
+	{"wget/", "Wget"},
+	{"edg/", "Edge"},
+	{"opr/", "Opera"},
+	{"yabrowser/", "Yandex"},
+	{"samsungbrowser/", "Samsung Internet"},
+	{"firefox/", "Firefox"},
+	{"chrome/", "Chrome"},
+	{"crios/", "Chrome"},
+	{"safari/", "Safari"},
+}
+
+// UserAgentFamily reduces a User-Agent header to the browser family, "Other" when it is not recognized
+func UserAgentFamily(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	for _, f := range userAgentFamilies {
+		if strings.Contains(ua, f.marker) {
+			return f.family
+		}
+	}
+	return "Other"
+}
+
+const (
+	DeviceIOS     = "ios"
+	DeviceAndroid = "android"
+	DeviceMobile  = "mobile"
+	DeviceDesktop = "desktop"
+	DeviceBot     = "bot"
+)
+
+// Devices are the values Device returns
+var Devices = []string{DeviceIOS, DeviceAndroid, DeviceMobile, DeviceDesktop, DeviceBot}
+
+// Device reduces a User-Agent header to the kind of device. iPads asking for desktop sites
+// pretend to be Macs and get DeviceDesktop
+func Device(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	switch {
+	case UserAgentFamily(userAgent) == "Bot":
+		return DeviceBot
+	case strings.Contains(ua, "android"):
+		return DeviceAndroid
+	case strings.Contains(ua, "iphone"), strings.Contains(ua, "ipad"), strings.Contains(ua, "ipod"):
+		return DeviceIOS
+	case strings.Contains(ua, "mobile"):
+		return DeviceMobile
+	}
+	return DeviceDesktop
+}
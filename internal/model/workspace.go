@@ -0,0 +1,88 @@
+package model
+
+import (
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/net/idna"
+)
+
+// Workspace is a tenant owning short domains, API keys and links of a workspace have its ID as the owner ID
+type Workspace struct {
+	ID        string
+	Name      string
+	Domains   []string
+	CreatedAt time.Time
+}
+
+var domainLabel = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// NormalizeHost lowercases the host of a request or URL and drops its port and trailing dot
+func NormalizeHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+}
+
+// ASCIIHost lowercases the host and converts an internationalized one to punycode,
+// hosts that can't be converted are only lowercased
+func ASCIIHost(host string) string {
+	host = strings.ToLower(host)
+	if ascii, err := idna.Lookup.ToASCII(host); err == nil {
+		return ascii
+	}
+	return host
+}
+
+// IsValidDomain reports whether the normalized domain can serve short links: at least two ASCII labels,
+// internationalized domains are expected in punycode
+func IsValidDomain(domain string) bool {
+	if len(domain) > 253 || net.ParseIP(domain) != nil {
+		return false
+	}
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return false
+	}
+	for _, label := range labels {
+		if !domainLabel.MatchString(label) {
+			return false
+		}
+	}
+	return true
+}
+
+// LinkKey identifies a link in the storage: the code itself on the default domain and domain/code
+// on custom short domains, so codes are unique per domain
+func LinkKey(domain, code string) string {
+	if domain == "" {
+		return code
+	}
+	return domain + "/" + code
+}
+
+// SplitLinkKey returns the domain and the code of the link, the domain is empty for the default domain
+func SplitLinkKey(key string) (domain, code string) {
+	domain, code, found := strings.Cut(key, "/")
+	if !found {
+		return "", key
+	}
+	return domain, code
+}
+
+// ShortURL builds the short URL of the link, links on custom domains share the scheme of the default base URL
+func ShortURL(base, key string) string {
+	domain, code := SplitLinkKey(key)
+	if domain == "" {
+		return base + code
+	}
+	scheme := "https"
+	if parsed, err := url.Parse(base); err == nil && parsed.Scheme != "" {
+		scheme = parsed.Scheme
+	}
+	return scheme + "://" + domain + "/" + code
+}
@@ -0,0 +1,208 @@
+package preview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"link-shortener-service/internal/model"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+const (
+	maxTitleLength       = 300
+	maxDescriptionLength = 1000
+	maxURLLength         = 2048
+)
+
+var (
+	ErrUnexpectedStatus = errors.New("unexpected response status")
+	ErrNilClient        = errors.New("preview fetcher needs an HTTP client")
+)
+
+type Options struct {
+	// MaxBytes limits how much of the page is read, the metadata is expected in the head
+	MaxBytes  int64
+	UserAgent string
+}
+
+// Fetcher reads the metadata of HTML pages, other content gets empty metadata
+type Fetcher struct {
+	client  *http.Client
+	options Options
+}
+
+// New returns a fetcher sending the requests with client. The client is expected to limit the time
+// of the requests and the addresses it connects to, such as the safehttp one
+func New(client *http.Client, options Options) (*Fetcher, error) {
+	if client == nil {
+		return nil, ErrNilClient
+	}
+	return &Fetcher{
+		client:  client,
+		options: options,
+	}, nil
+}
+
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (model.LinkMetadata, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return model.LinkMetadata{}, err
+	}
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.1")
+	if f.options.UserAgent != "" {
+		req.Header.Set("User-Agent", f.options.UserAgent)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return model.LinkMetadata{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return model.LinkMetadata{}, fmt.Errorf("%w: %d", ErrUnexpectedStatus, resp.StatusCode)
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return model.LinkMetadata{}, nil
+	}
+
+	var body io.Reader = resp.Body
+	if f.options.MaxBytes > 0 {
+		body = io.LimitReader(body, f.options.MaxBytes)
+	}
+	body, err = charset.NewReader(body, contentType)
+	if err != nil {
+		return model.LinkMetadata{}, err
+	}
+
+	// relative URLs are resolved against the page the redirects ended at
+	return parse(body, resp.Request.URL), nil
+}
+
+// parse reads the head of the page, a page cut by the size limit keeps what was read
+func parse(body io.Reader, base *url.URL) model.LinkMetadata {
+	var (
+		title, ogTitle, description, ogDescription string
+		icon, touchIcon, image                     string
+		inTitle                                    bool
+	)
+
+	tokenizer := html.NewTokenizer(body)
+	for done := false; !done; {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			done = true
+		case html.TextToken:
+			if inTitle && title == "" {
+				title = string(tokenizer.Text())
+			}
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			switch string(name) {
+			case "title":
+				inTitle = false
+			case "head":
+				done = true
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttrs := tokenizer.TagName()
+			attrs := map[string]string{}
+			for hasAttrs {
+				var key, value []byte
+				key, value, hasAttrs = tokenizer.TagAttr()
+				if _, seen := attrs[string(key)]; !seen {
+					attrs[string(key)] = string(value)
+				}
+			}
+
+			switch string(name) {
+			case "title":
+				inTitle = true
+			case "body":
+				done = true
+			case "base":
+				if href, err := base.Parse(attrs["href"]); err == nil && attrs["href"] != "" {
+					base = href
+				}
+			case "meta":
+				content, key := attrs["content"], attrs["property"]
+				if key == "" {
+					key = attrs["name"]
+				}
+				switch strings.ToLower(key) {
+				case "og:title":
+					ogTitle = first(ogTitle, content)
+				case "og:description":
+					ogDescription = first(ogDescription, content)
+				case "description":
+					description = first(description, content)
+				case "og:image", "og:image:url", "og:image:secure_url":
+					image = first(image, resolve(base, content))
+				}
+			case "link":
+				for _, rel := range strings.Fields(strings.ToLower(attrs["rel"])) {
+					switch rel {
+					case "icon":
+						icon = first(icon, resolve(base, attrs["href"]))
+					case "apple-touch-icon":
+						touchIcon = first(touchIcon, resolve(base, attrs["href"]))
+					}
+				}
+			}
+		}
+	}
+
+	return model.LinkMetadata{
+		Title:       truncate(clean(first(title, ogTitle)), maxTitleLength),
+		Description: truncate(clean(first(description, ogDescription)), maxDescriptionLength),
+		// browsers look for the icon at the root of the site when the page does not link one
+		Favicon: first(icon, touchIcon, resolve(base, "/favicon.ico")),
+		Image:   image,
+	}
+}
+
+// resolve returns the absolute HTTP URL of ref, empty for other schemes and too long URLs
+func resolve(base *url.URL, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return ""
+	}
+	resolved, err := base.Parse(ref)
+	if err != nil || (resolved.Scheme != "http" && resolved.Scheme != "https") {
+		return ""
+	}
+	if result := resolved.String(); len(result) <= maxURLLength {
+		return result
+	}
+	return ""
+}
+
+func first(values ...string) string {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func clean(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func truncate(text string, length int) string {
+	if utf8.RuneCountInString(text) <= length {
+		return text
+	}
+	return string([]rune(text)[:length-1]) + "…"
+}
@@ -0,0 +1,143 @@
+package preview
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"link-shortener-service/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const page = `<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>
+    Fish &amp; Chips
+  </title>
+  <meta name="description" content="The best   fish in town">
+  <meta property="og:title" content="Fish and Chips shop">
+  <meta property="og:image" content="/img/og.png">
+  <link rel="apple-touch-icon" href="/touch.png">
+  <link rel="shortcut icon" href="static/favicon.png">
+  <link rel="icon" href="javascript:alert(1)">
+</head>
+<body>
+  <title>Not the title</title>
+</body>
+</html>`
+
+func TestFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test-fetcher", r.UserAgent())
+
+		switch r.URL.Path {
+		case "/moved":
+			http.Redirect(w, r, "/shop/", http.StatusFound)
+		case "/shop/":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write([]byte(page))
+		case "/bare":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte(`<html><head><meta property="og:title" content="Only OG">` +
+				`<meta property="og:description" content="From OG"></head></html>`))
+		case "/latin1":
+			w.Header().Set("Content-Type", "text/html; charset=iso-8859-1")
+			_, _ = w.Write([]byte("<title>Caf\xe9</title>"))
+		case "/huge":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte("<html><head>" + strings.Repeat("<!-- padding -->", 1000) + "<title>Too far</title>"))
+		case "/file.pdf":
+			w.Header().Set("Content-Type", "application/pdf")
+			_, _ = w.Write([]byte("%PDF-1.4"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	fetcher, err := New(server.Client(), Options{MaxBytes: 4096, UserAgent: "test-fetcher"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		path          string
+		expected      model.LinkMetadata
+		expectedError error
+	}{
+		{
+			name: "page behind a redirect",
+			path: "/moved",
+			expected: model.LinkMetadata{
+				Title:       "Fish & Chips",
+				Description: "The best fish in town",
+				Favicon:     server.URL + "/shop/static/favicon.png",
+				Image:       server.URL + "/img/og.png",
+			},
+		},
+		{
+			name: "open graph fallbacks",
+			path: "/bare",
+			expected: model.LinkMetadata{
+				Title:       "Only OG",
+				Description: "From OG",
+				Favicon:     server.URL + "/favicon.ico",
+			},
+		},
+		{
+			name: "page in another charset",
+			path: "/latin1",
+			expected: model.LinkMetadata{
+				Title:   "Café",
+				Favicon: server.URL + "/favicon.ico",
+			},
+		},
+		{
+			name: "page over the size limit",
+			path: "/huge",
+			expected: model.LinkMetadata{
+				Favicon: server.URL + "/favicon.ico",
+			},
+		},
+		{
+			name: "not HTML",
+			path: "/file.pdf",
+		},
+		{
+			name:          "missing page",
+			path:          "/gone",
+			expectedError: ErrUnexpectedStatus,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata, err := fetcher.Fetch(context.Background(), server.URL+tt.path)
+
+			assert.ErrorIs(t, err, tt.expectedError)
+			assert.Equal(t, tt.expected, metadata)
+		})
+	}
+}
+
+func TestNewRequiresClient(t *testing.T) {
+	fetcher, err := New(nil, Options{})
+
+	assert.ErrorIs(t, err, ErrNilClient)
+	assert.Nil(t, fetcher)
+}
+
+func TestTruncate(t *testing.T) {
+	title := strings.Repeat("я", maxTitleLength+10)
+
+	metadata := parse(strings.NewReader("<title>"+title+"</title>"), &url.URL{Scheme: "https", Host: "some.com"})
+
+	require.Equal(t, maxTitleLength, len([]rune(metadata.Title)))
+	assert.True(t, strings.HasSuffix(metadata.Title, "…"))
+}
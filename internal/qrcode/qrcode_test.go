@@ -0,0 +1,369 @@
+package qrcode
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"rsc.io/qr/coding"
+)
+
+var update = flag.Bool("update", false, "regenerate the golden symbols in testdata with rsc.io/qr/coding")
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		input         string
+		expected      Level
+		expectedError error
+	}{
+		{"L", LevelL, nil},
+		{"m", LevelM, nil},
+		{"Q", LevelQ, nil},
+		{"h", LevelH, nil},
+		{"", 0, ErrInvalidLevel},
+		{"X", 0, ErrInvalidLevel},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			level, err := ParseLevel(tt.input)
+
+			assert.ErrorIs(t, err, tt.expectedError)
+			assert.Equal(t, tt.expected, level)
+		})
+	}
+}
+
+func TestDataCodewords(t *testing.T) {
+	tests := []struct {
+		version  int
+		level    Level
+		expected int
+	}{
+		{1, LevelL, 19},
+		{1, LevelM, 16},
+		{1, LevelQ, 13},
+		{1, LevelH, 9},
+		{5, LevelQ, 62},
+		{10, LevelM, 216},
+		{20, LevelH, 385},
+		{40, LevelL, 2956},
+		{40, LevelH, 1276},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d-%s", tt.version, tt.level), func(t *testing.T) {
+			assert.Equal(t, tt.expected, dataCodewords(tt.version, tt.level))
+		})
+	}
+}
+
+func TestAlignmentPositions(t *testing.T) {
+	tests := []struct {
+		version  int
+		expected []int
+	}{
+		{1, nil},
+		{2, []int{6, 18}},
+		{7, []int{6, 22, 38}},
+		{22, []int{6, 26, 50, 74, 98}},
+		{32, []int{6, 34, 60, 86, 112, 138}},
+		{36, []int{6, 24, 50, 76, 102, 128, 154}},
+		{40, []int{6, 30, 58, 86, 114, 142, 170}},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.version), func(t *testing.T) {
+			assert.Equal(t, tt.expected, alignmentPositions(tt.version))
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		name            string
+		length          int
+		level           Level
+		expectedVersion int
+		expectedError   error
+	}{
+		{"smallest", 17, LevelL, 1, nil},
+		{"one byte over", 18, LevelL, 2, nil},
+		{"level raises version", 17, LevelH, 3, nil},
+		{"largest", 2953, LevelL, 40, nil},
+		{"too long", 2954, LevelL, 0, ErrTooLong},
+		{"invalid level", 1, Level(4), 0, ErrInvalidLevel},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := Encode([]byte(strings.Repeat("a", tt.length)), tt.level)
+
+			assert.ErrorIs(t, err, tt.expectedError)
+			if tt.expectedError != nil {
+				return
+			}
+			assert.Equal(t, tt.expectedVersion, code.Version)
+			assert.Equal(t, tt.expectedVersion*4+17, code.Size)
+		})
+	}
+}
+
+func TestEncodeFunctionPatterns(t *testing.T) {
+	code, err := Encode([]byte("https://sho.rt/abc123"), LevelM)
+	require.NoError(t, err)
+
+	finder := []string{
+		"#######.",
+		"#.....#.",
+		"#.###.#.",
+		"#.###.#.",
+		"#.###.#.",
+		"#.....#.",
+		"#######.",
+		"........",
+	}
+	last := code.Size - 1
+	for y, row := range finder {
+		for x, c := range row {
+			dark := c == '#'
+			assert.Equal(t, dark, code.Dark(x, y), "top left %d,%d", x, y)
+			assert.Equal(t, dark, code.Dark(last-x, y), "top right %d,%d", x, y)
+			assert.Equal(t, dark, code.Dark(x, last-y), "bottom left %d,%d", x, y)
+		}
+	}
+	for i := 8; i < code.Size-8; i++ {
+		assert.Equal(t, i%2 == 0, code.Dark(i, 6), "horizontal timing %d", i)
+		assert.Equal(t, i%2 == 0, code.Dark(6, i), "vertical timing %d", i)
+	}
+	assert.True(t, code.Dark(8, code.Size-8), "dark module")
+}
+
+// format bits from ISO/IEC 18004 annex C, for mask 0 and every level
+func TestEncodeFormatBits(t *testing.T) {
+	tests := []struct {
+		level    Level
+		expected int
+	}{
+		{LevelL, 0b111011111000100},
+		{LevelM, 0b101010000010010},
+		{LevelQ, 0b011010101011111},
+		{LevelH, 0b001011010001001},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.level.String(), func(t *testing.T) {
+			code, err := encode([]byte("https://sho.rt/abc123"), tt.level, 0)
+			require.NoError(t, err)
+
+			first, second := 0, 0
+			for i := range 15 {
+				var x, y int
+				switch {
+				case i < 6:
+					x, y = 8, i
+				case i < 8:
+					x, y = 8, i+1
+				case i == 8:
+					x, y = 7, 8
+				default:
+					x, y = 14-i, 8
+				}
+				if code.Dark(x, y) {
+					first |= 1 << i
+				}
+
+				x, y = code.Size-1-i, 8
+				if i >= 8 {
+					x, y = 8, code.Size-15+i
+				}
+				if code.Dark(x, y) {
+					second |= 1 << i
+				}
+			}
+			assert.Equal(t, tt.expected, first)
+			assert.Equal(t, tt.expected, second)
+		})
+	}
+}
+
+// version information of version 7 from ISO/IEC 18004 annex D
+func TestEncodeVersionBits(t *testing.T) {
+	code, err := Encode([]byte(strings.Repeat("a", 150)), LevelL)
+	require.NoError(t, err)
+	require.Equal(t, 7, code.Version)
+
+	bottomLeft, topRight := 0, 0
+	for i := range 18 {
+		a, b := code.Size-11+i%3, i/3
+		if code.Dark(b, a) {
+			bottomLeft |= 1 << i
+		}
+		if code.Dark(a, b) {
+			topRight |= 1 << i
+		}
+	}
+	assert.Equal(t, 0b000111110010010100, bottomLeft)
+	assert.Equal(t, 0b000111110010010100, topRight)
+}
+
+// the golden symbols in testdata come from rsc.io/qr/coding, an independent encoder that takes
+// the version and the mask as given, '#' is a dark module. Regenerate them with
+//
+//	go test ./internal/qrcode -run TestEncodeGolden -update
+func TestEncodeGolden(t *testing.T) {
+	const long = "https://links.example.com/spring-campaign?utm_source=newsletter&utm_medium=email&utm_campaign=launch-2025-spring-edition-with-extras-for-everyone"
+
+	tests := []struct {
+		golden          string
+		data            string
+		level           Level
+		mask            int
+		expectedVersion int
+		expectedMask    int
+	}{
+		{"version1-M-mask0", "sho.rt/Zx9", LevelM, 0, 1, 0},
+		{"version2-L-mask0", "https://sho.rt/abc123", LevelL, 0, 2, 0},
+		{"version2-M-mask1", "https://sho.rt/abc123", LevelM, 1, 2, 1},
+		{"version3-H-mask2", "https://sho.rt/Zx9", LevelH, 2, 3, 2},
+		{"version6-Q-mask3", "https://links.example.com/spring-campaign?utm_source=newsletter", LevelQ, 3, 6, 3},
+		{"version7-L-mask4", long, LevelL, 4, 7, 4},
+		{"version8-M-mask5", long, LevelM, 5, 8, 5},
+		{"version10-Q-mask6", long, LevelQ, 6, 10, 6},
+		{"version12-H-mask7", long, LevelH, 7, 12, 7},
+		{"version3-Q-lowest-penalty", "https://sho.rt/abc123", LevelQ, -1, 3, 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			path := filepath.Join("testdata", tt.golden+".golden")
+			if *update {
+				require.NoError(t, os.WriteFile(path, []byte(referenceSymbol(t, tt.data, tt.level, tt.expectedVersion, tt.expectedMask)), 0o644))
+			}
+			golden, err := os.ReadFile(path)
+			require.NoError(t, err)
+
+			code, err := encode([]byte(tt.data), tt.level, tt.mask)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedVersion, code.Version)
+			assert.Equal(t, tt.expectedMask, code.Mask)
+			assert.Equal(t, string(golden), render(code))
+		})
+	}
+}
+
+// referenceSymbol encodes data in byte mode with rsc.io/qr/coding and draws it like render
+func referenceSymbol(t *testing.T, data string, level Level, version, mask int) string {
+	plan, err := coding.NewPlan(coding.Version(version), coding.Level(level), coding.Mask(mask))
+	require.NoError(t, err)
+	code, err := plan.Encode(coding.String(data))
+	require.NoError(t, err)
+
+	var b strings.Builder
+	for y := range code.Size {
+		for x := range code.Size {
+			if code.Black(x, y) {
+				b.WriteByte('#')
+			} else {
+				b.WriteByte('.')
+			}
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// render draws the code a row per line, '#' for dark modules and '.' for light ones
+func render(code *Code) string {
+	var b strings.Builder
+	for y := range code.Size {
+		for x := range code.Size {
+			if code.Dark(x, y) {
+				b.WriteByte('#')
+			} else {
+				b.WriteByte('.')
+			}
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+func TestEncodeIsDeterministic(t *testing.T) {
+	first, err := Encode([]byte("https://sho.rt/abc123"), LevelQ)
+	require.NoError(t, err)
+	second, err := Encode([]byte("https://sho.rt/abc123"), LevelQ)
+	require.NoError(t, err)
+
+	assert.Equal(t, first, second)
+}
+
+func TestPNG(t *testing.T) {
+	code, err := Encode([]byte("https://s.io"), LevelM)
+	require.NoError(t, err)
+	require.Equal(t, 21, code.Size)
+
+	style := Style{
+		Size:       300,
+		Margin:     4,
+		Foreground: color.NRGBA{R: 0x11, G: 0x22, B: 0x33, A: 0xff},
+		Background: color.NRGBA{R: 0xff, G: 0xee, B: 0xdd, A: 0xff},
+	}
+	var buf bytes.Buffer
+	require.NoError(t, code.PNG(&buf, style))
+
+	img, err := png.Decode(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, 300, img.Bounds().Dx())
+	assert.Equal(t, 300, img.Bounds().Dy())
+
+	// 29 modules of 10 pixels centered with a 5 pixel border, the quiet zone is 40 pixels
+	toNRGBA := func(x, y int) color.NRGBA {
+		return color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+	}
+	assert.Equal(t, style.Background, toNRGBA(0, 0))
+	assert.Equal(t, style.Background, toNRGBA(44, 44))
+	assert.Equal(t, style.Foreground, toNRGBA(45, 45))
+	assert.Equal(t, style.Foreground, toNRGBA(54, 54))
+	assert.Equal(t, style.Background, toNRGBA(55, 55))
+}
+
+func TestPNGGrowsToFitModules(t *testing.T) {
+	code, err := Encode([]byte("https://s.io"), LevelM)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, code.PNG(&buf, Style{Size: 10, Margin: 4}))
+
+	img, err := png.Decode(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, 29, img.Bounds().Dx())
+}
+
+func TestSVG(t *testing.T) {
+	code, err := Encode([]byte("https://s.io"), LevelM)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	err = code.SVG(&buf, Style{
+		Size:       300,
+		Margin:     4,
+		Foreground: color.NRGBA{A: 0xff},
+		Background: color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0x80},
+	})
+	require.NoError(t, err)
+
+	svg := buf.String()
+	assert.True(t, strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" width="300" height="300" viewBox="0 0 300 300"`))
+	assert.Contains(t, svg, `<rect width="300" height="300" fill="#ffffff" fill-opacity="0.502"/>`)
+	assert.Contains(t, svg, `<path transform="translate(45 45) scale(10)" fill="#000000" d="M0 0h7v1h-7z`)
+}
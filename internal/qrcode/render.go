@@ -0,0 +1,89 @@
+package qrcode
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strings"
+)
+
+// Style is how a code is drawn, Size is in pixels and Margin is the quiet zone in modules
+type Style struct {
+	Size       int
+	Margin     int
+	Foreground color.NRGBA
+	Background color.NRGBA
+}
+
+// scale is the whole number of pixels per module that fits the style size, never less than one,
+// the image grows when the modules do not fit
+func (c *Code) scale(style Style) (side, scale, offset int) {
+	modules := c.Size + 2*style.Margin
+	scale = max(style.Size/modules, 1)
+	side = max(style.Size, modules*scale)
+	offset = (side-modules*scale)/2 + style.Margin*scale
+	return side, scale, offset
+}
+
+// PNG writes the code as a two color paletted image
+func (c *Code) PNG(w io.Writer, style Style) error {
+	side, scale, offset := c.scale(style)
+	img := image.NewPaletted(image.Rect(0, 0, side, side), color.Palette{style.Background, style.Foreground})
+	for y := range c.Size {
+		for x := range c.Size {
+			if !c.Dark(x, y) {
+				continue
+			}
+			for py := offset + y*scale; py < offset+(y+1)*scale; py++ {
+				row := img.Pix[py*img.Stride:]
+				for px := offset + x*scale; px < offset+(x+1)*scale; px++ {
+					row[px] = 1
+				}
+			}
+		}
+	}
+
+	encoder := png.Encoder{CompressionLevel: png.BestCompression}
+	return encoder.Encode(w, img)
+}
+
+// SVG writes the code as a path of horizontal runs of dark modules in module units,
+// the view box keeps the same geometry as the PNG
+func (c *Code) SVG(w io.Writer, style Style) error {
+	side, scale, offset := c.scale(style)
+
+	var path strings.Builder
+	for y := range c.Size {
+		for x := 0; x < c.Size; x++ {
+			if !c.Dark(x, y) {
+				continue
+			}
+			start := x
+			for x < c.Size && c.Dark(x, y) {
+				x++
+			}
+			fmt.Fprintf(&path, "M%d %dh%dv1h-%dz", start, y, x-start, x-start)
+		}
+	}
+
+	_, err := fmt.Fprintf(w,
+		`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`+
+			`<rect width="%d" height="%d"%s/>`+
+			`<path transform="translate(%d %d) scale(%d)"%s d="%s"/>`+
+			`</svg>`+"\n",
+		side, side, side, side,
+		side, side, fill(style.Background),
+		offset, offset, scale, fill(style.Foreground), path.String(),
+	)
+	return err
+}
+
+func fill(c color.NRGBA) string {
+	result := fmt.Sprintf(` fill="#%02x%02x%02x"`, c.R, c.G, c.B)
+	if c.A != 0xff {
+		result += fmt.Sprintf(` fill-opacity="%.3g"`, float64(c.A)/0xff)
+	}
+	return result
+}
@@ -0,0 +1,285 @@
+package qrcode
+
+// symbol is a code being built, function modules are the patterns that are never masked
+type symbol struct {
+	version    int
+	size       int
+	modules    []bool
+	isFunction []bool
+}
+
+func newSymbol(version int) *symbol {
+	size := version*4 + 17
+	return &symbol{
+		version:    version,
+		size:       size,
+		modules:    make([]bool, size*size),
+		isFunction: make([]bool, size*size),
+	}
+}
+
+func (s *symbol) dark(x, y int) bool {
+	return s.modules[y*s.size+x]
+}
+
+func (s *symbol) setFunction(x, y int, dark bool) {
+	s.modules[y*s.size+x] = dark
+	s.isFunction[y*s.size+x] = true
+}
+
+func (s *symbol) drawFunctionPatterns() {
+	for i := range s.size {
+		s.setFunction(6, i, i%2 == 0)
+		s.setFunction(i, 6, i%2 == 0)
+	}
+
+	s.drawFinder(3, 3)
+	s.drawFinder(s.size-4, 3)
+	s.drawFinder(3, s.size-4)
+
+	positions := alignmentPositions(s.version)
+	last := len(positions) - 1
+	for i, x := range positions {
+		for j, y := range positions {
+			// the corners taken by the finders
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			s.drawAlignment(x, y)
+		}
+	}
+
+	// the format and version areas are reserved now and drawn once the mask is known
+	s.drawFormatBits(LevelL, 0)
+	s.drawVersionBits()
+}
+
+// drawFinder draws the finder centered at x, y with its separator
+func (s *symbol) drawFinder(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || xx >= s.size || yy < 0 || yy >= s.size {
+				continue
+			}
+			dist := max(abs(dx), abs(dy))
+			s.setFunction(xx, yy, dist != 2 && dist != 4)
+		}
+	}
+}
+
+func (s *symbol) drawAlignment(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			s.setFunction(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+// alignmentPositions are the centers of the alignment patterns on each axis
+func alignmentPositions(version int) []int {
+	if version == 1 {
+		return nil
+	}
+	count := version/7 + 2
+	step := (version*8 + count*3 + 5) / (count*4 - 4) * 2
+	result := make([]int, count)
+	result[0] = 6
+	for i, pos := count-1, version*4+10; i > 0; i, pos = i-1, pos-step {
+		result[i] = pos
+	}
+	return result
+}
+
+// drawFormatBits draws both copies of the level and mask with their BCH code, and the dark module
+func (s *symbol) drawFormatBits(level Level, mask int) {
+	data := level.formatBits()<<3 | mask
+	rem := data
+	for range 10 {
+		rem = rem<<1 ^ (rem>>9)*0x537
+	}
+	bits := (data<<10 | rem) ^ 0x5412
+	bit := func(i int) bool { return bits>>i&1 == 1 }
+
+	for i := 0; i <= 5; i++ {
+		s.setFunction(8, i, bit(i))
+	}
+	s.setFunction(8, 7, bit(6))
+	s.setFunction(8, 8, bit(7))
+	s.setFunction(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		s.setFunction(14-i, 8, bit(i))
+	}
+
+	for i := range 8 {
+		s.setFunction(s.size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		s.setFunction(8, s.size-15+i, bit(i))
+	}
+	s.setFunction(8, s.size-8, true)
+}
+
+// drawVersionBits draws both copies of the version with its BCH code, codes from version 7 have them
+func (s *symbol) drawVersionBits() {
+	if s.version < 7 {
+		return
+	}
+	rem := s.version
+	for range 12 {
+		rem = rem<<1 ^ (rem>>11)*0x1F25
+	}
+	bits := s.version<<12 | rem
+
+	for i := range 18 {
+		dark := bits>>i&1 == 1
+		a, b := s.size-11+i%3, i/3
+		s.setFunction(a, b, dark)
+		s.setFunction(b, a, dark)
+	}
+}
+
+// drawCodewords fills the modules left by the function patterns in the zigzag order:
+// column pairs from the right, upwards and downwards in turn, skipping the vertical timing pattern
+func (s *symbol) drawCodewords(codewords []byte) {
+	i := 0
+	for right := s.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := range s.size {
+			for j := range 2 {
+				x := right - j
+				y := vert
+				if (right+1)&2 == 0 {
+					y = s.size - 1 - vert
+				}
+				if s.isFunction[y*s.size+x] {
+					continue
+				}
+				// the remainder bits left after the codewords are light
+				if i < len(codewords)*8 {
+					s.modules[y*s.size+x] = codewords[i/8]>>(7-i%8)&1 == 1
+					i++
+				}
+			}
+		}
+	}
+}
+
+// applyMask flips the data modules selected by the mask pattern
+func (s *symbol) applyMask(mask int) {
+	for y := range s.size {
+		for x := range s.size {
+			if s.isFunction[y*s.size+x] {
+				continue
+			}
+			var flip bool
+			switch mask {
+			case 0:
+				flip = (x+y)%2 == 0
+			case 1:
+				flip = y%2 == 0
+			case 2:
+				flip = x%3 == 0
+			case 3:
+				flip = (x+y)%3 == 0
+			case 4:
+				flip = (x/3+y/2)%2 == 0
+			case 5:
+				flip = x*y%2+x*y%3 == 0
+			case 6:
+				flip = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				flip = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if flip {
+				s.modules[y*s.size+x] = !s.modules[y*s.size+x]
+			}
+		}
+	}
+}
+
+// penalty scores the symbol by the rules of ISO/IEC 18004 section 8.8.2, the mask with the
+// lowest score is the easiest to read
+func (s *symbol) penalty() int {
+	const (
+		n1 = 3
+		n2 = 3
+		n3 = 40
+		n4 = 10
+	)
+	result := 0
+
+	// runs of five or more modules of the same color, and finder-like patterns in rows and columns
+	for _, horizontal := range []bool{true, false} {
+		for a := range s.size {
+			at := func(b int) bool {
+				if horizontal {
+					return s.dark(b, a)
+				}
+				return s.dark(a, b)
+			}
+			run := 0
+			for b := range s.size {
+				if b > 0 && at(b) == at(b-1) {
+					run++
+				} else {
+					run = 1
+				}
+				if run == 5 {
+					result += n1
+				} else if run > 5 {
+					result++
+				}
+				if b >= 10 && isFinderLike(at, b-10) {
+					result += n3
+				}
+			}
+		}
+	}
+
+	// 2x2 blocks of the same color
+	for y := 0; y < s.size-1; y++ {
+		for x := 0; x < s.size-1; x++ {
+			c := s.dark(x, y)
+			if c == s.dark(x+1, y) && c == s.dark(x, y+1) && c == s.dark(x+1, y+1) {
+				result += n2
+			}
+		}
+	}
+
+	// the share of dark modules far from a half, in steps of five percent
+	dark := 0
+	for _, m := range s.modules {
+		if m {
+			dark++
+		}
+	}
+	total := len(s.modules)
+	result += abs(dark*20-total*10) / total * n4
+
+	return result
+}
+
+// isFinderLike reports the 1:1:3:1:1 dark pattern with four light modules on either side
+// among the eleven modules starting at start
+func isFinderLike(at func(int) bool, start int) bool {
+	const pattern = 0b10111010000
+	matches := func(p int) bool {
+		for i := range 11 {
+			if at(start+i) != (p>>(10-i)&1 == 1) {
+				return false
+			}
+		}
+		return true
+	}
+	return matches(pattern) || matches(0b00001011101)
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,284 @@
+package screening
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"link-shortener-service/internal/model"
+)
+
+// minPrefixLength keeps a short line of a hash prefix file from blocking a good share of the web
+const minPrefixLength = 8
+
+var (
+	ErrBlocked     = errors.New("destination is blocklisted")
+	ErrInvalidURL  = errors.New("destination URL can't be screened")
+	ErrInvalidList = errors.New("invalid blocklist")
+)
+
+// Sources are the blocklist files. Domain files list a domain per line, hosts file lines work as well,
+// and block the subdomains too. Pattern files list regular expressions matched against the whole URL.
+// Hash prefix files list hex prefixes of SHA-256 hashes of the URL expressions, like Safe Browsing does.
+// Empty lines and lines starting with # are skipped
+type Sources struct {
+	DomainFiles     []string
+	PatternFiles    []string
+	HashPrefixFiles []string
+}
+
+func (s Sources) files() []string {
+	files := append([]string(nil), s.DomainFiles...)
+	files = append(files, s.PatternFiles...)
+	return append(files, s.HashPrefixFiles...)
+}
+
+type lists struct {
+	domains  map[string]struct{}
+	patterns []*regexp.Regexp
+	// prefixes by their length
+	prefixes map[int]map[string]struct{}
+}
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// Screener checks destinations against the blocklists, Reload picks up the changed files
+// without blocking the checks
+type Screener struct {
+	sources Sources
+	lists   atomic.Pointer[lists]
+
+	mu     sync.Mutex
+	stamps map[string]fileStamp
+}
+
+func New(sources Sources) (*Screener, error) {
+	s := &Screener{sources: sources}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Check returns ErrBlocked with the matched entry when the URL is blocklisted and ErrInvalidURL
+// when it can't be parsed. Internationalized hosts are checked in punycode, patterns are matched
+// against both forms of the URL
+func (s *Screener) Check(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+	host := strings.TrimSuffix(model.ASCIIHost(parsed.Hostname()), ".")
+	forms := []string{rawURL}
+	if host != parsed.Hostname() && parsed.Host != "" {
+		ascii := *parsed
+		ascii.Host = host
+		if port := parsed.Port(); port != "" {
+			ascii.Host = net.JoinHostPort(host, port)
+		}
+		forms = append(forms, ascii.String())
+	}
+	l := s.lists.Load()
+
+	for _, suffix := range hostSuffixes(host) {
+		if _, blocked := l.domains[suffix]; blocked {
+			return fmt.Errorf("%w: domain %s", ErrBlocked, suffix)
+		}
+	}
+	for _, pattern := range l.patterns {
+		for _, form := range forms {
+			if pattern.MatchString(form) {
+				return fmt.Errorf("%w: pattern %s", ErrBlocked, pattern)
+			}
+		}
+	}
+	if len(l.prefixes) > 0 {
+		for _, expression := range expressions(host, parsed) {
+			sum := sha256.Sum256([]byte(expression))
+			hash := hex.EncodeToString(sum[:])
+			for length, prefixes := range l.prefixes {
+				if _, blocked := prefixes[hash[:length]]; blocked {
+					return fmt.Errorf("%w: hash prefix of %s", ErrBlocked, expression)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Reload loads the lists again when any of the files changed, the old lists stay in use when the new ones are broken
+func (s *Screener) Reload(_ context.Context) error {
+	s.mu.Lock()
+	changed := false
+	for _, file := range s.sources.files() {
+		stamp, err := stampOf(file)
+		if err != nil || stamp != s.stamps[file] {
+			changed = true
+			break
+		}
+	}
+	s.mu.Unlock()
+
+	if !changed {
+		return nil
+	}
+	return s.load()
+}
+
+func (s *Screener) load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stamps := make(map[string]fileStamp)
+	l := &lists{
+		domains:  make(map[string]struct{}),
+		prefixes: make(map[int]map[string]struct{}),
+	}
+
+	err := readLists(s.sources.DomainFiles, stamps, func(line string) error {
+		fields := strings.Fields(line)
+		domain := strings.TrimPrefix(strings.TrimSuffix(model.ASCIIHost(fields[len(fields)-1]), "."), "*.")
+		l.domains[domain] = struct{}{}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = readLists(s.sources.PatternFiles, stamps, func(line string) error {
+		pattern, err := regexp.Compile(line)
+		if err != nil {
+			return err
+		}
+		l.patterns = append(l.patterns, pattern)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = readLists(s.sources.HashPrefixFiles, stamps, func(line string) error {
+		prefix := strings.ToLower(line)
+		if _, err := hex.DecodeString(prefix); err != nil || len(prefix) < minPrefixLength || len(prefix) > sha256.Size*2 {
+			return fmt.Errorf("hash prefix %q must be %d to %d hex digits", line, minPrefixLength, sha256.Size*2)
+		}
+		if l.prefixes[len(prefix)] == nil {
+			l.prefixes[len(prefix)] = make(map[string]struct{})
+		}
+		l.prefixes[len(prefix)][prefix] = struct{}{}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.lists.Store(l)
+	s.stamps = stamps
+	return nil
+}
+
+func readLists(files []string, stamps map[string]fileStamp, add func(line string) error) error {
+	for _, file := range files {
+		stamp, err := stampOf(file)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidList, err)
+		}
+		if err = readList(file, add); err != nil {
+			return err
+		}
+		stamps[file] = stamp
+	}
+	return nil
+}
+
+func readList(file string, add func(line string) error) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidList, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if err = add(line); err != nil {
+			return fmt.Errorf("%w: %s:%d: %v", ErrInvalidList, file, n, err)
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidList, file, err)
+	}
+	return nil
+}
+
+func stampOf(file string) (fileStamp, error) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return fileStamp{}, err
+	}
+	return fileStamp{modTime: info.ModTime(), size: info.Size()}, nil
+}
+
+// hostSuffixes are the host and its parent domains, a.b.c gives a.b.c, b.c and c
+func hostSuffixes(host string) []string {
+	if host == "" {
+		return nil
+	}
+	suffixes := []string{host}
+	for i := strings.IndexByte(host, '.'); i >= 0; i = strings.IndexByte(host, '.') {
+		host = host[i+1:]
+		if host != "" {
+			suffixes = append(suffixes, host)
+		}
+	}
+	return suffixes
+}
+
+// expressions combine the host suffixes of at least two labels with the path prefixes:
+// a.b.c/1/2?x gives a.b.c/1/2?x, a.b.c/1/2, a.b.c/1/, a.b.c/, b.c/1/2?x and so on
+func expressions(host string, parsed *url.URL) []string {
+	path := parsed.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	paths := []string{path}
+	if parsed.RawQuery != "" {
+		paths = append([]string{path + "?" + parsed.RawQuery}, paths...)
+	}
+	for i := strings.IndexByte(path, '/'); i >= 0 && i < len(path)-1; {
+		paths = append(paths, path[:i+1])
+		next := strings.IndexByte(path[i+1:], '/')
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+
+	var result []string
+	for _, suffix := range hostSuffixes(host) {
+		if suffix != host && !strings.Contains(suffix, ".") {
+			continue
+		}
+		for _, p := range paths {
+			result = append(result, suffix+p)
+		}
+	}
+	return result
+}
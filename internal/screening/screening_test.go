@@ -0,0 +1,121 @@
+package screening
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeList(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	file := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+	return file
+}
+
+func hashPrefix(expression string) string {
+	sum := sha256.Sum256([]byte(expression))
+	return hex.EncodeToString(sum[:])[:8]
+}
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	s, err := New(Sources{
+		DomainFiles: []string{writeList(t, dir, "domains.txt", "# phishing\nevil.com\n0.0.0.0 Tracker.NET.\n\n*.casino.io\n")},
+		PatternFiles: []string{writeList(t, dir, "patterns.txt",
+			`(?i)^https?://[^/]*paypa1\.`+"\n"+`\.exe$`+"\n")},
+		HashPrefixFiles: []string{writeList(t, dir, "prefixes.txt", hashPrefix("bad.org/login/")+"\n")},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		url     string
+		blocked bool
+	}{
+		{url: "https://evil.com/", blocked: true},
+		{url: "https://login.EVIL.com:8443/x", blocked: true},
+		{url: "https://notevil.com/", blocked: false},
+		{url: "http://tracker.net/pixel", blocked: true},
+		{url: "https://www.casino.io/", blocked: true},
+		{url: "https://PAYPA1.com.example/signin", blocked: true},
+		{url: "https://example.com/setup.exe", blocked: true},
+		{url: "https://example.com/setup.exe.txt", blocked: false},
+		{url: "https://www.bad.org/login/form?next=1", blocked: true},
+		{url: "https://www.bad.org/about", blocked: false},
+		{url: "https://example.com/", blocked: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			err := s.Check(tt.url)
+			if tt.blocked {
+				assert.ErrorIs(t, err, ErrBlocked)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCheckInternationalHosts(t *testing.T) {
+	dir := t.TempDir()
+	s, err := New(Sources{
+		DomainFiles:  []string{writeList(t, dir, "domains.txt", "xn--e1afmkfd.xn--p1ai\nmünchen.de\n")},
+		PatternFiles: []string{writeList(t, dir, "patterns.txt", `^https://xn--80ak6aa92e\.com/`+"\n")},
+	})
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, s.Check("https://ПРИМЕР.рф/login"), ErrBlocked)
+	assert.ErrorIs(t, s.Check("https://xn--mnchen-3ya.de/"), ErrBlocked)
+	assert.ErrorIs(t, s.Check("https://www.München.de/"), ErrBlocked)
+	assert.ErrorIs(t, s.Check("https://аррӏе.com/signin"), ErrBlocked)
+	assert.NoError(t, s.Check("https://example.рф/"))
+
+	assert.ErrorIs(t, s.Check("http://[::1"), ErrInvalidURL)
+	assert.ErrorIs(t, s.Check("https://example.com/%zz"), ErrInvalidURL)
+}
+
+func TestNewInvalidLists(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := New(Sources{DomainFiles: []string{filepath.Join(dir, "missing.txt")}})
+	assert.ErrorIs(t, err, ErrInvalidList)
+
+	_, err = New(Sources{PatternFiles: []string{writeList(t, dir, "patterns.txt", "([a-z]+\n")}})
+	assert.ErrorIs(t, err, ErrInvalidList)
+
+	_, err = New(Sources{HashPrefixFiles: []string{writeList(t, dir, "prefixes.txt", "abc\n")}})
+	assert.ErrorIs(t, err, ErrInvalidList)
+}
+
+func TestReload(t *testing.T) {
+	dir := t.TempDir()
+	domains := writeList(t, dir, "domains.txt", "evil.com\n")
+	patterns := writeList(t, dir, "patterns.txt", "")
+
+	s, err := New(Sources{DomainFiles: []string{domains}, PatternFiles: []string{patterns}})
+	require.NoError(t, err)
+	assert.NoError(t, s.Check("https://phish.net/"))
+
+	// unchanged files are not read again
+	require.NoError(t, s.Reload(context.Background()))
+
+	writeList(t, dir, "domains.txt", "evil.com\nphish.net\n")
+	require.NoError(t, os.Chtimes(domains, time.Now(), time.Now().Add(time.Minute)))
+	require.NoError(t, s.Reload(context.Background()))
+	assert.ErrorIs(t, s.Check("https://phish.net/"), ErrBlocked)
+
+	// a broken list keeps the lists in use
+	writeList(t, dir, "patterns.txt", "([a-z]+\n")
+	require.NoError(t, os.Chtimes(patterns, time.Now(), time.Now().Add(2*time.Minute)))
+	assert.ErrorIs(t, s.Reload(context.Background()), ErrInvalidList)
+	assert.ErrorIs(t, s.Check("https://evil.com/"), ErrBlocked)
+	assert.ErrorIs(t, s.Check("https://phish.net/"), ErrBlocked)
+}
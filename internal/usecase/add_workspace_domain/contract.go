@@ -0,0 +1,6 @@
+package add_workspace_domain
+
+type In struct {
+	WorkspaceID string
+	Domain      string
+}
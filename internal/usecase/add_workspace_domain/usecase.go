@@ -0,0 +1,49 @@
+package add_workspace_domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	"link-shortener-service/internal/model"
+	"link-shortener-service/internal/usecase/contract/repository"
+)
+
+var (
+	ErrInvalidDomain     = errors.New("invalid short domain")
+	ErrWorkspaceNotFound = errors.New("workspace not found")
+	ErrDomainTaken       = errors.New("short domain belongs to another workspace")
+	ErrDomainAdd         = errors.New("failed to add short domain")
+)
+
+type usecase struct {
+	repo        repository.WorkspaceRepository
+	defaultHost string
+}
+
+func NewUsecase(repo repository.WorkspaceRepository, defaultHost string) *usecase {
+	return &usecase{
+		repo:        repo,
+		defaultHost: model.NormalizeHost(defaultHost),
+	}
+}
+
+func (u *usecase) Run(ctx context.Context, req In) (string, error) {
+	domain := model.NormalizeHost(req.Domain)
+	if !model.IsValidDomain(domain) || domain == u.defaultHost {
+		return "", fmt.Errorf("%w: %s", ErrInvalidDomain, domain)
+	}
+
+	if err := u.repo.AddWorkspaceDomain(ctx, req.WorkspaceID, domain); err != nil {
+		switch {
+		case errors.Is(err, rep.ErrNotFound):
+			return "", fmt.Errorf("%w: %s", ErrWorkspaceNotFound, req.WorkspaceID)
+		case errors.Is(err, rep.ErrDomainExist):
+			return "", fmt.Errorf("%w: %s", ErrDomainTaken, domain)
+		}
+		return "", fmt.Errorf("%w: %v", ErrDomainAdd, err)
+	}
+
+	return domain, nil
+}
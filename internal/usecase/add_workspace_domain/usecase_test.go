@@ -0,0 +1,70 @@
+package add_workspace_domain
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	mockstorage "link-shortener-service/internal/usecase/contract/repository/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddWorkspaceDomain(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name          string
+		domain        string
+		repoErr       error
+		expectedError error
+	}{
+		{
+			name:   "domain is added",
+			domain: "Acme.link",
+		},
+		{
+			name:          "unknown workspace",
+			domain:        "acme.link",
+			repoErr:       rep.ErrNotFound,
+			expectedError: ErrWorkspaceNotFound,
+		},
+		{
+			name:          "domain is taken",
+			domain:        "acme.link",
+			repoErr:       rep.ErrDomainExist,
+			expectedError: ErrDomainTaken,
+		},
+		{
+			name:          "error from storage",
+			domain:        "acme.link",
+			repoErr:       errors.New("db is down"),
+			expectedError: ErrDomainAdd,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mockstorage.NewMockWorkspaceRepository(ctrl)
+			mockRepo.EXPECT().AddWorkspaceDomain(gomock.Any(), "acme", "acme.link").Return(tt.repoErr)
+
+			domain, err := NewUsecase(mockRepo, "some.com").Run(context.Background(), In{WorkspaceID: "acme", Domain: tt.domain})
+
+			assert.ErrorIs(t, err, tt.expectedError)
+			if tt.expectedError == nil {
+				assert.Equal(t, "acme.link", domain)
+			}
+		})
+	}
+
+	t.Run("invalid domain", func(t *testing.T) {
+		mockRepo := mockstorage.NewMockWorkspaceRepository(ctrl)
+
+		_, err := NewUsecase(mockRepo, "some.com").Run(context.Background(), In{WorkspaceID: "acme", Domain: "localhost"})
+
+		assert.ErrorIs(t, err, ErrInvalidDomain)
+	})
+}
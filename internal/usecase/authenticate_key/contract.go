@@ -0,0 +1,5 @@
+package authenticate_key
+
+type In struct {
+	Key string
+}
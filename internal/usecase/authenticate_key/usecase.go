@@ -0,0 +1,66 @@
+package authenticate_key
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	"link-shortener-service/internal/model"
+	"link-shortener-service/internal/usecase/contract/repository"
+)
+
+// AdminOwnerID owns the links created with the bootstrap admin key
+const AdminOwnerID = "admin"
+
+var (
+	ErrInvalidKey   = errors.New("invalid API key")
+	ErrKeyRevoked   = errors.New("API key revoked")
+	ErrKeyRetrieval = errors.New("failed to retrieve API key")
+)
+
+type usecase struct {
+	repo repository.APIKeyRepository
+	// adminKeyHash is the hash of the bootstrap admin key from the config, empty when there is none
+	adminKeyHash string
+}
+
+func NewUsecase(repo repository.APIKeyRepository, adminKey string) *usecase {
+	u := &usecase{repo: repo}
+	if adminKey != "" {
+		u.adminKeyHash = model.HashAPIKeySecret(adminKey)
+	}
+	return u
+}
+
+func (u *usecase) Run(ctx context.Context, req In) (*model.APIKey, error) {
+	if u.adminKeyHash != "" && hashesEqual(model.HashAPIKeySecret(req.Key), u.adminKeyHash) {
+		return &model.APIKey{ID: AdminOwnerID, OwnerID: AdminOwnerID, Scopes: []model.Scope{model.ScopeAdmin}}, nil
+	}
+
+	id, secret, ok := model.ParseAPIKey(req.Key)
+	if !ok {
+		return nil, ErrInvalidKey
+	}
+
+	key, err := u.repo.GetAPIKey(ctx, id)
+	if err != nil {
+		if errors.Is(err, rep.ErrNotFound) {
+			return nil, ErrInvalidKey
+		}
+		return nil, fmt.Errorf("%w: %v", ErrKeyRetrieval, err)
+	}
+	if !hashesEqual(model.HashAPIKeySecret(secret), key.Hash) {
+		return nil, ErrInvalidKey
+	}
+	if key.IsRevoked() {
+		return nil, fmt.Errorf("%w: %s", ErrKeyRevoked, key.ID)
+	}
+
+	return key, nil
+}
+
+func hashesEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
@@ -0,0 +1,103 @@
+package authenticate_key
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	"link-shortener-service/internal/model"
+	mockstorage "link-shortener-service/internal/usecase/contract/repository/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthenticateKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	key := model.APIKey{
+		ID:      "k1",
+		OwnerID: "team-a",
+		Hash:    model.HashAPIKeySecret("secret"),
+		Scopes:  []model.Scope{model.ScopeWrite},
+	}
+	revoked := key
+	revoked.RevokedAt = time.Now()
+
+	tests := []struct {
+		name          string
+		key           string
+		setupMock     func(*mockstorage.MockAPIKeyRepository)
+		expected      *model.APIKey
+		expectedError error
+	}{
+		{
+			name: "valid key",
+			key:  "lsk_k1_secret",
+			setupMock: func(mockRepo *mockstorage.MockAPIKeyRepository) {
+				mockRepo.EXPECT().GetAPIKey(gomock.Any(), "k1").Return(&key, nil)
+			},
+			expected: &key,
+		},
+		{
+			name:     "bootstrap admin key",
+			key:      "bootstrap",
+			expected: &model.APIKey{ID: AdminOwnerID, OwnerID: AdminOwnerID, Scopes: []model.Scope{model.ScopeAdmin}},
+		},
+		{
+			name:          "malformed key",
+			key:           "secret",
+			expectedError: ErrInvalidKey,
+		},
+		{
+			name: "wrong secret",
+			key:  "lsk_k1_guess",
+			setupMock: func(mockRepo *mockstorage.MockAPIKeyRepository) {
+				mockRepo.EXPECT().GetAPIKey(gomock.Any(), "k1").Return(&key, nil)
+			},
+			expectedError: ErrInvalidKey,
+		},
+		{
+			name: "unknown key",
+			key:  "lsk_k2_secret",
+			setupMock: func(mockRepo *mockstorage.MockAPIKeyRepository) {
+				mockRepo.EXPECT().GetAPIKey(gomock.Any(), "k2").Return(nil, rep.ErrNotFound)
+			},
+			expectedError: ErrInvalidKey,
+		},
+		{
+			name: "revoked key",
+			key:  "lsk_k1_secret",
+			setupMock: func(mockRepo *mockstorage.MockAPIKeyRepository) {
+				mockRepo.EXPECT().GetAPIKey(gomock.Any(), "k1").Return(&revoked, nil)
+			},
+			expectedError: ErrKeyRevoked,
+		},
+		{
+			name: "error from storage",
+			key:  "lsk_k1_secret",
+			setupMock: func(mockRepo *mockstorage.MockAPIKeyRepository) {
+				mockRepo.EXPECT().GetAPIKey(gomock.Any(), "k1").Return(nil, errors.New("db is down"))
+			},
+			expectedError: ErrKeyRetrieval,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mockstorage.NewMockAPIKeyRepository(ctrl)
+			if tt.setupMock != nil {
+				tt.setupMock(mockRepo)
+			}
+
+			u := NewUsecase(mockRepo, "bootstrap")
+			result, err := u.Run(context.Background(), In{Key: tt.key})
+
+			assert.ErrorIs(t, err, tt.expectedError)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
@@ -0,0 +1,11 @@
+package broken_links
+
+import (
+	"link-shortener-service/internal/model"
+)
+
+type In struct {
+	// admins see the broken links of every owner, the others only their own
+	Principal model.Principal
+	Limit     int
+}
@@ -0,0 +1,60 @@
+package broken_links
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"link-shortener-service/internal/model"
+	"link-shortener-service/internal/usecase/contract/repository"
+)
+
+const (
+	DefaultLimit = 100
+	MaxLimit     = 1000
+)
+
+var (
+	ErrInvalidLimit    = errors.New("invalid limit")
+	ErrBrokenRetrieval = errors.New("failed to retrieve broken links")
+)
+
+type usecase struct {
+	repo        repository.LinkHealthRepository
+	leftURLPart string
+}
+
+func NewUsecase(repo repository.LinkHealthRepository, leftURLPart string) *usecase {
+	return &usecase{
+		repo:        repo,
+		leftURLPart: leftURLPart,
+	}
+}
+
+// Run returns the broken links of the principal with their short URLs
+func (u *usecase) Run(ctx context.Context, req In) ([]model.LinkHealth, error) {
+	if req.Limit == 0 {
+		req.Limit = DefaultLimit
+	}
+	if req.Limit < 0 || req.Limit > MaxLimit {
+		return nil, fmt.Errorf("%w: must be between 1 and %d", ErrInvalidLimit, MaxLimit)
+	}
+	// an empty owner means every owner to the repository, a key without an owner owns no links
+	if !req.Principal.Admin && req.Principal.OwnerID == "" {
+		return []model.LinkHealth{}, nil
+	}
+
+	ownerID := req.Principal.OwnerID
+	if req.Principal.Admin {
+		ownerID = ""
+	}
+	broken, err := u.repo.GetBrokenLinks(ctx, ownerID, req.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBrokenRetrieval, err)
+	}
+
+	for i := range broken {
+		broken[i].Shorted = model.ShortURL(u.leftURLPart, broken[i].Shorted)
+	}
+	return broken, nil
+}
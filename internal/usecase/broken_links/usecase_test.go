@@ -0,0 +1,93 @@
+package broken_links
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"link-shortener-service/internal/model"
+	mockstorage "link-shortener-service/internal/usecase/contract/repository/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	checkedAt := time.Now()
+	leftURLPart := "http://localhost:8080/"
+
+	tests := []struct {
+		name          string
+		req           In
+		setupMock     func(*mockstorage.MockLinkHealthRepository)
+		expected      []model.LinkHealth
+		expectedError error
+	}{
+		{
+			name: "owner sees own broken links",
+			req:  In{Principal: model.Principal{OwnerID: "team-a"}},
+			setupMock: func(mockRepo *mockstorage.MockLinkHealthRepository) {
+				mockRepo.EXPECT().
+					GetBrokenLinks(gomock.Any(), "team-a", DefaultLimit).
+					Return([]model.LinkHealth{
+						{Shorted: "abc", Original: "https://some.com", OwnerID: "team-a", LinkCheck: model.LinkCheck{Status: 404}, CheckedAt: checkedAt},
+						{Shorted: "go.team.com/xyz", Original: "https://down.com", OwnerID: "team-a", LinkCheck: model.LinkCheck{Error: "timeout"}, CheckedAt: checkedAt},
+					}, nil)
+			},
+			expected: []model.LinkHealth{
+				{Shorted: "http://localhost:8080/abc", Original: "https://some.com", OwnerID: "team-a", LinkCheck: model.LinkCheck{Status: 404}, CheckedAt: checkedAt},
+				{Shorted: "http://go.team.com/xyz", Original: "https://down.com", OwnerID: "team-a", LinkCheck: model.LinkCheck{Error: "timeout"}, CheckedAt: checkedAt},
+			},
+		},
+		{
+			name: "admin sees every owner",
+			req:  In{Principal: model.Principal{OwnerID: "ops", Admin: true}, Limit: 10},
+			setupMock: func(mockRepo *mockstorage.MockLinkHealthRepository) {
+				mockRepo.EXPECT().
+					GetBrokenLinks(gomock.Any(), "", 10).
+					Return([]model.LinkHealth{}, nil)
+			},
+			expected: []model.LinkHealth{},
+		},
+		{
+			name:      "key without owner",
+			req:       In{Principal: model.Principal{}},
+			setupMock: func(mockRepo *mockstorage.MockLinkHealthRepository) {},
+			expected:  []model.LinkHealth{},
+		},
+		{
+			name:          "limit too large",
+			req:           In{Principal: model.Principal{OwnerID: "team-a"}, Limit: MaxLimit + 1},
+			setupMock:     func(mockRepo *mockstorage.MockLinkHealthRepository) {},
+			expectedError: ErrInvalidLimit,
+		},
+		{
+			name: "error from storage",
+			req:  In{Principal: model.Principal{OwnerID: "team-a"}},
+			setupMock: func(mockRepo *mockstorage.MockLinkHealthRepository) {
+				mockRepo.EXPECT().
+					GetBrokenLinks(gomock.Any(), "team-a", DefaultLimit).
+					Return(nil, errors.New("storage error"))
+			},
+			expectedError: ErrBrokenRetrieval,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mockstorage.NewMockLinkHealthRepository(ctrl)
+			tt.setupMock(mockRepo)
+
+			u := NewUsecase(mockRepo, leftURLPart)
+
+			broken, err := u.Run(context.Background(), tt.req)
+
+			assert.ErrorIs(t, err, tt.expectedError)
+			assert.Equal(t, tt.expected, broken)
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package check_links
+
+import (
+	"context"
+	"time"
+
+	"link-shortener-service/internal/model"
+)
+
+// LinkChecker requests the destinations, the checks are aligned with the URLs
+type LinkChecker interface {
+	CheckAll(ctx context.Context, urls []string) []model.LinkCheck
+}
+
+type Policy struct {
+	// BatchSize is how many links a run checks at most
+	BatchSize int
+	// Recheck is the interval between the checks of a healthy link, and the longest one for a broken link
+	Recheck time.Duration
+	// Retry is the first interval after a broken check, it doubles with each broken check in a row
+	Retry time.Duration
+}
@@ -0,0 +1,83 @@
+package check_links
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"link-shortener-service/internal/usecase/contract/repository"
+)
+
+var (
+	ErrCheckLinks = errors.New("failed to check links")
+)
+
+type usecase struct {
+	repo    repository.LinkHealthRepository
+	checker LinkChecker
+	policy  Policy
+	now     func() time.Time
+}
+
+func NewUsecase(repo repository.LinkHealthRepository, checker LinkChecker, policy Policy) *usecase {
+	return &usecase{
+		repo:    repo,
+		checker: checker,
+		policy:  policy,
+		now:     time.Now,
+	}
+}
+
+func (u *usecase) Run(ctx context.Context) error {
+	due, err := u.repo.GetLinksToCheck(ctx, u.now(), u.policy.BatchSize)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrCheckLinks, err)
+	}
+	if len(due) == 0 {
+		return nil
+	}
+
+	urls := make([]string, len(due))
+	for i, link := range due {
+		urls[i] = link.Original
+	}
+	checks := u.checker.CheckAll(ctx, urls)
+	if ctx.Err() != nil {
+		// the checks cut short by the shutdown are not the fault of the destinations
+		return ctx.Err()
+	}
+
+	checkedAt := u.now()
+	broken := 0
+	for i := range due {
+		due[i].LinkCheck = checks[i]
+		due[i].CheckedAt = checkedAt
+		if checks[i].IsBroken() {
+			broken++
+			due[i].Failures++
+			due[i].NextCheckAt = checkedAt.Add(u.retryAfter(due[i].Failures))
+		} else {
+			due[i].Failures = 0
+			due[i].NextCheckAt = checkedAt.Add(u.policy.Recheck)
+		}
+	}
+
+	if err := u.repo.SaveLinkHealth(ctx, due); err != nil {
+		return fmt.Errorf("%w: %v", ErrCheckLinks, err)
+	}
+	if broken > 0 {
+		log.Printf("checked %d links, %d broken", len(due), broken)
+	}
+	return nil
+}
+
+// retryAfter backs off exponentially from the retry interval up to the recheck interval
+func (u *usecase) retryAfter(failures int) time.Duration {
+	interval := u.policy.Retry
+	for i := 1; i < failures && interval < u.policy.Recheck; i++ {
+		interval *= 2
+	}
+	return min(interval, u.policy.Recheck)
+}
@@ -0,0 +1,136 @@
+package check_links
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"link-shortener-service/internal/model"
+	mockstorage "link-shortener-service/internal/usecase/contract/repository/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// fixedChecks answers the checks by URL
+type fixedChecks map[string]model.LinkCheck
+
+func (c fixedChecks) CheckAll(_ context.Context, urls []string) []model.LinkCheck {
+	checks := make([]model.LinkCheck, len(urls))
+	for i, url := range urls {
+		checks[i] = c[url]
+	}
+	return checks
+}
+
+func TestRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Now()
+	policy := Policy{BatchSize: 100, Recheck: 24 * time.Hour, Retry: time.Hour}
+	checker := fixedChecks{
+		"https://some.com/ok":   {Status: 200, ResponseTime: time.Second},
+		"https://some.com/gone": {Status: 410},
+		"https://down.com":      {Error: "connection refused"},
+	}
+
+	tests := []struct {
+		name          string
+		setupMock     func(*mockstorage.MockLinkHealthRepository)
+		expectedError error
+	}{
+		{
+			name: "links checked",
+			setupMock: func(mockRepo *mockstorage.MockLinkHealthRepository) {
+				mockRepo.EXPECT().
+					GetLinksToCheck(gomock.Any(), now, 100).
+					Return([]model.LinkHealth{
+						{Shorted: "ok", Original: "https://some.com/ok", Failures: 2},
+						{Shorted: "gone", Original: "https://some.com/gone"},
+						{Shorted: "down", Original: "https://down.com", Failures: 2},
+						{Shorted: "long-down", Original: "https://down.com", Failures: 9},
+					}, nil)
+				mockRepo.EXPECT().
+					SaveLinkHealth(gomock.Any(), []model.LinkHealth{
+						{
+							Shorted:     "ok",
+							Original:    "https://some.com/ok",
+							LinkCheck:   model.LinkCheck{Status: 200, ResponseTime: time.Second},
+							CheckedAt:   now,
+							NextCheckAt: now.Add(24 * time.Hour),
+						},
+						{
+							Shorted:     "gone",
+							Original:    "https://some.com/gone",
+							LinkCheck:   model.LinkCheck{Status: 410},
+							CheckedAt:   now,
+							Failures:    1,
+							NextCheckAt: now.Add(time.Hour),
+						},
+						{
+							Shorted:     "down",
+							Original:    "https://down.com",
+							LinkCheck:   model.LinkCheck{Error: "connection refused"},
+							CheckedAt:   now,
+							Failures:    3,
+							NextCheckAt: now.Add(4 * time.Hour),
+						},
+						{
+							Shorted:     "long-down",
+							Original:    "https://down.com",
+							LinkCheck:   model.LinkCheck{Error: "connection refused"},
+							CheckedAt:   now,
+							Failures:    10,
+							NextCheckAt: now.Add(24 * time.Hour),
+						},
+					}).
+					Return(nil)
+			},
+		},
+		{
+			name: "nothing to check",
+			setupMock: func(mockRepo *mockstorage.MockLinkHealthRepository) {
+				mockRepo.EXPECT().
+					GetLinksToCheck(gomock.Any(), now, 100).
+					Return(nil, nil)
+			},
+		},
+		{
+			name: "error getting links",
+			setupMock: func(mockRepo *mockstorage.MockLinkHealthRepository) {
+				mockRepo.EXPECT().
+					GetLinksToCheck(gomock.Any(), now, 100).
+					Return(nil, errors.New("storage error"))
+			},
+			expectedError: ErrCheckLinks,
+		},
+		{
+			name: "error saving health",
+			setupMock: func(mockRepo *mockstorage.MockLinkHealthRepository) {
+				mockRepo.EXPECT().
+					GetLinksToCheck(gomock.Any(), now, 100).
+					Return([]model.LinkHealth{{Shorted: "ok", Original: "https://some.com/ok"}}, nil)
+				mockRepo.EXPECT().
+					SaveLinkHealth(gomock.Any(), gomock.Any()).
+					Return(errors.New("storage error"))
+			},
+			expectedError: ErrCheckLinks,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mockstorage.NewMockLinkHealthRepository(ctrl)
+			tt.setupMock(mockRepo)
+
+			u := NewUsecase(mockRepo, checker, policy)
+			u.now = func() time.Time { return now }
+
+			err := u.Run(context.Background())
+
+			assert.ErrorIs(t, err, tt.expectedError)
+		})
+	}
+}
@@ -2,12 +2,85 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"link-shortener-service/internal/model"
 )
 
-//go:generate mockgen -source=contract.go -destination=mocks/contract_mock.go -package=repository URLRepository
+//go:generate mockgen -source=contract.go -destination=mocks/contract_mock.go -package=repository URLRepository,ClickRepository,StatsRepository,SequenceRepository,APIKeyRepository,WorkspaceRepository,RateLimitRepository,LinkHealthRepository,LinkPreviewRepository
 type URLRepository interface {
 	PutURLPair(ctx context.Context, urlPair model.URLPair) (*model.URLPair, error)
+	// PutURLPairs stores the pairs at once, the result is aligned with the pairs: the stored pair,
+	// the existing pair with the same dedup key, or nil when the short URL is taken
+	PutURLPairs(ctx context.Context, urlPairs []model.URLPair) ([]*model.URLPair, error)
 	GetByURL(ctx context.Context, urlType string, knownURL string) (*model.URLPair, error)
+	// GetByShortedURLs returns the live pairs among the short URLs, unknown short URLs are skipped
+	GetByShortedURLs(ctx context.Context, shorted []string) ([]model.URLPair, error)
+	UpdateOriginal(ctx context.Context, shorted, original string) (*model.URLPair, error)
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+	ConsumeClick(ctx context.Context, shorted string) error
+	DeleteURLPair(ctx context.Context, shorted string) error
+	SetDisabled(ctx context.Context, shorted string, disabled bool) error
+	// GetDeduplicated returns up to limit live pairs with a dedup key, ordered by the short URL and following after
+	GetDeduplicated(ctx context.Context, after string, limit int) ([]model.URLPair, error)
+	// SetDedupKey replaces the dedup key of the live pair, ErrOriginalURLExist when another pair has the key
+	SetDedupKey(ctx context.Context, shorted, key string) error
+}
+
+type ClickRepository interface {
+	SaveClicks(ctx context.Context, clicks []model.Click) error
+}
+
+type StatsRepository interface {
+	// RollupClicks adds up to limit clicks recorded before until into the rollups, returns how many were added
+	RollupClicks(ctx context.Context, until time.Time, limit int) (int64, error)
+	GetClickStats(ctx context.Context, code string, from, to time.Time, top int) (*model.ClickStats, error)
+}
+
+type SequenceRepository interface {
+	// NextCodeSequences reserves n increasing values of the short code sequence, a value is never returned twice
+	NextCodeSequences(ctx context.Context, n int) ([]int64, error)
+}
+
+type APIKeyRepository interface {
+	PutAPIKey(ctx context.Context, key model.APIKey) error
+	// GetAPIKey returns revoked keys as well, callers check IsRevoked
+	GetAPIKey(ctx context.Context, id string) (*model.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id string, at time.Time) error
+}
+
+type WorkspaceRepository interface {
+	// PutWorkspace stores the workspace with its domains at once
+	PutWorkspace(ctx context.Context, workspace model.Workspace) error
+	AddWorkspaceDomain(ctx context.Context, workspaceID, domain string) error
+	// GetDomainWorkspace returns the ID of the workspace owning the short domain
+	GetDomainWorkspace(ctx context.Context, domain string) (string, error)
+}
+
+type RateLimitRepository interface {
+	// TakeToken refills the bucket of the key up to now and takes a token from it when there is one,
+	// returns the tokens left in the bucket and whether the token was taken
+	TakeToken(ctx context.Context, key string, limit model.RateLimit, now time.Time) (float64, bool, error)
+	// DeleteIdleBuckets forgets the buckets untouched since before, returns how many were deleted
+	DeleteIdleBuckets(ctx context.Context, before time.Time) (int64, error)
+}
+
+type LinkHealthRepository interface {
+	// GetLinksToCheck returns up to limit live links due for a check at now with their last health,
+	// links never checked go first
+	GetLinksToCheck(ctx context.Context, now time.Time, limit int) ([]model.LinkHealth, error)
+	SaveLinkHealth(ctx context.Context, health []model.LinkHealth) error
+	// GetBrokenLinks returns up to limit live links whose last check found them broken, the latest checks go first.
+	// Links of every owner are returned when ownerID is empty
+	GetBrokenLinks(ctx context.Context, ownerID string, limit int) ([]model.LinkHealth, error)
+}
+
+type LinkPreviewRepository interface {
+	// GetLinksToFetch returns up to limit live links due for a metadata fetch at now with their last preview.
+	// Links never fetched and links whose destination was changed since the last fetch go first,
+	// the preview of a changed link is empty
+	GetLinksToFetch(ctx context.Context, now time.Time, limit int) ([]model.LinkPreview, error)
+	SaveLinkPreviews(ctx context.Context, previews []model.LinkPreview) error
+	// GetLinkPreview returns ErrNotFound when the metadata of the link was never fetched
+	GetLinkPreview(ctx context.Context, shorted string) (*model.LinkPreview, error)
 }
@@ -0,0 +1,24 @@
+// Package service holds the contracts of the services usecases share besides the storage
+package service
+
+import (
+	"context"
+
+	"link-shortener-service/internal/usecase/route_domain"
+)
+
+// DomainRouter returns the custom short domain the host belongs to, empty for the default domain
+type DomainRouter interface {
+	Run(ctx context.Context, req route_domain.In) (string, error)
+}
+
+// Screener reports destinations found in the blocklists
+type Screener interface {
+	Check(rawURL string) error
+}
+
+// Locator returns the ISO 3166-1 alpha-2 code of the country of the IP address, empty when unknown.
+// It is nil when there is no GeoIP database
+type Locator interface {
+	Country(ip string) string
+}
@@ -0,0 +1,36 @@
+// Package servicetest provides stubs of the service contracts for tests
+package servicetest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"link-shortener-service/internal/usecase/route_domain"
+)
+
+// Domains routes the hosts it knows to their custom domains and the rest to the default domain
+type Domains map[string]string
+
+func (d Domains) Run(_ context.Context, req route_domain.In) (string, error) {
+	return d[req.Host], nil
+}
+
+// BlockedHosts reports URLs on any of the hosts
+type BlockedHosts []string
+
+func (b BlockedHosts) Check(rawURL string) error {
+	for _, host := range b {
+		if strings.Contains(rawURL, "://"+host+"/") {
+			return fmt.Errorf("host %s is blocked", host)
+		}
+	}
+	return nil
+}
+
+// Countries locates the addresses it knows
+type Countries map[string]string
+
+func (c Countries) Country(ip string) string {
+	return c[ip]
+}
@@ -0,0 +1,15 @@
+package create_api_key
+
+import "link-shortener-service/internal/model"
+
+type In struct {
+	OwnerID string
+	Name    string
+	Scopes  []model.Scope
+}
+
+// Out holds the stored key and the key itself, which is shown only once
+type Out struct {
+	APIKey model.APIKey
+	Key    string
+}
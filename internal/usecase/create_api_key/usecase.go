@@ -0,0 +1,77 @@
+package create_api_key
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"link-shortener-service/internal/model"
+	"link-shortener-service/internal/usecase/contract/repository"
+)
+
+const (
+	idBytes     = 8
+	secretBytes = 32
+)
+
+var (
+	ErrInvalidScope = errors.New("invalid scope")
+	ErrKeyCreate    = errors.New("failed to create API key")
+)
+
+type usecase struct {
+	repo repository.APIKeyRepository
+	now  func() time.Time
+}
+
+func NewUsecase(repo repository.APIKeyRepository) *usecase {
+	return &usecase{
+		repo: repo,
+		now:  time.Now,
+	}
+}
+
+func (u *usecase) Run(ctx context.Context, req In) (*Out, error) {
+	if len(req.Scopes) == 0 {
+		return nil, fmt.Errorf("%w: at least one scope is required", ErrInvalidScope)
+	}
+	for _, scope := range req.Scopes {
+		if !scope.IsValid() {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidScope, scope)
+		}
+	}
+
+	id, err := randomHex(idBytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrKeyCreate, err)
+	}
+	secret, err := randomHex(secretBytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrKeyCreate, err)
+	}
+
+	key := model.APIKey{
+		ID:        id,
+		OwnerID:   req.OwnerID,
+		Name:      req.Name,
+		Hash:      model.HashAPIKeySecret(secret),
+		Scopes:    req.Scopes,
+		CreatedAt: u.now().UTC(),
+	}
+	if err = u.repo.PutAPIKey(ctx, key); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrKeyCreate, err)
+	}
+
+	return &Out{APIKey: key, Key: model.FormatAPIKey(id, secret)}, nil
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
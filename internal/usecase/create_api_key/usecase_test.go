@@ -0,0 +1,61 @@
+package create_api_key
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"link-shortener-service/internal/model"
+	mockstorage "link-shortener-service/internal/usecase/contract/repository/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	req := In{OwnerID: "team-a", Name: "ci", Scopes: []model.Scope{model.ScopeWrite}}
+
+	t.Run("key is created", func(t *testing.T) {
+		var stored model.APIKey
+		mockRepo := mockstorage.NewMockAPIKeyRepository(ctrl)
+		mockRepo.EXPECT().
+			PutAPIKey(gomock.Any(), gomock.AssignableToTypeOf(model.APIKey{})).
+			DoAndReturn(func(_ context.Context, key model.APIKey) error {
+				stored = key
+				return nil
+			})
+
+		out, err := NewUsecase(mockRepo).Run(context.Background(), req)
+		require.NoError(t, err)
+		assert.Equal(t, stored, out.APIKey)
+		assert.Equal(t, "team-a", stored.OwnerID)
+
+		// only the hash of the secret is stored
+		id, secret, ok := model.ParseAPIKey(out.Key)
+		require.True(t, ok)
+		assert.Equal(t, stored.ID, id)
+		assert.NotContains(t, stored.Hash, secret)
+		assert.Equal(t, model.HashAPIKeySecret(secret), stored.Hash)
+	})
+
+	t.Run("invalid scope", func(t *testing.T) {
+		mockRepo := mockstorage.NewMockAPIKeyRepository(ctrl)
+
+		_, err := NewUsecase(mockRepo).Run(context.Background(), In{OwnerID: "team-a", Scopes: []model.Scope{"root"}})
+		assert.ErrorIs(t, err, ErrInvalidScope)
+		_, err = NewUsecase(mockRepo).Run(context.Background(), In{OwnerID: "team-a"})
+		assert.ErrorIs(t, err, ErrInvalidScope)
+	})
+
+	t.Run("error from storage", func(t *testing.T) {
+		mockRepo := mockstorage.NewMockAPIKeyRepository(ctrl)
+		mockRepo.EXPECT().PutAPIKey(gomock.Any(), gomock.Any()).Return(errors.New("db is down"))
+
+		_, err := NewUsecase(mockRepo).Run(context.Background(), req)
+		assert.ErrorIs(t, err, ErrKeyCreate)
+	})
+}
@@ -0,0 +1,7 @@
+package create_workspace
+
+type In struct {
+	ID      string
+	Name    string
+	Domains []string
+}
@@ -0,0 +1,66 @@
+package create_workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	"link-shortener-service/internal/model"
+	"link-shortener-service/internal/usecase/contract/repository"
+)
+
+var (
+	ErrInvalidDomain   = errors.New("invalid short domain")
+	ErrWorkspaceExist  = errors.New("workspace already exists")
+	ErrDomainTaken     = errors.New("short domain belongs to another workspace")
+	ErrWorkspaceCreate = errors.New("failed to create workspace")
+)
+
+type usecase struct {
+	repo        repository.WorkspaceRepository
+	defaultHost string
+	now         func() time.Time
+}
+
+func NewUsecase(repo repository.WorkspaceRepository, defaultHost string) *usecase {
+	return &usecase{
+		repo:        repo,
+		defaultHost: model.NormalizeHost(defaultHost),
+		now:         time.Now,
+	}
+}
+
+func (u *usecase) Run(ctx context.Context, req In) (*model.Workspace, error) {
+	domains := make([]string, 0, len(req.Domains))
+	seen := make(map[string]struct{}, len(req.Domains))
+	for _, domain := range req.Domains {
+		domain = model.NormalizeHost(domain)
+		if !model.IsValidDomain(domain) || domain == u.defaultHost {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidDomain, domain)
+		}
+		if _, exists := seen[domain]; !exists {
+			seen[domain] = struct{}{}
+			domains = append(domains, domain)
+		}
+	}
+
+	workspace := model.Workspace{
+		ID:        req.ID,
+		Name:      req.Name,
+		Domains:   domains,
+		CreatedAt: u.now().UTC(),
+	}
+	if err := u.repo.PutWorkspace(ctx, workspace); err != nil {
+		switch {
+		case errors.Is(err, rep.ErrWorkspaceExist):
+			return nil, fmt.Errorf("%w: %s", ErrWorkspaceExist, req.ID)
+		case errors.Is(err, rep.ErrDomainExist):
+			return nil, fmt.Errorf("%w: %v", ErrDomainTaken, err)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrWorkspaceCreate, err)
+	}
+
+	return &workspace, nil
+}
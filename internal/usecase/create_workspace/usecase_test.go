@@ -0,0 +1,86 @@
+package create_workspace
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	"link-shortener-service/internal/model"
+	mockstorage "link-shortener-service/internal/usecase/contract/repository/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateWorkspace(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name          string
+		req           In
+		setupMock     func(*mockstorage.MockWorkspaceRepository)
+		expected      []string
+		expectedError error
+	}{
+		{
+			name: "workspace is created with normalized domains",
+			req:  In{ID: "acme", Domains: []string{"GO.acme.io.", "acme.link", "go.acme.io"}},
+			setupMock: func(mockRepo *mockstorage.MockWorkspaceRepository) {
+				mockRepo.EXPECT().PutWorkspace(gomock.Any(), gomock.AssignableToTypeOf(model.Workspace{})).Return(nil)
+			},
+			expected: []string{"go.acme.io", "acme.link"},
+		},
+		{
+			name:          "invalid domain",
+			req:           In{ID: "acme", Domains: []string{"acme_link"}},
+			setupMock:     func(*mockstorage.MockWorkspaceRepository) {},
+			expectedError: ErrInvalidDomain,
+		},
+		{
+			name:          "default domain",
+			req:           In{ID: "acme", Domains: []string{"some.com"}},
+			setupMock:     func(*mockstorage.MockWorkspaceRepository) {},
+			expectedError: ErrInvalidDomain,
+		},
+		{
+			name: "workspace exists",
+			req:  In{ID: "acme"},
+			setupMock: func(mockRepo *mockstorage.MockWorkspaceRepository) {
+				mockRepo.EXPECT().PutWorkspace(gomock.Any(), gomock.Any()).Return(rep.ErrWorkspaceExist)
+			},
+			expectedError: ErrWorkspaceExist,
+		},
+		{
+			name: "domain is taken",
+			req:  In{ID: "acme", Domains: []string{"go.acme.io"}},
+			setupMock: func(mockRepo *mockstorage.MockWorkspaceRepository) {
+				mockRepo.EXPECT().PutWorkspace(gomock.Any(), gomock.Any()).Return(rep.ErrDomainExist)
+			},
+			expectedError: ErrDomainTaken,
+		},
+		{
+			name: "error from storage",
+			req:  In{ID: "acme"},
+			setupMock: func(mockRepo *mockstorage.MockWorkspaceRepository) {
+				mockRepo.EXPECT().PutWorkspace(gomock.Any(), gomock.Any()).Return(errors.New("db is down"))
+			},
+			expectedError: ErrWorkspaceCreate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mockstorage.NewMockWorkspaceRepository(ctrl)
+			tt.setupMock(mockRepo)
+
+			result, err := NewUsecase(mockRepo, "some.com").Run(context.Background(), tt.req)
+
+			assert.ErrorIs(t, err, tt.expectedError)
+			if tt.expectedError == nil {
+				assert.Equal(t, tt.expected, result.Domains)
+			}
+		})
+	}
+}
@@ -0,0 +1,8 @@
+package delete_url
+
+import "link-shortener-service/internal/model"
+
+type In struct {
+	Principal  model.Principal
+	ShortedURL string
+}
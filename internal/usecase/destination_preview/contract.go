@@ -0,0 +1,8 @@
+package destination_preview
+
+type In struct {
+	// ShortedURL is the key of the link as it is stored
+	ShortedURL string
+	// Destination is the URL the visitor is about to be sent to
+	Destination string
+}
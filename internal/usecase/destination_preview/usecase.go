@@ -0,0 +1,39 @@
+package destination_preview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	"link-shortener-service/internal/model"
+	"link-shortener-service/internal/usecase/contract/repository"
+)
+
+var ErrPreviewRetrieval = errors.New("failed to retrieve link preview")
+
+type usecase struct {
+	previews repository.LinkPreviewRepository
+}
+
+func NewUsecase(previews repository.LinkPreviewRepository) *usecase {
+	return &usecase{
+		previews: previews,
+	}
+}
+
+// Run returns the metadata of the destination for the interstitial page of the link,
+// nil until it is fetched and for destinations other than the fetched one, like the targets of rules
+func (u *usecase) Run(ctx context.Context, req In) (*model.LinkPreview, error) {
+	preview, err := u.previews.GetLinkPreview(ctx, req.ShortedURL)
+	if err != nil {
+		if errors.Is(err, rep.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%w: %v", ErrPreviewRetrieval, err)
+	}
+	if preview.Original != req.Destination || preview.FetchedAt.IsZero() {
+		return nil, nil
+	}
+	return preview, nil
+}
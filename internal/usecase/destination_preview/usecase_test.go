@@ -0,0 +1,86 @@
+package destination_preview
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	"link-shortener-service/internal/model"
+	mockstorage "link-shortener-service/internal/usecase/contract/repository/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	preview := &model.LinkPreview{
+		Shorted:      "abc",
+		Original:     "https://some.com",
+		LinkMetadata: model.LinkMetadata{Title: "Some"},
+		FetchedAt:    time.Date(2025, 7, 8, 12, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name          string
+		destination   string
+		setupMock     func(*mockstorage.MockLinkPreviewRepository)
+		expected      *model.LinkPreview
+		expectedError error
+	}{
+		{
+			name:        "metadata of the destination",
+			destination: "https://some.com",
+			setupMock: func(previews *mockstorage.MockLinkPreviewRepository) {
+				previews.EXPECT().GetLinkPreview(gomock.Any(), "abc").Return(preview, nil)
+			},
+			expected: preview,
+		},
+		{
+			name:        "destination of a rule",
+			destination: "https://some.de",
+			setupMock: func(previews *mockstorage.MockLinkPreviewRepository) {
+				previews.EXPECT().GetLinkPreview(gomock.Any(), "abc").Return(preview, nil)
+			},
+		},
+		{
+			name:        "metadata never fetched",
+			destination: "https://some.com",
+			setupMock: func(previews *mockstorage.MockLinkPreviewRepository) {
+				previews.EXPECT().
+					GetLinkPreview(gomock.Any(), "abc").
+					Return(&model.LinkPreview{Shorted: "abc", Original: "https://some.com", Error: "timeout"}, nil)
+			},
+		},
+		{
+			name:        "metadata not scheduled yet",
+			destination: "https://some.com",
+			setupMock: func(previews *mockstorage.MockLinkPreviewRepository) {
+				previews.EXPECT().GetLinkPreview(gomock.Any(), "abc").Return(nil, rep.ErrNotFound)
+			},
+		},
+		{
+			name:        "error db",
+			destination: "https://some.com",
+			setupMock: func(previews *mockstorage.MockLinkPreviewRepository) {
+				previews.EXPECT().GetLinkPreview(gomock.Any(), "abc").Return(nil, errors.New("db is down"))
+			},
+			expectedError: ErrPreviewRetrieval,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previews := mockstorage.NewMockLinkPreviewRepository(ctrl)
+			tt.setupMock(previews)
+
+			result, err := NewUsecase(previews).Run(context.Background(), In{ShortedURL: "abc", Destination: tt.destination})
+			assert.ErrorIs(t, err, tt.expectedError)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
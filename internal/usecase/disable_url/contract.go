@@ -0,0 +1,9 @@
+package disable_url
+
+import "link-shortener-service/internal/model"
+
+type In struct {
+	Principal  model.Principal
+	ShortedURL string
+	Disabled   bool
+}
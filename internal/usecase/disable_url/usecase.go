@@ -0,0 +1,48 @@
+package disable_url
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	"link-shortener-service/internal/usecase/contract/repository"
+)
+
+const shortURLColumnName = "shorted_url"
+
+var (
+	ErrURLNotFound  = errors.New("URLPair not found")
+	ErrURLRetrieval = errors.New("failed to retrieve URLPair")
+	ErrForbidden    = errors.New("URLPair belongs to another owner")
+	ErrURLUpdate    = errors.New("failed to update URLPair")
+)
+
+type usecase struct {
+	repo repository.URLRepository
+}
+
+func NewUsecase(repo repository.URLRepository) *usecase {
+	return &usecase{repo: repo}
+}
+
+func (u *usecase) Run(ctx context.Context, req In) error {
+	pair, err := u.repo.GetByURL(ctx, shortURLColumnName, req.ShortedURL)
+	if err != nil {
+		if errors.Is(err, rep.ErrNotFound) {
+			return fmt.Errorf("%w: %s", ErrURLNotFound, req.ShortedURL)
+		}
+		return fmt.Errorf("%w: %v", ErrURLRetrieval, err)
+	}
+	if !req.Principal.CanManage(pair) {
+		return fmt.Errorf("%w: %s", ErrForbidden, req.ShortedURL)
+	}
+
+	if err = u.repo.SetDisabled(ctx, req.ShortedURL, req.Disabled); err != nil {
+		if errors.Is(err, rep.ErrNotFound) {
+			return fmt.Errorf("%w: %s", ErrURLNotFound, req.ShortedURL)
+		}
+		return fmt.Errorf("%w: %v", ErrURLUpdate, err)
+	}
+	return nil
+}
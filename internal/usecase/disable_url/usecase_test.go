@@ -0,0 +1,97 @@
+package disable_url
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	"link-shortener-service/internal/model"
+	mockstorage "link-shortener-service/internal/usecase/contract/repository/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDisableURL(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	reqURL := In{
+		Principal:  model.Principal{OwnerID: "team-a"},
+		ShortedURL: "xHsvC_0NTU",
+		Disabled:   true,
+	}
+
+	tests := []struct {
+		name          string
+		setupMock     func(*mockstorage.MockURLRepository)
+		expectedError error
+	}{
+		{
+			name: "successful disable",
+			setupMock: func(mockRepo *mockstorage.MockURLRepository) {
+				mockRepo.EXPECT().
+					GetByURL(gomock.Any(), shortURLColumnName, "xHsvC_0NTU").
+					Return(&model.URLPair{Shorted: "xHsvC_0NTU", OwnerID: "team-a"}, nil)
+				mockRepo.EXPECT().
+					SetDisabled(gomock.Any(), "xHsvC_0NTU", true).
+					Return(nil)
+			},
+		},
+		{
+			name: "unknown short URL",
+			setupMock: func(mockRepo *mockstorage.MockURLRepository) {
+				mockRepo.EXPECT().
+					GetByURL(gomock.Any(), shortURLColumnName, "xHsvC_0NTU").
+					Return(&model.URLPair{Shorted: "xHsvC_0NTU", OwnerID: "team-a"}, nil)
+				mockRepo.EXPECT().
+					SetDisabled(gomock.Any(), "xHsvC_0NTU", true).
+					Return(rep.ErrNotFound)
+			},
+			expectedError: ErrURLNotFound,
+		},
+		{
+			name: "internal error from storage",
+			setupMock: func(mockRepo *mockstorage.MockURLRepository) {
+				mockRepo.EXPECT().
+					GetByURL(gomock.Any(), shortURLColumnName, "xHsvC_0NTU").
+					Return(&model.URLPair{Shorted: "xHsvC_0NTU", OwnerID: "team-a"}, nil)
+				mockRepo.EXPECT().
+					SetDisabled(gomock.Any(), "xHsvC_0NTU", true).
+					Return(errors.New("db is down"))
+			},
+			expectedError: ErrURLUpdate,
+		},
+		{
+			name: "link of another owner",
+			setupMock: func(mockRepo *mockstorage.MockURLRepository) {
+				mockRepo.EXPECT().
+					GetByURL(gomock.Any(), shortURLColumnName, "xHsvC_0NTU").
+					Return(&model.URLPair{Shorted: "xHsvC_0NTU", OwnerID: "team-b"}, nil)
+			},
+			expectedError: ErrForbidden,
+		},
+		{
+			name: "unknown short URL on lookup",
+			setupMock: func(mockRepo *mockstorage.MockURLRepository) {
+				mockRepo.EXPECT().
+					GetByURL(gomock.Any(), shortURLColumnName, "xHsvC_0NTU").
+					Return(nil, rep.ErrNotFound)
+			},
+			expectedError: ErrURLNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mockstorage.NewMockURLRepository(ctrl)
+			tt.setupMock(mockRepo)
+
+			u := NewUsecase(mockRepo)
+			err := u.Run(context.Background(), reqURL)
+
+			assert.ErrorIs(t, err, tt.expectedError)
+		})
+	}
+}
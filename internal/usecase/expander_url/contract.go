@@ -1,5 +1,34 @@
 package expander_url
 
+import (
+	"time"
+
+	"link-shortener-service/internal/model"
+)
+
 type In struct {
+	// ShortedURL is the short URL or the code alone, the code is looked up on the domain of the short URL,
+	// or on the domain of Host, the host the request came to, when there is no domain
 	ShortedURL string
+	Host       string
+	// Peek resolves the link without spending a click of click-limited links and without tracking it
+	Peek bool
+	// Confirmed is set when the visitor continues from the interstitial page of the link,
+	// links with an interstitial resolve like Peek until then
+	Confirmed bool
+	Password  string
+	// Visitor picks the redirect rule of the link, its country is looked up by IP when empty
+	Visitor model.Visitor
+	// Variant is the split target the visitor was sent to before, kept while the target takes visitors
+	Variant string
+}
+
+type ClickTracker interface {
+	Track(click model.Click)
+}
+
+type PasswordPolicy struct {
+	// MaxAttempts failed attempts within Lockout lock the short code for Lockout
+	MaxAttempts int
+	Lockout     time.Duration
 }
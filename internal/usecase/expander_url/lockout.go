@@ -0,0 +1,78 @@
+package expander_url
+
+import (
+	"sync"
+	"time"
+)
+
+// pruneThreshold bounds the attempts map, stale entries are dropped once it is exceeded
+const pruneThreshold = 1024
+
+type attempts struct {
+	failures    int
+	firstFailAt time.Time
+	lockedUntil time.Time
+}
+
+// lockout counts failed password attempts per short code and locks the code
+// for a while once MaxAttempts failures happened within the Lockout window
+type lockout struct {
+	mu     sync.Mutex
+	policy PasswordPolicy
+	codes  map[string]*attempts
+}
+
+func newLockout(policy PasswordPolicy) *lockout {
+	return &lockout{
+		policy: policy,
+		codes:  make(map[string]*attempts),
+	}
+}
+
+func (l *lockout) isLocked(code string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	a, ok := l.codes[code]
+	return ok && now.Before(a.lockedUntil)
+}
+
+func (l *lockout) fail(code string, now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.codes) > pruneThreshold {
+		l.prune(now)
+	}
+
+	a, ok := l.codes[code]
+	if !ok || l.isStale(a, now) {
+		a = &attempts{firstFailAt: now}
+		l.codes[code] = a
+	}
+	a.failures++
+	if a.failures >= l.policy.MaxAttempts {
+		a.lockedUntil = now.Add(l.policy.Lockout)
+		a.failures = 0
+		a.firstFailAt = now
+	}
+}
+
+func (l *lockout) reset(code string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.codes, code)
+}
+
+func (l *lockout) isStale(a *attempts, now time.Time) bool {
+	return !now.Before(a.lockedUntil) && now.Sub(a.firstFailAt) > l.policy.Lockout
+}
+
+func (l *lockout) prune(now time.Time) {
+	for code, a := range l.codes {
+		if l.isStale(a, now) {
+			delete(l.codes, code)
+		}
+	}
+}
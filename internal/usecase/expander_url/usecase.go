@@ -4,11 +4,19 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/rand/v2"
+	"net/url"
 	"regexp"
+	"strings"
+	"time"
 
 	rep "link-shortener-service/internal/infastracture/repository"
 	"link-shortener-service/internal/model"
 	"link-shortener-service/internal/usecase/contract/repository"
+	"link-shortener-service/internal/usecase/contract/service"
+	"link-shortener-service/internal/usecase/route_domain"
+
+	"golang.org/x/crypto/bcrypt"
 )
 
 const (
@@ -18,29 +26,153 @@ const (
 var (
 	ErrURLNotFound  = errors.New("URLPair not found")
 	ErrURLRetrieval = errors.New("failed to retrieve URLPair")
+	ErrURLExpired   = errors.New("URLPair expired")
+	ErrURLExhausted = errors.New("URLPair has no clicks left")
+	ErrURLDisabled  = errors.New("URLPair disabled")
+	ErrURLBlocked   = errors.New("URLPair destination is blocklisted")
+
+	ErrPasswordRequired = errors.New("password required")
+	ErrWrongPassword    = errors.New("wrong password")
+	ErrTooManyAttempts  = errors.New("too many failed password attempts")
 
 	leftURLPart = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*://[^/]+/`)
 )
 
 type usecase struct {
-	repo repository.URLRepository
+	repo     repository.URLRepository
+	domains  service.DomainRouter
+	clicks   ClickTracker
+	screener service.Screener
+	locator  service.Locator
+	lockout  *lockout
+	// caseInsensitive resolves codes in lowercase, codes are stored in lowercase then
+	caseInsensitive bool
+	now             func() time.Time
+	// roll picks split targets, returns a random number in [0, n)
+	roll func(n int) int
 }
 
-func NewUsecase(repo repository.URLRepository) *usecase {
-	return &usecase{repo: repo}
+func NewUsecase(repo repository.URLRepository, domains service.DomainRouter, clicks ClickTracker, screener service.Screener,
+	locator service.Locator, passwordPolicy PasswordPolicy, caseInsensitive bool,
+) *usecase {
+	return &usecase{
+		repo:            repo,
+		domains:         domains,
+		clicks:          clicks,
+		screener:        screener,
+		locator:         locator,
+		lockout:         newLockout(passwordPolicy),
+		caseInsensitive: caseInsensitive,
+		now:             time.Now,
+		roll:            rand.IntN,
+	}
 }
 
+// Run returns the pair with Original set to the destination of the visitor, the target of the first matching rule
+// or of the split target the visitor is assigned to when no rule matches
 func (u *usecase) Run(ctx context.Context, req In) (*model.URLPair, error) {
-	record, err := u.repo.GetByURL(ctx, shortURLColumnName, u.trimLeftPartURL(req.ShortedURL))
+	key, err := u.linkKey(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	record, err := u.repo.GetByURL(ctx, shortURLColumnName, key)
 	if err != nil {
 		if errors.Is(err, rep.ErrNotFound) {
 			return nil, fmt.Errorf("%w: %s", ErrURLNotFound, req.ShortedURL)
 		}
 		return nil, fmt.Errorf("%w: %v", ErrURLRetrieval, err)
 	}
+	if record.Disabled {
+		return nil, fmt.Errorf("%w: %s", ErrURLDisabled, req.ShortedURL)
+	}
+	now := u.now()
+	if record.IsExpired(now) {
+		return nil, fmt.Errorf("%w: %s", ErrURLExpired, req.ShortedURL)
+	}
+	if record.IsExhausted() {
+		return nil, fmt.Errorf("%w: %s", ErrURLExhausted, req.ShortedURL)
+	}
+	visitor := req.Visitor
+	if visitor.Country == "" && u.locator != nil {
+		visitor.Country = u.locator.Country(visitor.IP)
+	}
+	matched, destination := record.Route(model.NewAudience(visitor, now))
+	record.Original = destination
+	if matched < 0 {
+		if target, ok := record.PickTarget(req.Variant, u.roll); ok {
+			record.Original, record.Variant = target.URL, target.Variant
+		}
+	}
+
+	// destinations blocklisted after the link was created stop resolving
+	if u.screener != nil {
+		if err = u.screener.Check(record.Original); err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrURLBlocked, req.ShortedURL, err)
+		}
+	}
+	if err = u.checkPassword(record, req.Password, now); err != nil {
+		return nil, err
+	}
+
+	if req.Peek || (record.Interstitial && !req.Confirmed) {
+		return record, nil
+	}
+	if record.MaxClicks > 0 {
+		if err = u.repo.ConsumeClick(ctx, record.Shorted); err != nil {
+			if errors.Is(err, rep.ErrClicksExhausted) {
+				return nil, fmt.Errorf("%w: %s", ErrURLExhausted, req.ShortedURL)
+			}
+			return nil, fmt.Errorf("%w: %v", ErrURLRetrieval, err)
+		}
+		record.ClicksUsed++
+	}
+	u.clicks.Track(model.Click{
+		Code:    record.Shorted,
+		At:      now,
+		Variant: record.Variant,
+		Visitor: visitor,
+	})
 	return record, nil
 }
 
-func (u *usecase) trimLeftPartURL(fullURL string) string {
-	return leftURLPart.ReplaceAllString(fullURL, "")
+func (u *usecase) checkPassword(record *model.URLPair, password string, now time.Time) error {
+	if !record.IsProtected() {
+		return nil
+	}
+	if u.lockout.isLocked(record.Shorted, now) {
+		return fmt.Errorf("%w: %s", ErrTooManyAttempts, record.Shorted)
+	}
+	if password == "" {
+		return fmt.Errorf("%w: %s", ErrPasswordRequired, record.Shorted)
+	}
+
+	// bcrypt compares hashes in constant time
+	if err := bcrypt.CompareHashAndPassword([]byte(record.PasswordHash), []byte(password)); err != nil {
+		u.lockout.fail(record.Shorted, now)
+		return fmt.Errorf("%w: %s", ErrWrongPassword, record.Shorted)
+	}
+	u.lockout.reset(record.Shorted)
+
+	return nil
+}
+
+// linkKey finds the stored key of the link from the code and the domain it is requested on
+func (u *usecase) linkKey(ctx context.Context, req In) (string, error) {
+	code, host := req.ShortedURL, req.Host
+	if prefix := leftURLPart.FindString(code); prefix != "" {
+		if parsed, err := url.Parse(prefix); err == nil {
+			host = parsed.Host
+		}
+		code = code[len(prefix):]
+	}
+	if u.caseInsensitive {
+		code = strings.ToLower(code)
+	}
+
+	domain, err := u.domains.Run(ctx, route_domain.In{Host: host})
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrURLRetrieval, err)
+	}
+	return model.LinkKey(domain, code), nil
 }
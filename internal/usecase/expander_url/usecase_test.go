@@ -5,17 +5,20 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	rep "link-shortener-service/internal/infastracture/repository"
 	"link-shortener-service/internal/model"
 	mockstorage "link-shortener-service/internal/usecase/contract/repository/mocks"
+	"link-shortener-service/internal/usecase/contract/service/servicetest"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
 )
 
-func TestTrimLeftPartURL(t *testing.T) {
+func TestLinkKey(t *testing.T) {
 	var leftPartURLs = []string{
 		"http://example.com/",
 		"https://example.com/",
@@ -33,15 +36,27 @@ func TestTrimLeftPartURL(t *testing.T) {
 	}
 
 	shortedURL := "xHsvC_0NTU"
-	u := usecase{}
+	u := usecase{domains: servicetest.Domains{"go.acme.io": "go.acme.io"}}
 
 	for _, leftPart := range leftPartURLs {
 		fullURL := fmt.Sprintf("%s%s", leftPart, shortedURL)
 		t.Run(fullURL, func(t *testing.T) {
-			result := u.trimLeftPartURL(fullURL)
+			result, err := u.linkKey(context.Background(), In{ShortedURL: fullURL})
+			require.NoError(t, err)
 			assert.Equal(t, shortedURL, result, "failed trim for: %s", fullURL)
 		})
 	}
+
+	// codes on custom domains are looked up by the host of the short URL or of the request
+	for _, req := range []In{
+		{ShortedURL: "https://go.acme.io/" + shortedURL},
+		{ShortedURL: "https://go.acme.io/" + shortedURL, Host: "some.com"},
+		{ShortedURL: shortedURL, Host: "go.acme.io"},
+	} {
+		result, err := u.linkKey(context.Background(), req)
+		require.NoError(t, err)
+		assert.Equal(t, "go.acme.io/"+shortedURL, result)
+	}
 }
 
 func TestPutURLPair(t *testing.T) {
@@ -87,6 +102,82 @@ func TestPutURLPair(t *testing.T) {
 			expected:      nil,
 			expectedError: ErrURLNotFound,
 		},
+		{
+			name: "disabled URL",
+			req:  reqURL,
+			setupMock: func(mockDB *mockstorage.MockURLRepository) {
+				mockDB.EXPECT().
+					GetByURL(ctx, shortURLColumnName, "xHsvC_0NTU").
+					Return(&model.URLPair{Original: outURL.Original, Shorted: outURL.Shorted, Disabled: true}, nil)
+			},
+			expected:      nil,
+			expectedError: ErrURLDisabled,
+		},
+		{
+			name: "expired URL",
+			req:  reqURL,
+			setupMock: func(mockDB *mockstorage.MockURLRepository) {
+				mockDB.EXPECT().
+					GetByURL(ctx, shortURLColumnName, "xHsvC_0NTU").
+					Return(&model.URLPair{
+						Original:  outURL.Original,
+						Shorted:   outURL.Shorted,
+						ExpiresAt: time.Now().Add(-time.Second),
+					}, nil)
+			},
+			expected:      nil,
+			expectedError: ErrURLExpired,
+		},
+		{
+			name: "click-limited URL",
+			req:  reqURL,
+			setupMock: func(mockDB *mockstorage.MockURLRepository) {
+				mockDB.EXPECT().
+					GetByURL(ctx, shortURLColumnName, "xHsvC_0NTU").
+					Return(&model.URLPair{Original: outURL.Original, Shorted: outURL.Shorted, MaxClicks: 2, ClicksUsed: 1}, nil)
+				mockDB.EXPECT().
+					ConsumeClick(ctx, "xHsvC_0NTU").
+					Return(nil)
+			},
+			expected:      &model.URLPair{Original: outURL.Original, Shorted: outURL.Shorted, MaxClicks: 2, ClicksUsed: 2},
+			expectedError: nil,
+		},
+		{
+			name: "click-limited URL - exhausted",
+			req:  reqURL,
+			setupMock: func(mockDB *mockstorage.MockURLRepository) {
+				mockDB.EXPECT().
+					GetByURL(ctx, shortURLColumnName, "xHsvC_0NTU").
+					Return(&model.URLPair{Original: outURL.Original, Shorted: outURL.Shorted, MaxClicks: 1, ClicksUsed: 1}, nil)
+			},
+			expected:      nil,
+			expectedError: ErrURLExhausted,
+		},
+		{
+			name: "click-limited URL - last click taken concurrently",
+			req:  reqURL,
+			setupMock: func(mockDB *mockstorage.MockURLRepository) {
+				mockDB.EXPECT().
+					GetByURL(ctx, shortURLColumnName, "xHsvC_0NTU").
+					Return(&model.URLPair{Original: outURL.Original, Shorted: outURL.Shorted, MaxClicks: 1}, nil)
+				mockDB.EXPECT().
+					ConsumeClick(ctx, "xHsvC_0NTU").
+					Return(rep.ErrClicksExhausted)
+			},
+			expected:      nil,
+			expectedError: ErrURLExhausted,
+		},
+		{
+			name: "click-limited URL - peek",
+			req:  In{ShortedURL: "xHsvC_0NTU", Peek: true},
+			setupMock: func(mockDB *mockstorage.MockURLRepository) {
+				mockDB.EXPECT().
+					GetByURL(ctx, shortURLColumnName, "xHsvC_0NTU").
+					Return(&model.URLPair{Original: outURL.Original, Shorted: outURL.Shorted, MaxClicks: 1}, nil)
+			},
+			expected:      &model.URLPair{Original: outURL.Original, Shorted: outURL.Shorted, MaxClicks: 1},
+			expectedError: nil,
+		},
 		{
 			name: "internal error from storage",
 			req:  reqURL,
@@ -105,8 +196,8 @@ func TestPutURLPair(t *testing.T) {
 			mockStorage := mockstorage.NewMockURLRepository(ctrl)
 			tt.setupMock(mockStorage)
 
-			u := NewUsecase(mockStorage)
-			result, err := u.Run(context.Background(), reqURL)
+			u := NewUsecase(mockStorage, servicetest.Domains{}, &clickRecorder{}, nil, nil, PasswordPolicy{MaxAttempts: 3, Lockout: time.Minute}, false)
+			result, err := u.Run(context.Background(), tt.req)
 
 			if tt.expectedError != nil {
 				require.Error(t, err)
@@ -124,3 +215,316 @@ func TestPutURLPair(t *testing.T) {
 		})
 	}
 }
+
+func TestPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	require.NoError(t, err)
+	protected := model.URLPair{
+		Original:     "https://some.com/asdasd",
+		Shorted:      "xHsvC_0NTU",
+		PasswordHash: string(hash),
+	}
+
+	mockStorage := mockstorage.NewMockURLRepository(ctrl)
+	mockStorage.EXPECT().
+		GetByURL(gomock.Any(), shortURLColumnName, protected.Shorted).
+		DoAndReturn(func(context.Context, string, string) (*model.URLPair, error) {
+			pair := protected
+			return &pair, nil
+		}).
+		AnyTimes()
+
+	now := time.Now()
+	u := NewUsecase(mockStorage, servicetest.Domains{}, &clickRecorder{}, nil, nil, PasswordPolicy{MaxAttempts: 2, Lockout: time.Minute}, false)
+	u.now = func() time.Time { return now }
+
+	run := func(password string) error {
+		_, err := u.Run(context.Background(), In{ShortedURL: protected.Shorted, Password: password})
+		return err
+	}
+
+	assert.ErrorIs(t, run(""), ErrPasswordRequired)
+	assert.ErrorIs(t, run("wrong"), ErrWrongPassword)
+	assert.NoError(t, run("secret"))
+
+	// successful attempt resets the counter
+	assert.ErrorIs(t, run("wrong"), ErrWrongPassword)
+	assert.ErrorIs(t, run("wrong"), ErrWrongPassword)
+	assert.ErrorIs(t, run("secret"), ErrTooManyAttempts)
+
+	now = now.Add(time.Minute)
+	assert.NoError(t, run("secret"))
+}
+
+type clickRecorder struct {
+	clicks []model.Click
+}
+
+func (r *clickRecorder) Track(click model.Click) {
+	r.clicks = append(r.clicks, click)
+}
+
+func TestClickTracking(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mockstorage.NewMockURLRepository(ctrl)
+	mockStorage.EXPECT().
+		GetByURL(gomock.Any(), shortURLColumnName, "xHsvC_0NTU").
+		Return(&model.URLPair{Original: "https://some.com/asdasd", Shorted: "xHsvC_0NTU"}, nil).
+		AnyTimes()
+	mockStorage.EXPECT().
+		GetByURL(gomock.Any(), shortURLColumnName, "unknown").
+		Return(nil, rep.ErrNotFound)
+
+	now := time.Now()
+	clicks := &clickRecorder{}
+	u := NewUsecase(mockStorage, servicetest.Domains{}, clicks, nil, nil, PasswordPolicy{}, false)
+	u.now = func() time.Time { return now }
+
+	visitor := model.Visitor{
+		Referrer:       "https://ref.com/",
+		UserAgent:      "curl/8.0",
+		IP:             "10.0.0.1",
+		AcceptLanguage: "en-US",
+	}
+
+	_, err := u.Run(context.Background(), In{ShortedURL: "xHsvC_0NTU", Visitor: visitor})
+	require.NoError(t, err)
+	_, err = u.Run(context.Background(), In{ShortedURL: "xHsvC_0NTU", Peek: true})
+	require.NoError(t, err)
+	_, err = u.Run(context.Background(), In{ShortedURL: "unknown"})
+	require.ErrorIs(t, err, ErrURLNotFound)
+
+	assert.Equal(t, []model.Click{{Code: "xHsvC_0NTU", At: now, Visitor: visitor}}, clicks.clicks)
+}
+
+func TestCaseInsensitiveCodes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mockstorage.NewMockURLRepository(ctrl)
+	mockStorage.EXPECT().
+		GetByURL(gomock.Any(), shortURLColumnName, "spring-sale").
+		Return(&model.URLPair{Original: "https://some.com/asdasd", Shorted: "spring-sale"}, nil)
+
+	u := NewUsecase(mockStorage, servicetest.Domains{}, &clickRecorder{}, nil, nil, PasswordPolicy{}, true)
+	result, err := u.Run(context.Background(), In{ShortedURL: "https://some.com/Spring-SALE", Peek: true})
+	require.NoError(t, err)
+	assert.Equal(t, "https://some.com/asdasd", result.Original)
+}
+
+func TestBlockedDestination(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mockstorage.NewMockURLRepository(ctrl)
+	mockStorage.EXPECT().
+		GetByURL(gomock.Any(), shortURLColumnName, "xHsvC_0NTU").
+		Return(&model.URLPair{Original: "https://evil.com/login", Shorted: "xHsvC_0NTU", MaxClicks: 5}, nil).
+		Times(2)
+
+	// the destination was blocklisted after the link was created, no click is spent or tracked
+	clicks := &clickRecorder{}
+	u := NewUsecase(mockStorage, servicetest.Domains{}, clicks, servicetest.BlockedHosts{"evil.com"}, nil, PasswordPolicy{}, false)
+
+	_, err := u.Run(context.Background(), In{ShortedURL: "xHsvC_0NTU"})
+	assert.ErrorIs(t, err, ErrURLBlocked)
+	_, err = u.Run(context.Background(), In{ShortedURL: "xHsvC_0NTU", Peek: true})
+	assert.ErrorIs(t, err, ErrURLBlocked)
+	assert.Empty(t, clicks.clicks)
+}
+
+func TestRedirectRules(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 7, 15, 12, 0, 0, 0, time.UTC)
+	pair := model.URLPair{
+		Original: "https://some.com",
+		Shorted:  "app",
+		Rules: []model.RedirectRule{
+			{Target: "https://apps.apple.com/app/some", Devices: []string{model.DeviceIOS}},
+			{Target: "https://play.google.com/store/apps/some", Devices: []string{model.DeviceAndroid}},
+			{Target: "https://some.de", Countries: []string{"DE", "AT"}},
+			{Target: "https://some.com/pt", Languages: []string{"pt"}},
+			{Target: "https://some.com/sale", From: now.Add(-time.Hour), Until: now.Add(time.Hour)},
+			{Target: "https://evil.com/", Languages: []string{"fr"}},
+		},
+	}
+
+	mockStorage := mockstorage.NewMockURLRepository(ctrl)
+	mockStorage.EXPECT().
+		GetByURL(gomock.Any(), shortURLColumnName, "app").
+		DoAndReturn(func(context.Context, string, string) (*model.URLPair, error) {
+			p := pair
+			return &p, nil
+		}).
+		AnyTimes()
+
+	clicks := &clickRecorder{}
+	u := NewUsecase(mockStorage, servicetest.Domains{}, clicks, servicetest.BlockedHosts{"evil.com"}, servicetest.Countries{"81.2.69.160": "DE"},
+		PasswordPolicy{}, false)
+	u.now = func() time.Time { return now.Add(2 * time.Hour) }
+
+	tests := []struct {
+		name          string
+		visitor       model.Visitor
+		expected      string
+		expectedError error
+	}{
+		{
+			name:     "iPhone",
+			visitor:  model.Visitor{UserAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) Mobile/15E148 Safari/604.1"},
+			expected: "https://apps.apple.com/app/some",
+		},
+		{
+			name:     "Android",
+			visitor:  model.Visitor{UserAgent: "Mozilla/5.0 (Linux; Android 14; Pixel 8) Chrome/124.0 Mobile Safari/537.36"},
+			expected: "https://play.google.com/store/apps/some",
+		},
+		{
+			name:     "country from the header",
+			visitor:  model.Visitor{Country: "AT"},
+			expected: "https://some.de",
+		},
+		{
+			name:     "country from the GeoIP database",
+			visitor:  model.Visitor{IP: "81.2.69.160"},
+			expected: "https://some.de",
+		},
+		{
+			name:     "preferred language",
+			visitor:  model.Visitor{AcceptLanguage: "en;q=0.5, pt-BR"},
+			expected: "https://some.com/pt",
+		},
+		{
+			name:     "no rule matches",
+			visitor:  model.Visitor{UserAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) Chrome/124.0", AcceptLanguage: "en"},
+			expected: "https://some.com",
+		},
+		{
+			name:          "blocklisted target",
+			visitor:       model.Visitor{AcceptLanguage: "fr-FR"},
+			expectedError: ErrURLBlocked,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := u.Run(context.Background(), In{ShortedURL: "app", Visitor: tt.visitor})
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result.Original)
+		})
+	}
+
+	// the time window is checked as well
+	u.now = func() time.Time { return now }
+	result, err := u.Run(context.Background(), In{ShortedURL: "app"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://some.com/sale", result.Original)
+
+	// clicks are recorded with the located country
+	assert.Equal(t, "DE", clicks.clicks[3].Country)
+}
+
+func TestSplitTargets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pair := model.URLPair{
+		Original: "https://some.com",
+		Shorted:  "ab",
+		Rules:    []model.RedirectRule{{Target: "https://some.com/pt", Languages: []string{"pt"}}},
+		Targets: []model.SplitTarget{
+			{Variant: "a", URL: "https://some.com/a", Weight: 3},
+			{Variant: "paused", URL: "https://some.com/paused", Weight: 0},
+			{Variant: "b", URL: "https://some.com/b", Weight: 1},
+		},
+	}
+
+	mockStorage := mockstorage.NewMockURLRepository(ctrl)
+	mockStorage.EXPECT().
+		GetByURL(gomock.Any(), shortURLColumnName, "ab").
+		DoAndReturn(func(context.Context, string, string) (*model.URLPair, error) {
+			p := pair
+			return &p, nil
+		}).
+		AnyTimes()
+
+	clicks := &clickRecorder{}
+	u := NewUsecase(mockStorage, servicetest.Domains{}, clicks, nil, nil, PasswordPolicy{}, false)
+	var rolled int
+	u.roll = func(n int) int {
+		assert.Equal(t, 4, n)
+		return rolled
+	}
+
+	tests := []struct {
+		name            string
+		roll            int
+		req             In
+		expected        string
+		expectedVariant string
+	}{
+		{name: "first weight", roll: 2, req: In{ShortedURL: "ab"}, expected: "https://some.com/a", expectedVariant: "a"},
+		{name: "second weight", roll: 3, req: In{ShortedURL: "ab"}, expected: "https://some.com/b", expectedVariant: "b"},
+		{name: "sticky variant", roll: 0, req: In{ShortedURL: "ab", Variant: "b"}, expected: "https://some.com/b", expectedVariant: "b"},
+		{name: "paused variant", roll: 0, req: In{ShortedURL: "ab", Variant: "paused"}, expected: "https://some.com/a", expectedVariant: "a"},
+		{name: "removed variant", roll: 3, req: In{ShortedURL: "ab", Variant: "c"}, expected: "https://some.com/b", expectedVariant: "b"},
+		{
+			name:     "rules go first",
+			req:      In{ShortedURL: "ab", Visitor: model.Visitor{AcceptLanguage: "pt-BR"}},
+			expected: "https://some.com/pt",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rolled = tt.roll
+			result, err := u.Run(context.Background(), tt.req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result.Original)
+			assert.Equal(t, tt.expectedVariant, result.Variant)
+		})
+	}
+
+	// clicks are recorded with the variant
+	require.Len(t, clicks.clicks, len(tests))
+	assert.Equal(t, "b", clicks.clicks[1].Variant)
+	assert.Empty(t, clicks.clicks[5].Variant)
+}
+
+func TestInterstitial(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mockstorage.NewMockURLRepository(ctrl)
+	mockStorage.EXPECT().
+		GetByURL(gomock.Any(), shortURLColumnName, "xHsvC_0NTU").
+		Return(&model.URLPair{Original: "https://some.com/asdasd", Shorted: "xHsvC_0NTU", MaxClicks: 5, Interstitial: true}, nil).
+		Times(2)
+	mockStorage.EXPECT().ConsumeClick(gomock.Any(), "xHsvC_0NTU")
+
+	clicks := &clickRecorder{}
+	u := NewUsecase(mockStorage, servicetest.Domains{}, clicks, nil, nil, PasswordPolicy{}, false)
+
+	// the interstitial page spends no click, continuing from it does
+	result, err := u.Run(context.Background(), In{ShortedURL: "xHsvC_0NTU"})
+	require.NoError(t, err)
+	assert.True(t, result.Interstitial)
+	assert.Zero(t, result.ClicksUsed)
+	assert.Empty(t, clicks.clicks)
+
+	result, err = u.Run(context.Background(), In{ShortedURL: "xHsvC_0NTU", Confirmed: true})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.ClicksUsed)
+	assert.Len(t, clicks.clicks, 1)
+}
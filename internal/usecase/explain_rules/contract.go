@@ -0,0 +1,30 @@
+package explain_rules
+
+import (
+	"time"
+
+	"link-shortener-service/internal/model"
+)
+
+type In struct {
+	Principal  model.Principal
+	ShortedURL string
+	// Visitor is the visitor the rules are checked for, its country is looked up by IP when empty
+	Visitor model.Visitor
+	// At is the time of the visit, now when zero
+	At time.Time
+}
+
+type Out struct {
+	Audience model.Audience
+	Rules    []RuleResult
+	// Matched is the index of the rule the visitor is sent by, -1 for the original URL
+	Matched     int
+	Destination string
+}
+
+type RuleResult struct {
+	Rule model.RedirectRule
+	// Mismatches are the reasons the rule does not match, empty for matching rules
+	Mismatches []string
+}
@@ -0,0 +1,64 @@
+package explain_rules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	"link-shortener-service/internal/model"
+	"link-shortener-service/internal/usecase/contract/repository"
+	"link-shortener-service/internal/usecase/contract/service"
+)
+
+const shortURLColumnName = "shorted_url"
+
+var (
+	ErrURLNotFound  = errors.New("URLPair not found")
+	ErrURLRetrieval = errors.New("failed to retrieve URLPair")
+	ErrForbidden    = errors.New("URLPair belongs to another owner")
+)
+
+type usecase struct {
+	urls    repository.URLRepository
+	locator service.Locator
+	now     func() time.Time
+}
+
+func NewUsecase(urls repository.URLRepository, locator service.Locator) *usecase {
+	return &usecase{
+		urls:    urls,
+		locator: locator,
+		now:     time.Now,
+	}
+}
+
+// Run checks the rules of the link the way the redirect does, without resolving the link
+func (u *usecase) Run(ctx context.Context, req In) (*Out, error) {
+	pair, err := u.urls.GetByURL(ctx, shortURLColumnName, req.ShortedURL)
+	if err != nil {
+		if errors.Is(err, rep.ErrNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrURLNotFound, req.ShortedURL)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrURLRetrieval, err)
+	}
+	if !req.Principal.CanManage(pair) {
+		return nil, fmt.Errorf("%w: %s", ErrForbidden, req.ShortedURL)
+	}
+
+	visitor, at := req.Visitor, req.At
+	if visitor.Country == "" && u.locator != nil {
+		visitor.Country = u.locator.Country(visitor.IP)
+	}
+	if at.IsZero() {
+		at = u.now()
+	}
+
+	out := &Out{Audience: model.NewAudience(visitor, at)}
+	out.Matched, out.Destination = pair.Route(out.Audience)
+	for _, rule := range pair.Rules {
+		out.Rules = append(out.Rules, RuleResult{Rule: rule, Mismatches: rule.Mismatches(out.Audience)})
+	}
+	return out, nil
+}
@@ -0,0 +1,127 @@
+package explain_rules
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	"link-shortener-service/internal/model"
+	mockstorage "link-shortener-service/internal/usecase/contract/repository/mocks"
+	"link-shortener-service/internal/usecase/contract/service/servicetest"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 7, 15, 12, 0, 0, 0, time.UTC)
+	owner := model.Principal{OwnerID: "team-a"}
+	pair := &model.URLPair{
+		Original: "https://some.com",
+		Shorted:  "app",
+		OwnerID:  "team-a",
+		Rules: []model.RedirectRule{
+			{Target: "https://apps.apple.com/app/some", Devices: []string{model.DeviceIOS}},
+			{Target: "https://some.de", Countries: []string{"DE"}, Languages: []string{"de"}},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		req           In
+		setupMock     func(*mockstorage.MockURLRepository)
+		expected      *Out
+		expectedError error
+	}{
+		{
+			name: "second rule matches with the located country",
+			req: In{
+				Principal:  owner,
+				ShortedURL: "app",
+				Visitor:    model.Visitor{IP: "81.2.69.160", AcceptLanguage: "de-AT,de;q=0.9"},
+			},
+			setupMock: func(urls *mockstorage.MockURLRepository) {
+				urls.EXPECT().GetByURL(gomock.Any(), shortURLColumnName, "app").Return(pair, nil)
+			},
+			expected: &Out{
+				Audience: model.Audience{Device: model.DeviceDesktop, Language: "de-at", Country: "DE", At: now},
+				Rules: []RuleResult{
+					{Rule: pair.Rules[0], Mismatches: []string{"device desktop is not one of ios"}},
+					{Rule: pair.Rules[1]},
+				},
+				Matched:     1,
+				Destination: "https://some.de",
+			},
+		},
+		{
+			name: "no rule matches",
+			req: In{
+				Principal:  model.Principal{Admin: true},
+				ShortedURL: "app",
+				Visitor:    model.Visitor{Country: "fr"},
+				At:         now.Add(time.Hour),
+			},
+			setupMock: func(urls *mockstorage.MockURLRepository) {
+				urls.EXPECT().GetByURL(gomock.Any(), shortURLColumnName, "app").Return(pair, nil)
+			},
+			expected: &Out{
+				Audience: model.Audience{Device: model.DeviceDesktop, Country: "FR", At: now.Add(time.Hour)},
+				Rules: []RuleResult{
+					{Rule: pair.Rules[0], Mismatches: []string{"device desktop is not one of ios"}},
+					{Rule: pair.Rules[1], Mismatches: []string{"language unknown is not one of de", "country FR is not one of DE"}},
+				},
+				Matched:     -1,
+				Destination: "https://some.com",
+			},
+		},
+		{
+			name: "link of another owner",
+			req:  In{Principal: model.Principal{OwnerID: "team-b"}, ShortedURL: "app"},
+			setupMock: func(urls *mockstorage.MockURLRepository) {
+				urls.EXPECT().GetByURL(gomock.Any(), shortURLColumnName, "app").Return(pair, nil)
+			},
+			expectedError: ErrForbidden,
+		},
+		{
+			name: "unknown link",
+			req:  In{Principal: owner, ShortedURL: "app"},
+			setupMock: func(urls *mockstorage.MockURLRepository) {
+				urls.EXPECT().GetByURL(gomock.Any(), shortURLColumnName, "app").Return(nil, rep.ErrNotFound)
+			},
+			expectedError: ErrURLNotFound,
+		},
+		{
+			name: "storage error",
+			req:  In{Principal: owner, ShortedURL: "app"},
+			setupMock: func(urls *mockstorage.MockURLRepository) {
+				urls.EXPECT().GetByURL(gomock.Any(), shortURLColumnName, "app").Return(nil, errors.New("db is down"))
+			},
+			expectedError: ErrURLRetrieval,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			urls := mockstorage.NewMockURLRepository(ctrl)
+			tt.setupMock(urls)
+
+			u := NewUsecase(urls, servicetest.Countries{"81.2.69.160": "DE"})
+			u.now = func() time.Time { return now }
+			result, err := u.Run(context.Background(), tt.req)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, result)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package fetch_metadata
+
+import (
+	"context"
+	"time"
+
+	"link-shortener-service/internal/model"
+)
+
+// MetadataFetcher reads the metadata of the destination page
+type MetadataFetcher interface {
+	Fetch(ctx context.Context, rawURL string) (model.LinkMetadata, error)
+}
+
+type Policy struct {
+	// QueueSize bounds the new links waiting for their first fetch, links over it are dropped
+	// and left to the scheduled fetches
+	QueueSize int
+	// BatchSize is how many due links a scheduled run fetches at most
+	BatchSize int
+	// Concurrency is how many pages are fetched at once
+	Concurrency int
+	// Refresh is the interval between the fetches of a link, and the longest retry interval
+	Refresh time.Duration
+	// Retry is the first interval after a failed fetch, it doubles with each failure in a row
+	Retry time.Duration
+}
@@ -0,0 +1,143 @@
+package fetch_metadata
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"link-shortener-service/internal/model"
+	"link-shortener-service/internal/usecase/contract/repository"
+)
+
+var (
+	ErrFetchMetadata = errors.New("failed to fetch link metadata")
+
+	previewsDropped = expvar.NewInt("previews_dropped")
+)
+
+type usecase struct {
+	repo    repository.LinkPreviewRepository
+	fetcher MetadataFetcher
+	queue   chan model.LinkPreview
+	policy  Policy
+	now     func() time.Time
+}
+
+func NewUsecase(repo repository.LinkPreviewRepository, fetcher MetadataFetcher, policy Policy) *usecase {
+	policy.Concurrency = max(policy.Concurrency, 1)
+	return &usecase{
+		repo:    repo,
+		fetcher: fetcher,
+		queue:   make(chan model.LinkPreview, policy.QueueSize),
+		policy:  policy,
+		now:     time.Now,
+	}
+}
+
+// Prefetch enqueues the first fetch of a new link without blocking
+func (u *usecase) Prefetch(pair model.URLPair) {
+	select {
+	case u.queue <- model.LinkPreview{Shorted: pair.Shorted, Original: pair.Original}:
+	default:
+		previewsDropped.Add(1)
+	}
+}
+
+// Run fetches the queued links until the context is cancelled, the links left in the queue
+// are fetched by the scheduled runs
+func (u *usecase) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case preview := <-u.queue:
+			batch := u.takeQueued([]model.LinkPreview{preview})
+			if err := u.fetchAll(ctx, batch); err != nil && ctx.Err() == nil {
+				log.Printf("failed to fetch metadata of %d new links: %v", len(batch), err)
+			}
+		}
+	}
+}
+
+// takeQueued adds the links already waiting in the queue to the batch, up to one link per concurrent fetch
+func (u *usecase) takeQueued(batch []model.LinkPreview) []model.LinkPreview {
+	for len(batch) < u.policy.Concurrency {
+		select {
+		case preview := <-u.queue:
+			batch = append(batch, preview)
+		default:
+			return batch
+		}
+	}
+	return batch
+}
+
+// Refresh fetches the links due for a fetch: never fetched, changed, failed or stale ones
+func (u *usecase) Refresh(ctx context.Context) error {
+	due, err := u.repo.GetLinksToFetch(ctx, u.now(), u.policy.BatchSize)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrFetchMetadata, err)
+	}
+	if len(due) == 0 {
+		return nil
+	}
+	return u.fetchAll(ctx, due)
+}
+
+func (u *usecase) fetchAll(ctx context.Context, previews []model.LinkPreview) error {
+	sem := make(chan struct{}, u.policy.Concurrency)
+	var wg sync.WaitGroup
+	for i := range previews {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			previews[i] = u.fetch(ctx, previews[i])
+		}()
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		// the fetches cut short by the shutdown are not the fault of the destinations
+		return ctx.Err()
+	}
+	if err := u.repo.SaveLinkPreviews(ctx, previews); err != nil {
+		return fmt.Errorf("%w: %v", ErrFetchMetadata, err)
+	}
+	return nil
+}
+
+func (u *usecase) fetch(ctx context.Context, preview model.LinkPreview) model.LinkPreview {
+	metadata, err := u.fetcher.Fetch(ctx, preview.Original)
+	now := u.now()
+	if err != nil {
+		// the metadata of the last successful fetch stays until the page is back
+		preview.Error = err.Error()
+		preview.Failures++
+		preview.NextFetchAt = now.Add(u.retryAfter(preview.Failures))
+		return preview
+	}
+
+	preview.LinkMetadata = metadata
+	preview.FetchedAt = now
+	preview.Error = ""
+	preview.Failures = 0
+	preview.NextFetchAt = now.Add(u.policy.Refresh)
+	return preview
+}
+
+// retryAfter backs off exponentially from the retry interval up to the refresh interval
+func (u *usecase) retryAfter(failures int) time.Duration {
+	interval := u.policy.Retry
+	for i := 1; i < failures && interval < u.policy.Refresh; i++ {
+		interval *= 2
+	}
+	return min(interval, u.policy.Refresh)
+}
@@ -0,0 +1,176 @@
+package fetch_metadata
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"link-shortener-service/internal/model"
+	mockstorage "link-shortener-service/internal/usecase/contract/repository/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// fixedPages answers the fetches by URL, unknown URLs fail
+type fixedPages map[string]model.LinkMetadata
+
+func (p fixedPages) Fetch(_ context.Context, rawURL string) (model.LinkMetadata, error) {
+	metadata, ok := p[rawURL]
+	if !ok {
+		return model.LinkMetadata{}, errors.New("connection refused")
+	}
+	return metadata, nil
+}
+
+var pages = fixedPages{
+	"https://some.com": {Title: "Some", Favicon: "https://some.com/favicon.ico"},
+}
+
+func TestRefresh(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Now()
+	policy := Policy{BatchSize: 100, Concurrency: 2, Refresh: 7 * 24 * time.Hour, Retry: time.Hour}
+
+	tests := []struct {
+		name          string
+		setupMock     func(*mockstorage.MockLinkPreviewRepository)
+		expectedError error
+	}{
+		{
+			name: "links fetched",
+			setupMock: func(mockRepo *mockstorage.MockLinkPreviewRepository) {
+				mockRepo.EXPECT().
+					GetLinksToFetch(gomock.Any(), now, 100).
+					Return([]model.LinkPreview{
+						{Shorted: "ok", Original: "https://some.com", Error: "timeout", Failures: 2},
+						{Shorted: "new", Original: "https://down.com"},
+						{
+							Shorted:      "down",
+							Original:     "https://down.com",
+							LinkMetadata: model.LinkMetadata{Title: "Down"},
+							FetchedAt:    now.Add(-time.Hour),
+							Failures:     3,
+						},
+					}, nil)
+				mockRepo.EXPECT().
+					SaveLinkPreviews(gomock.Any(), []model.LinkPreview{
+						{
+							Shorted:      "ok",
+							Original:     "https://some.com",
+							LinkMetadata: model.LinkMetadata{Title: "Some", Favicon: "https://some.com/favicon.ico"},
+							FetchedAt:    now,
+							NextFetchAt:  now.Add(7 * 24 * time.Hour),
+						},
+						{
+							Shorted:     "new",
+							Original:    "https://down.com",
+							Error:       "connection refused",
+							Failures:    1,
+							NextFetchAt: now.Add(time.Hour),
+						},
+						{
+							Shorted:      "down",
+							Original:     "https://down.com",
+							LinkMetadata: model.LinkMetadata{Title: "Down"},
+							FetchedAt:    now.Add(-time.Hour),
+							Error:        "connection refused",
+							Failures:     4,
+							NextFetchAt:  now.Add(8 * time.Hour),
+						},
+					}).
+					Return(nil)
+			},
+		},
+		{
+			name: "nothing to fetch",
+			setupMock: func(mockRepo *mockstorage.MockLinkPreviewRepository) {
+				mockRepo.EXPECT().
+					GetLinksToFetch(gomock.Any(), now, 100).
+					Return(nil, nil)
+			},
+		},
+		{
+			name: "error getting links",
+			setupMock: func(mockRepo *mockstorage.MockLinkPreviewRepository) {
+				mockRepo.EXPECT().
+					GetLinksToFetch(gomock.Any(), now, 100).
+					Return(nil, errors.New("storage error"))
+			},
+			expectedError: ErrFetchMetadata,
+		},
+		{
+			name: "error saving previews",
+			setupMock: func(mockRepo *mockstorage.MockLinkPreviewRepository) {
+				mockRepo.EXPECT().
+					GetLinksToFetch(gomock.Any(), now, 100).
+					Return([]model.LinkPreview{{Shorted: "ok", Original: "https://some.com"}}, nil)
+				mockRepo.EXPECT().
+					SaveLinkPreviews(gomock.Any(), gomock.Any()).
+					Return(errors.New("storage error"))
+			},
+			expectedError: ErrFetchMetadata,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mockstorage.NewMockLinkPreviewRepository(ctrl)
+			tt.setupMock(mockRepo)
+
+			u := NewUsecase(mockRepo, pages, policy)
+			u.now = func() time.Time { return now }
+
+			err := u.Refresh(context.Background())
+
+			assert.ErrorIs(t, err, tt.expectedError)
+		})
+	}
+}
+
+func TestPrefetch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Now()
+	saved := make(chan []model.LinkPreview, 1)
+	mockRepo := mockstorage.NewMockLinkPreviewRepository(ctrl)
+	mockRepo.EXPECT().
+		SaveLinkPreviews(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, previews []model.LinkPreview) error {
+			saved <- previews
+			return nil
+		})
+
+	u := NewUsecase(mockRepo, pages, Policy{QueueSize: 1, Concurrency: 4, Refresh: time.Hour, Retry: time.Minute})
+	u.now = func() time.Time { return now }
+
+	dropped := previewsDropped.Value()
+	u.Prefetch(model.URLPair{Shorted: "abc", Original: "https://some.com"})
+	u.Prefetch(model.URLPair{Shorted: "xyz", Original: "https://some.com"})
+	assert.Equal(t, dropped+1, previewsDropped.Value())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		u.Run(ctx)
+	}()
+
+	previews := <-saved
+	cancel()
+	wg.Wait()
+
+	assert.Equal(t, []model.LinkPreview{{
+		Shorted:      "abc",
+		Original:     "https://some.com",
+		LinkMetadata: model.LinkMetadata{Title: "Some", Favicon: "https://some.com/favicon.ico"},
+		FetchedAt:    now,
+		NextFetchAt:  now.Add(time.Hour),
+	}}, previews)
+}
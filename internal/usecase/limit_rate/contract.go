@@ -0,0 +1,22 @@
+package limit_rate
+
+import (
+	"time"
+
+	"link-shortener-service/internal/model"
+)
+
+type In struct {
+	// Key identifies the limited client, requests with the same key share the bucket
+	Key   string
+	Limit model.RateLimit
+}
+
+type Out struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is when the bucket is full again, RetryAfter is when the next request is allowed
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
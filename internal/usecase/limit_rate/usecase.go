@@ -0,0 +1,53 @@
+package limit_rate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"link-shortener-service/internal/usecase/contract/repository"
+)
+
+var (
+	ErrRateLimitCheck = errors.New("failed to check rate limit")
+)
+
+type usecase struct {
+	repo repository.RateLimitRepository
+	now  func() time.Time
+}
+
+func NewUsecase(repo repository.RateLimitRepository) *usecase {
+	return &usecase{
+		repo: repo,
+		now:  time.Now,
+	}
+}
+
+func (u *usecase) Run(ctx context.Context, req In) (*Out, error) {
+	tokens, taken, err := u.repo.TakeToken(ctx, req.Key, req.Limit, u.now())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrRateLimitCheck, err)
+	}
+
+	out := &Out{
+		Allowed:   taken,
+		Limit:     req.Limit.Burst,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     refillTime(float64(req.Limit.Burst)-tokens, req.Limit.Rate),
+	}
+	if !taken {
+		out.RetryAfter = refillTime(1-tokens, req.Limit.Rate)
+	}
+	return out, nil
+}
+
+// refillTime is how long the bucket takes to get the tokens back
+func refillTime(tokens, rate float64) time.Duration {
+	if tokens <= 0 {
+		return 0
+	}
+	return time.Duration(tokens / rate * float64(time.Second))
+}
@@ -0,0 +1,74 @@
+package limit_rate
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"link-shortener-service/internal/model"
+	mockstorage "link-shortener-service/internal/usecase/contract/repository/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 6, 24, 12, 0, 0, 0, time.UTC)
+	req := In{Key: "create|ip:10.0.0.1", Limit: model.RateLimit{Rate: 0.5, Burst: 10}}
+
+	tests := []struct {
+		name          string
+		tokens        float64
+		taken         bool
+		repoErr       error
+		expected      *Out
+		expectedError error
+	}{
+		{
+			name:   "request is allowed",
+			tokens: 7.5,
+			taken:  true,
+			expected: &Out{
+				Allowed:   true,
+				Limit:     10,
+				Remaining: 7,
+				Reset:     5 * time.Second,
+			},
+		},
+		{
+			name:   "request is limited",
+			tokens: 0.25,
+			expected: &Out{
+				Limit:      10,
+				Remaining:  0,
+				Reset:      19500 * time.Millisecond,
+				RetryAfter: 1500 * time.Millisecond,
+			},
+		},
+		{
+			name:          "error from storage",
+			repoErr:       errors.New("db is down"),
+			expectedError: ErrRateLimitCheck,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mockstorage.NewMockRateLimitRepository(ctrl)
+			mockRepo.EXPECT().
+				TakeToken(gomock.Any(), req.Key, req.Limit, now).
+				Return(tt.tokens, tt.taken, tt.repoErr)
+
+			u := NewUsecase(mockRepo)
+			u.now = func() time.Time { return now }
+
+			out, err := u.Run(context.Background(), req)
+			assert.ErrorIs(t, err, tt.expectedError)
+			assert.Equal(t, tt.expected, out)
+		})
+	}
+}
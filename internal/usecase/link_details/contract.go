@@ -0,0 +1,17 @@
+package link_details
+
+import (
+	"link-shortener-service/internal/model"
+)
+
+type In struct {
+	Principal  model.Principal
+	ShortedURL string
+}
+
+type Out struct {
+	// URLPair has the full short URL
+	URLPair model.URLPair
+	// Preview is nil until the metadata of the current destination is fetched
+	Preview *model.LinkPreview
+}
@@ -0,0 +1,62 @@
+package link_details
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	"link-shortener-service/internal/model"
+	"link-shortener-service/internal/usecase/contract/repository"
+)
+
+const shortURLColumnName = "shorted_url"
+
+var (
+	ErrURLNotFound      = errors.New("URLPair not found")
+	ErrURLRetrieval     = errors.New("failed to retrieve URLPair")
+	ErrForbidden        = errors.New("URLPair belongs to another owner")
+	ErrPreviewRetrieval = errors.New("failed to retrieve link preview")
+)
+
+type usecase struct {
+	urls        repository.URLRepository
+	previews    repository.LinkPreviewRepository
+	leftURLPart string
+}
+
+func NewUsecase(urls repository.URLRepository, previews repository.LinkPreviewRepository, leftURLPart string) *usecase {
+	return &usecase{
+		urls:        urls,
+		previews:    previews,
+		leftURLPart: leftURLPart,
+	}
+}
+
+func (u *usecase) Run(ctx context.Context, req In) (*Out, error) {
+	pair, err := u.urls.GetByURL(ctx, shortURLColumnName, req.ShortedURL)
+	if err != nil {
+		if errors.Is(err, rep.ErrNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrURLNotFound, req.ShortedURL)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrURLRetrieval, err)
+	}
+	if !req.Principal.CanManage(pair) {
+		return nil, fmt.Errorf("%w: %s", ErrForbidden, req.ShortedURL)
+	}
+
+	out := &Out{URLPair: *pair}
+	out.URLPair.Shorted = model.ShortURL(u.leftURLPart, pair.Shorted)
+
+	preview, err := u.previews.GetLinkPreview(ctx, req.ShortedURL)
+	switch {
+	case errors.Is(err, rep.ErrNotFound):
+	case err != nil:
+		return nil, fmt.Errorf("%w: %v", ErrPreviewRetrieval, err)
+	// the metadata of the previous destination or of failed fetches only is not shown
+	case preview.Original == pair.Original && !preview.FetchedAt.IsZero():
+		out.Preview = preview
+	}
+
+	return out, nil
+}
@@ -0,0 +1,115 @@
+package link_details
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	"link-shortener-service/internal/model"
+	mockstorage "link-shortener-service/internal/usecase/contract/repository/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	fetchedAt := time.Date(2025, 7, 8, 12, 0, 0, 0, time.UTC)
+	owner := model.Principal{OwnerID: "team-a"}
+	pair := &model.URLPair{Original: "https://some.com", Shorted: "abc", OwnerID: "team-a"}
+	preview := &model.LinkPreview{
+		Shorted:      "abc",
+		Original:     "https://some.com",
+		LinkMetadata: model.LinkMetadata{Title: "Some"},
+		FetchedAt:    fetchedAt,
+	}
+
+	tests := []struct {
+		name          string
+		principal     model.Principal
+		setupMock     func(*mockstorage.MockURLRepository, *mockstorage.MockLinkPreviewRepository)
+		expected      *Out
+		expectedError error
+	}{
+		{
+			name:      "link with preview",
+			principal: owner,
+			setupMock: func(urls *mockstorage.MockURLRepository, previews *mockstorage.MockLinkPreviewRepository) {
+				urls.EXPECT().GetByURL(gomock.Any(), shortURLColumnName, "abc").Return(pair, nil)
+				previews.EXPECT().GetLinkPreview(gomock.Any(), "abc").Return(preview, nil)
+			},
+			expected: &Out{
+				URLPair: model.URLPair{Original: "https://some.com", Shorted: "https://sho.rt/abc", OwnerID: "team-a"},
+				Preview: preview,
+			},
+		},
+		{
+			name:      "metadata not fetched yet",
+			principal: owner,
+			setupMock: func(urls *mockstorage.MockURLRepository, previews *mockstorage.MockLinkPreviewRepository) {
+				urls.EXPECT().GetByURL(gomock.Any(), shortURLColumnName, "abc").Return(pair, nil)
+				previews.EXPECT().GetLinkPreview(gomock.Any(), "abc").Return(nil, rep.ErrNotFound)
+			},
+			expected: &Out{
+				URLPair: model.URLPair{Original: "https://some.com", Shorted: "https://sho.rt/abc", OwnerID: "team-a"},
+			},
+		},
+		{
+			name:      "metadata of the previous destination",
+			principal: model.Principal{Admin: true},
+			setupMock: func(urls *mockstorage.MockURLRepository, previews *mockstorage.MockLinkPreviewRepository) {
+				urls.EXPECT().GetByURL(gomock.Any(), shortURLColumnName, "abc").Return(pair, nil)
+				previews.EXPECT().
+					GetLinkPreview(gomock.Any(), "abc").
+					Return(&model.LinkPreview{Shorted: "abc", Original: "https://old.com", FetchedAt: fetchedAt}, nil)
+			},
+			expected: &Out{
+				URLPair: model.URLPair{Original: "https://some.com", Shorted: "https://sho.rt/abc", OwnerID: "team-a"},
+			},
+		},
+		{
+			name:      "unknown link",
+			principal: owner,
+			setupMock: func(urls *mockstorage.MockURLRepository, previews *mockstorage.MockLinkPreviewRepository) {
+				urls.EXPECT().GetByURL(gomock.Any(), shortURLColumnName, "abc").Return(nil, rep.ErrNotFound)
+			},
+			expectedError: ErrURLNotFound,
+		},
+		{
+			name:      "link of another owner",
+			principal: model.Principal{OwnerID: "team-b"},
+			setupMock: func(urls *mockstorage.MockURLRepository, previews *mockstorage.MockLinkPreviewRepository) {
+				urls.EXPECT().GetByURL(gomock.Any(), shortURLColumnName, "abc").Return(pair, nil)
+			},
+			expectedError: ErrForbidden,
+		},
+		{
+			name:      "error getting preview",
+			principal: owner,
+			setupMock: func(urls *mockstorage.MockURLRepository, previews *mockstorage.MockLinkPreviewRepository) {
+				urls.EXPECT().GetByURL(gomock.Any(), shortURLColumnName, "abc").Return(pair, nil)
+				previews.EXPECT().GetLinkPreview(gomock.Any(), "abc").Return(nil, errors.New("storage error"))
+			},
+			expectedError: ErrPreviewRetrieval,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			urls := mockstorage.NewMockURLRepository(ctrl)
+			previews := mockstorage.NewMockLinkPreviewRepository(ctrl)
+			tt.setupMock(urls, previews)
+
+			u := NewUsecase(urls, previews, "https://sho.rt/")
+
+			out, err := u.Run(context.Background(), In{Principal: tt.principal, ShortedURL: "abc"})
+
+			assert.ErrorIs(t, err, tt.expectedError)
+			assert.Equal(t, tt.expected, out)
+		})
+	}
+}
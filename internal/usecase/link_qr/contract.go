@@ -0,0 +1,32 @@
+package link_qr
+
+import (
+	"link-shortener-service/internal/model"
+	"link-shortener-service/internal/qrcode"
+)
+
+type In struct {
+	Principal  model.Principal
+	ShortedURL string
+	// Format is FormatPNG or FormatSVG
+	Format string
+	// Size is the side of the image in pixels
+	Size int
+	// Margin is the quiet zone in modules
+	Margin int
+	// Level is L, M, Q or H
+	Level string
+	// Foreground and Background are RRGGBB or RRGGBBAA hex colors
+	Foreground string
+	Background string
+}
+
+type Out struct {
+	// ShortURL is the full short URL in the code
+	ShortURL string
+	Code     *qrcode.Code
+	Format   string
+	Style    qrcode.Style
+	// ETag is a strong validator of the image, it is the same for the same short URL and options
+	ETag string
+}
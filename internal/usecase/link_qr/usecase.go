@@ -0,0 +1,153 @@
+package link_qr
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"image/color"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	"link-shortener-service/internal/model"
+	"link-shortener-service/internal/qrcode"
+	"link-shortener-service/internal/usecase/contract/repository"
+)
+
+const shortURLColumnName = "shorted_url"
+
+const (
+	FormatPNG = "png"
+	FormatSVG = "svg"
+
+	DefaultSize       = 256
+	MinSize           = 64
+	MaxSize           = 4096
+	DefaultMargin     = 4
+	MaxMargin         = 16
+	DefaultLevel      = "M"
+	DefaultForeground = "000000"
+	DefaultBackground = "ffffff"
+)
+
+// renderVersion is a part of every ETag, it changes when the same options are drawn differently
+const renderVersion = "1"
+
+var (
+	ErrURLNotFound    = errors.New("URLPair not found")
+	ErrURLRetrieval   = errors.New("failed to retrieve URLPair")
+	ErrForbidden      = errors.New("URLPair belongs to another owner")
+	ErrInvalidOptions = errors.New("invalid QR code options")
+	ErrEncode         = errors.New("failed to encode QR code")
+)
+
+type usecase struct {
+	urls        repository.URLRepository
+	leftURLPart string
+}
+
+func NewUsecase(urls repository.URLRepository, leftURLPart string) *usecase {
+	return &usecase{
+		urls:        urls,
+		leftURLPart: leftURLPart,
+	}
+}
+
+// Run encodes the full short URL of the link, the image is drawn by the caller with the returned style
+func (u *usecase) Run(ctx context.Context, req In) (*Out, error) {
+	out, level, err := parseOptions(req)
+	if err != nil {
+		return nil, err
+	}
+
+	pair, err := u.urls.GetByURL(ctx, shortURLColumnName, req.ShortedURL)
+	if err != nil {
+		if errors.Is(err, rep.ErrNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrURLNotFound, req.ShortedURL)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrURLRetrieval, err)
+	}
+	if !req.Principal.CanManage(pair) {
+		return nil, fmt.Errorf("%w: %s", ErrForbidden, req.ShortedURL)
+	}
+
+	out.ShortURL = model.ShortURL(u.leftURLPart, pair.Shorted)
+	if out.Code, err = qrcode.Encode([]byte(out.ShortURL), level); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrEncode, err)
+	}
+
+	s := out.Style
+	hash := sha256.Sum256(fmt.Appendf(nil, "%s\x00%s\x00%s\x00%s\x00%d\x00%d\x00%02x\x00%02x",
+		renderVersion, out.ShortURL, out.Format, level, s.Size, s.Margin,
+		[]uint8{s.Foreground.R, s.Foreground.G, s.Foreground.B, s.Foreground.A},
+		[]uint8{s.Background.R, s.Background.G, s.Background.B, s.Background.A},
+	))
+	out.ETag = fmt.Sprintf(`"%x"`, hash)
+
+	return out, nil
+}
+
+func parseOptions(req In) (*Out, qrcode.Level, error) {
+	if req.Format != FormatPNG && req.Format != FormatSVG {
+		return nil, 0, fmt.Errorf("%w: format must be %s or %s", ErrInvalidOptions, FormatPNG, FormatSVG)
+	}
+	if req.Size < MinSize || req.Size > MaxSize {
+		return nil, 0, fmt.Errorf("%w: size must be between %d and %d", ErrInvalidOptions, MinSize, MaxSize)
+	}
+	if req.Margin < 0 || req.Margin > MaxMargin {
+		return nil, 0, fmt.Errorf("%w: margin must be between 0 and %d", ErrInvalidOptions, MaxMargin)
+	}
+	level, err := qrcode.ParseLevel(req.Level)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", ErrInvalidOptions, err)
+	}
+	foreground, ok := parseColor(req.Foreground)
+	if !ok {
+		return nil, 0, fmt.Errorf("%w: foreground must be RRGGBB or RRGGBBAA: %s", ErrInvalidOptions, req.Foreground)
+	}
+	background, ok := parseColor(req.Background)
+	if !ok {
+		return nil, 0, fmt.Errorf("%w: background must be RRGGBB or RRGGBBAA: %s", ErrInvalidOptions, req.Background)
+	}
+
+	return &Out{
+		Format: req.Format,
+		Style: qrcode.Style{
+			Size:       req.Size,
+			Margin:     req.Margin,
+			Foreground: foreground,
+			Background: background,
+		},
+	}, level, nil
+}
+
+func parseColor(s string) (color.NRGBA, bool) {
+	if len(s) != 6 && len(s) != 8 {
+		return color.NRGBA{}, false
+	}
+	var values [4]uint8
+	values[3] = 0xff
+	for i := 0; i < len(s); i += 2 {
+		hi, ok := fromHex(s[i])
+		if !ok {
+			return color.NRGBA{}, false
+		}
+		lo, ok := fromHex(s[i+1])
+		if !ok {
+			return color.NRGBA{}, false
+		}
+		values[i/2] = hi<<4 | lo
+	}
+	return color.NRGBA{R: values[0], G: values[1], B: values[2], A: values[3]}, true
+}
+
+func fromHex(c byte) (uint8, bool) {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0', true
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10, true
+	case 'A' <= c && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
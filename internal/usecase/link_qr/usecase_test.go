@@ -0,0 +1,192 @@
+package link_qr
+
+import (
+	"context"
+	"errors"
+	"image/color"
+	"testing"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	"link-shortener-service/internal/model"
+	"link-shortener-service/internal/qrcode"
+	mockstorage "link-shortener-service/internal/usecase/contract/repository/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func defaultIn() In {
+	return In{
+		Principal:  model.Principal{OwnerID: "team-a"},
+		ShortedURL: "abc",
+		Format:     FormatPNG,
+		Size:       DefaultSize,
+		Margin:     DefaultMargin,
+		Level:      DefaultLevel,
+		Foreground: DefaultForeground,
+		Background: DefaultBackground,
+	}
+}
+
+func TestRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pair := &model.URLPair{Original: "https://some.com", Shorted: "abc", OwnerID: "team-a"}
+
+	tests := []struct {
+		name          string
+		modify        func(*In)
+		setupMock     func(*mockstorage.MockURLRepository)
+		expectedStyle qrcode.Style
+		expectedError error
+	}{
+		{
+			name:   "defaults",
+			modify: func(in *In) {},
+			setupMock: func(urls *mockstorage.MockURLRepository) {
+				urls.EXPECT().GetByURL(gomock.Any(), shortURLColumnName, "abc").Return(pair, nil)
+			},
+			expectedStyle: qrcode.Style{
+				Size:       256,
+				Margin:     4,
+				Foreground: color.NRGBA{A: 0xff},
+				Background: color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+			},
+		},
+		{
+			name: "custom colors",
+			modify: func(in *In) {
+				in.Principal = model.Principal{Admin: true}
+				in.Margin = 0
+				in.Foreground = "1A2b3C"
+				in.Background = "ffffff00"
+			},
+			setupMock: func(urls *mockstorage.MockURLRepository) {
+				urls.EXPECT().GetByURL(gomock.Any(), shortURLColumnName, "abc").Return(pair, nil)
+			},
+			expectedStyle: qrcode.Style{
+				Size:       256,
+				Foreground: color.NRGBA{R: 0x1a, G: 0x2b, B: 0x3c, A: 0xff},
+				Background: color.NRGBA{R: 0xff, G: 0xff, B: 0xff},
+			},
+		},
+		{
+			name:          "unknown format",
+			modify:        func(in *In) { in.Format = "gif" },
+			setupMock:     func(urls *mockstorage.MockURLRepository) {},
+			expectedError: ErrInvalidOptions,
+		},
+		{
+			name:          "too small",
+			modify:        func(in *In) { in.Size = MinSize - 1 },
+			setupMock:     func(urls *mockstorage.MockURLRepository) {},
+			expectedError: ErrInvalidOptions,
+		},
+		{
+			name:          "too large",
+			modify:        func(in *In) { in.Size = MaxSize + 1 },
+			setupMock:     func(urls *mockstorage.MockURLRepository) {},
+			expectedError: ErrInvalidOptions,
+		},
+		{
+			name:          "negative margin",
+			modify:        func(in *In) { in.Margin = -1 },
+			setupMock:     func(urls *mockstorage.MockURLRepository) {},
+			expectedError: ErrInvalidOptions,
+		},
+		{
+			name:          "unknown level",
+			modify:        func(in *In) { in.Level = "X" },
+			setupMock:     func(urls *mockstorage.MockURLRepository) {},
+			expectedError: ErrInvalidOptions,
+		},
+		{
+			name:          "invalid color",
+			modify:        func(in *In) { in.Foreground = "#000000" },
+			setupMock:     func(urls *mockstorage.MockURLRepository) {},
+			expectedError: ErrInvalidOptions,
+		},
+		{
+			name:   "unknown link",
+			modify: func(in *In) {},
+			setupMock: func(urls *mockstorage.MockURLRepository) {
+				urls.EXPECT().GetByURL(gomock.Any(), shortURLColumnName, "abc").Return(nil, rep.ErrNotFound)
+			},
+			expectedError: ErrURLNotFound,
+		},
+		{
+			name:   "error getting link",
+			modify: func(in *In) {},
+			setupMock: func(urls *mockstorage.MockURLRepository) {
+				urls.EXPECT().GetByURL(gomock.Any(), shortURLColumnName, "abc").Return(nil, errors.New("storage error"))
+			},
+			expectedError: ErrURLRetrieval,
+		},
+		{
+			name:   "link of another owner",
+			modify: func(in *In) { in.Principal = model.Principal{OwnerID: "team-b"} },
+			setupMock: func(urls *mockstorage.MockURLRepository) {
+				urls.EXPECT().GetByURL(gomock.Any(), shortURLColumnName, "abc").Return(pair, nil)
+			},
+			expectedError: ErrForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			urls := mockstorage.NewMockURLRepository(ctrl)
+			tt.setupMock(urls)
+
+			u := NewUsecase(urls, "https://sho.rt/")
+
+			in := defaultIn()
+			tt.modify(&in)
+			out, err := u.Run(context.Background(), in)
+
+			assert.ErrorIs(t, err, tt.expectedError)
+			if tt.expectedError != nil {
+				assert.Nil(t, out)
+				return
+			}
+			assert.Equal(t, "https://sho.rt/abc", out.ShortURL)
+			assert.Equal(t, qrcode.LevelM, out.Code.Level)
+			assert.Equal(t, FormatPNG, out.Format)
+			assert.Equal(t, tt.expectedStyle, out.Style)
+			assert.Regexp(t, `^"[0-9a-f]{64}"$`, out.ETag)
+		})
+	}
+}
+
+func TestRunETag(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	urls := mockstorage.NewMockURLRepository(ctrl)
+	urls.EXPECT().
+		GetByURL(gomock.Any(), shortURLColumnName, "abc").
+		Return(&model.URLPair{Shorted: "abc", OwnerID: "team-a"}, nil).
+		AnyTimes()
+
+	etag := func(u *usecase, modify func(*In)) string {
+		in := defaultIn()
+		modify(&in)
+		out, err := u.Run(context.Background(), in)
+		require.NoError(t, err)
+		return out.ETag
+	}
+
+	u := NewUsecase(urls, "https://sho.rt/")
+	base := etag(u, func(in *In) {})
+
+	assert.Equal(t, base, etag(u, func(in *In) {}))
+	assert.Equal(t, base, etag(u, func(in *In) { in.Foreground = "000000FF" }), "same color")
+	assert.Equal(t, base, etag(u, func(in *In) { in.Level = "m" }), "same level")
+	assert.NotEqual(t, base, etag(u, func(in *In) { in.Format = FormatSVG }))
+	assert.NotEqual(t, base, etag(u, func(in *In) { in.Size = 512 }))
+	assert.NotEqual(t, base, etag(u, func(in *In) { in.Margin = 2 }))
+	assert.NotEqual(t, base, etag(u, func(in *In) { in.Level = "H" }))
+	assert.NotEqual(t, base, etag(u, func(in *In) { in.Background = "fefefe" }))
+	assert.NotEqual(t, base, etag(NewUsecase(urls, "https://other.rt/"), func(in *In) {}), "short URL")
+}
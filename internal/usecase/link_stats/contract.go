@@ -0,0 +1,26 @@
+package link_stats
+
+import (
+	"time"
+
+	"link-shortener-service/internal/model"
+)
+
+const (
+	GranularityHour = "hour"
+	GranularityDay  = "day"
+	GranularityWeek = "week"
+)
+
+type In struct {
+	Principal  model.Principal
+	ShortedURL string
+	// From and To bound the stats, To is exclusive. Zero To means now, zero From means DefaultRange before To
+	From time.Time
+	To   time.Time
+	// Location is the timezone of day and week buckets, UTC when nil
+	Location    *time.Location
+	Granularity string
+	// Top is the length of the referrers, countries and user agents lists
+	Top int
+}
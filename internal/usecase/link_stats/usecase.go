@@ -0,0 +1,165 @@
+package link_stats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	"link-shortener-service/internal/model"
+	"link-shortener-service/internal/usecase/contract/repository"
+)
+
+const (
+	shortURLColumnName = "shorted_url"
+
+	DefaultRange = 30 * 24 * time.Hour
+	DefaultTop   = 10
+	MaxTop       = 100
+	MaxBuckets   = 1000
+)
+
+var (
+	ErrURLNotFound        = errors.New("URLPair not found")
+	ErrURLRetrieval       = errors.New("failed to retrieve URLPair")
+	ErrForbidden          = errors.New("URLPair belongs to another owner")
+	ErrInvalidRange       = errors.New("invalid stats range")
+	ErrStatsRetrieval     = errors.New("failed to retrieve stats")
+	ErrInvalidGranularity = errors.New("unknown granularity")
+	ErrInvalidTop         = errors.New("invalid top length")
+)
+
+type usecase struct {
+	urls  repository.URLRepository
+	stats repository.StatsRepository
+	now   func() time.Time
+}
+
+func NewUsecase(urls repository.URLRepository, stats repository.StatsRepository) *usecase {
+	return &usecase{
+		urls:  urls,
+		stats: stats,
+		now:   time.Now,
+	}
+}
+
+func (u *usecase) Run(ctx context.Context, req In) (*model.ClickStats, error) {
+	req, err := u.withDefaults(req)
+	if err != nil {
+		return nil, err
+	}
+
+	pair, err := u.urls.GetByURL(ctx, shortURLColumnName, req.ShortedURL)
+	if err != nil {
+		if errors.Is(err, rep.ErrNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrURLNotFound, req.ShortedURL)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrURLRetrieval, err)
+	}
+	if !req.Principal.CanManage(pair) {
+		return nil, fmt.Errorf("%w: %s", ErrForbidden, req.ShortedURL)
+	}
+
+	starts, err := bucketStarts(req)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := u.stats.GetClickStats(ctx, req.ShortedURL, starts[0], req.To, req.Top)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrStatsRetrieval, err)
+	}
+	stats.From, stats.To = starts[0], req.To
+	stats.Buckets = regroup(stats.Buckets, starts)
+
+	return stats, nil
+}
+
+func (u *usecase) withDefaults(req In) (In, error) {
+	if req.Location == nil {
+		req.Location = time.UTC
+	}
+	if req.Granularity == "" {
+		req.Granularity = GranularityDay
+	}
+	if req.To.IsZero() {
+		req.To = u.now()
+	}
+	if req.From.IsZero() {
+		req.From = req.To.Add(-DefaultRange)
+	}
+	if req.Top == 0 {
+		req.Top = DefaultTop
+	}
+
+	switch {
+	case req.Granularity != GranularityHour && req.Granularity != GranularityDay && req.Granularity != GranularityWeek:
+		return req, fmt.Errorf("%w: %s", ErrInvalidGranularity, req.Granularity)
+	case !req.From.Before(req.To):
+		return req, fmt.Errorf("%w: from must be before to", ErrInvalidRange)
+	case req.Top < 0 || req.Top > MaxTop:
+		return req, fmt.Errorf("%w: must be between 1 and %d", ErrInvalidTop, MaxTop)
+	}
+	return req, nil
+}
+
+// bucketStarts lists the starts of buckets covering the range, the first one is aligned
+// to the start of the hour, day or week in the requested timezone
+func bucketStarts(req In) ([]time.Time, error) {
+	var starts []time.Time
+	for start := truncate(req.From, req.Granularity, req.Location); start.Before(req.To); start = next(start, req.Granularity) {
+		if len(starts) == MaxBuckets {
+			return nil, fmt.Errorf("%w: more than %d buckets, use a shorter range or a coarser granularity",
+				ErrInvalidRange, MaxBuckets)
+		}
+		starts = append(starts, start)
+	}
+	return starts, nil
+}
+
+func truncate(t time.Time, granularity string, loc *time.Location) time.Time {
+	t = t.In(loc)
+	switch granularity {
+	case GranularityHour:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, loc)
+	case GranularityWeek:
+		// weeks start on Monday
+		daysSinceMonday := (int(t.Weekday()) + 6) % 7
+		return time.Date(t.Year(), t.Month(), t.Day()-daysSinceMonday, 0, 0, 0, 0, loc)
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	}
+}
+
+func next(start time.Time, granularity string) time.Time {
+	switch granularity {
+	case GranularityHour:
+		return start.Add(time.Hour)
+	case GranularityWeek:
+		return start.AddDate(0, 0, 7)
+	default:
+		// days are added to the calendar date, so days around DST changes stay aligned
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// regroup sums hourly buckets into buckets starting at starts, empty buckets are kept with zero clicks.
+// Hourly buckets are UTC hours, so with timezones offset by a fraction of an hour
+// a bucket gets the clicks of the hour it starts in
+func regroup(hourly []model.ClickBucket, starts []time.Time) []model.ClickBucket {
+	result := make([]model.ClickBucket, len(starts))
+	for i, start := range starts {
+		result[i].Start = start
+	}
+	for _, bucket := range hourly {
+		i := sort.Search(len(starts), func(i int) bool {
+			return starts[i].After(bucket.Start)
+		}) - 1
+		if i >= 0 {
+			result[i].Clicks += bucket.Clicks
+		}
+	}
+	return result
+}
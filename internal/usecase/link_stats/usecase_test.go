@@ -0,0 +1,193 @@
+package link_stats
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	"link-shortener-service/internal/model"
+	mockstorage "link-shortener-service/internal/usecase/contract/repository/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinkStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	require.NoError(t, err)
+
+	// Wednesday
+	from := time.Date(2025, 5, 7, 10, 30, 0, 0, moscow)
+	to := time.Date(2025, 5, 9, 0, 0, 0, 0, moscow)
+	hourly := []model.ClickBucket{
+		// 2025-05-07 23:00 in Moscow
+		{Start: time.Date(2025, 5, 7, 20, 0, 0, 0, time.UTC), Clicks: 3},
+		// 2025-05-08 00:00 in Moscow
+		{Start: time.Date(2025, 5, 7, 21, 0, 0, 0, time.UTC), Clicks: 4},
+		{Start: time.Date(2025, 5, 8, 12, 0, 0, 0, time.UTC), Clicks: 5},
+	}
+	stored := &model.ClickStats{
+		Total:          12,
+		UniqueVisitors: 7,
+		Buckets:        hourly,
+		Referrers:      []model.Counter{{Value: "ref.com", Clicks: 10}},
+	}
+
+	owner := model.Principal{OwnerID: "team-a"}
+
+	tests := []struct {
+		name          string
+		req           In
+		setupMock     func(*mockstorage.MockURLRepository, *mockstorage.MockStatsRepository)
+		expected      *model.ClickStats
+		expectedError error
+	}{
+		{
+			name: "daily buckets in timezone",
+			req:  In{Principal: owner, ShortedURL: "xHsvC_0NTU", From: from, To: to, Location: moscow},
+			setupMock: func(urls *mockstorage.MockURLRepository, stats *mockstorage.MockStatsRepository) {
+				urls.EXPECT().
+					GetByURL(gomock.Any(), shortURLColumnName, "xHsvC_0NTU").
+					Return(&model.URLPair{Shorted: "xHsvC_0NTU", OwnerID: "team-a"}, nil)
+				stats.EXPECT().
+					GetClickStats(gomock.Any(), "xHsvC_0NTU", time.Date(2025, 5, 7, 0, 0, 0, 0, moscow), to, DefaultTop).
+					Return(&model.ClickStats{
+						Total:          stored.Total,
+						UniqueVisitors: stored.UniqueVisitors,
+						Buckets:        hourly,
+						Referrers:      stored.Referrers,
+					}, nil)
+			},
+			expected: &model.ClickStats{
+				From:           time.Date(2025, 5, 7, 0, 0, 0, 0, moscow),
+				To:             to,
+				Total:          12,
+				UniqueVisitors: 7,
+				Buckets: []model.ClickBucket{
+					{Start: time.Date(2025, 5, 7, 0, 0, 0, 0, moscow), Clicks: 3},
+					{Start: time.Date(2025, 5, 8, 0, 0, 0, 0, moscow), Clicks: 9},
+				},
+				Referrers: stored.Referrers,
+			},
+		},
+		{
+			name: "weekly buckets start on Monday",
+			req:  In{Principal: owner, ShortedURL: "xHsvC_0NTU", From: from, To: to, Location: moscow, Granularity: GranularityWeek, Top: 3},
+			setupMock: func(urls *mockstorage.MockURLRepository, stats *mockstorage.MockStatsRepository) {
+				urls.EXPECT().
+					GetByURL(gomock.Any(), shortURLColumnName, "xHsvC_0NTU").
+					Return(&model.URLPair{Shorted: "xHsvC_0NTU", OwnerID: "team-a"}, nil)
+				stats.EXPECT().
+					GetClickStats(gomock.Any(), "xHsvC_0NTU", time.Date(2025, 5, 5, 0, 0, 0, 0, moscow), to, 3).
+					Return(&model.ClickStats{Total: 12, Buckets: hourly}, nil)
+			},
+			expected: &model.ClickStats{
+				From:  time.Date(2025, 5, 5, 0, 0, 0, 0, moscow),
+				To:    to,
+				Total: 12,
+				Buckets: []model.ClickBucket{
+					{Start: time.Date(2025, 5, 5, 0, 0, 0, 0, moscow), Clicks: 12},
+				},
+			},
+		},
+		{
+			name: "unknown short URL",
+			req:  In{Principal: owner, ShortedURL: "xHsvC_0NTU", From: from, To: to},
+			setupMock: func(urls *mockstorage.MockURLRepository, stats *mockstorage.MockStatsRepository) {
+				urls.EXPECT().
+					GetByURL(gomock.Any(), shortURLColumnName, "xHsvC_0NTU").
+					Return(nil, rep.ErrNotFound)
+			},
+			expectedError: ErrURLNotFound,
+		},
+		{
+			name:          "range is reversed",
+			req:           In{Principal: owner, ShortedURL: "xHsvC_0NTU", From: to, To: from},
+			setupMock:     func(*mockstorage.MockURLRepository, *mockstorage.MockStatsRepository) {},
+			expectedError: ErrInvalidRange,
+		},
+		{
+			name: "too many buckets",
+			req:  In{Principal: owner, ShortedURL: "xHsvC_0NTU", From: to.AddDate(-1, 0, 0), To: to, Granularity: GranularityHour},
+			setupMock: func(urls *mockstorage.MockURLRepository, stats *mockstorage.MockStatsRepository) {
+				urls.EXPECT().
+					GetByURL(gomock.Any(), shortURLColumnName, "xHsvC_0NTU").
+					Return(&model.URLPair{Shorted: "xHsvC_0NTU", OwnerID: "team-a"}, nil)
+			},
+			expectedError: ErrInvalidRange,
+		},
+		{
+			name:          "unknown granularity",
+			req:           In{Principal: owner, ShortedURL: "xHsvC_0NTU", Granularity: "month"},
+			setupMock:     func(*mockstorage.MockURLRepository, *mockstorage.MockStatsRepository) {},
+			expectedError: ErrInvalidGranularity,
+		},
+		{
+			name:          "top is too long",
+			req:           In{Principal: owner, ShortedURL: "xHsvC_0NTU", Top: MaxTop + 1},
+			setupMock:     func(*mockstorage.MockURLRepository, *mockstorage.MockStatsRepository) {},
+			expectedError: ErrInvalidTop,
+		},
+		{
+			name: "error from stats storage",
+			req:  In{Principal: owner, ShortedURL: "xHsvC_0NTU", From: from, To: to},
+			setupMock: func(urls *mockstorage.MockURLRepository, stats *mockstorage.MockStatsRepository) {
+				urls.EXPECT().
+					GetByURL(gomock.Any(), shortURLColumnName, "xHsvC_0NTU").
+					Return(&model.URLPair{Shorted: "xHsvC_0NTU", OwnerID: "team-a"}, nil)
+				stats.EXPECT().
+					GetClickStats(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil, errors.New("db is down"))
+			},
+			expectedError: ErrStatsRetrieval,
+		},
+		{
+			name: "link of another owner",
+			req:  In{Principal: model.Principal{OwnerID: "team-b"}, ShortedURL: "xHsvC_0NTU", From: from, To: to},
+			setupMock: func(urls *mockstorage.MockURLRepository, stats *mockstorage.MockStatsRepository) {
+				urls.EXPECT().
+					GetByURL(gomock.Any(), shortURLColumnName, "xHsvC_0NTU").
+					Return(&model.URLPair{Shorted: "xHsvC_0NTU", OwnerID: "team-a"}, nil)
+			},
+			expectedError: ErrForbidden,
+		},
+		{
+			name: "admin sees stats of any link",
+			req:  In{Principal: model.Principal{Admin: true}, ShortedURL: "xHsvC_0NTU", From: from, To: to},
+			setupMock: func(urls *mockstorage.MockURLRepository, stats *mockstorage.MockStatsRepository) {
+				urls.EXPECT().
+					GetByURL(gomock.Any(), shortURLColumnName, "xHsvC_0NTU").
+					Return(&model.URLPair{Shorted: "xHsvC_0NTU"}, nil)
+				stats.EXPECT().
+					GetClickStats(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil, errors.New("db is down"))
+			},
+			expectedError: ErrStatsRetrieval,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			urls := mockstorage.NewMockURLRepository(ctrl)
+			stats := mockstorage.NewMockStatsRepository(ctrl)
+			tt.setupMock(urls, stats)
+
+			u := NewUsecase(urls, stats)
+			result, err := u.Run(context.Background(), tt.req)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, result)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
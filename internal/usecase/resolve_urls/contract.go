@@ -0,0 +1,21 @@
+package resolve_urls
+
+import (
+	"link-shortener-service/internal/model"
+)
+
+type In struct {
+	ShortedURLs []string
+	// Host is the host the request came to, codes given without a domain are looked up on its domain
+	Host string
+}
+
+// Result is the resolution of one requested short URL, Err is set when the link can't be followed
+type Result struct {
+	ShortedURL string
+	// Domain is the custom short domain of the link, empty for the default domain
+	Domain  string
+	Code    string
+	URLPair *model.URLPair
+	Err     error
+}
@@ -0,0 +1,127 @@
+package resolve_urls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"link-shortener-service/internal/model"
+	"link-shortener-service/internal/usecase/contract/repository"
+	"link-shortener-service/internal/usecase/contract/service"
+	"link-shortener-service/internal/usecase/route_domain"
+)
+
+var (
+	ErrURLNotFound      = errors.New("URLPair not found")
+	ErrURLRetrieval     = errors.New("failed to retrieve URLPairs")
+	ErrURLExpired       = errors.New("URLPair expired")
+	ErrURLExhausted     = errors.New("URLPair has no clicks left")
+	ErrURLDisabled      = errors.New("URLPair disabled")
+	ErrURLBlocked       = errors.New("URLPair destination is blocklisted")
+	ErrPasswordRequired = errors.New("password required")
+
+	leftURLPart = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*://[^/]+/`)
+)
+
+type usecase struct {
+	repo     repository.URLRepository
+	domains  service.DomainRouter
+	screener service.Screener
+	// caseInsensitive resolves codes in lowercase, codes are stored in lowercase then
+	caseInsensitive bool
+	now             func() time.Time
+}
+
+func NewUsecase(repo repository.URLRepository, domains service.DomainRouter, screener service.Screener, caseInsensitive bool) *usecase {
+	return &usecase{
+		repo:            repo,
+		domains:         domains,
+		screener:        screener,
+		caseInsensitive: caseInsensitive,
+		now:             time.Now,
+	}
+}
+
+// Run resolves the short URLs with a single storage call, the results are aligned with the requests.
+// Resolving doesn't count as a click, and the original URLs of password protected links stay hidden
+func (u *usecase) Run(ctx context.Context, req In) ([]Result, error) {
+	results := make([]Result, len(req.ShortedURLs))
+	seen := make(map[string]struct{}, len(req.ShortedURLs))
+	keys := make([]string, 0, len(req.ShortedURLs))
+	// hosts are routed to their domains once per request
+	domains := make(map[string]string)
+	for i, shorted := range req.ShortedURLs {
+		code, host := shorted, req.Host
+		if prefix := leftURLPart.FindString(shorted); prefix != "" {
+			if parsed, err := url.Parse(prefix); err == nil {
+				host = parsed.Host
+			}
+			code = shorted[len(prefix):]
+		}
+		if u.caseInsensitive {
+			code = strings.ToLower(code)
+		}
+
+		domain, routed := domains[host]
+		if !routed {
+			var err error
+			if domain, err = u.domains.Run(ctx, route_domain.In{Host: host}); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrURLRetrieval, err)
+			}
+			domains[host] = domain
+		}
+
+		results[i] = Result{ShortedURL: shorted, Domain: domain, Code: code}
+		key := model.LinkKey(domain, code)
+		if _, exists := seen[key]; !exists {
+			seen[key] = struct{}{}
+			keys = append(keys, key)
+		}
+	}
+
+	pairs, err := u.repo.GetByShortedURLs(ctx, keys)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrURLRetrieval, err)
+	}
+	byKey := make(map[string]model.URLPair, len(pairs))
+	for _, pair := range pairs {
+		byKey[pair.Shorted] = pair
+	}
+
+	now := u.now()
+	for i := range results {
+		pair, exists := byKey[model.LinkKey(results[i].Domain, results[i].Code)]
+		if !exists {
+			results[i].Err = fmt.Errorf("%w: %s", ErrURLNotFound, results[i].ShortedURL)
+			continue
+		}
+		if results[i].Err = u.check(pair, now); results[i].Err != nil {
+			continue
+		}
+		results[i].URLPair = &pair
+	}
+	return results, nil
+}
+
+func (u *usecase) check(pair model.URLPair, now time.Time) error {
+	switch {
+	case pair.Disabled:
+		return fmt.Errorf("%w: %s", ErrURLDisabled, pair.Shorted)
+	case pair.IsExpired(now):
+		return fmt.Errorf("%w: %s", ErrURLExpired, pair.Shorted)
+	case pair.IsExhausted():
+		return fmt.Errorf("%w: %s", ErrURLExhausted, pair.Shorted)
+	case pair.IsProtected():
+		return fmt.Errorf("%w: %s", ErrPasswordRequired, pair.Shorted)
+	}
+	if u.screener != nil {
+		if err := u.screener.Check(pair.Original); err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrURLBlocked, pair.Shorted, err)
+		}
+	}
+	return nil
+}
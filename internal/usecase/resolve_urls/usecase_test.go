@@ -0,0 +1,125 @@
+package resolve_urls
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"link-shortener-service/internal/model"
+	mockstorage "link-shortener-service/internal/usecase/contract/repository/mocks"
+	"link-shortener-service/internal/usecase/contract/service/servicetest"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveURLs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	mockRepo := mockstorage.NewMockURLRepository(ctrl)
+	mockRepo.EXPECT().
+		GetByShortedURLs(gomock.Any(), []string{"active", "unknown", "disabled", "expired", "exhausted", "protected"}).
+		Return([]model.URLPair{
+			{Original: "https://some.com/1", Shorted: "active"},
+			{Original: "https://some.com/2", Shorted: "disabled", Disabled: true},
+			{Original: "https://some.com/3", Shorted: "expired", ExpiresAt: now.Add(-time.Minute)},
+			{Original: "https://some.com/4", Shorted: "exhausted", MaxClicks: 1, ClicksUsed: 1},
+			{Original: "https://some.com/5", Shorted: "protected", PasswordHash: "hash"},
+		}, nil)
+
+	u := NewUsecase(mockRepo, servicetest.Domains{}, nil, false)
+	u.now = func() time.Time { return now }
+
+	results, err := u.Run(context.Background(), In{ShortedURLs: []string{
+		"https://some.com/active",
+		"unknown",
+		"disabled",
+		"expired",
+		"exhausted",
+		"protected",
+		"active",
+	}})
+	require.NoError(t, err)
+	require.Len(t, results, 7)
+
+	assert.Equal(t, Result{
+		ShortedURL: "https://some.com/active",
+		Code:       "active",
+		URLPair:    &model.URLPair{Original: "https://some.com/1", Shorted: "active"},
+	}, results[0])
+	assert.ErrorIs(t, results[1].Err, ErrURLNotFound)
+	assert.ErrorIs(t, results[2].Err, ErrURLDisabled)
+	assert.ErrorIs(t, results[3].Err, ErrURLExpired)
+	assert.ErrorIs(t, results[4].Err, ErrURLExhausted)
+	assert.ErrorIs(t, results[5].Err, ErrPasswordRequired)
+	assert.Nil(t, results[5].URLPair)
+	// repeated short URLs are looked up once but get their own result
+	assert.Equal(t, "active", results[6].Code)
+	assert.NotNil(t, results[6].URLPair)
+}
+
+func TestResolveURLsStorageError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mockstorage.NewMockURLRepository(ctrl)
+	mockRepo.EXPECT().
+		GetByShortedURLs(gomock.Any(), []string{"xHsvC_0NTU"}).
+		Return(nil, errors.New("db is down"))
+
+	results, err := NewUsecase(mockRepo, servicetest.Domains{}, nil, false).Run(context.Background(), In{ShortedURLs: []string{"xHsvC_0NTU"}})
+
+	assert.ErrorIs(t, err, ErrURLRetrieval)
+	assert.Nil(t, results)
+}
+
+func TestResolveURLsOnCustomDomains(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mockstorage.NewMockURLRepository(ctrl)
+	mockRepo.EXPECT().
+		GetByShortedURLs(gomock.Any(), []string{"go.acme.io/sale", "sale"}).
+		Return([]model.URLPair{
+			{Original: "https://acme.io/sale", Shorted: "go.acme.io/sale"},
+			{Original: "https://some.com/sale", Shorted: "sale"},
+		}, nil)
+
+	u := NewUsecase(mockRepo, servicetest.Domains{"go.acme.io": "go.acme.io"}, nil, false)
+	results, err := u.Run(context.Background(), In{
+		ShortedURLs: []string{"https://go.acme.io/sale", "https://some.com/sale", "sale"},
+		Host:        "go.acme.io",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://acme.io/sale", results[0].URLPair.Original)
+	assert.Equal(t, "go.acme.io", results[0].Domain)
+	assert.Equal(t, "https://some.com/sale", results[1].URLPair.Original)
+	// codes alone are looked up on the domain of the request
+	assert.Equal(t, "https://acme.io/sale", results[2].URLPair.Original)
+}
+
+func TestResolveURLsBlockedDestination(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mockstorage.NewMockURLRepository(ctrl)
+	mockRepo.EXPECT().
+		GetByShortedURLs(gomock.Any(), []string{"good", "phishing"}).
+		Return([]model.URLPair{
+			{Original: "https://some.com/1", Shorted: "good"},
+			{Original: "https://evil.com/login", Shorted: "phishing"},
+		}, nil)
+
+	u := NewUsecase(mockRepo, servicetest.Domains{}, servicetest.BlockedHosts{"evil.com"}, false)
+	results, err := u.Run(context.Background(), In{ShortedURLs: []string{"good", "phishing"}})
+	require.NoError(t, err)
+
+	assert.NoError(t, results[0].Err)
+	assert.ErrorIs(t, results[1].Err, ErrURLBlocked)
+	assert.Nil(t, results[1].URLPair)
+}
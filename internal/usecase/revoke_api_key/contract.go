@@ -0,0 +1,5 @@
+package revoke_api_key
+
+type In struct {
+	ID string
+}
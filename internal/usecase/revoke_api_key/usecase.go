@@ -0,0 +1,38 @@
+package revoke_api_key
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	"link-shortener-service/internal/usecase/contract/repository"
+)
+
+var (
+	ErrKeyNotFound = errors.New("API key not found")
+	ErrKeyRevoke   = errors.New("failed to revoke API key")
+)
+
+type usecase struct {
+	repo repository.APIKeyRepository
+	now  func() time.Time
+}
+
+func NewUsecase(repo repository.APIKeyRepository) *usecase {
+	return &usecase{
+		repo: repo,
+		now:  time.Now,
+	}
+}
+
+func (u *usecase) Run(ctx context.Context, req In) error {
+	if err := u.repo.RevokeAPIKey(ctx, req.ID, u.now().UTC()); err != nil {
+		if errors.Is(err, rep.ErrNotFound) {
+			return fmt.Errorf("%w: %s", ErrKeyNotFound, req.ID)
+		}
+		return fmt.Errorf("%w: %v", ErrKeyRevoke, err)
+	}
+	return nil
+}
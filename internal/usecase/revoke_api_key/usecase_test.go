@@ -0,0 +1,49 @@
+package revoke_api_key
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	mockstorage "link-shortener-service/internal/usecase/contract/repository/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRevokeAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name          string
+		repoErr       error
+		expectedError error
+	}{
+		{
+			name: "key is revoked",
+		},
+		{
+			name:          "unknown key",
+			repoErr:       rep.ErrNotFound,
+			expectedError: ErrKeyNotFound,
+		},
+		{
+			name:          "error from storage",
+			repoErr:       errors.New("db is down"),
+			expectedError: ErrKeyRevoke,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mockstorage.NewMockAPIKeyRepository(ctrl)
+			mockRepo.EXPECT().RevokeAPIKey(gomock.Any(), "k1", gomock.Any()).Return(tt.repoErr)
+
+			err := NewUsecase(mockRepo).Run(context.Background(), In{ID: "k1"})
+
+			assert.ErrorIs(t, err, tt.expectedError)
+		})
+	}
+}
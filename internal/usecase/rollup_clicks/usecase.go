@@ -0,0 +1,47 @@
+package rollup_clicks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"link-shortener-service/internal/usecase/contract/repository"
+)
+
+const (
+	// settleDelay leaves recent clicks for the next run, so clicks still being written
+	// by other instances are not passed by the rollup watermark
+	settleDelay = 30 * time.Second
+	batchSize   = 10000
+)
+
+var (
+	ErrRollup = errors.New("failed to roll up clicks")
+)
+
+type usecase struct {
+	repo repository.StatsRepository
+	now  func() time.Time
+}
+
+func NewUsecase(repo repository.StatsRepository) *usecase {
+	return &usecase{
+		repo: repo,
+		now:  time.Now,
+	}
+}
+
+func (u *usecase) Run(ctx context.Context) error {
+	until := u.now().Add(-settleDelay)
+	for ctx.Err() == nil {
+		rolledUp, err := u.repo.RollupClicks(ctx, until, batchSize)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrRollup, err)
+		}
+		if rolledUp < batchSize {
+			return nil
+		}
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,71 @@
+package rollup_clicks
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	mockstorage "link-shortener-service/internal/usecase/contract/repository/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Now()
+	until := now.Add(-settleDelay)
+
+	tests := []struct {
+		name          string
+		setupMock     func(*mockstorage.MockStatsRepository)
+		expectedError error
+	}{
+		{
+			name: "single batch",
+			setupMock: func(mockRepo *mockstorage.MockStatsRepository) {
+				mockRepo.EXPECT().
+					RollupClicks(gomock.Any(), until, batchSize).
+					Return(int64(42), nil)
+			},
+		},
+		{
+			name: "full batches are followed by the next one",
+			setupMock: func(mockRepo *mockstorage.MockStatsRepository) {
+				gomock.InOrder(
+					mockRepo.EXPECT().
+						RollupClicks(gomock.Any(), until, batchSize).
+						Return(int64(batchSize), nil).
+						Times(2),
+					mockRepo.EXPECT().
+						RollupClicks(gomock.Any(), until, batchSize).
+						Return(int64(0), nil),
+				)
+			},
+		},
+		{
+			name: "storage error",
+			setupMock: func(mockRepo *mockstorage.MockStatsRepository) {
+				mockRepo.EXPECT().
+					RollupClicks(gomock.Any(), until, batchSize).
+					Return(int64(0), errors.New("db is down"))
+			},
+			expectedError: ErrRollup,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mockstorage.NewMockStatsRepository(ctrl)
+			tt.setupMock(mockRepo)
+
+			u := NewUsecase(mockRepo)
+			u.now = func() time.Time { return now }
+
+			assert.ErrorIs(t, u.Run(context.Background()), tt.expectedError)
+		})
+	}
+}
@@ -0,0 +1,6 @@
+package route_domain
+
+type In struct {
+	// Host is the host of the request or of the short URL, the port is ignored
+	Host string
+}
@@ -0,0 +1,102 @@
+package route_domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	"link-shortener-service/internal/model"
+	"link-shortener-service/internal/usecase/contract/repository"
+)
+
+// maxCachedHosts bounds the cache, requests may carry any Host header
+const maxCachedHosts = 10_000
+
+var ErrDomainRetrieval = errors.New("failed to retrieve short domain")
+
+// cachedDomain is the routed domain of a host, empty for the default domain
+type cachedDomain struct {
+	domain string
+	until  time.Time
+}
+
+type usecase struct {
+	repo        repository.WorkspaceRepository
+	defaultHost string
+	// cacheTTL is how long routed hosts are remembered, unknown hosts included, zero disables the cache
+	cacheTTL time.Duration
+	mu       sync.Mutex
+	cache    map[string]cachedDomain
+	now      func() time.Time
+}
+
+func NewUsecase(repo repository.WorkspaceRepository, defaultHost string, cacheTTL time.Duration) *usecase {
+	return &usecase{
+		repo:        repo,
+		defaultHost: model.NormalizeHost(defaultHost),
+		cacheTTL:    cacheTTL,
+		cache:       make(map[string]cachedDomain),
+		now:         time.Now,
+	}
+}
+
+// Run returns the custom short domain the host belongs to, or an empty domain for the default one.
+// Hosts that are no workspace domain, like the service address itself, serve the default domain
+func (u *usecase) Run(ctx context.Context, req In) (string, error) {
+	host := model.NormalizeHost(req.Host)
+	if host == "" || host == u.defaultHost {
+		return "", nil
+	}
+	if domain, ok := u.cached(host); ok {
+		return domain, nil
+	}
+
+	domain := host
+	if _, err := u.repo.GetDomainWorkspace(ctx, host); err != nil {
+		if !errors.Is(err, rep.ErrNotFound) {
+			return "", fmt.Errorf("%w: %v", ErrDomainRetrieval, err)
+		}
+		domain = ""
+	}
+	u.remember(host, domain)
+	return domain, nil
+}
+
+func (u *usecase) cached(host string) (string, bool) {
+	if u.cacheTTL <= 0 {
+		return "", false
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	entry, ok := u.cache[host]
+	if !ok || !u.now().Before(entry.until) {
+		return "", false
+	}
+	return entry.domain, true
+}
+
+func (u *usecase) remember(host, domain string) {
+	if u.cacheTTL <= 0 {
+		return
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	now := u.now()
+	if len(u.cache) >= maxCachedHosts {
+		for cachedHost, entry := range u.cache {
+			if !now.Before(entry.until) {
+				delete(u.cache, cachedHost)
+			}
+		}
+		// still full of live entries, they are cheap to look up again
+		if len(u.cache) >= maxCachedHosts {
+			clear(u.cache)
+		}
+	}
+	u.cache[host] = cachedDomain{domain: domain, until: now.Add(u.cacheTTL)}
+}
@@ -0,0 +1,117 @@
+package route_domain
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	mockstorage "link-shortener-service/internal/usecase/contract/repository/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouteDomain(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name          string
+		host          string
+		setupMock     func(*mockstorage.MockWorkspaceRepository)
+		expected      string
+		expectedError error
+	}{
+		{
+			name:      "default host",
+			host:      "Some.com:443",
+			setupMock: func(*mockstorage.MockWorkspaceRepository) {},
+		},
+		{
+			name:      "no host",
+			setupMock: func(*mockstorage.MockWorkspaceRepository) {},
+		},
+		{
+			name: "custom domain",
+			host: "GO.acme.io:8080",
+			setupMock: func(mockRepo *mockstorage.MockWorkspaceRepository) {
+				mockRepo.EXPECT().GetDomainWorkspace(gomock.Any(), "go.acme.io").Return("acme", nil)
+			},
+			expected: "go.acme.io",
+		},
+		{
+			name: "unknown host serves the default domain",
+			host: "localhost:8080",
+			setupMock: func(mockRepo *mockstorage.MockWorkspaceRepository) {
+				mockRepo.EXPECT().GetDomainWorkspace(gomock.Any(), "localhost").Return("", rep.ErrNotFound)
+			},
+		},
+		{
+			name: "error from storage",
+			host: "go.acme.io",
+			setupMock: func(mockRepo *mockstorage.MockWorkspaceRepository) {
+				mockRepo.EXPECT().GetDomainWorkspace(gomock.Any(), "go.acme.io").Return("", errors.New("db is down"))
+			},
+			expectedError: ErrDomainRetrieval,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mockstorage.NewMockWorkspaceRepository(ctrl)
+			tt.setupMock(mockRepo)
+
+			domain, err := NewUsecase(mockRepo, "some.com", 0).Run(context.Background(), In{Host: tt.host})
+
+			assert.ErrorIs(t, err, tt.expectedError)
+			assert.Equal(t, tt.expected, domain)
+		})
+	}
+}
+
+func TestRouteDomainCache(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 5, 7, 10, 0, 0, 0, time.UTC)
+	mockRepo := mockstorage.NewMockWorkspaceRepository(ctrl)
+	gomock.InOrder(
+		mockRepo.EXPECT().GetDomainWorkspace(gomock.Any(), "go.acme.io").Return("acme", nil),
+		mockRepo.EXPECT().GetDomainWorkspace(gomock.Any(), "localhost").Return("", rep.ErrNotFound),
+		// errors are not cached
+		mockRepo.EXPECT().GetDomainWorkspace(gomock.Any(), "go.other.io").Return("", errors.New("db is down")),
+		mockRepo.EXPECT().GetDomainWorkspace(gomock.Any(), "go.other.io").Return("other", nil),
+		// the entry expires after the TTL
+		mockRepo.EXPECT().GetDomainWorkspace(gomock.Any(), "go.acme.io").Return("acme", nil),
+	)
+
+	u := NewUsecase(mockRepo, "some.com", time.Minute)
+	u.now = func() time.Time { return now }
+	route := func(host string) (string, error) {
+		return u.Run(context.Background(), In{Host: host})
+	}
+
+	for range 2 {
+		domain, err := route("go.acme.io")
+		require.NoError(t, err)
+		assert.Equal(t, "go.acme.io", domain)
+
+		domain, err = route("localhost:8080")
+		require.NoError(t, err)
+		assert.Empty(t, domain)
+	}
+
+	_, err := route("go.other.io")
+	assert.ErrorIs(t, err, ErrDomainRetrieval)
+	domain, err := route("go.other.io")
+	require.NoError(t, err)
+	assert.Equal(t, "go.other.io", domain)
+
+	now = now.Add(time.Minute)
+	domain, err = route("go.acme.io")
+	require.NoError(t, err)
+	assert.Equal(t, "go.acme.io", domain)
+}
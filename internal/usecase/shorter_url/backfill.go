@@ -0,0 +1,59 @@
+package shorter_url
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	"link-shortener-service/internal/model"
+)
+
+// backfillBatchSize is the number of deduplicated links read at once
+const backfillBatchSize = 1000
+
+var ErrBackfillDedupKeys = errors.New("failed to backfill dedup keys")
+
+// BackfillDedupKeys gives the deduplicated links the dedup key of the current canonical policy. Links stored
+// before canonical URLs and workspaces kept the original URL as their key and never matched new requests.
+// A link keeps its key when another link has the new one already
+func (u *usecase) BackfillDedupKeys(ctx context.Context) error {
+	var updated, kept int
+	for after := ""; ; {
+		pairs, err := u.repo.GetDeduplicated(ctx, after, backfillBatchSize)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrBackfillDedupKeys, err)
+		}
+
+		for _, pair := range pairs {
+			domain, _ := model.SplitLinkKey(pair.Shorted)
+			key := dedupKey(pair.OwnerID, domain, u.canonical.canonicalURL(pair.Original))
+			if key == pair.DedupKey {
+				continue
+			}
+
+			err = u.repo.SetDedupKey(ctx, pair.Shorted, key)
+			switch {
+			case err == nil:
+				updated++
+			case errors.Is(err, rep.ErrOriginalURLExist):
+				kept++
+			case errors.Is(err, rep.ErrNotFound):
+				// deleted in the meantime
+			default:
+				return fmt.Errorf("%w: %v", ErrBackfillDedupKeys, err)
+			}
+		}
+
+		if len(pairs) < backfillBatchSize {
+			break
+		}
+		after = pairs[len(pairs)-1].Shorted
+	}
+
+	if updated > 0 || kept > 0 {
+		log.Printf("updated %d dedup keys, %d links kept theirs as other links have the same canonical URL", updated, kept)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package shorter_url
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	"link-shortener-service/internal/model"
+	mockstorage "link-shortener-service/internal/usecase/contract/repository/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackfillDedupKeys(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// a full page is followed by the next one
+	page := make([]model.URLPair, backfillBatchSize)
+	for i := range page {
+		url := fmt.Sprintf("https://some.com/%04d", i)
+		page[i] = model.URLPair{Original: url, Shorted: fmt.Sprintf("code%04d", i), DedupKey: url}
+	}
+	last := []model.URLPair{
+		{Original: "HTTPS://Some.com:443/a", Shorted: "old", DedupKey: "HTTPS://Some.com:443/a"},
+		{Original: "HTTPS://Some.com/b", Shorted: "taken", DedupKey: "HTTPS://Some.com/b"},
+		{Original: "https://some.com/c", Shorted: "team", DedupKey: "https://some.com/c", OwnerID: "team-a"},
+		{Original: "https://some.com/d", Shorted: "go.example.com/gone", DedupKey: "https://some.com/d"},
+	}
+
+	mockRepo := mockstorage.NewMockURLRepository(ctrl)
+	gomock.InOrder(
+		mockRepo.EXPECT().GetDeduplicated(gomock.Any(), "", backfillBatchSize).Return(page, nil),
+		mockRepo.EXPECT().GetDeduplicated(gomock.Any(), "code0999", backfillBatchSize).Return(last, nil),
+	)
+	mockRepo.EXPECT().SetDedupKey(gomock.Any(), "old", "https://some.com/a").Return(nil)
+	mockRepo.EXPECT().SetDedupKey(gomock.Any(), "taken", "https://some.com/b").Return(rep.ErrOriginalURLExist)
+	mockRepo.EXPECT().SetDedupKey(gomock.Any(), "team", "team-a|https://some.com/c").Return(nil)
+	mockRepo.EXPECT().SetDedupKey(gomock.Any(), "go.example.com/gone", "go.example.com|https://some.com/d").Return(rep.ErrNotFound)
+
+	u := NewUsecase(mockRepo, nil, &codeStub{}, nil, nil, "https://some.com/", 5, AliasPolicy{}, CanonicalPolicy{})
+	assert.NoError(t, u.BackfillDedupKeys(context.Background()))
+}
+
+func TestBackfillDedupKeysErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mockstorage.NewMockURLRepository(ctrl)
+	mockRepo.EXPECT().
+		GetDeduplicated(gomock.Any(), "", backfillBatchSize).
+		Return([]model.URLPair{{Original: "HTTPS://Some.com/", Shorted: "old", DedupKey: "HTTPS://Some.com/"}}, nil)
+	mockRepo.EXPECT().SetDedupKey(gomock.Any(), "old", "https://some.com/").Return(errors.New("db is down"))
+
+	u := NewUsecase(mockRepo, nil, &codeStub{}, nil, nil, "https://some.com/", 5, AliasPolicy{}, CanonicalPolicy{})
+	assert.ErrorIs(t, u.BackfillDedupKeys(context.Background()), ErrBackfillDedupKeys)
+}
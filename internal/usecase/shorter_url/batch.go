@@ -0,0 +1,129 @@
+package shorter_url
+
+import (
+	"context"
+	"fmt"
+
+	"link-shortener-service/internal/model"
+)
+
+type BatchResult struct {
+	URLPair *model.URLPair
+	Err     error
+}
+
+// RunBatch shortens the URLs with a single storage call per round, the results are aligned with the requests.
+// Invalid requests get their own error, the error is returned only when the storage fails
+func (u *usecase) RunBatch(ctx context.Context, reqs []In) ([]BatchResult, error) {
+	results := make([]BatchResult, len(reqs))
+	urlPairs := make([]model.URLPair, len(reqs))
+	// the requests get their checked short domains, the caller's slice is left intact
+	reqs = append([]In(nil), reqs...)
+	domains := make(map[string]error)
+
+	var pending []int
+	for i, req := range reqs {
+		domain, err := u.batchDomain(ctx, req, domains)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		req.Domain = domain
+		reqs[i] = req
+
+		urlPair, err := u.newURLPair(req)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		urlPairs[i] = urlPair
+		pending = append(pending, i)
+	}
+
+	// generated short URLs colliding with existing ones are regenerated up to the attempts limit
+	for round := 0; round < u.maxAttempts && len(pending) > 0; round++ {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrShortURLGeneration, err)
+		}
+		generated, err := u.generateCodes(ctx, reqs, urlPairs, pending, round)
+		if err != nil {
+			return nil, err
+		}
+		batch := make([]model.URLPair, len(pending))
+		for j, i := range pending {
+			batch[j] = urlPairs[i]
+		}
+
+		stored, err := u.repo.PutURLPairs(ctx, batch)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrCheckExistingURL, err)
+		}
+
+		var retry []int
+		for j, i := range pending {
+			switch record := stored[j]; {
+			case record == nil && reqs[i].Alias != "":
+				results[i].Err = fmt.Errorf("%w: %s", ErrAliasTaken, reqs[i].Alias)
+			case record == nil:
+				retry = append(retry, i)
+			case record.Disabled:
+				results[i].Err = fmt.Errorf("%w: %s", ErrOriginalURLDisabled, reqs[i].OriginalURL)
+			default:
+				if record.Shorted == batch[j].Shorted {
+					u.prefetch(*record)
+				}
+				record.Shorted = model.ShortURL(u.leftURLPart, record.Shorted)
+				results[i].URLPair = record
+			}
+		}
+		u.codes.Observe(generated, len(retry))
+		pending = retry
+	}
+
+	for _, i := range pending {
+		results[i].Err = fmt.Errorf("%w: %s", ErrShortURLGeneration, reqs[i].OriginalURL)
+	}
+	return results, nil
+}
+
+// generateCodes gives new codes to the pending pairs without an alias with a single generator call
+// and returns the number of generated codes
+func (u *usecase) generateCodes(ctx context.Context, reqs []In, urlPairs []model.URLPair, pending []int, round int) (int, error) {
+	var (
+		originals []string
+		generated []int
+	)
+	for _, i := range pending {
+		if reqs[i].Alias == "" {
+			originals = append(originals, urlPairs[i].Original)
+			generated = append(generated, i)
+		}
+	}
+	if len(originals) == 0 {
+		return 0, nil
+	}
+
+	codes, err := u.codes.Generate(ctx, originals, round)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrShortURLGeneration, err)
+	}
+	for j, i := range generated {
+		urlPairs[i].Shorted = model.LinkKey(reqs[i].Domain, codes[j])
+	}
+	return len(generated), nil
+}
+
+// batchDomain checks the short domain of the request once per owner and domain in a batch
+func (u *usecase) batchDomain(ctx context.Context, req In, checked map[string]error) (string, error) {
+	domain := model.NormalizeHost(req.Domain)
+	key := req.OwnerID + "|" + domain
+	err, exists := checked[key]
+	if !exists {
+		domain, err = u.shortDomain(ctx, req)
+		checked[key] = err
+	}
+	if err != nil || domain == u.defaultHost {
+		return "", err
+	}
+	return domain, nil
+}
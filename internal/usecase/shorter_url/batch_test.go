@@ -0,0 +1,141 @@
+package shorter_url
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	"link-shortener-service/internal/model"
+	mockstorage "link-shortener-service/internal/usecase/contract/repository/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	policy := AliasPolicy{MinLength: 3, MaxLength: 30}
+	reqs := []In{
+		{OriginalURL: "https://some.com/1"},
+		{OriginalURL: "https://some.com/2", Alias: "x"},
+		{OriginalURL: "https://some.com/3", Alias: "spring-sale"},
+		{OriginalURL: "https://some.com/4"},
+		{OriginalURL: "https://some.com/5"},
+		{OriginalURL: "https://some.com/6", Alias: "summer-sale"},
+	}
+
+	mockRepo := mockstorage.NewMockURLRepository(ctrl)
+	gomock.InOrder(
+		mockRepo.EXPECT().
+			PutURLPairs(gomock.Any(), gomock.Len(5)).
+			DoAndReturn(func(_ context.Context, pairs []model.URLPair) ([]*model.URLPair, error) {
+				assert.Equal(t, "https://some.com/1", pairs[0].DedupKey)
+				assert.Equal(t, "spring-sale", pairs[1].Shorted)
+				assert.Empty(t, pairs[1].DedupKey)
+				return []*model.URLPair{
+					&pairs[0],
+					// alias is taken
+					nil,
+					// generated short URL collides
+					nil,
+					// the original URL belongs to a disabled link
+					{Original: "https://some.com/5", Shorted: "disabled", Disabled: true},
+					&pairs[4],
+				}, nil
+			}),
+		mockRepo.EXPECT().
+			PutURLPairs(gomock.Any(), gomock.Len(1)).
+			DoAndReturn(func(_ context.Context, pairs []model.URLPair) ([]*model.URLPair, error) {
+				assert.Equal(t, "https://some.com/4", pairs[0].Original)
+				return []*model.URLPair{&pairs[0]}, nil
+			}),
+	)
+
+	u := NewUsecase(mockRepo, nil, &codeStub{}, nil, nil, "https://some.com/", 5, policy, CanonicalPolicy{})
+	results, err := u.RunBatch(context.Background(), reqs)
+	require.NoError(t, err)
+	require.Len(t, results, len(reqs))
+
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, "https://some.com/1", results[0].URLPair.Original)
+	assert.ErrorIs(t, results[1].Err, ErrInvalidAlias)
+	assert.ErrorIs(t, results[2].Err, ErrAliasTaken)
+	assert.NoError(t, results[3].Err)
+	assert.Equal(t, "https://some.com/4", results[3].URLPair.Original)
+	assert.ErrorIs(t, results[4].Err, ErrOriginalURLDisabled)
+	assert.NoError(t, results[5].Err)
+	assert.Equal(t, "https://some.com/summer-sale", results[5].URLPair.Shorted)
+}
+
+func TestRunBatchErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	reqs := []In{{OriginalURL: "https://some.com/1"}}
+
+	t.Run("storage error fails the batch", func(t *testing.T) {
+		mockRepo := mockstorage.NewMockURLRepository(ctrl)
+		mockRepo.EXPECT().
+			PutURLPairs(gomock.Any(), gomock.Any()).
+			Return(nil, errors.New("db is down"))
+
+		u := NewUsecase(mockRepo, nil, &codeStub{}, nil, nil, "https://some.com/", 5, AliasPolicy{}, CanonicalPolicy{})
+		results, err := u.RunBatch(context.Background(), reqs)
+		assert.ErrorIs(t, err, ErrCheckExistingURL)
+		assert.Nil(t, results)
+	})
+
+	t.Run("short URL collides every round", func(t *testing.T) {
+		mockRepo := mockstorage.NewMockURLRepository(ctrl)
+		mockRepo.EXPECT().
+			PutURLPairs(gomock.Any(), gomock.Any()).
+			Return([]*model.URLPair{nil}, nil).
+			Times(5)
+
+		codes := &codeStub{}
+		u := NewUsecase(mockRepo, nil, codes, nil, nil, "https://some.com/", 5, AliasPolicy{}, CanonicalPolicy{})
+		results, err := u.RunBatch(context.Background(), reqs)
+		require.NoError(t, err)
+		assert.ErrorIs(t, results[0].Err, ErrShortURLGeneration)
+		assert.Equal(t, [2]int{5, 5}, codes.observed)
+	})
+}
+
+func TestRunBatchShortDomain(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	reqs := []In{
+		{OriginalURL: "https://acme.io/1", OwnerID: "acme", Domain: "go.acme.io"},
+		{OriginalURL: "https://acme.io/2", OwnerID: "acme", Domain: "Go.Acme.io"},
+		{OriginalURL: "https://acme.io/3", OwnerID: "acme", Domain: "acme.link"},
+	}
+
+	urls := mockstorage.NewMockURLRepository(ctrl)
+	workspaces := mockstorage.NewMockWorkspaceRepository(ctrl)
+	// the domain is checked once for the whole batch
+	workspaces.EXPECT().GetDomainWorkspace(gomock.Any(), "go.acme.io").Return("acme", nil)
+	workspaces.EXPECT().GetDomainWorkspace(gomock.Any(), "acme.link").Return("", rep.ErrNotFound)
+	urls.EXPECT().
+		PutURLPairs(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, pairs []model.URLPair) ([]*model.URLPair, error) {
+			require.Len(t, pairs, 2)
+			stored := make([]*model.URLPair, len(pairs))
+			for i := range pairs {
+				stored[i] = &pairs[i]
+			}
+			return stored, nil
+		})
+
+	u := NewUsecase(urls, workspaces, &codeStub{}, nil, nil, "https://some.com/", 5, AliasPolicy{}, CanonicalPolicy{})
+	results, err := u.RunBatch(context.Background(), reqs)
+	require.NoError(t, err)
+	assert.Equal(t, "https://go.acme.io/code1", results[0].URLPair.Shorted)
+	assert.Equal(t, "https://go.acme.io/code2", results[1].URLPair.Shorted)
+	assert.ErrorIs(t, results[2].Err, ErrUnknownDomain)
+	assert.Equal(t, "Go.Acme.io", reqs[1].Domain)
+}
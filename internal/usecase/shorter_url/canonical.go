@@ -0,0 +1,73 @@
+package shorter_url
+
+import (
+	"net"
+	"net/url"
+	"strings"
+
+	"link-shortener-service/internal/model"
+)
+
+const (
+	FragmentsKeep  = "keep"
+	FragmentsStrip = "strip"
+)
+
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// canonicalURL is the form of the URL used to find links to the same page: lowercase scheme and host,
+// punycode host, no default port, sorted query and, by the policy, no tracking parameters and fragment
+func (p CanonicalPolicy) canonicalURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	host, port := model.ASCIIHost(u.Hostname()), u.Port()
+	if port == defaultPorts[u.Scheme] {
+		port = ""
+	}
+	switch {
+	case port != "":
+		u.Host = net.JoinHostPort(host, port)
+	case strings.Contains(host, ":"):
+		u.Host = "[" + host + "]"
+	default:
+		u.Host = host
+	}
+
+	if u.Path == "" {
+		u.Path = "/"
+	}
+
+	if u.RawQuery != "" {
+		query, err := url.ParseQuery(u.RawQuery)
+		if err == nil {
+			if p.StripTracking {
+				for key := range query {
+					if isTrackingParam(key) {
+						query.Del(key)
+					}
+				}
+			}
+			// Encode sorts the parameters by key
+			u.RawQuery = query.Encode()
+		}
+	}
+	u.ForceQuery = false
+
+	if p.Fragments == FragmentsStrip {
+		u.Fragment, u.RawFragment = "", ""
+	}
+
+	return u.String()
+}
+
+func isTrackingParam(key string) bool {
+	key = strings.ToLower(key)
+	return strings.HasPrefix(key, "utm_") || key == "fbclid"
+}
@@ -0,0 +1,80 @@
+package shorter_url
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonicalURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   CanonicalPolicy
+		url      string
+		expected string
+	}{
+		{
+			name:     "scheme and host are lowercased, default port is dropped",
+			url:      "HTTPS://Example.COM:443/Path",
+			expected: "https://example.com/Path",
+		},
+		{
+			name:     "other ports are kept",
+			url:      "http://example.com:8080/a",
+			expected: "http://example.com:8080/a",
+		},
+		{
+			name:     "empty path is a slash",
+			url:      "https://example.com",
+			expected: "https://example.com/",
+		},
+		{
+			name:     "IDN host is converted to punycode",
+			url:      "https://Пример.рф/a",
+			expected: "https://xn--e1afmkfd.xn--p1ai/a",
+		},
+		{
+			name:     "IPv6 host keeps its brackets",
+			url:      "http://[::1]:80/a",
+			expected: "http://[::1]/a",
+		},
+		{
+			name:     "query is sorted",
+			url:      "https://example.com/a?b=1&a=2&a=1",
+			expected: "https://example.com/a?a=2&a=1&b=1",
+		},
+		{
+			name:     "tracking parameters are kept by default",
+			url:      "https://example.com/a?utm_source=mail&fbclid=x",
+			expected: "https://example.com/a?fbclid=x&utm_source=mail",
+		},
+		{
+			name:     "tracking parameters are stripped",
+			policy:   CanonicalPolicy{StripTracking: true},
+			url:      "https://example.com/a?UTM_Source=mail&fbclid=x&id=1",
+			expected: "https://example.com/a?id=1",
+		},
+		{
+			name:     "fragment is kept",
+			url:      "https://example.com/a#top",
+			expected: "https://example.com/a#top",
+		},
+		{
+			name:     "fragment is stripped",
+			policy:   CanonicalPolicy{Fragments: FragmentsStrip},
+			url:      "https://example.com/a?#top",
+			expected: "https://example.com/a",
+		},
+		{
+			name:     "unparsable URL is left as is",
+			url:      "https://exa mple.com/%zz",
+			expected: "https://exa mple.com/%zz",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.policy.canonicalURL(tt.url))
+		})
+	}
+}
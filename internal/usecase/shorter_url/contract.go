@@ -1,5 +1,66 @@
 package shorter_url
 
+import (
+	"context"
+	"time"
+
+	"link-shortener-service/internal/model"
+)
+
 type In struct {
-	OriginalURL string
+	OriginalURL    string
+	RedirectStatus int
+	// Alias is a requested short code, a random one is generated when empty
+	Alias string
+	// ExpiresAt and TTL are mutually exclusive, the link never expires when both are zero
+	ExpiresAt time.Time
+	TTL       time.Duration
+	// MaxClicks limits the number of resolutions, 0 means unlimited
+	MaxClicks int
+	// Password protects the link, it is stored as a bcrypt hash
+	Password string
+	// OwnerID is the owner of the API key creating the link, links are deduplicated per owner
+	OwnerID string
+	// Domain is a short domain of the owner's workspace, empty for the default domain
+	Domain string
+	// Rules send the visitors matching them elsewhere than OriginalURL, the first matching rule wins
+	Rules []model.RedirectRule
+	// Targets split the visitors no rule matched between weighted destinations, unnamed variants are named by position
+	Targets []model.SplitTarget
+	// Interstitial shows the destination with a countdown before redirecting
+	Interstitial bool
+}
+
+type AliasPolicy struct {
+	MinLength int
+	MaxLength int
+	Reserved  []string
+	// CaseInsensitive stores aliases in lowercase, so they resolve whatever case they are typed in
+	CaseInsensitive bool
+	Blocklist       WordBlocklist
+}
+
+// CanonicalPolicy tunes the canonical form of original URLs used for deduplication,
+// Fragments is FragmentsKeep or FragmentsStrip
+type CanonicalPolicy struct {
+	StripTracking bool
+	Fragments     string
+}
+
+// WordBlocklist reports codes containing offensive words
+type WordBlocklist interface {
+	Contains(code string) bool
+}
+
+// CodeGenerator returns a short code for every original URL, attempt counts the earlier tries
+// for the same URLs whose codes were taken. Observe reports how many generated codes were tried
+// and how many of them were taken
+type CodeGenerator interface {
+	Generate(ctx context.Context, originals []string, attempt int) ([]string, error)
+	Observe(generated, collided int)
+}
+
+// Prefetcher fetches the metadata of the destinations of new links in the background
+type Prefetcher interface {
+	Prefetch(pair model.URLPair)
 }
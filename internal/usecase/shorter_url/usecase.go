@@ -4,64 +4,389 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"math/rand"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
 
 	rep "link-shortener-service/internal/infastracture/repository"
 	"link-shortener-service/internal/model"
 	"link-shortener-service/internal/usecase/contract/repository"
+	"link-shortener-service/internal/usecase/contract/service"
+
+	"golang.org/x/crypto/bcrypt"
 )
 
 var (
-	ErrCheckExistingURL = errors.New("failed to get short URLPair")
+	ErrCheckExistingURL    = errors.New("failed to get short URLPair")
+	ErrInvalidAlias        = errors.New("invalid alias")
+	ErrReservedAlias       = errors.New("alias is reserved")
+	ErrBlockedAlias        = errors.New("alias contains a blocked word")
+	ErrAliasTaken          = errors.New("alias is already taken")
+	ErrInvalidExpiry       = errors.New("invalid expiration")
+	ErrHashPassword        = errors.New("failed to hash password")
+	ErrOriginalURLDisabled = errors.New("original URL belongs to a disabled short URL")
+	ErrShortURLGeneration  = errors.New("failed to generate a free short URL")
+	ErrUnknownDomain       = errors.New("unknown short domain")
+	ErrForeignDomain       = errors.New("short domain belongs to another workspace")
+	ErrCheckDomain         = errors.New("failed to check short domain")
+	ErrBlockedURL          = errors.New("original URL is blocklisted")
+	ErrInvalidRules        = errors.New("invalid redirect rules")
+	ErrInvalidTargets      = errors.New("invalid split targets")
+
+	aliasRunes = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+)
 
-	letterRunes = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ01234567890_")
+const (
+	MaxSplitTargets  = 20
+	maxVariantLength = 32
+	// maxTargetWeight keeps the sum of the weights far from overflowing
+	maxTargetWeight = 1_000_000
 )
 
 type usecase struct {
 	repo        repository.URLRepository
+	workspaces  repository.WorkspaceRepository
+	codes       CodeGenerator
+	screener    service.Screener
+	previews    Prefetcher
 	leftURLPart string
-	urlLength   int
+	defaultHost string
+	maxAttempts int
+	aliasPolicy AliasPolicy
+	canonical   CanonicalPolicy
+	reserved    map[string]struct{}
+	now         func() time.Time
 }
 
-func NewUsecase(repo repository.URLRepository, leftURLPart string, urlLength int) *usecase {
+func NewUsecase(repo repository.URLRepository, workspaces repository.WorkspaceRepository, codes CodeGenerator,
+	screener service.Screener, previews Prefetcher, leftURLPart string, maxAttempts int, aliasPolicy AliasPolicy, canonicalPolicy CanonicalPolicy,
+) *usecase {
+	reserved := make(map[string]struct{}, len(aliasPolicy.Reserved))
+	for _, word := range aliasPolicy.Reserved {
+		reserved[strings.ToLower(strings.TrimSpace(word))] = struct{}{}
+	}
+
+	var defaultHost string
+	if base, err := url.Parse(leftURLPart); err == nil {
+		defaultHost = model.NormalizeHost(base.Host)
+	}
+
 	return &usecase{
 		repo:        repo,
+		workspaces:  workspaces,
+		codes:       codes,
+		screener:    screener,
+		previews:    previews,
 		leftURLPart: leftURLPart,
-		urlLength:   urlLength,
+		defaultHost: defaultHost,
+		maxAttempts: maxAttempts,
+		aliasPolicy: aliasPolicy,
+		canonical:   canonicalPolicy,
+		reserved:    reserved,
+		now:         time.Now,
 	}
 }
 
 func (u *usecase) Run(ctx context.Context, req In) (*model.URLPair, error) {
-	var shortedURL string
-	urlPair := model.URLPair{
-		Original: req.OriginalURL,
-		Shorted:  shortedURL,
+	domain, err := u.shortDomain(ctx, req)
+	if err != nil {
+		return nil, err
 	}
+	req.Domain = domain
 
-	for {
-		urlPair.Shorted = u.generateShortURL()
-		record, err := u.repo.PutURLPair(ctx, urlPair)
-		record.Shorted = fmt.Sprintf("%s%s", u.leftURLPart, record.Shorted)
-		if err == nil {
-			return record, nil
-		}
+	urlPair, err := u.newURLPair(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Alias != "" {
+		return u.putAlias(ctx, urlPair)
+	}
 
+	for attempt := 0; attempt < u.maxAttempts; attempt++ {
+		if err = ctx.Err(); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrShortURLGeneration, err)
+		}
+		codes, err := u.codes.Generate(ctx, []string{urlPair.Original}, attempt)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrShortURLGeneration, err)
+		}
+		urlPair.Shorted = model.LinkKey(req.Domain, codes[0])
+		record, err := u.repo.PutURLPair(ctx, urlPair)
 		if errors.Is(err, rep.ErrShortedURLExist) {
+			u.codes.Observe(1, 1)
 			continue
 		}
-		if errors.Is(err, rep.ErrOriginalURLExist) {
-			return record, nil
+		// the storage returns no record when the link holding the original URL could not be read
+		if record == nil || err != nil && !errors.Is(err, rep.ErrOriginalURLExist) {
+			return nil, fmt.Errorf("%w: %v", ErrCheckExistingURL, err)
+		}
+		u.codes.Observe(1, 0)
+
+		// the original URL may already belong to a link that was taken down
+		if record.Disabled {
+			return nil, fmt.Errorf("%w: %s", ErrOriginalURLDisabled, req.OriginalURL)
+		}
+		// an existing link with the same destination has its metadata fetched already
+		if record.Shorted == urlPair.Shorted {
+			u.prefetch(*record)
+		}
+		record.Shorted = model.ShortURL(u.leftURLPart, record.Shorted)
+		return record, nil
+	}
+
+	return nil, fmt.Errorf("%w: %d attempts for %s", ErrShortURLGeneration, u.maxAttempts, req.OriginalURL)
+}
+
+// shortDomain checks that the requested short domain belongs to the workspace of the owner
+// and returns it normalized, the default domain is empty
+func (u *usecase) shortDomain(ctx context.Context, req In) (string, error) {
+	domain := model.NormalizeHost(req.Domain)
+	if domain == "" || domain == u.defaultHost {
+		return "", nil
+	}
+
+	workspaceID, err := u.workspaces.GetDomainWorkspace(ctx, domain)
+	if err != nil {
+		if errors.Is(err, rep.ErrNotFound) {
+			return "", fmt.Errorf("%w: %s", ErrUnknownDomain, domain)
+		}
+		return "", fmt.Errorf("%w: %v", ErrCheckDomain, err)
+	}
+	if workspaceID != req.OwnerID {
+		return "", fmt.Errorf("%w: %s", ErrForeignDomain, domain)
+	}
+	return domain, nil
+}
+
+// newURLPair validates the request and builds the pair to store, short URL is left empty unless an alias is requested.
+// The short domain of the request must be already checked
+func (u *usecase) newURLPair(req In) (model.URLPair, error) {
+	if u.screener != nil {
+		if err := u.screener.Check(req.OriginalURL); err != nil {
+			return model.URLPair{}, fmt.Errorf("%w: %v", ErrBlockedURL, err)
+		}
+	}
+
+	rules, err := u.redirectRules(req.Rules)
+	if err != nil {
+		return model.URLPair{}, err
+	}
+	targets, err := u.splitTargets(req.Targets)
+	if err != nil {
+		return model.URLPair{}, err
+	}
+
+	alias := req.Alias
+	if alias != "" {
+		if u.aliasPolicy.CaseInsensitive {
+			alias = strings.ToLower(alias)
+		}
+		if err := u.checkAlias(alias); err != nil {
+			return model.URLPair{}, err
+		}
+	}
+
+	expiresAt, err := u.expiresAt(req)
+	if err != nil {
+		return model.URLPair{}, err
+	}
+
+	var passwordHash string
+	if req.Password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return model.URLPair{}, fmt.Errorf("%w: %v", ErrHashPassword, err)
+		}
+		passwordHash = string(hash)
+	}
+
+	urlPair := model.URLPair{
+		Original:       req.OriginalURL,
+		RedirectStatus: req.RedirectStatus,
+		ExpiresAt:      expiresAt,
+		MaxClicks:      req.MaxClicks,
+		PasswordHash:   passwordHash,
+		OwnerID:        req.OwnerID,
+		Rules:          rules,
+		Targets:        targets,
+		Interstitial:   req.Interstitial,
+	}
+	if alias != "" {
+		urlPair.Shorted = model.LinkKey(req.Domain, alias)
+	}
+	// the exact original URL is kept for redirecting, links are deduplicated by its canonical form
+	if isPlain(req) {
+		urlPair.DedupKey = dedupKey(req.OwnerID, req.Domain, u.canonical.canonicalURL(req.OriginalURL))
+	}
+	return urlPair, nil
+}
+
+// dedupKey scopes the canonical URL to the workspace of the owner and the short domain,
+// so workspaces never get each other's links
+func dedupKey(ownerID, domain, canonical string) string {
+	key := canonical
+	if domain != "" {
+		key = domain + "|" + key
+	}
+	if ownerID != "" {
+		key = ownerID + "|" + key
+	}
+	return key
+}
+
+func (u *usecase) putAlias(ctx context.Context, urlPair model.URLPair) (*model.URLPair, error) {
+	record, err := u.repo.PutURLPair(ctx, urlPair)
+	if err != nil {
+		if errors.Is(err, rep.ErrShortedURLExist) {
+			_, alias := model.SplitLinkKey(urlPair.Shorted)
+			return nil, fmt.Errorf("%w: %s", ErrAliasTaken, alias)
 		}
 		return nil, fmt.Errorf("%w: %v", ErrCheckExistingURL, err)
 	}
+
+	u.prefetch(*record)
+	record.Shorted = model.ShortURL(u.leftURLPart, record.Shorted)
+	return record, nil
+}
+
+func (u *usecase) prefetch(pair model.URLPair) {
+	if u.previews != nil {
+		u.previews.Prefetch(pair)
+	}
+}
+
+func (u *usecase) expiresAt(req In) (time.Time, error) {
+	now := u.now()
+	switch {
+	case req.TTL < 0:
+		return time.Time{}, fmt.Errorf("%w: TTL must be positive", ErrInvalidExpiry)
+	case req.TTL > 0 && !req.ExpiresAt.IsZero():
+		return time.Time{}, fmt.Errorf("%w: TTL and expiration date are mutually exclusive", ErrInvalidExpiry)
+	case req.TTL > 0:
+		return now.Add(req.TTL), nil
+	case !req.ExpiresAt.IsZero() && !req.ExpiresAt.After(now):
+		return time.Time{}, fmt.Errorf("%w: expiration date must be in the future", ErrInvalidExpiry)
+	}
+	return req.ExpiresAt, nil
+}
+
+// isPlain reports whether the request asks for nothing but a short URL,
+// only such links are deduplicated by the original URL
+func isPlain(req In) bool {
+	return req.Alias == "" &&
+		req.RedirectStatus == 0 &&
+		req.ExpiresAt.IsZero() &&
+		req.TTL == 0 &&
+		req.MaxClicks == 0 &&
+		req.Password == "" &&
+		len(req.Rules) == 0 &&
+		len(req.Targets) == 0 &&
+		!req.Interstitial
+}
+
+// redirectRules checks the rules and normalizes their conditions, targets are screened like original URLs
+func (u *usecase) redirectRules(rules []model.RedirectRule) ([]model.RedirectRule, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	result := make([]model.RedirectRule, 0, len(rules))
+	for i, rule := range rules {
+		if parsed, err := url.Parse(rule.Target); err != nil || parsed.Host == "" {
+			return nil, fmt.Errorf("%w: rule %d: invalid target %q", ErrInvalidRules, i, rule.Target)
+		}
+		if !rule.From.IsZero() && !rule.Until.IsZero() && !rule.Until.After(rule.From) {
+			return nil, fmt.Errorf("%w: rule %d: until must be after from", ErrInvalidRules, i)
+		}
+		if u.screener != nil {
+			if err := u.screener.Check(rule.Target); err != nil {
+				return nil, fmt.Errorf("%w: rule %d: %v", ErrBlockedURL, i, err)
+			}
+		}
+
+		rule.Devices = mapStrings(rule.Devices, strings.ToLower)
+		rule.Languages = mapStrings(rule.Languages, strings.ToLower)
+		rule.Countries = mapStrings(rule.Countries, strings.ToUpper)
+		for _, device := range rule.Devices {
+			if !slices.Contains(model.Devices, device) {
+				return nil, fmt.Errorf("%w: rule %d: unknown device %q", ErrInvalidRules, i, device)
+			}
+		}
+		result = append(result, rule)
+	}
+	return result, nil
 }
 
-func (u *usecase) generateShortURL() string {
-	cntSymbols := len(letterRunes)
-	b := make([]rune, u.urlLength)
+// splitTargets checks the targets and names the unnamed ones by position, "a", "b" and so on.
+// Targets are screened like original URLs
+func (u *usecase) splitTargets(targets []model.SplitTarget) ([]model.SplitTarget, error) {
+	if len(targets) == 0 {
+		return nil, nil
+	}
+	if len(targets) > MaxSplitTargets {
+		return nil, fmt.Errorf("%w: at most %d targets are allowed", ErrInvalidTargets, MaxSplitTargets)
+	}
+	result := make([]model.SplitTarget, 0, len(targets))
+	variants := make(map[string]struct{}, len(targets))
+	total := 0
+	for i, target := range targets {
+		if parsed, err := url.Parse(target.URL); err != nil || parsed.Host == "" {
+			return nil, fmt.Errorf("%w: target %d: invalid URL %q", ErrInvalidTargets, i, target.URL)
+		}
+		if target.Weight < 0 || target.Weight > maxTargetWeight {
+			return nil, fmt.Errorf("%w: target %d: weight must be between 0 and %d", ErrInvalidTargets, i, maxTargetWeight)
+		}
+		if target.Variant == "" {
+			target.Variant = string(rune('a' + i))
+		}
+		if len(target.Variant) > maxVariantLength || !aliasRunes.MatchString(target.Variant) {
+			return nil, fmt.Errorf("%w: target %d: variant must be up to %d latin letters, digits, '-' and '_'",
+				ErrInvalidTargets, i, maxVariantLength)
+		}
+		if _, ok := variants[target.Variant]; ok {
+			return nil, fmt.Errorf("%w: target %d: duplicate variant %q", ErrInvalidTargets, i, target.Variant)
+		}
+		variants[target.Variant] = struct{}{}
+		if u.screener != nil {
+			if err := u.screener.Check(target.URL); err != nil {
+				return nil, fmt.Errorf("%w: target %d: %v", ErrBlockedURL, i, err)
+			}
+		}
+		total += target.Weight
+		result = append(result, target)
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("%w: at least one target must have a positive weight", ErrInvalidTargets)
+	}
+	return result, nil
+}
 
-	for i := range b {
-		b[i] = letterRunes[rand.Intn(cntSymbols)]
+func mapStrings(values []string, f func(string) string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		result = append(result, f(strings.TrimSpace(v)))
+	}
+	return result
+}
+
+func (u *usecase) checkAlias(alias string) error {
+	if len(alias) < u.aliasPolicy.MinLength || len(alias) > u.aliasPolicy.MaxLength {
+		return fmt.Errorf("%w: length must be between %d and %d",
+			ErrInvalidAlias, u.aliasPolicy.MinLength, u.aliasPolicy.MaxLength)
+	}
+	if !aliasRunes.MatchString(alias) {
+		return fmt.Errorf("%w: only latin letters, digits, '-' and '_' are allowed", ErrInvalidAlias)
+	}
+	if _, ok := u.reserved[strings.ToLower(alias)]; ok {
+		return fmt.Errorf("%w: %s", ErrReservedAlias, alias)
+	}
+	if u.aliasPolicy.Blocklist != nil && u.aliasPolicy.Blocklist.Contains(alias) {
+		return fmt.Errorf("%w: %s", ErrBlockedAlias, alias)
 	}
-	return string(b)
+	return nil
 }
@@ -3,24 +3,65 @@ package shorter_url
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	rep "link-shortener-service/internal/infastracture/repository"
 	"link-shortener-service/internal/model"
 	mockstorage "link-shortener-service/internal/usecase/contract/repository/mocks"
+	"link-shortener-service/internal/usecase/contract/service/servicetest"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
 )
 
+// codeStub numbers the codes it generates and records the attempts it was asked for and the observed collisions
+type codeStub struct {
+	generated int
+	attempts  []int
+	observed  [2]int
+	err       error
+}
+
+func (c *codeStub) Observe(generated, collided int) {
+	c.observed[0] += generated
+	c.observed[1] += collided
+}
+
+func (c *codeStub) Generate(_ context.Context, originals []string, attempt int) ([]string, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	c.attempts = append(c.attempts, attempt)
+	codes := make([]string, len(originals))
+	for i := range codes {
+		c.generated++
+		codes[i] = fmt.Sprintf("code%d", c.generated)
+	}
+	return codes, nil
+}
+
+// blockedWords matches codes containing any of the words regardless of case
+type blockedWords []string
+
+func (b blockedWords) Contains(code string) bool {
+	for _, word := range b {
+		if strings.Contains(strings.ToLower(code), word) {
+			return true
+		}
+	}
+	return false
+}
+
 func TestPutURLPair(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	leftURLPart := "https://some.com/"
-	URLLen := 10
 
 	reqURL := In{
 		OriginalURL: "https://some.com/asdasd",
@@ -89,6 +130,26 @@ func TestPutURLPair(t *testing.T) {
 				Original: reqURL.OriginalURL,
 			},
 		},
+		{
+			name: "original URL belongs to a disabled URL",
+			req:  reqURL,
+			setupMock: func(mockRepo *mockstorage.MockURLRepository) {
+				mockRepo.EXPECT().
+					PutURLPair(gomock.Any(), gomock.AssignableToTypeOf(model.URLPair{})).
+					Return(&model.URLPair{Original: reqURL.OriginalURL, Shorted: "xHsvC_0NTU", Disabled: true}, nil)
+			},
+			expectedError: ErrOriginalURLDisabled,
+		},
+		{
+			name: "existing URL could not be read",
+			req:  reqURL,
+			setupMock: func(mockRepo *mockstorage.MockURLRepository) {
+				mockRepo.EXPECT().
+					PutURLPair(gomock.Any(), gomock.AssignableToTypeOf(model.URLPair{})).
+					Return(nil, rep.ErrOriginalURLExist)
+			},
+			expectedError: ErrCheckExistingURL,
+		},
 		{
 			name: "unexpected error on insert",
 			req:  reqURL,
@@ -106,7 +167,7 @@ func TestPutURLPair(t *testing.T) {
 			mockRepo := mockstorage.NewMockURLRepository(ctrl)
 			tt.setupMock(mockRepo)
 
-			u := NewUsecase(mockRepo, leftURLPart, URLLen)
+			u := NewUsecase(mockRepo, nil, &codeStub{}, nil, nil, leftURLPart, 5, AliasPolicy{}, CanonicalPolicy{})
 			result, err := u.Run(context.Background(), tt.req)
 
 			if tt.expectedError != nil {
@@ -120,10 +181,654 @@ func TestPutURLPair(t *testing.T) {
 				require.NotNil(t, result)
 				assert.Equal(t, tt.expected.Original, result.Original)
 				assert.True(t, strings.HasPrefix(result.Shorted, leftURLPart))
-				assert.Equal(t, URLLen, len(strings.TrimPrefix(result.Shorted, leftURLPart)))
+				assert.NotEmpty(t, strings.TrimPrefix(result.Shorted, leftURLPart))
 			} else {
 				assert.Nil(t, result)
 			}
 		})
 	}
 }
+
+func TestPutAlias(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	leftURLPart := "https://some.com/"
+	policy := AliasPolicy{
+		MinLength: 3,
+		MaxLength: 16,
+		Reserved:  []string{"api", "Admin"},
+		Blocklist: blockedWords{"bad"},
+	}
+
+	tests := []struct {
+		name          string
+		req           In
+		setupMock     func(*mockstorage.MockURLRepository)
+		expected      *model.URLPair
+		expectedError error
+	}{
+		{
+			name: "successful put",
+			req:  In{OriginalURL: "https://some.com/asdasd", Alias: "spring-sale"},
+			setupMock: func(mockRepo *mockstorage.MockURLRepository) {
+				mockRepo.EXPECT().
+					PutURLPair(gomock.Any(), model.URLPair{Original: "https://some.com/asdasd", Shorted: "spring-sale"}).
+					DoAndReturn(func(_ context.Context, in model.URLPair) (*model.URLPair, error) {
+						return &in, nil
+					})
+			},
+			expected: &model.URLPair{
+				Original: "https://some.com/asdasd",
+				Shorted:  leftURLPart + "spring-sale",
+			},
+		},
+		{
+			name:          "too short alias",
+			req:           In{OriginalURL: "https://some.com/asdasd", Alias: "ab"},
+			setupMock:     func(mockRepo *mockstorage.MockURLRepository) {},
+			expectedError: ErrInvalidAlias,
+		},
+		{
+			name:          "not allowed characters",
+			req:           In{OriginalURL: "https://some.com/asdasd", Alias: "spring/sale"},
+			setupMock:     func(mockRepo *mockstorage.MockURLRepository) {},
+			expectedError: ErrInvalidAlias,
+		},
+		{
+			name:          "reserved alias",
+			req:           In{OriginalURL: "https://some.com/asdasd", Alias: "ADMIN"},
+			setupMock:     func(mockRepo *mockstorage.MockURLRepository) {},
+			expectedError: ErrReservedAlias,
+		},
+		{
+			name:          "blocked alias",
+			req:           In{OriginalURL: "https://some.com/asdasd", Alias: "so-BAD-sale"},
+			setupMock:     func(mockRepo *mockstorage.MockURLRepository) {},
+			expectedError: ErrBlockedAlias,
+		},
+		{
+			name: "alias is taken",
+			req:  In{OriginalURL: "https://some.com/asdasd", Alias: "spring-sale"},
+			setupMock: func(mockRepo *mockstorage.MockURLRepository) {
+				mockRepo.EXPECT().
+					PutURLPair(gomock.Any(), gomock.AssignableToTypeOf(model.URLPair{})).
+					Return(&model.URLPair{}, rep.ErrShortedURLExist)
+			},
+			expectedError: ErrAliasTaken,
+		},
+		{
+			name: "unexpected error on insert",
+			req:  In{OriginalURL: "https://some.com/asdasd", Alias: "spring-sale"},
+			setupMock: func(mockRepo *mockstorage.MockURLRepository) {
+				mockRepo.EXPECT().
+					PutURLPair(gomock.Any(), gomock.AssignableToTypeOf(model.URLPair{})).
+					Return(nil, errors.New("db is down"))
+			},
+			expectedError: ErrCheckExistingURL,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mockstorage.NewMockURLRepository(ctrl)
+			tt.setupMock(mockRepo)
+
+			u := NewUsecase(mockRepo, nil, &codeStub{}, nil, nil, leftURLPart, 5, policy, CanonicalPolicy{})
+			result, err := u.Run(context.Background(), tt.req)
+
+			if tt.expectedError != nil {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, result)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestExpiration(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 4, 18, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		req           In
+		expected      time.Time
+		expectedError error
+	}{
+		{
+			name:     "without expiration",
+			req:      In{OriginalURL: "https://some.com/asdasd"},
+			expected: time.Time{},
+		},
+		{
+			name:     "with TTL",
+			req:      In{OriginalURL: "https://some.com/asdasd", TTL: time.Hour},
+			expected: now.Add(time.Hour),
+		},
+		{
+			name:     "with expiration date",
+			req:      In{OriginalURL: "https://some.com/asdasd", ExpiresAt: now.Add(24 * time.Hour)},
+			expected: now.Add(24 * time.Hour),
+		},
+		{
+			name:          "expiration date in the past",
+			req:           In{OriginalURL: "https://some.com/asdasd", ExpiresAt: now.Add(-time.Second)},
+			expectedError: ErrInvalidExpiry,
+		},
+		{
+			name:          "both TTL and expiration date",
+			req:           In{OriginalURL: "https://some.com/asdasd", TTL: time.Hour, ExpiresAt: now.Add(time.Hour)},
+			expectedError: ErrInvalidExpiry,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mockstorage.NewMockURLRepository(ctrl)
+			if tt.expectedError == nil {
+				mockRepo.EXPECT().
+					PutURLPair(gomock.Any(), gomock.AssignableToTypeOf(model.URLPair{})).
+					DoAndReturn(func(_ context.Context, in model.URLPair) (*model.URLPair, error) {
+						return &in, nil
+					})
+			}
+
+			u := NewUsecase(mockRepo, nil, &codeStub{}, nil, nil, "https://some.com/", 5, AliasPolicy{}, CanonicalPolicy{})
+			u.now = func() time.Time { return now }
+			result, err := u.Run(context.Background(), tt.req)
+
+			if tt.expectedError != nil {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, result)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result.ExpiresAt)
+		})
+	}
+}
+
+func TestShortDomain(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name          string
+		req           In
+		setupMock     func(*mockstorage.MockURLRepository, *mockstorage.MockWorkspaceRepository)
+		expected      string
+		expectedError error
+	}{
+		{
+			name: "alias on a workspace domain",
+			req:  In{OriginalURL: "https://acme.io/sale", Alias: "sale", OwnerID: "acme", Domain: "GO.acme.io"},
+			setupMock: func(urls *mockstorage.MockURLRepository, workspaces *mockstorage.MockWorkspaceRepository) {
+				workspaces.EXPECT().GetDomainWorkspace(gomock.Any(), "go.acme.io").Return("acme", nil)
+				urls.EXPECT().
+					PutURLPair(gomock.Any(), model.URLPair{Original: "https://acme.io/sale", Shorted: "go.acme.io/sale", OwnerID: "acme"}).
+					DoAndReturn(func(_ context.Context, in model.URLPair) (*model.URLPair, error) {
+						return &in, nil
+					})
+			},
+			expected: "https://go.acme.io/sale",
+		},
+		{
+			name: "generated code on a workspace domain is deduplicated per domain",
+			req:  In{OriginalURL: "https://acme.io/", OwnerID: "acme", Domain: "go.acme.io"},
+			setupMock: func(urls *mockstorage.MockURLRepository, workspaces *mockstorage.MockWorkspaceRepository) {
+				workspaces.EXPECT().GetDomainWorkspace(gomock.Any(), "go.acme.io").Return("acme", nil)
+				urls.EXPECT().
+					PutURLPair(gomock.Any(), model.URLPair{
+						Original: "https://acme.io/",
+						Shorted:  "go.acme.io/code1",
+						OwnerID:  "acme",
+						DedupKey: "acme|go.acme.io|https://acme.io/",
+					}).
+					DoAndReturn(func(_ context.Context, in model.URLPair) (*model.URLPair, error) {
+						return &in, nil
+					})
+			},
+			expected: "https://go.acme.io/code1",
+		},
+		{
+			name: "default domain",
+			req:  In{OriginalURL: "https://acme.io/sale", Alias: "sale", OwnerID: "acme", Domain: "some.com"},
+			setupMock: func(urls *mockstorage.MockURLRepository, workspaces *mockstorage.MockWorkspaceRepository) {
+				urls.EXPECT().
+					PutURLPair(gomock.Any(), model.URLPair{Original: "https://acme.io/sale", Shorted: "sale", OwnerID: "acme"}).
+					DoAndReturn(func(_ context.Context, in model.URLPair) (*model.URLPair, error) {
+						return &in, nil
+					})
+			},
+			expected: "https://some.com/sale",
+		},
+		{
+			name: "domain of another workspace",
+			req:  In{OriginalURL: "https://acme.io/", OwnerID: "globex", Domain: "go.acme.io"},
+			setupMock: func(_ *mockstorage.MockURLRepository, workspaces *mockstorage.MockWorkspaceRepository) {
+				workspaces.EXPECT().GetDomainWorkspace(gomock.Any(), "go.acme.io").Return("acme", nil)
+			},
+			expectedError: ErrForeignDomain,
+		},
+		{
+			name: "unknown domain",
+			req:  In{OriginalURL: "https://acme.io/", OwnerID: "acme", Domain: "acme.link"},
+			setupMock: func(_ *mockstorage.MockURLRepository, workspaces *mockstorage.MockWorkspaceRepository) {
+				workspaces.EXPECT().GetDomainWorkspace(gomock.Any(), "acme.link").Return("", rep.ErrNotFound)
+			},
+			expectedError: ErrUnknownDomain,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			urls := mockstorage.NewMockURLRepository(ctrl)
+			workspaces := mockstorage.NewMockWorkspaceRepository(ctrl)
+			tt.setupMock(urls, workspaces)
+
+			u := NewUsecase(urls, workspaces, &codeStub{}, nil, nil, "https://some.com/", 5,
+				AliasPolicy{MinLength: 3, MaxLength: 30}, CanonicalPolicy{})
+			result, err := u.Run(context.Background(), tt.req)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result.Shorted)
+		})
+	}
+}
+
+func TestCaseInsensitiveAlias(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mockstorage.NewMockURLRepository(ctrl)
+	mockRepo.EXPECT().
+		PutURLPair(gomock.Any(), model.URLPair{Original: "https://some.com/", Shorted: "spring-sale"}).
+		DoAndReturn(func(_ context.Context, in model.URLPair) (*model.URLPair, error) {
+			return &in, nil
+		})
+
+	u := NewUsecase(mockRepo, nil, &codeStub{}, nil, nil, "https://some.com/", 5, AliasPolicy{
+		MinLength:       3,
+		MaxLength:       16,
+		Reserved:        []string{"admin"},
+		CaseInsensitive: true,
+	}, CanonicalPolicy{})
+	result, err := u.Run(context.Background(), In{OriginalURL: "https://some.com/", Alias: "Spring-Sale"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://some.com/spring-sale", result.Shorted)
+}
+
+func TestCodeGeneration(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mockstorage.NewMockURLRepository(ctrl)
+	gomock.InOrder(
+		mockRepo.EXPECT().
+			PutURLPair(gomock.Any(), model.URLPair{Original: "https://some.com/", Shorted: "code1", DedupKey: "https://some.com/"}).
+			Return(&model.URLPair{}, rep.ErrShortedURLExist),
+		mockRepo.EXPECT().
+			PutURLPair(gomock.Any(), model.URLPair{Original: "https://some.com/", Shorted: "code2", DedupKey: "https://some.com/"}).
+			DoAndReturn(func(_ context.Context, in model.URLPair) (*model.URLPair, error) {
+				return &in, nil
+			}),
+	)
+
+	codes := &codeStub{}
+	u := NewUsecase(mockRepo, nil, codes, nil, nil, "https://some.com/", 5, AliasPolicy{}, CanonicalPolicy{})
+	result, err := u.Run(context.Background(), In{OriginalURL: "https://some.com/"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://some.com/code2", result.Shorted)
+	assert.Equal(t, []int{0, 1}, codes.attempts)
+	assert.Equal(t, [2]int{2, 1}, codes.observed)
+
+	u = NewUsecase(mockRepo, nil, &codeStub{err: errors.New("db is down")}, nil, nil, "https://some.com/", 5, AliasPolicy{}, CanonicalPolicy{})
+	_, err = u.Run(context.Background(), In{OriginalURL: "https://some.com/"})
+	assert.ErrorIs(t, err, ErrShortURLGeneration)
+}
+
+func TestCodeGenerationLimits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("attempts are exhausted", func(t *testing.T) {
+		mockRepo := mockstorage.NewMockURLRepository(ctrl)
+		mockRepo.EXPECT().
+			PutURLPair(gomock.Any(), gomock.AssignableToTypeOf(model.URLPair{})).
+			Return(&model.URLPair{}, rep.ErrShortedURLExist).
+			Times(3)
+
+		codes := &codeStub{}
+		u := NewUsecase(mockRepo, nil, codes, nil, nil, "https://some.com/", 3, AliasPolicy{}, CanonicalPolicy{})
+		result, err := u.Run(context.Background(), In{OriginalURL: "https://some.com/"})
+		assert.ErrorIs(t, err, ErrShortURLGeneration)
+		assert.Nil(t, result)
+		assert.Equal(t, [2]int{3, 3}, codes.observed)
+	})
+
+	t.Run("context is canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		mockRepo := mockstorage.NewMockURLRepository(ctrl)
+		mockRepo.EXPECT().
+			PutURLPair(gomock.Any(), gomock.AssignableToTypeOf(model.URLPair{})).
+			DoAndReturn(func(_ context.Context, _ model.URLPair) (*model.URLPair, error) {
+				cancel()
+				return &model.URLPair{}, rep.ErrShortedURLExist
+			})
+
+		u := NewUsecase(mockRepo, nil, &codeStub{}, nil, nil, "https://some.com/", 3, AliasPolicy{}, CanonicalPolicy{})
+		_, err := u.Run(ctx, In{OriginalURL: "https://some.com/"})
+		assert.ErrorIs(t, err, ErrShortURLGeneration)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestPasswordHash(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var stored model.URLPair
+	mockRepo := mockstorage.NewMockURLRepository(ctrl)
+	mockRepo.EXPECT().
+		PutURLPair(gomock.Any(), gomock.AssignableToTypeOf(model.URLPair{})).
+		DoAndReturn(func(_ context.Context, in model.URLPair) (*model.URLPair, error) {
+			stored = in
+			return &in, nil
+		})
+
+	u := NewUsecase(mockRepo, nil, &codeStub{}, nil, nil, "https://some.com/", 5, AliasPolicy{}, CanonicalPolicy{})
+	_, err := u.Run(context.Background(), In{OriginalURL: "https://some.com/asdasd", Password: "secret"})
+	require.NoError(t, err)
+
+	assert.True(t, stored.IsProtected())
+	assert.NotContains(t, stored.PasswordHash, "secret")
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(stored.PasswordHash), []byte("secret")))
+}
+
+func TestRunBlockedURL(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// blocklisted destinations never reach the storage
+	mockRepo := mockstorage.NewMockURLRepository(ctrl)
+	u := NewUsecase(mockRepo, nil, &codeStub{}, servicetest.BlockedHosts{"evil.com"}, nil, "https://some.com/", 5, AliasPolicy{}, CanonicalPolicy{})
+
+	_, err := u.Run(context.Background(), In{OriginalURL: "https://evil.com/login"})
+	assert.ErrorIs(t, err, ErrBlockedURL)
+
+	results, err := u.RunBatch(context.Background(), []In{{OriginalURL: "https://evil.com/login"}})
+	require.NoError(t, err)
+	assert.ErrorIs(t, results[0].Err, ErrBlockedURL)
+}
+
+// prefetched collects the links passed for prefetching
+type prefetched []string
+
+func (p *prefetched) Prefetch(pair model.URLPair) {
+	*p = append(*p, pair.Shorted)
+}
+
+func TestRunPrefetchesNewLinks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mockstorage.NewMockURLRepository(ctrl)
+	mockRepo.EXPECT().
+		PutURLPair(gomock.Any(), gomock.AssignableToTypeOf(model.URLPair{})).
+		DoAndReturn(func(_ context.Context, in model.URLPair) (*model.URLPair, error) {
+			return &in, nil
+		})
+	mockRepo.EXPECT().
+		PutURLPair(gomock.Any(), gomock.AssignableToTypeOf(model.URLPair{})).
+		Return(&model.URLPair{Original: "https://some.com/asdasd", Shorted: "existing"}, rep.ErrOriginalURLExist)
+	mockRepo.EXPECT().
+		PutURLPairs(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, in []model.URLPair) ([]*model.URLPair, error) {
+			return []*model.URLPair{&in[0], {Original: in[1].Original, Shorted: "existing"}}, nil
+		})
+
+	var previews prefetched
+	u := NewUsecase(mockRepo, nil, &codeStub{}, nil, &previews, "https://some.com/", 5, AliasPolicy{}, CanonicalPolicy{})
+
+	_, err := u.Run(context.Background(), In{OriginalURL: "https://some.com/new"})
+	require.NoError(t, err)
+	_, err = u.Run(context.Background(), In{OriginalURL: "https://some.com/asdasd"})
+	require.NoError(t, err)
+	_, err = u.RunBatch(context.Background(), []In{{OriginalURL: "https://some.com/1"}, {OriginalURL: "https://some.com/asdasd"}})
+	require.NoError(t, err)
+
+	// the existing links have their metadata fetched already
+	assert.Equal(t, prefetched{"code1", "code3"}, previews)
+}
+
+func TestDedupKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name     string
+		req      In
+		expected string
+	}{
+		{
+			name:     "plain link is deduplicated",
+			req:      In{OriginalURL: "https://some.com/asdasd"},
+			expected: "https://some.com/asdasd",
+		},
+		{
+			name:     "plain link is deduplicated by the canonical URL",
+			req:      In{OriginalURL: "HTTPS://Some.com:443/asdasd?b=1&a=2&utm_source=mail"},
+			expected: "https://some.com/asdasd?a=2&b=1",
+		},
+		{
+			name:     "plain link is deduplicated per owner",
+			req:      In{OriginalURL: "https://some.com/asdasd", OwnerID: "team-a"},
+			expected: "team-a|https://some.com/asdasd",
+		},
+		{
+			name: "link with alias is not deduplicated",
+			req:  In{OriginalURL: "https://some.com/asdasd", Alias: "spring-sale"},
+		},
+		{
+			name: "link with TTL is not deduplicated",
+			req:  In{OriginalURL: "https://some.com/asdasd", TTL: time.Hour},
+		},
+		{
+			name: "link with expiration date is not deduplicated",
+			req:  In{OriginalURL: "https://some.com/asdasd", ExpiresAt: time.Now().Add(time.Hour)},
+		},
+		{
+			name: "link with password is not deduplicated",
+			req:  In{OriginalURL: "https://some.com/asdasd", Password: "secret"},
+		},
+		{
+			name: "link with click limit is not deduplicated",
+			req:  In{OriginalURL: "https://some.com/asdasd", MaxClicks: 1},
+		},
+		{
+			name: "link with redirect status is not deduplicated",
+			req:  In{OriginalURL: "https://some.com/asdasd", RedirectStatus: 307},
+		},
+		{
+			name: "link with interstitial is not deduplicated",
+			req:  In{OriginalURL: "https://some.com/asdasd", Interstitial: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stored model.URLPair
+			mockRepo := mockstorage.NewMockURLRepository(ctrl)
+			mockRepo.EXPECT().
+				PutURLPair(gomock.Any(), gomock.AssignableToTypeOf(model.URLPair{})).
+				DoAndReturn(func(_ context.Context, in model.URLPair) (*model.URLPair, error) {
+					stored = in
+					return &in, nil
+				})
+
+			u := NewUsecase(mockRepo, nil, &codeStub{}, nil, nil, "https://some.com/", 5,
+				AliasPolicy{MinLength: 3, MaxLength: 30}, CanonicalPolicy{StripTracking: true})
+			_, err := u.Run(context.Background(), tt.req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, stored.DedupKey)
+		})
+	}
+}
+
+func TestRedirectRules(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	from := time.Date(2025, 7, 15, 0, 0, 0, 0, time.UTC)
+
+	var stored model.URLPair
+	mockRepo := mockstorage.NewMockURLRepository(ctrl)
+	mockRepo.EXPECT().
+		PutURLPair(gomock.Any(), gomock.AssignableToTypeOf(model.URLPair{})).
+		DoAndReturn(func(_ context.Context, in model.URLPair) (*model.URLPair, error) {
+			stored = in
+			return &in, nil
+		})
+
+	u := NewUsecase(mockRepo, nil, &codeStub{}, servicetest.BlockedHosts{"evil.com"}, nil, "https://some.com/", 5, AliasPolicy{}, CanonicalPolicy{})
+	_, err := u.Run(context.Background(), In{
+		OriginalURL: "https://some.com/asdasd",
+		Rules: []model.RedirectRule{
+			{Target: "https://apps.apple.com/app/some", Devices: []string{"iOS"}, Languages: []string{"pt-BR"}, Countries: []string{"br"}},
+		},
+	})
+	require.NoError(t, err)
+
+	// conditions are normalized and links with rules are never deduplicated
+	assert.Equal(t, []model.RedirectRule{
+		{Target: "https://apps.apple.com/app/some", Devices: []string{"ios"}, Languages: []string{"pt-br"}, Countries: []string{"BR"}},
+	}, stored.Rules)
+	assert.Empty(t, stored.DedupKey)
+
+	tests := []struct {
+		name          string
+		rule          model.RedirectRule
+		expectedError error
+	}{
+		{
+			name:          "relative target",
+			rule:          model.RedirectRule{Target: "/somewhere"},
+			expectedError: ErrInvalidRules,
+		},
+		{
+			name:          "unknown device",
+			rule:          model.RedirectRule{Target: "https://some.com/tv", Devices: []string{"tv"}},
+			expectedError: ErrInvalidRules,
+		},
+		{
+			name:          "empty time window",
+			rule:          model.RedirectRule{Target: "https://some.com/sale", From: from, Until: from},
+			expectedError: ErrInvalidRules,
+		},
+		{
+			name:          "blocklisted target",
+			rule:          model.RedirectRule{Target: "https://evil.com/login"},
+			expectedError: ErrBlockedURL,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := u.Run(context.Background(), In{OriginalURL: "https://some.com/asdasd", Rules: []model.RedirectRule{tt.rule}})
+			assert.ErrorIs(t, err, tt.expectedError)
+		})
+	}
+}
+
+func TestSplitTargets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var stored model.URLPair
+	mockRepo := mockstorage.NewMockURLRepository(ctrl)
+	mockRepo.EXPECT().
+		PutURLPair(gomock.Any(), gomock.AssignableToTypeOf(model.URLPair{})).
+		DoAndReturn(func(_ context.Context, in model.URLPair) (*model.URLPair, error) {
+			stored = in
+			return &in, nil
+		})
+
+	u := NewUsecase(mockRepo, nil, &codeStub{}, servicetest.BlockedHosts{"evil.com"}, nil, "https://some.com/", 5, AliasPolicy{}, CanonicalPolicy{})
+	_, err := u.Run(context.Background(), In{
+		OriginalURL: "https://some.com/asdasd",
+		Targets: []model.SplitTarget{
+			{URL: "https://some.com/a", Weight: 70},
+			{Variant: "new-landing", URL: "https://some.com/new", Weight: 30},
+			{URL: "https://some.com/old", Weight: 0},
+		},
+	})
+	require.NoError(t, err)
+
+	// unnamed variants are named by position and links with targets are never deduplicated
+	assert.Equal(t, []model.SplitTarget{
+		{Variant: "a", URL: "https://some.com/a", Weight: 70},
+		{Variant: "new-landing", URL: "https://some.com/new", Weight: 30},
+		{Variant: "c", URL: "https://some.com/old", Weight: 0},
+	}, stored.Targets)
+	assert.Empty(t, stored.DedupKey)
+
+	tests := []struct {
+		name          string
+		targets       []model.SplitTarget
+		expectedError error
+	}{
+		{
+			name:          "relative URL",
+			targets:       []model.SplitTarget{{URL: "/somewhere", Weight: 1}},
+			expectedError: ErrInvalidTargets,
+		},
+		{
+			name:          "negative weight",
+			targets:       []model.SplitTarget{{URL: "https://some.com/a", Weight: 2}, {URL: "https://some.com/b", Weight: -1}},
+			expectedError: ErrInvalidTargets,
+		},
+		{
+			name:          "no positive weight",
+			targets:       []model.SplitTarget{{URL: "https://some.com/a"}, {URL: "https://some.com/b"}},
+			expectedError: ErrInvalidTargets,
+		},
+		{
+			name: "duplicate variant",
+			targets: []model.SplitTarget{
+				{URL: "https://some.com/a", Weight: 1},
+				{Variant: "a", URL: "https://some.com/b", Weight: 1},
+			},
+			expectedError: ErrInvalidTargets,
+		},
+		{
+			name:          "invalid variant",
+			targets:       []model.SplitTarget{{Variant: "new landing", URL: "https://some.com/a", Weight: 1}},
+			expectedError: ErrInvalidTargets,
+		},
+		{
+			name:          "blocklisted URL",
+			targets:       []model.SplitTarget{{URL: "https://evil.com/login", Weight: 1}},
+			expectedError: ErrBlockedURL,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := u.Run(context.Background(), In{OriginalURL: "https://some.com/asdasd", Targets: tt.targets})
+			assert.ErrorIs(t, err, tt.expectedError)
+		})
+	}
+}
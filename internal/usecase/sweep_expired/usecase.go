@@ -0,0 +1,38 @@
+package sweep_expired
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"link-shortener-service/internal/usecase/contract/repository"
+)
+
+var (
+	ErrSweep = errors.New("failed to delete expired URLPairs")
+)
+
+type usecase struct {
+	repo repository.URLRepository
+	now  func() time.Time
+}
+
+func NewUsecase(repo repository.URLRepository) *usecase {
+	return &usecase{
+		repo: repo,
+		now:  time.Now,
+	}
+}
+
+func (u *usecase) Run(ctx context.Context) error {
+	deleted, err := u.repo.DeleteExpired(ctx, u.now())
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrSweep, err)
+	}
+	if deleted > 0 {
+		log.Printf("deleted %d expired URL pairs", deleted)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package sweep_expired
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	mockstorage "link-shortener-service/internal/usecase/contract/repository/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Now()
+
+	tests := []struct {
+		name          string
+		setupMock     func(*mockstorage.MockURLRepository)
+		expectedError error
+	}{
+		{
+			name: "successful sweep",
+			setupMock: func(mockRepo *mockstorage.MockURLRepository) {
+				mockRepo.EXPECT().
+					DeleteExpired(gomock.Any(), now).
+					Return(int64(5), nil)
+			},
+		},
+		{
+			name: "error from storage",
+			setupMock: func(mockRepo *mockstorage.MockURLRepository) {
+				mockRepo.EXPECT().
+					DeleteExpired(gomock.Any(), now).
+					Return(int64(0), errors.New("db is down"))
+			},
+			expectedError: ErrSweep,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mockstorage.NewMockURLRepository(ctrl)
+			tt.setupMock(mockRepo)
+
+			u := NewUsecase(mockRepo)
+			u.now = func() time.Time { return now }
+
+			err := u.Run(context.Background())
+			assert.ErrorIs(t, err, tt.expectedError)
+		})
+	}
+}
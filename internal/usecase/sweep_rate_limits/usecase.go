@@ -0,0 +1,41 @@
+package sweep_rate_limits
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"link-shortener-service/internal/usecase/contract/repository"
+)
+
+var (
+	ErrSweep = errors.New("failed to delete idle rate limit buckets")
+)
+
+type usecase struct {
+	repo repository.RateLimitRepository
+	// idle is how long a bucket takes to refill, an idle bucket is as good as a missing one
+	idle time.Duration
+	now  func() time.Time
+}
+
+func NewUsecase(repo repository.RateLimitRepository, idle time.Duration) *usecase {
+	return &usecase{
+		repo: repo,
+		idle: idle,
+		now:  time.Now,
+	}
+}
+
+func (u *usecase) Run(ctx context.Context) error {
+	deleted, err := u.repo.DeleteIdleBuckets(ctx, u.now().Add(-u.idle))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrSweep, err)
+	}
+	if deleted > 0 {
+		log.Printf("deleted %d idle rate limit buckets", deleted)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package sweep_rate_limits
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	mockstorage "link-shortener-service/internal/usecase/contract/repository/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Now()
+	idle := 20 * time.Second
+
+	tests := []struct {
+		name          string
+		setupMock     func(*mockstorage.MockRateLimitRepository)
+		expectedError error
+	}{
+		{
+			name: "successful sweep",
+			setupMock: func(mockRepo *mockstorage.MockRateLimitRepository) {
+				mockRepo.EXPECT().
+					DeleteIdleBuckets(gomock.Any(), now.Add(-idle)).
+					Return(int64(3), nil)
+			},
+		},
+		{
+			name: "error from storage",
+			setupMock: func(mockRepo *mockstorage.MockRateLimitRepository) {
+				mockRepo.EXPECT().
+					DeleteIdleBuckets(gomock.Any(), now.Add(-idle)).
+					Return(int64(0), errors.New("db is down"))
+			},
+			expectedError: ErrSweep,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mockstorage.NewMockRateLimitRepository(ctrl)
+			tt.setupMock(mockRepo)
+
+			u := NewUsecase(mockRepo, idle)
+			u.now = func() time.Time { return now }
+
+			err := u.Run(context.Background())
+			assert.ErrorIs(t, err, tt.expectedError)
+		})
+	}
+}
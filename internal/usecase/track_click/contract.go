@@ -0,0 +1,11 @@
+package track_click
+
+import "time"
+
+type Policy struct {
+	// QueueSize bounds the clicks waiting to be written, clicks over it are dropped
+	QueueSize int
+	// BatchSize clicks are written at once, smaller batches are flushed every FlushInterval
+	BatchSize     int
+	FlushInterval time.Duration
+}
@@ -0,0 +1,98 @@
+package track_click
+
+import (
+	"context"
+	"expvar"
+	"log"
+	"time"
+
+	"link-shortener-service/internal/model"
+	"link-shortener-service/internal/usecase/contract/repository"
+)
+
+// flushTimeout bounds writing the clicks left in the queue on shutdown
+const flushTimeout = 5 * time.Second
+
+var (
+	clicksDropped = expvar.NewInt("clicks_dropped")
+	clicksFailed  = expvar.NewInt("clicks_failed")
+	clicksWritten = expvar.NewInt("clicks_written")
+)
+
+type usecase struct {
+	repo   repository.ClickRepository
+	queue  chan model.Click
+	policy Policy
+}
+
+func NewUsecase(repo repository.ClickRepository, policy Policy) *usecase {
+	return &usecase{
+		repo:   repo,
+		queue:  make(chan model.Click, policy.QueueSize),
+		policy: policy,
+	}
+}
+
+// Track enqueues the click without blocking, the click is dropped when the queue is full
+func (u *usecase) Track(click model.Click) {
+	select {
+	case u.queue <- click:
+	default:
+		clicksDropped.Add(1)
+	}
+}
+
+// Run writes queued clicks in batches until the context is cancelled,
+// then writes whatever is left in the queue
+func (u *usecase) Run(ctx context.Context) {
+	ticker := time.NewTicker(u.policy.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]model.Click, 0, u.policy.BatchSize)
+	for {
+		select {
+		case <-ctx.Done():
+			u.drain(batch)
+			return
+		case click := <-u.queue:
+			batch = append(batch, click)
+			if len(batch) >= u.policy.BatchSize {
+				batch = u.flush(ctx, batch)
+			}
+		case <-ticker.C:
+			batch = u.flush(ctx, batch)
+		}
+	}
+}
+
+func (u *usecase) drain(batch []model.Click) {
+	ctx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+	defer cancel()
+
+	for {
+		select {
+		case click := <-u.queue:
+			batch = append(batch, click)
+			if len(batch) >= u.policy.BatchSize {
+				batch = u.flush(ctx, batch)
+			}
+		default:
+			u.flush(ctx, batch)
+			return
+		}
+	}
+}
+
+func (u *usecase) flush(ctx context.Context, batch []model.Click) []model.Click {
+	if len(batch) == 0 {
+		return batch
+	}
+	if err := u.repo.SaveClicks(ctx, batch); err != nil {
+		clicksFailed.Add(int64(len(batch)))
+		log.Printf("failed to write %d clicks: %v", len(batch), err)
+	} else {
+		clicksWritten.Add(int64(len(batch)))
+	}
+	// the repository may keep the slice, so the next batch gets a fresh one
+	return make([]model.Click, 0, u.policy.BatchSize)
+}
@@ -0,0 +1,134 @@
+package track_click
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"link-shortener-service/internal/model"
+	mockstorage "link-shortener-service/internal/usecase/contract/repository/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrackDropsWhenQueueIsFull(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	u := NewUsecase(mockstorage.NewMockClickRepository(ctrl), Policy{QueueSize: 2, BatchSize: 10, FlushInterval: time.Hour})
+
+	dropped := clicksDropped.Value()
+	for range 5 {
+		u.Track(model.Click{Code: "xHsvC_0NTU"})
+	}
+
+	assert.Len(t, u.queue, 2)
+	assert.Equal(t, dropped+3, clicksDropped.Value())
+}
+
+func TestRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name      string
+		policy    Policy
+		clicks    int
+		setupMock func(*mockstorage.MockClickRepository, chan<- []model.Click)
+		// wait returns once the expected batches are written before the writer is stopped
+		wait func(<-chan []model.Click) [][]model.Click
+		// expected batch sizes, including the ones written on shutdown
+		expected []int
+	}{
+		{
+			name:   "full batches are written at once",
+			policy: Policy{QueueSize: 10, BatchSize: 2, FlushInterval: time.Hour},
+			clicks: 5,
+			setupMock: func(mockRepo *mockstorage.MockClickRepository, written chan<- []model.Click) {
+				mockRepo.EXPECT().
+					SaveClicks(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, clicks []model.Click) error {
+						written <- clicks
+						return nil
+					}).
+					Times(3)
+			},
+			wait: func(written <-chan []model.Click) [][]model.Click {
+				return [][]model.Click{<-written, <-written}
+			},
+			expected: []int{2, 2, 1},
+		},
+		{
+			name:   "incomplete batch is written on tick",
+			policy: Policy{QueueSize: 10, BatchSize: 100, FlushInterval: 10 * time.Millisecond},
+			clicks: 3,
+			setupMock: func(mockRepo *mockstorage.MockClickRepository, written chan<- []model.Click) {
+				mockRepo.EXPECT().
+					SaveClicks(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, clicks []model.Click) error {
+						written <- clicks
+						return nil
+					})
+			},
+			wait: func(written <-chan []model.Click) [][]model.Click {
+				return [][]model.Click{<-written}
+			},
+			expected: []int{3},
+		},
+		{
+			name:   "failed batch is not retried",
+			policy: Policy{QueueSize: 10, BatchSize: 1, FlushInterval: time.Hour},
+			clicks: 1,
+			setupMock: func(mockRepo *mockstorage.MockClickRepository, written chan<- []model.Click) {
+				mockRepo.EXPECT().
+					SaveClicks(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, clicks []model.Click) error {
+						written <- clicks
+						return errors.New("db is down")
+					})
+			},
+			wait: func(written <-chan []model.Click) [][]model.Click {
+				return [][]model.Click{<-written}
+			},
+			expected: []int{1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mockstorage.NewMockClickRepository(ctrl)
+			written := make(chan []model.Click, 10)
+			tt.setupMock(mockRepo, written)
+
+			u := NewUsecase(mockRepo, tt.policy)
+			for range tt.clicks {
+				u.Track(model.Click{Code: "xHsvC_0NTU"})
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			var wg sync.WaitGroup
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				u.Run(ctx)
+			}()
+
+			batches := tt.wait(written)
+			cancel()
+			wg.Wait()
+			close(written)
+			for batch := range written {
+				batches = append(batches, batch)
+			}
+
+			var sizes []int
+			for _, batch := range batches {
+				sizes = append(sizes, len(batch))
+			}
+			assert.Equal(t, tt.expected, sizes)
+		})
+	}
+}
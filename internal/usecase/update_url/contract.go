@@ -0,0 +1,9 @@
+package update_url
+
+import "link-shortener-service/internal/model"
+
+type In struct {
+	Principal   model.Principal
+	ShortedURL  string
+	OriginalURL string
+}
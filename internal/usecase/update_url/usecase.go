@@ -0,0 +1,66 @@
+package update_url
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	"link-shortener-service/internal/model"
+	"link-shortener-service/internal/usecase/contract/repository"
+	"link-shortener-service/internal/usecase/contract/service"
+)
+
+const shortURLColumnName = "shorted_url"
+
+var (
+	ErrURLNotFound  = errors.New("URLPair not found")
+	ErrURLRetrieval = errors.New("failed to retrieve URLPair")
+	ErrForbidden    = errors.New("URLPair belongs to another owner")
+	ErrURLUpdate    = errors.New("failed to update URLPair")
+	ErrBlockedURL   = errors.New("original URL is blocklisted")
+)
+
+type usecase struct {
+	repo        repository.URLRepository
+	screener    service.Screener
+	leftURLPart string
+}
+
+func NewUsecase(repo repository.URLRepository, screener service.Screener, leftURLPart string) *usecase {
+	return &usecase{
+		repo:        repo,
+		screener:    screener,
+		leftURLPart: leftURLPart,
+	}
+}
+
+func (u *usecase) Run(ctx context.Context, req In) (*model.URLPair, error) {
+	pair, err := u.repo.GetByURL(ctx, shortURLColumnName, req.ShortedURL)
+	if err != nil {
+		if errors.Is(err, rep.ErrNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrURLNotFound, req.ShortedURL)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrURLRetrieval, err)
+	}
+	if !req.Principal.CanManage(pair) {
+		return nil, fmt.Errorf("%w: %s", ErrForbidden, req.ShortedURL)
+	}
+	// a link is screened when it is pointed somewhere else just like when it is created
+	if u.screener != nil {
+		if err = u.screener.Check(req.OriginalURL); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrBlockedURL, err)
+		}
+	}
+
+	record, err := u.repo.UpdateOriginal(ctx, req.ShortedURL, req.OriginalURL)
+	if err != nil {
+		if errors.Is(err, rep.ErrNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrURLNotFound, req.ShortedURL)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrURLUpdate, err)
+	}
+
+	record.Shorted = model.ShortURL(u.leftURLPart, record.Shorted)
+	return record, nil
+}
@@ -0,0 +1,121 @@
+package update_url
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	rep "link-shortener-service/internal/infastracture/repository"
+	"link-shortener-service/internal/model"
+	mockstorage "link-shortener-service/internal/usecase/contract/repository/mocks"
+	"link-shortener-service/internal/usecase/contract/service/servicetest"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateURL(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	reqURL := In{
+		Principal:   model.Principal{OwnerID: "team-a"},
+		ShortedURL:  "xHsvC_0NTU",
+		OriginalURL: "https://other.com/",
+	}
+
+	tests := []struct {
+		name          string
+		setupMock     func(*mockstorage.MockURLRepository)
+		expected      *model.URLPair
+		expectedError error
+	}{
+		{
+			name: "successful update",
+			setupMock: func(mockRepo *mockstorage.MockURLRepository) {
+				mockRepo.EXPECT().
+					GetByURL(gomock.Any(), shortURLColumnName, "xHsvC_0NTU").
+					Return(&model.URLPair{Shorted: "xHsvC_0NTU", OwnerID: "team-a"}, nil)
+				mockRepo.EXPECT().
+					UpdateOriginal(gomock.Any(), "xHsvC_0NTU", "https://other.com/").
+					Return(&model.URLPair{Original: "https://other.com/", Shorted: "xHsvC_0NTU"}, nil)
+			},
+			expected: &model.URLPair{Original: "https://other.com/", Shorted: "https://some.com/xHsvC_0NTU"},
+		},
+		{
+			name: "unknown short URL",
+			setupMock: func(mockRepo *mockstorage.MockURLRepository) {
+				mockRepo.EXPECT().
+					GetByURL(gomock.Any(), shortURLColumnName, "xHsvC_0NTU").
+					Return(&model.URLPair{Shorted: "xHsvC_0NTU", OwnerID: "team-a"}, nil)
+				mockRepo.EXPECT().
+					UpdateOriginal(gomock.Any(), "xHsvC_0NTU", "https://other.com/").
+					Return(nil, rep.ErrNotFound)
+			},
+			expectedError: ErrURLNotFound,
+		},
+		{
+			name: "error from storage",
+			setupMock: func(mockRepo *mockstorage.MockURLRepository) {
+				mockRepo.EXPECT().
+					GetByURL(gomock.Any(), shortURLColumnName, "xHsvC_0NTU").
+					Return(&model.URLPair{Shorted: "xHsvC_0NTU", OwnerID: "team-a"}, nil)
+				mockRepo.EXPECT().
+					UpdateOriginal(gomock.Any(), "xHsvC_0NTU", "https://other.com/").
+					Return(nil, errors.New("db is down"))
+			},
+			expectedError: ErrURLUpdate,
+		},
+		{
+			name: "link of another owner",
+			setupMock: func(mockRepo *mockstorage.MockURLRepository) {
+				mockRepo.EXPECT().
+					GetByURL(gomock.Any(), shortURLColumnName, "xHsvC_0NTU").
+					Return(&model.URLPair{Shorted: "xHsvC_0NTU", OwnerID: "team-b"}, nil)
+			},
+			expectedError: ErrForbidden,
+		},
+		{
+			name: "unknown short URL on lookup",
+			setupMock: func(mockRepo *mockstorage.MockURLRepository) {
+				mockRepo.EXPECT().
+					GetByURL(gomock.Any(), shortURLColumnName, "xHsvC_0NTU").
+					Return(nil, rep.ErrNotFound)
+			},
+			expectedError: ErrURLNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mockstorage.NewMockURLRepository(ctrl)
+			tt.setupMock(mockRepo)
+
+			u := NewUsecase(mockRepo, servicetest.BlockedHosts{"evil.com"}, "https://some.com/")
+			result, err := u.Run(context.Background(), reqURL)
+
+			assert.ErrorIs(t, err, tt.expectedError)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestUpdateURLBlocked(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mockstorage.NewMockURLRepository(ctrl)
+	mockRepo.EXPECT().
+		GetByURL(gomock.Any(), shortURLColumnName, "xHsvC_0NTU").
+		Return(&model.URLPair{Shorted: "xHsvC_0NTU", OwnerID: "team-a"}, nil)
+
+	u := NewUsecase(mockRepo, servicetest.BlockedHosts{"evil.com"}, "https://some.com/")
+	result, err := u.Run(context.Background(), In{
+		Principal:   model.Principal{OwnerID: "team-a"},
+		ShortedURL:  "xHsvC_0NTU",
+		OriginalURL: "https://evil.com/login",
+	})
+
+	assert.ErrorIs(t, err, ErrBlockedURL)
+	assert.Nil(t, result)
+}
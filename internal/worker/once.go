@@ -0,0 +1,25 @@
+package worker
+
+import (
+	"context"
+	"log"
+)
+
+// Once runs a job a single time in the background, for the jobs catching up with data of older versions
+type Once struct {
+	name string
+	job  Job
+}
+
+func NewOnce(name string, job Job) *Once {
+	return &Once{
+		name: name,
+		job:  job,
+	}
+}
+
+func (o *Once) Run(ctx context.Context) {
+	if err := o.job(ctx); err != nil && ctx.Err() == nil {
+		log.Printf("%s failed: %v", o.name, err)
+	}
+}
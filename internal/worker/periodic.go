@@ -0,0 +1,50 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+type Worker interface {
+	Run(ctx context.Context)
+}
+
+type Job func(ctx context.Context) error
+
+// Periodic runs a job every interval until the context is cancelled
+type Periodic struct {
+	name     string
+	interval time.Duration
+	job      Job
+}
+
+func NewPeriodic(name string, interval time.Duration, job Job) *Periodic {
+	return &Periodic{
+		name:     name,
+		interval: interval,
+		job:      job,
+	}
+}
+
+func (p *Periodic) Run(ctx context.Context) {
+	// a ticker panics on a non-positive interval, such a job is never run
+	if p.interval <= 0 {
+		log.Printf("%s disabled: non-positive interval %s", p.name, p.interval)
+		return
+	}
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := p.job(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("%s failed: %v", p.name, err)
+			}
+		}
+	}
+}
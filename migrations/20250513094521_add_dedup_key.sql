@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS dedup_key TEXT;
+
+-- only plain links are deduplicated, an edited link may point to any URL
+UPDATE urls SET dedup_key = original_url
+WHERE deleted_at IS NULL
+  AND redirect_status = 0
+  AND expires_at IS NULL
+  AND max_clicks = 0
+  AND password_hash = '';
+
+DROP INDEX IF EXISTS urls_original_url_key;
+CREATE UNIQUE INDEX IF NOT EXISTS urls_dedup_key_key ON urls (dedup_key) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS urls_original_url_idx ON urls (original_url) WHERE deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS urls_dedup_key_key;
+-- codes may share a destination by now, so the original URL index is kept without uniqueness
+-- under the name the previous migration drops
+DROP INDEX IF EXISTS urls_original_url_idx;
+CREATE INDEX IF NOT EXISTS urls_original_url_key ON urls (original_url) WHERE deleted_at IS NULL;
+ALTER TABLE urls DROP COLUMN IF EXISTS dedup_key;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS clicks (
+    id              BIGSERIAL   PRIMARY KEY,
+    code            TEXT        NOT NULL,
+    clicked_at      TIMESTAMPTZ NOT NULL,
+    referrer        TEXT        NOT NULL DEFAULT '',
+    user_agent      TEXT        NOT NULL DEFAULT '',
+    ip              TEXT        NOT NULL DEFAULT '',
+    accept_language TEXT        NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS clicks_code_clicked_at_idx ON clicks (code, clicked_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS clicks;
+-- +goose StatementEnd
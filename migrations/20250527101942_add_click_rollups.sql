@@ -0,0 +1,48 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE clicks ADD COLUMN IF NOT EXISTS country   TEXT NOT NULL DEFAULT '';
+ALTER TABLE clicks ADD COLUMN IF NOT EXISTS ua_family TEXT NOT NULL DEFAULT '';
+ALTER TABLE clicks ADD COLUMN IF NOT EXISTS referrer_host TEXT NOT NULL DEFAULT '';
+
+-- clicks are rolled up into hourly buckets, daily and weekly stats are summed from them
+CREATE TABLE IF NOT EXISTS click_rollups (
+    code   TEXT        NOT NULL,
+    bucket TIMESTAMPTZ NOT NULL,
+    clicks BIGINT      NOT NULL,
+    PRIMARY KEY (code, bucket)
+);
+
+CREATE TABLE IF NOT EXISTS click_visitors (
+    code    TEXT        NOT NULL,
+    bucket  TIMESTAMPTZ NOT NULL,
+    visitor TEXT        NOT NULL,
+    PRIMARY KEY (code, bucket, visitor)
+);
+
+CREATE TABLE IF NOT EXISTS click_dimensions (
+    code      TEXT        NOT NULL,
+    bucket    TIMESTAMPTZ NOT NULL,
+    dimension TEXT        NOT NULL,
+    value     TEXT        NOT NULL,
+    clicks    BIGINT      NOT NULL,
+    PRIMARY KEY (code, bucket, dimension, value)
+);
+
+-- the id of the last click rolled up, a single row table
+CREATE TABLE IF NOT EXISTS click_rollup_state (
+    id            BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    last_click_id BIGINT  NOT NULL
+);
+INSERT INTO click_rollup_state (last_click_id) VALUES (0) ON CONFLICT DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS click_rollup_state;
+DROP TABLE IF EXISTS click_dimensions;
+DROP TABLE IF EXISTS click_visitors;
+DROP TABLE IF EXISTS click_rollups;
+ALTER TABLE clicks DROP COLUMN IF EXISTS referrer_host;
+ALTER TABLE clicks DROP COLUMN IF EXISTS ua_family;
+ALTER TABLE clicks DROP COLUMN IF EXISTS country;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS api_keys (
+    id         TEXT        PRIMARY KEY,
+    owner_id   TEXT        NOT NULL,
+    name       TEXT        NOT NULL DEFAULT '',
+    key_hash   TEXT        NOT NULL,
+    scopes     TEXT[]      NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    revoked_at TIMESTAMPTZ
+);
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS owner_id TEXT;
+CREATE INDEX IF NOT EXISTS urls_owner_id_idx ON urls (owner_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS urls_owner_id_idx;
+ALTER TABLE urls DROP COLUMN IF EXISTS owner_id;
+DROP TABLE IF EXISTS api_keys;
+-- +goose StatementEnd
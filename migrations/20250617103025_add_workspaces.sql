@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS workspaces (
+    id         TEXT        PRIMARY KEY,
+    name       TEXT        NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+-- a short domain belongs to a single workspace, links on it are stored as domain/code
+-- in shorted_url, so codes are unique per domain
+CREATE TABLE IF NOT EXISTS workspace_domains (
+    domain       TEXT PRIMARY KEY,
+    workspace_id TEXT NOT NULL REFERENCES workspaces (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS workspace_domains_workspace_id_idx ON workspace_domains (workspace_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS workspace_domains;
+DROP TABLE IF EXISTS workspaces;
+-- +goose StatementEnd
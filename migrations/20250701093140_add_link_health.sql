@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- the last check of the destination of every link, written by the dead link checker
+CREATE TABLE IF NOT EXISTS link_health (
+    shorted_url      TEXT        PRIMARY KEY,
+    status           INT         NOT NULL,
+    error            TEXT        NOT NULL DEFAULT '',
+    response_time_ms INT         NOT NULL,
+    redirects        TEXT[]      NOT NULL DEFAULT '{}',
+    broken           BOOLEAN     NOT NULL,
+    checked_at       TIMESTAMPTZ NOT NULL,
+    failures         INT         NOT NULL DEFAULT 0,
+    next_check_at    TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS link_health_next_check_at_idx ON link_health (next_check_at);
+CREATE INDEX IF NOT EXISTS link_health_broken_idx ON link_health (checked_at DESC) WHERE broken;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS link_health;
+-- +goose StatementEnd
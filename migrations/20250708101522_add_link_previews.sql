@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- the metadata of the destination of every link, original_url is the destination it was fetched from
+CREATE TABLE IF NOT EXISTS link_previews (
+    shorted_url   TEXT        PRIMARY KEY,
+    original_url  TEXT        NOT NULL,
+    title         TEXT        NOT NULL DEFAULT '',
+    description   TEXT        NOT NULL DEFAULT '',
+    favicon       TEXT        NOT NULL DEFAULT '',
+    image         TEXT        NOT NULL DEFAULT '',
+    fetched_at    TIMESTAMPTZ,
+    error         TEXT        NOT NULL DEFAULT '',
+    failures      INT         NOT NULL DEFAULT 0,
+    next_fetch_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS link_previews_next_fetch_at_idx ON link_previews (next_fetch_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS link_previews;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- the ordered conditional redirect rules of the link, null for links redirecting to original_url only
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS redirect_rules JSONB;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE urls DROP COLUMN IF EXISTS redirect_rules;
+-- +goose StatementEnd
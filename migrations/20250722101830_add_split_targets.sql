@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- the weighted destinations of A/B split links in their order, links without targets have no rows
+CREATE TABLE IF NOT EXISTS link_targets (
+    shorted_url TEXT NOT NULL REFERENCES urls (shorted_url) ON DELETE CASCADE,
+    ordinal     INT  NOT NULL,
+    variant     TEXT NOT NULL,
+    url         TEXT NOT NULL,
+    weight      INT  NOT NULL CHECK (weight >= 0),
+    PRIMARY KEY (shorted_url, ordinal),
+    UNIQUE (shorted_url, variant)
+);
+-- the split target every click was sent to, empty for links without targets
+ALTER TABLE clicks ADD COLUMN IF NOT EXISTS variant TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE clicks DROP COLUMN IF EXISTS variant;
+DROP TABLE IF EXISTS link_targets;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- links with an interstitial show the destination with a countdown before redirecting
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS interstitial BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE urls DROP COLUMN IF EXISTS interstitial;
+-- +goose StatementEnd